@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnvBool(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		fallback bool
+		expected bool
+	}{
+		{"Unset uses fallback", "", true, true},
+		{"True value", "true", false, true},
+		{"False value", "false", true, false},
+		{"Numeric value", "1", false, true},
+		{"Garbage uses fallback", "maybe", false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TEST_ENV_BOOL", tc.value)
+
+			if got := envBool("TEST_ENV_BOOL", tc.fallback); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestEnvInt(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		fallback int
+		expected int
+	}{
+		{"Unset uses fallback", "", 7, 7},
+		{"Valid value", "42", 7, 42},
+		{"Garbage uses fallback", "lots", 7, 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TEST_ENV_INT", tc.value)
+
+			if got := envInt("TEST_ENV_INT", tc.fallback); got != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestEnvList(t *testing.T) {
+	t.Setenv("TEST_ENV_LIST", " admin@example.com, ,ops@example.com ")
+
+	got := envList("TEST_ENV_LIST")
+
+	if len(got) != 2 || got[0] != "admin@example.com" || got[1] != "ops@example.com" {
+		t.Errorf("Expected [admin@example.com ops@example.com], got %v", got)
+	}
+}
+
+func TestEnvDuration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		fallback time.Duration
+		expected time.Duration
+	}{
+		{"Unset uses fallback", "", time.Hour, time.Hour},
+		{"Hours", "168h", time.Hour, 168 * time.Hour},
+		{"Minutes", "30m", time.Hour, 30 * time.Minute},
+		{"Zero uses fallback", "0s", time.Hour, time.Hour},
+		{"Garbage uses fallback", "a week", time.Hour, time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TEST_ENV_DURATION", tc.value)
+
+			if got := envDuration("TEST_ENV_DURATION", tc.fallback); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
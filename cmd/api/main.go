@@ -1,21 +1,33 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/handlers"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/middleware"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/migrate"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/readability"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/reconcile"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/scraper"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
+	"github.com/mehmettalhairmak/rss-aggregator/sql/schema"
 
 	_ "github.com/mehmettalhairmak/rss-aggregator/docs" // docs is generated by Swag CLI
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -60,9 +72,8 @@ func main() {
 		logger.Fatal("$DB_URL environment variable must be set")
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		logger.Fatal("$JWT_SECRET environment variable must be set")
+	if err := auth.CheckSigningConfig(); err != nil {
+		logger.Fatalf("Invalid JWT configuration: %v", err)
 	}
 
 	logger.Infof("Starting RSS Aggregator API on port %s", portString)
@@ -81,14 +92,84 @@ func main() {
 	}()
 	logger.Info("Successfully connected to database")
 
-	// Run Hub
-	hub := realtime.NewHub(log)
-	go hub.Run()
+	// Apply pending migrations before serving when RUN_MIGRATIONS=true
+	if envBool("RUN_MIGRATIONS", false) {
+		applied, err := migrate.Up(context.Background(), conn, schema.FS, log)
+		if err != nil {
+			logger.ErrorErr(err, "Failed to run database migrations")
+			os.Exit(1)
+		}
+		logger.Infof("Database migrations up to date (%d applied)", len(applied))
+	}
+
+	// Run Hub unless realtime is disabled (REALTIME_ENABLED=false)
+	// Without a Hub, WebSocket connections are refused and no signals are sent
+	var hub *realtime.Hub
+	if envBool("REALTIME_ENABLED", true) {
+		hub = realtime.NewHubWithBuffer(log, envInt("WS_BUFFER_SIZE", realtime.DefaultBufferSize))
+		hub.MaxConnections = envInt("WS_MAX_CONNECTIONS", 0)
+		hub.MaxConnectionsPerUser = envInt("WS_MAX_CONNECTIONS_PER_USER", 0)
+		go hub.Run()
+	} else {
+		logger.Warn("Realtime updates are disabled")
+	}
+
+	// Feed names and scraped post titles share one length limit
+	maxTitleBytes := envInt("MAX_TITLE_BYTES", textutil.DefaultMaxTitleBytes)
 
 	// Create database queries and handler configs
 	dbQueries := database.New(conn)
 	handlerConfig := handlers.NewConfig(dbQueries, conn, log, hub)
+	handlerConfig.PostsAfterFollowOnly = envBool("POSTS_AFTER_FOLLOW_ONLY", false)
+	handlerConfig.PublicFeedMaxPages = envInt("PUBLIC_FEED_MAX_PAGES", 5)
+	handlerConfig.MaxFeedFollowsPerUser = envInt("MAX_FEED_FOLLOWS_PER_USER", 0)
+	handlerConfig.MaxTitleBytes = maxTitleBytes
+	handlerConfig.UniqueFeedNamesPerUser = envBool("UNIQUE_FEED_NAMES_PER_USER", false)
+	handlerConfig.FeedDomainAllowlist = envList("FEED_DOMAIN_ALLOWLIST")
+	handlerConfig.FeedDomainBlocklist = envList("FEED_DOMAIN_BLOCKLIST")
+	handlerConfig.BlockPrivateFeedHosts = envBool("FEED_BLOCK_PRIVATE_HOSTS", true)
+	handlerConfig.FeedPreviewMaxItems = envInt("FEED_PREVIEW_MAX_ITEMS", 10)
+
+	// The scraper and user-submitted feeds share one fetcher, so connections are pooled across fetches
+	feedFetcher := feedfetch.NewGofeedFetcher()
+	feedFetcher.Timeout = envDuration("FEED_FETCH_TIMEOUT", feedfetch.DefaultTimeout)
+	feedFetcher.MaxRedirects = envInt("FEED_MAX_REDIRECTS", feedfetch.DefaultMaxRedirects)
+	// Redirects get the same private host check as submitted feed URLs
+	feedFetcher.CheckURL = handlerConfig.CheckFetchURL
+	handlerConfig.FeedFetcher = feedFetcher
+	handlerConfig.RefreshTokenTTL = envDuration("JWT_REFRESH_TTL", handlers.DefaultRefreshTokenTTL)
+	handlerConfig.RefreshTokenCookie = envBool("REFRESH_TOKEN_COOKIE_ENABLED", false)
+	refreshTokenBinding, err := handlers.ParseRefreshTokenBinding(os.Getenv("REFRESH_TOKEN_BIND"))
+	if err != nil {
+		logger.Fatalf("Invalid REFRESH_TOKEN_BIND: %v", err)
+	}
+	handlerConfig.RefreshTokenBinding = refreshTokenBinding
+	handlerConfig.MinPasswordLength = envInt("PASSWORD_MIN_LENGTH", handlers.DefaultMinPasswordLength)
+	handlerConfig.OAuthProviders = oauthProvidersFromEnv(portString)
+
+	// Optional in-process cache for users' post pages (POSTS_CACHE_ENABLED=true)
+	var postsCache *cache.PostsCache
+	if envBool("POSTS_CACHE_ENABLED", false) {
+		postsCache = cache.NewPostsCache(
+			envInt("POSTS_CACHE_SIZE", 1000),
+			time.Duration(envInt("POSTS_CACHE_TTL_SECONDS", 30))*time.Second,
+		)
+		handlerConfig.PostsCache = postsCache
+	}
+
+	// Fetched feed logos are kept in memory so /v1/feed/{feedID}/logo rarely hits the publisher
+	if size := envInt("FEED_LOGO_CACHE_SIZE", 500); size > 0 {
+		handlerConfig.FeedLogoCache = cache.NewLogosCache(size, envDuration("FEED_LOGO_CACHE_TTL", 24*time.Hour))
+	}
+	handlerConfig.FeedLogoMaxBytes = int64(envInt("FEED_LOGO_MAX_BYTES", 256<<10))
+
 	middlewareConfig := middleware.NewConfig(dbQueries)
+	middlewareConfig.AdminEmails = envList("ADMIN_EMAILS")
+	middlewareConfig.DegradedReadAuth = envBool("AUTH_DEGRADED_READS", false)
+	// Optional in-process cache of authenticated users, so requests skip GetUserByID (USER_CACHE_ENABLED=true)
+	if envBool("USER_CACHE_ENABLED", false) {
+		middlewareConfig.UserCache = cache.NewUsersCache(envInt("USER_CACHE_SIZE", 10000), envDuration("USER_CACHE_TTL", 30*time.Second))
+	}
 
 	// Initialize rate limiter
 	// Allow 60 requests per minute with burst size of 10
@@ -97,17 +178,48 @@ func main() {
 		BurstSize:         10,
 	})
 
+	// Stricter per-IP limit for public browsing endpoints that could be used for scraping
+	publicRateLimiter := middleware.NewIPRateLimiter(middleware.RateLimitConfig{
+		RequestsPerMinute: envInt("PUBLIC_RATE_LIMIT_PER_MINUTE", 20),
+		BurstSize:         5,
+	})
+
+	// Creating a feed fetches it, so each user may only create so many per hour (0 disables the limit)
+	createFeed := middleware.AuthedHandler(handlerConfig.HandlerCreateFeed)
+	if limit := envInt("FEED_CREATE_LIMIT_PER_HOUR", 20); limit > 0 {
+		createFeed = middleware.NewUserRateLimiter(limit, time.Hour).Limit(createFeed)
+	}
+
 	// Create Chi router
 	router := chi.NewRouter()
 
+	// "/v1/feed/" is served as "/v1/feed" unless TRAILING_SLASH_POLICY says otherwise;
+	// runs first so every other middleware sees the final path
+	router.Use(middleware.TrailingSlashes(os.Getenv("TRAILING_SLASH_POLICY"), "/v1/"))
+
+	// Count in-flight requests so shutdown can report the ones still running at its deadline
+	inFlight := &middleware.InFlight{}
+	router.Use(inFlight.Middleware)
+
 	// Add rate limiting middleware (applied to all routes)
 	router.Use(middleware.RateLimit)
 
+	// Cancel work for requests that outlive REQUEST_TIMEOUT_SECONDS or the client's X-Request-Timeout
+	router.Use(middleware.RequestTimeout(time.Duration(envInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second))
+
+	// Cap request bodies at MAX_REQUEST_BODY_BYTES; OPML imports get their own, larger limit
+	router.Use(middleware.MaxBodyBytes(int64(envInt("MAX_REQUEST_BODY_BYTES", 1<<20)), map[string]int64{
+		"/v1/feed_follows/import": int64(envInt("OPML_IMPORT_MAX_BYTES", 5<<20)),
+	}))
+
+	// Log redacted request/response bodies when LOG_BODIES=true (debug level only)
+	router.Use(middleware.LogBodies(envBool("LOG_BODIES", false)))
+
 	// Add CORS middleware
 	// CORS: Cross-Origin Resource Sharing - allows API requests from different domains
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
@@ -118,37 +230,85 @@ func main() {
 	// Using versioning - we can add v2 in the future
 	v1Router := chi.NewRouter()
 
+	// Answer 406 to clients whose Accept header rules out JSON when REQUIRE_JSON_ACCEPT=true
+	v1Router.Use(middleware.RequireJSONAccept(envBool("REQUIRE_JSON_ACCEPT", false)))
+
+	// Indent JSON responses of requests with ?pretty=true, by default only in development
+	env := os.Getenv("ENV")
+	v1Router.Use(middleware.PrettyJSON(envBool("PRETTY_JSON_ENABLED", env == "development" || env == "dev")))
+
 	// Health check endpoints
-	v1Router.Get("/ready", handlers.HandlerReadiness)
+	v1Router.Get("/live", handlers.HandlerLiveness)
+	v1Router.Get("/ready", handlerConfig.HandlerReadiness)
+	v1Router.With(publicRateLimiter.Middleware).Get("/stats", handlerConfig.HandlerGetStats)
 	v1Router.Get("/error", handlers.HandlerErr)
 
+	// Auth and user responses carry tokens and user data and must never be cached
+	noStoreRouter := v1Router.With(middleware.NoStore)
+
 	// Authentication endpoints (Public - no auth required)
 	// POST /v1/auth/register
 	// POST /v1/auth/login
-	v1Router.Post("/auth/register", handlerConfig.HandlerRegister)
-	v1Router.Post("/auth/login", handlerConfig.HandlerLogin)
-	v1Router.Post("/auth/refresh", handlerConfig.HandlerRefreshToken)
-	v1Router.Get("/auth/logout", middlewareConfig.Auth(handlerConfig.HandlerLogout))
+	noStoreRouter.Post("/auth/register", handlerConfig.HandlerRegister)
+	noStoreRouter.Post("/auth/login", handlerConfig.HandlerLogin)
+	noStoreRouter.Post("/auth/refresh", handlerConfig.HandlerRefreshToken)
+	noStoreRouter.Post("/auth/logout", middlewareConfig.Auth(handlerConfig.HandlerLogout))
+	noStoreRouter.Get("/auth/sessions", middlewareConfig.Auth(handlerConfig.HandlerListSessions))
+	noStoreRouter.Delete("/auth/sessions/{sessionID}", middlewareConfig.Auth(handlerConfig.HandlerRevokeSession))
+	noStoreRouter.Get("/auth/oauth/{provider}", handlerConfig.HandlerOAuthStart)
+	noStoreRouter.Get("/auth/oauth/{provider}/callback", handlerConfig.HandlerOAuthCallback)
 
 	// User endpoints (Protected - JWT required)
 	// GET /v1/users/me - Returns the authenticated user's information
-	v1Router.Get("/users/me", middlewareConfig.Auth(handlerConfig.HandlerGetUser))
+	noStoreRouter.Get("/users/me", middlewareConfig.Auth(handlerConfig.HandlerGetUser))
+	noStoreRouter.Get("/users/me/identities", middlewareConfig.Auth(handlerConfig.HandlerListIdentities))
+	noStoreRouter.Delete("/users/me/identities/{identityID}", middlewareConfig.Auth(handlerConfig.HandlerUnlinkIdentity))
 
 	// Feed endpoints
-	v1Router.Post("/feed", middlewareConfig.Auth(handlerConfig.HandlerCreateFeed))
-	v1Router.Get("/feed", handlerConfig.HandlerGetFeed)
+	v1Router.Post("/feed", middlewareConfig.Auth(createFeed))
+	v1Router.Get("/feed", middlewareConfig.OptionalAuth(handlerConfig.HandlerGetFeed))
+	v1Router.Get("/feed/preview", middlewareConfig.Auth(handlerConfig.HandlerPreviewFeed))
+	v1Router.Post("/feed/validate", middlewareConfig.Auth(handlerConfig.HandlerValidateFeed))
+	v1Router.Patch("/feed/{feedID}", middlewareConfig.Auth(handlerConfig.HandlerUpdateFeed))
+	v1Router.Delete("/feed/{feedID}", middlewareConfig.Auth(handlerConfig.HandlerDeleteFeed))
+	v1Router.Put("/feed/{feedID}/priority", middlewareConfig.Auth(handlerConfig.HandlerUpdateFeedPriority))
+	v1Router.Get("/feed/{feedID}/activity", handlerConfig.HandlerGetFeedActivity)
+	v1Router.With(publicRateLimiter.Middleware).Get("/feed/{feedID}/logo", handlerConfig.HandlerGetFeedLogo)
+	v1Router.Post("/feed/{feedID}/follow", middlewareConfig.Auth(handlerConfig.HandlerFollowFeed))
+	v1Router.With(publicRateLimiter.Middleware).Get("/feed/{feedID}/posts", middlewareConfig.OptionalAuth(handlerConfig.HandlerGetPostsByFeed))
 
 	// Feed follows endpoints
 	v1Router.Post("/feed_follows", middlewareConfig.Auth(handlerConfig.HandlerCreateFeedFollow))
 	v1Router.Get("/feed_follows", middlewareConfig.Auth(handlerConfig.HandlerGetFeedFollow))
+	v1Router.Post("/feed_follows/import", middlewareConfig.Auth(handlerConfig.HandlerImportOPML))
+	v1Router.Get("/feed_follows/unread-summary", middlewareConfig.Auth(handlerConfig.HandlerGetUnreadSummary))
+	v1Router.Patch("/feed_follows/{feedFollowID}", middlewareConfig.Auth(handlerConfig.HandlerUpdateFeedFollow))
 	v1Router.Delete("/feed_follows/{feedFollowID}", middlewareConfig.Auth(handlerConfig.HandlerDeleteFeedFollow))
 
 	// Posts endpoints
 	v1Router.Get("/posts", middlewareConfig.Auth(handlerConfig.HandlerGetUserPostsForUser))
+	v1Router.With(publicRateLimiter.Middleware).Get("/posts/trending", handlerConfig.HandlerGetTrendingPosts)
+	v1Router.Post("/posts/read", middlewareConfig.Auth(handlerConfig.HandlerMarkPostsRead))
+	v1Router.Post("/posts/{postID}/read", middlewareConfig.Auth(handlerConfig.HandlerMarkPostRead))
+	v1Router.Delete("/posts/{postID}/read", middlewareConfig.Auth(handlerConfig.HandlerUnmarkPostRead))
+	v1Router.Post("/posts/{postID}/bookmark", middlewareConfig.Auth(handlerConfig.HandlerCreateBookmark))
+	v1Router.Delete("/posts/{postID}/bookmark", middlewareConfig.Auth(handlerConfig.HandlerDeleteBookmark))
+	v1Router.Get("/bookmarks", middlewareConfig.Auth(handlerConfig.HandlerGetBookmarks))
+	v1Router.Get("/posts/{postID}/content", middlewareConfig.Auth(handlerConfig.HandlerGetPostContent))
+
+	// Notifications outbox: new post signals kept until acknowledged
+	v1Router.Get("/notifications", middlewareConfig.Auth(handlerConfig.HandlerListNotifications))
+	v1Router.Post("/notifications/ack", middlewareConfig.Auth(handlerConfig.HandlerAckNotifications))
 
 	// Websocket endpoints
 	v1Router.Get("/ws", middlewareConfig.Auth(handlerConfig.HandlerWebsocket))
 
+	// Admin maintenance endpoints (ADMIN_EMAILS only)
+	v1Router.Post("/admin/feeds/merge-duplicates", middlewareConfig.AdminOnly(handlerConfig.HandlerMergeDuplicateFeeds))
+	v1Router.Post("/admin/feeds/reconcile-follower-counts", middlewareConfig.AdminOnly(handlerConfig.HandlerReconcileFollowerCounts))
+	v1Router.Post("/admin/posts/delete-orphans", middlewareConfig.AdminOnly(handlerConfig.HandlerDeleteOrphanedPosts))
+	v1Router.Get("/admin/debug/stats", middlewareConfig.AdminOnly(handlerConfig.HandlerDebugStats))
+
 	// Mount v1Router to main router
 	router.Mount("/v1", v1Router)
 
@@ -160,17 +320,68 @@ func main() {
 	// Start background scraper
 	logger.Info("Starting RSS feed scraper...")
 	sp := scraper.NewScraper(dbQueries, log, hub)
+	sp.Fetcher = feedFetcher
+	sp.HeadCheck = envBool("FEED_HEAD_CHECK_ENABLED", false)
+	sp.PostsCache = postsCache
+	sp.MaxTitleBytes = maxTitleBytes
+	sp.MaxDescriptionBytes = envInt("POST_DESCRIPTION_MAX_BYTES", 0)
+	sp.StoreFullDescription = envBool("POST_FULL_CONTENT_ENABLED", false)
+	sp.PersistNotifications = envBool("NOTIFICATIONS_ENABLED", true)
+	sp.NotificationRetention = envDuration("NOTIFICATION_RETENTION", scraper.DefaultNotificationRetention)
+	// Feeds opt in with fetch_full_content; article hosts get the same private host check as feeds
+	contentFetcher := readability.NewFetcher()
+	contentFetcher.Timeout = envDuration("ARTICLE_FETCH_TIMEOUT", readability.DefaultTimeout)
+	contentFetcher.MaxBytes = int64(envInt("ARTICLE_MAX_BYTES", readability.DefaultMaxBytes))
+	contentFetcher.CheckURL = handlerConfig.CheckFetchURL
+	sp.ContentFetcher = contentFetcher
+	// New feeds get their posts imported as they are added rather than on the next scrape
+	handlerConfig.PostImporter = sp
 	go sp.StartScraping(dbQueries, time.Minute)
 
+	// Correct drifted follower counts cached on feeds (0 disables the job)
+	if minutes := envInt("FOLLOWER_COUNT_RECONCILE_INTERVAL_MINUTES", 60); minutes > 0 {
+		go reconcile.NewFollowerCounts(dbQueries, log).Start(time.Duration(minutes) * time.Minute)
+	}
+
+	// Delete posts left behind by feeds deleted without cascading (0 disables the job)
+	if minutes := envInt("ORPHANED_POSTS_CLEANUP_INTERVAL_MINUTES", 0); minutes > 0 {
+		go reconcile.NewOrphanedPosts(dbQueries, log).Start(time.Duration(minutes) * time.Minute)
+	}
+
 	// Create and start HTTP server
 	srv := &http.Server{
 		Handler: router,
 		Addr:    ":" + portString,
 	}
 
-	logger.Infof("Server starting on port %s", portString)
-	if err := srv.ListenAndServe(); err != nil {
-		logger.ErrorErr(err, "Server failed to start")
-		os.Exit(1)
+	go func() {
+		logger.Infof("Server starting on port %s", portString)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.ErrorErr(err, "Server failed to start")
+			os.Exit(1)
+		}
+	}()
+
+	// Shut down gracefully on SIGINT/SIGTERM: WebSocket clients are told the server
+	// is going away, then in-flight requests get SHUTDOWN_TIMEOUT to finish
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	logger.Info("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), envDuration("SHUTDOWN_TIMEOUT", 10*time.Second))
+	defer cancel()
+
+	if hub != nil {
+		if err := hub.Shutdown(ctx); err != nil {
+			logger.ErrorErr(err, "Realtime Hub shutdown did not complete")
+		}
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		// Requests still running at the deadline are what blocked the shutdown; cut them off
+		log.Error().Err(err).Int64("in_flight_requests", inFlight.Count()).Msg("Server shutdown did not complete, forcing close")
+		if err := srv.Close(); err != nil {
+			logger.ErrorErr(err, "Server close failed")
+		}
 	}
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+)
+
+// oauthProvidersFromEnv returns the OAuth providers whose client id and secret are set.
+// Callback URLs are built from OAUTH_REDIRECT_BASE_URL, defaulting to localhost on the API port.
+func oauthProvidersFromEnv(port string) map[string]*auth.OAuthProvider {
+	baseURL := strings.TrimSuffix(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:" + port
+	}
+
+	constructors := map[string]func(clientID, clientSecret, redirectURL string) *auth.OAuthProvider{
+		"google": auth.NewGoogleProvider,
+		"github": auth.NewGitHubProvider,
+	}
+
+	providers := make(map[string]*auth.OAuthProvider)
+	for name, newProvider := range constructors {
+		prefix := strings.ToUpper(name)
+		clientID := os.Getenv(prefix + "_CLIENT_ID")
+		clientSecret := os.Getenv(prefix + "_CLIENT_SECRET")
+		if clientID == "" || clientSecret == "" {
+			continue
+		}
+
+		providers[name] = newProvider(clientID, clientSecret, baseURL+"/v1/auth/oauth/"+name+"/callback")
+		logger.Infof("OAuth login enabled for %s", name)
+	}
+
+	return providers
+}
@@ -0,0 +1,58 @@
+// Package apperr classifies repository errors into stable sentinel errors, so
+// callers can use errors.Is instead of comparing against sql.ErrNoRows or
+// sniffing PostgreSQL error codes.
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Sentinel errors returned (wrapped) by Classify
+var (
+	// ErrNotFound means the requested row doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means a unique constraint was violated
+	ErrConflict = errors.New("already exists")
+	// ErrForeignKey means a referenced row doesn't exist
+	ErrForeignKey = errors.New("referenced resource does not exist")
+)
+
+// PostgreSQL error codes Classify recognises
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// Classify wraps err with the sentinel it corresponds to. The original error
+// stays in the chain, so it can still be logged or inspected with errors.As.
+// nil, already classified and unrecognised errors are returned unchanged.
+func Classify(err error) error {
+	if err == nil || isClassified(err) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		case pqForeignKeyViolation:
+			return fmt.Errorf("%w: %w", ErrForeignKey, err)
+		}
+	}
+
+	return err
+}
+
+// isClassified reports whether err already wraps one of the sentinels
+func isClassified(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.Is(err, ErrForeignKey)
+}
@@ -0,0 +1,67 @@
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"No rows", sql.ErrNoRows, ErrNotFound},
+		{"Wrapped no rows", fmt.Errorf("get feed: %w", sql.ErrNoRows), ErrNotFound},
+		{"Unique violation", &pq.Error{Code: pqUniqueViolation}, ErrConflict},
+		{"Wrapped unique violation", fmt.Errorf("insert: %w", &pq.Error{Code: pqUniqueViolation}), ErrConflict},
+		{"Foreign key violation", &pq.Error{Code: pqForeignKeyViolation}, ErrForeignKey},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Classify(tc.err)
+			if !errors.Is(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("Expected the original error to stay in the chain, got %v", got)
+			}
+		})
+	}
+}
+
+func TestClassify_UnrecognisedErrors_ReturnedUnchanged(t *testing.T) {
+	otherPQ := &pq.Error{Code: "42P01"}
+	plain := errors.New("connection refused")
+
+	for _, err := range []error{nil, otherPQ, plain} {
+		if got := Classify(err); got != err {
+			t.Errorf("Expected %v unchanged, got %v", err, got)
+		}
+	}
+}
+
+func TestClassify_AlreadyClassified_NotWrappedTwice(t *testing.T) {
+	once := Classify(sql.ErrNoRows)
+
+	if got := Classify(once); got != once {
+		t.Errorf("Expected %v unchanged, got %v", once, got)
+	}
+}
+
+func TestClassify_KeepsPQErrorForInspection(t *testing.T) {
+	err := Classify(&pq.Error{Code: pqUniqueViolation, Constraint: "users_email_key"})
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		t.Fatal("Expected errors.As to find the *pq.Error")
+	}
+	if pqErr.Constraint != "users_email_key" {
+		t.Errorf("Expected constraint %q, got %q", "users_email_key", pqErr.Constraint)
+	}
+}
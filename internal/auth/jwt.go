@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -25,6 +27,130 @@ func getJWTSecret() []byte {
 	return []byte(secret)
 }
 
+// getJWTValidationSecrets returns every secret a token may be signed with: the
+// primary JWT_SECRET first, then the previous secrets listed in JWT_SECRETS in order.
+// Keeping the old secret in JWT_SECRETS after rotating JWT_SECRET lets tokens it
+// signed stay valid until they expire, instead of logging everyone out at once.
+func getJWTValidationSecrets() [][]byte {
+	primary := getJWTSecret()
+	secrets := [][]byte{primary}
+
+	for _, secret := range strings.Split(os.Getenv("JWT_SECRETS"), ",") {
+		secret = strings.TrimSpace(secret)
+		if secret == "" || secret == string(primary) {
+			continue
+		}
+		secrets = append(secrets, []byte(secret))
+	}
+
+	return secrets
+}
+
+// Supported JWT_ALG values
+const (
+	// AlgHS256 signs and verifies tokens with the shared JWT_SECRET (the default)
+	AlgHS256 = "HS256"
+	// AlgRS256 signs with the RSA key at JWT_PRIVATE_KEY_PATH and verifies with the
+	// one at JWT_PUBLIC_KEY_PATH, so other services can verify tokens without a secret
+	AlgRS256 = "RS256"
+)
+
+// rsaKeys caches parsed RSA keys by file path; key files are read once per process
+var rsaKeys sync.Map
+
+// jwtAlgorithm returns the signing algorithm selected by JWT_ALG, HS256 when unset
+func jwtAlgorithm() (string, error) {
+	switch alg := strings.ToUpper(strings.TrimSpace(os.Getenv("JWT_ALG"))); alg {
+	case "", AlgHS256:
+		return AlgHS256, nil
+	case AlgRS256:
+		return AlgRS256, nil
+	default:
+		return "", fmt.Errorf("unsupported JWT_ALG %q (use %s or %s)", alg, AlgHS256, AlgRS256)
+	}
+}
+
+// loadRSAKey reads and parses the PEM key at the path in env with parse, caching the result
+func loadRSAKey[K any](env string, parse func([]byte) (K, error)) (K, error) {
+	var zero K
+	path := strings.TrimSpace(os.Getenv(env))
+	if path == "" {
+		return zero, fmt.Errorf("%s must be set when JWT_ALG is %s", env, AlgRS256)
+	}
+	if key, ok := rsaKeys.Load(path); ok {
+		if key, ok := key.(K); ok {
+			return key, nil
+		}
+	}
+
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return zero, fmt.Errorf("failed to read %s: %w", env, err)
+	}
+	key, err := parse(pemBytes)
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse %s: %w", env, err)
+	}
+
+	rsaKeys.Store(path, key)
+	return key, nil
+}
+
+// signingKey returns the method and key GenerateJWT signs with
+func signingKey() (jwt.SigningMethod, interface{}, error) {
+	alg, err := jwtAlgorithm()
+	if err != nil {
+		return nil, nil, err
+	}
+	if alg == AlgRS256 {
+		key, err := loadRSAKey("JWT_PRIVATE_KEY_PATH", jwt.ParseRSAPrivateKeyFromPEM)
+		return jwt.SigningMethodRS256, key, err
+	}
+	return jwt.SigningMethodHS256, getJWTSecret(), nil
+}
+
+// verificationKeys returns the algorithm ValidateJWT accepts and the keys to try, in order
+func verificationKeys() (string, []interface{}, error) {
+	alg, err := jwtAlgorithm()
+	if err != nil {
+		return "", nil, err
+	}
+	if alg == AlgRS256 {
+		key, err := loadRSAKey("JWT_PUBLIC_KEY_PATH", jwt.ParseRSAPublicKeyFromPEM)
+		if err != nil {
+			return "", nil, err
+		}
+		return alg, []interface{}{key}, nil
+	}
+
+	var keys []interface{}
+	for _, secret := range getJWTValidationSecrets() {
+		keys = append(keys, secret)
+	}
+	return alg, keys, nil
+}
+
+// CheckSigningConfig verifies that the keys for the configured JWT_ALG are available,
+// so a misconfiguration is reported at startup rather than on the first login
+func CheckSigningConfig() error {
+	alg, err := jwtAlgorithm()
+	if err != nil {
+		return err
+	}
+	if alg == AlgHS256 {
+		if os.Getenv("JWT_SECRET") == "" {
+			return errors.New("JWT_SECRET environment variable must be set")
+		}
+		return nil
+	}
+
+	if _, _, err := signingKey(); err != nil {
+		return err
+	}
+	_, _, err = verificationKeys()
+	return err
+}
+
 // CustomClaims represents the JWT payload structure.
 // It embeds jwt.RegisteredClaims to include standard fields (exp, iat, sub, etc.)
 // and adds custom fields specific to our application.
@@ -34,8 +160,22 @@ type CustomClaims struct {
 	jwt.RegisteredClaims           // Standard JWT claims (expiry, issued at, etc.)
 }
 
+// defaultAccessTokenTTL is how long access tokens stay valid when JWT_ACCESS_TOKEN_TTL is unset
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// AccessTokenTTL returns the access token lifetime from JWT_ACCESS_TOKEN_TTL, a Go
+// duration such as "15m" or "1h". Unset, unparseable or non-positive values fall
+// back to defaultAccessTokenTTL.
+func AccessTokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(strings.TrimSpace(os.Getenv("JWT_ACCESS_TOKEN_TTL")))
+	if err != nil || ttl <= 0 {
+		return defaultAccessTokenTTL
+	}
+	return ttl
+}
+
 // GenerateJWT creates a signed JWT token for authenticated users.
-// The token includes user identification data and is valid for 24 hours.
+// The token includes user identification data and is valid for AccessTokenTTL.
 //
 // Parameters:
 //   - userID: Unique identifier of the user
@@ -46,24 +186,30 @@ type CustomClaims struct {
 //   - error: Any error encountered during token generation
 //
 // Security:
-//   - Uses HMAC-SHA256 (HS256) signing algorithm
-//   - Token expires after 24 hours
-//   - Secret key loaded from environment variable
+//   - Uses HMAC-SHA256 (HS256), or RS256 with JWT_PRIVATE_KEY_PATH when JWT_ALG=RS256
+//   - Token expires after AccessTokenTTL (JWT_ACCESS_TOKEN_TTL, default 15 minutes)
+//   - Secret or private key loaded from environment variables
 func GenerateJWT(userID uuid.UUID, email string) (string, error) {
-	expirationTime := time.Now().Add(15 * time.Minute)
+	now := time.Now()
+	expirationTime := now.Add(AccessTokenTTL())
 
 	claims := &CustomClaims{
 		UserID: userID,
 		Email:  email,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Subject:   userID.String(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(getJWTSecret())
+	method, key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -96,17 +242,30 @@ func GenerateRefreshToken() (string, error) {
 //   - error: Validation error if token is invalid, expired, or malformed
 //
 // Security considerations:
-//   - Validates signing algorithm to prevent algorithm substitution attacks
+//   - Only accepts the configured JWT_ALG, to prevent algorithm substitution attacks
+//     (e.g. an HS256 token "signed" with the public key while in RS256 mode)
 //   - Checks token expiration automatically
-//   - Verifies signature using secret key from environment
+//   - In HS256 mode, verifies signature using the primary secret, then each previous
+//     secret (JWT_SECRETS) in turn, so rotating the secret doesn't invalidate live tokens
+//   - In RS256 mode, verifies signature with the public key at JWT_PUBLIC_KEY_PATH
 func ValidateJWT(tokenString string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method to prevent algorithm substitution attacks
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	alg, keys, err := verificationKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var token *jwt.Token
+	for _, key := range keys {
+		token, err = jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+			return key, nil
+		}, jwt.WithValidMethods([]string{alg}))
+
+		// Only a signature mismatch means another secret might have signed it;
+		// an expired or malformed token is rejected whichever secret is used
+		if !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			break
 		}
-		return getJWTSecret(), nil
-	})
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
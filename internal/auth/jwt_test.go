@@ -1,10 +1,15 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -95,6 +100,99 @@ func TestValidateJWT_ExpiredToken_ReturnsError(t *testing.T) {
 	}
 }
 
+// signTestToken signs a token for userID with the given secret
+func signTestToken(t *testing.T, userID uuid.UUID, secret string, expiresIn time.Duration) string {
+	t.Helper()
+
+	claims := &CustomClaims{
+		UserID: userID,
+		Email:  "test@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return tokenString
+}
+
+func TestValidateJWT_PreviousSecret_DuringOverlap_ReturnsClaims(t *testing.T) {
+	userID := uuid.New()
+	oldToken := signTestToken(t, userID, "old-secret", 15*time.Minute)
+
+	// Rotate: the new secret becomes primary and the old one is kept as previous
+	t.Setenv("JWT_SECRET", "new-secret")
+	t.Setenv("JWT_SECRETS", "older-secret, old-secret")
+
+	claims, err := ValidateJWT(oldToken)
+	if err != nil {
+		t.Fatalf("Expected token signed with previous secret to be valid, got %v", err)
+	}
+
+	if claims.UserID != userID {
+		t.Errorf("Expected UserID %v, got %v", userID, claims.UserID)
+	}
+}
+
+func TestValidateJWT_PreviousSecret_AfterOverlap_ReturnsError(t *testing.T) {
+	oldToken := signTestToken(t, uuid.New(), "old-secret", 15*time.Minute)
+
+	t.Setenv("JWT_SECRET", "new-secret")
+	t.Setenv("JWT_SECRETS", "")
+
+	if _, err := ValidateJWT(oldToken); err == nil {
+		t.Error("Expected error once the previous secret is removed")
+	}
+}
+
+func TestValidateJWT_PreviousSecret_ExpiredToken_ReturnsError(t *testing.T) {
+	oldToken := signTestToken(t, uuid.New(), "old-secret", -time.Minute)
+
+	t.Setenv("JWT_SECRET", "new-secret")
+	t.Setenv("JWT_SECRETS", "old-secret")
+
+	if _, err := ValidateJWT(oldToken); err == nil {
+		t.Error("Expected error for expired token signed with previous secret")
+	}
+}
+
+func TestGenerateJWT_SignsWithPrimarySecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "new-secret")
+	t.Setenv("JWT_SECRETS", "old-secret")
+
+	token, err := GenerateJWT(uuid.New(), "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// Without the previous secret the token must still validate
+	t.Setenv("JWT_SECRETS", "")
+	if _, err := ValidateJWT(token); err != nil {
+		t.Errorf("Expected token signed with primary secret, got %v", err)
+	}
+}
+
+func TestGetJWTValidationSecrets_PrimaryFirstWithoutDuplicates(t *testing.T) {
+	t.Setenv("JWT_SECRET", "new-secret")
+	t.Setenv("JWT_SECRETS", "new-secret, old-secret,,older-secret")
+
+	secrets := getJWTValidationSecrets()
+
+	expected := []string{"new-secret", "old-secret", "older-secret"}
+	if len(secrets) != len(expected) {
+		t.Fatalf("Expected %d secrets, got %d", len(expected), len(secrets))
+	}
+	for i, secret := range secrets {
+		if string(secret) != expected[i] {
+			t.Errorf("Expected secret %d to be %s, got %s", i, expected[i], secret)
+		}
+	}
+}
+
 func TestGenerateRefreshToken_ReturnsNonEmptyToken(t *testing.T) {
 	token, err := GenerateRefreshToken()
 
@@ -246,3 +344,168 @@ func TestHashRefreshToken_ConsistencyWithSha256(t *testing.T) {
 
 	t.Logf("Hash: %s", actualHash)
 }
+
+func TestGenerateJWT_ExpiryFollowsAccessTokenTTL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"Unset", "", defaultAccessTokenTTL},
+		{"Configured", "1h", time.Hour},
+		{"Minutes", "5m", 5 * time.Minute},
+		{"Garbage", "not-a-duration", defaultAccessTokenTTL},
+		{"Bare number", "30", defaultAccessTokenTTL},
+		{"Negative", "-10m", defaultAccessTokenTTL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("JWT_ACCESS_TOKEN_TTL", tc.value)
+
+			token, err := GenerateJWT(uuid.New(), "test@example.com")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			claims, err := ValidateJWT(token)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+			if lifetime != tc.expected {
+				t.Errorf("Expected token lifetime %v, got %v", tc.expected, lifetime)
+			}
+		})
+	}
+}
+
+// useRS256 writes a new RSA keypair to temporary files and switches signing to RS256.
+// It returns the public key PEM.
+func useRS256(t *testing.T) []byte {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "jwt.key")
+	publicPath := filepath.Join(dir, "jwt.pub")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		t.Fatalf("Failed to write private key: %v", err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write public key: %v", err)
+	}
+
+	t.Setenv("JWT_ALG", "RS256")
+	t.Setenv("JWT_PRIVATE_KEY_PATH", privatePath)
+	t.Setenv("JWT_PUBLIC_KEY_PATH", publicPath)
+	return publicPEM
+}
+
+func TestGenerateJWT_RS256_RoundTrip(t *testing.T) {
+	useRS256(t)
+	userID := uuid.New()
+
+	tokenString, err := GenerateJWT(userID, "test@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &CustomClaims{})
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	if token.Method.Alg() != AlgRS256 {
+		t.Errorf("Expected alg %s, got %s", AlgRS256, token.Method.Alg())
+	}
+
+	claims, err := ValidateJWT(tokenString)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if claims.UserID != userID {
+		t.Errorf("Expected UserID %v, got %v", userID, claims.UserID)
+	}
+}
+
+func TestValidateJWT_RS256_RejectsHS256Tokens(t *testing.T) {
+	publicPEM := useRS256(t)
+
+	testCases := []struct {
+		name   string
+		secret string
+	}{
+		{"Signed with JWT_SECRET", os.Getenv("JWT_SECRET")},
+		// The classic substitution attack: HMAC keyed with the public key
+		{"Signed with the public key", string(publicPEM)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := signTestToken(t, uuid.New(), tc.secret, 15*time.Minute)
+
+			if _, err := ValidateJWT(token); err == nil {
+				t.Error("Expected an HS256 token to be rejected in RS256 mode")
+			}
+		})
+	}
+}
+
+func TestValidateJWT_HS256_RejectsRS256Tokens(t *testing.T) {
+	useRS256(t)
+	token, err := GenerateJWT(uuid.New(), "test@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Setenv("JWT_ALG", "")
+
+	if _, err := ValidateJWT(token); err == nil {
+		t.Error("Expected an RS256 token to be rejected in HS256 mode")
+	}
+}
+
+func TestCheckSigningConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		alg         string
+		privatePath string
+		expectedErr bool
+	}{
+		{"Default HS256", "", "", false},
+		{"Unsupported algorithm", "none", "", true},
+		{"RS256 without keys", "RS256", "", true},
+		{"RS256 with missing key file", "RS256", filepath.Join(t.TempDir(), "missing.key"), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("JWT_ALG", tc.alg)
+			t.Setenv("JWT_PRIVATE_KEY_PATH", tc.privatePath)
+			t.Setenv("JWT_PUBLIC_KEY_PATH", "")
+
+			if err := CheckSigningConfig(); (err != nil) != tc.expectedErr {
+				t.Errorf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckSigningConfig_RS256WithKeys(t *testing.T) {
+	useRS256(t)
+
+	if err := CheckSigningConfig(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oauthRequestTimeout bounds each request made to an OAuth provider
+const oauthRequestTimeout = 10 * time.Second
+
+// maxOAuthResponseBytes caps how much of a provider response is read (1 MB)
+const maxOAuthResponseBytes = 1 << 20
+
+// OAuthIdentity is the account information returned by an OAuth provider
+type OAuthIdentity struct {
+	ProviderUserID string
+	Email          string
+	EmailVerified  bool
+	Name           string
+}
+
+// OAuthProvider holds the endpoints and credentials of an OAuth 2.0 login provider.
+// Endpoints are fields rather than constants so tests can point them at a local server.
+type OAuthProvider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// EmailsURL lists the account's email addresses (GitHub only, where /user may omit the email)
+	EmailsURL string
+
+	// parseIdentity extracts the identity from the provider's userinfo response
+	parseIdentity func(ctx context.Context, p *OAuthProvider, accessToken string, body []byte) (OAuthIdentity, error)
+
+	Client *http.Client
+}
+
+// NewGoogleProvider returns a Google OAuth provider
+func NewGoogleProvider(clientID, clientSecret, redirectURL string) *OAuthProvider {
+	return &OAuthProvider{
+		Name:          "google",
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   redirectURL,
+		Scopes:        []string{"openid", "email", "profile"},
+		AuthURL:       "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:      "https://oauth2.googleapis.com/token",
+		UserInfoURL:   "https://openidconnect.googleapis.com/v1/userinfo",
+		parseIdentity: parseGoogleIdentity,
+	}
+}
+
+// NewGitHubProvider returns a GitHub OAuth provider
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *OAuthProvider {
+	return &OAuthProvider{
+		Name:          "github",
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   redirectURL,
+		Scopes:        []string{"read:user", "user:email"},
+		AuthURL:       "https://github.com/login/oauth/authorize",
+		TokenURL:      "https://github.com/login/oauth/access_token",
+		UserInfoURL:   "https://api.github.com/user",
+		EmailsURL:     "https://api.github.com/user/emails",
+		parseIdentity: parseGitHubIdentity,
+	}
+}
+
+// AuthCodeURL returns the provider URL the user is redirected to in order to log in
+func (p *OAuthProvider) AuthCodeURL(state string) string {
+	query := url.Values{
+		"client_id":     {p.ClientID},
+		"redirect_uri":  {p.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {strings.Join(p.Scopes, " ")},
+		"state":         {state},
+	}
+
+	return p.AuthURL + "?" + query.Encode()
+}
+
+// Exchange trades an authorization code for the user's identity.
+// It calls the token endpoint and then the userinfo endpoint with the resulting access token.
+func (p *OAuthProvider) Exchange(ctx context.Context, code string) (OAuthIdentity, error) {
+	accessToken, err := p.exchangeCode(ctx, code)
+	if err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	body, err := p.get(ctx, p.UserInfoURL, accessToken)
+	if err != nil {
+		return OAuthIdentity{}, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+
+	return p.parseIdentity(ctx, p, accessToken, body)
+}
+
+// exchangeCode calls the token endpoint and returns the access token
+func (p *OAuthProvider) exchangeCode(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, oauthRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub answers with a form-encoded body unless JSON is requested
+	req.Header.Set("Accept", "application/json")
+
+	body, err := p.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	// GitHub reports a bad code with status 200 and an error field
+	if token.Error != "" {
+		return "", fmt.Errorf("provider rejected code: %s", token.Error)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("provider returned no access token")
+	}
+
+	return token.AccessToken, nil
+}
+
+// get performs an authenticated GET against a provider API
+func (p *OAuthProvider) get(ctx context.Context, endpoint, accessToken string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, oauthRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	return p.do(req)
+}
+
+func (p *OAuthProvider) do(req *http.Request) ([]byte, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: oauthRequestTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOAuthResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+func parseGoogleIdentity(ctx context.Context, p *OAuthProvider, accessToken string, body []byte) (OAuthIdentity, error) {
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return OAuthIdentity{}, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	if info.Sub == "" {
+		return OAuthIdentity{}, errors.New("user info has no subject")
+	}
+
+	return OAuthIdentity{
+		ProviderUserID: info.Sub,
+		Email:          info.Email,
+		EmailVerified:  info.EmailVerified,
+		Name:           info.Name,
+	}, nil
+}
+
+func parseGitHubIdentity(ctx context.Context, p *OAuthProvider, accessToken string, body []byte) (OAuthIdentity, error) {
+	var info struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return OAuthIdentity{}, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	if info.ID == 0 {
+		return OAuthIdentity{}, errors.New("user info has no id")
+	}
+
+	identity := OAuthIdentity{
+		ProviderUserID: strconv.FormatInt(info.ID, 10),
+		Name:           info.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = info.Login
+	}
+
+	// The profile email is whatever the user made public and may be unverified,
+	// so the verified primary address is looked up separately
+	emailsBody, err := p.get(ctx, p.EmailsURL, accessToken)
+	if err != nil {
+		return OAuthIdentity{}, fmt.Errorf("failed to fetch emails: %w", err)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := json.Unmarshal(emailsBody, &emails); err != nil {
+		return OAuthIdentity{}, fmt.Errorf("failed to decode emails: %w", err)
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newStubOAuthServer mocks a provider's token and userinfo endpoints
+func newStubOAuthServer(t *testing.T, userInfo interface{}, emails interface{}) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse token request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("client_secret") != "secret" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "provider-token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer provider-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(userInfo)
+	})
+	mux.HandleFunc("/emails", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(emails)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func pointAt(p *OAuthProvider, server *httptest.Server) *OAuthProvider {
+	p.TokenURL = server.URL + "/token"
+	p.UserInfoURL = server.URL + "/userinfo"
+	p.EmailsURL = server.URL + "/emails"
+	return p
+}
+
+func TestGoogleProvider_Exchange_ReturnsIdentity(t *testing.T) {
+	server := newStubOAuthServer(t, map[string]interface{}{
+		"sub":            "1234",
+		"email":          "test@example.com",
+		"email_verified": true,
+		"name":           "Test User",
+	}, nil)
+	provider := pointAt(NewGoogleProvider("client", "secret", "http://localhost/callback"), server)
+
+	identity, err := provider.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if identity.ProviderUserID != "1234" || identity.Email != "test@example.com" || !identity.EmailVerified {
+		t.Errorf("Unexpected identity: %+v", identity)
+	}
+}
+
+func TestGitHubProvider_Exchange_UsesVerifiedPrimaryEmail(t *testing.T) {
+	server := newStubOAuthServer(t, map[string]interface{}{
+		"id":    42,
+		"login": "octocat",
+		"email": "public@example.com",
+	}, []map[string]interface{}{
+		{"email": "other@example.com", "primary": false, "verified": true},
+		{"email": "primary@example.com", "primary": true, "verified": true},
+	})
+	provider := pointAt(NewGitHubProvider("client", "secret", "http://localhost/callback"), server)
+
+	identity, err := provider.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if identity.ProviderUserID != "42" {
+		t.Errorf("Expected provider user ID 42, got %s", identity.ProviderUserID)
+	}
+
+	if identity.Email != "primary@example.com" || !identity.EmailVerified {
+		t.Errorf("Expected verified primary email, got %s (verified=%v)", identity.Email, identity.EmailVerified)
+	}
+
+	if identity.Name != "octocat" {
+		t.Errorf("Expected login as fallback name, got %s", identity.Name)
+	}
+}
+
+func TestOAuthProvider_Exchange_RejectedCode_ReturnsError(t *testing.T) {
+	server := newStubOAuthServer(t, map[string]interface{}{"sub": "1234"}, nil)
+	provider := pointAt(NewGoogleProvider("client", "secret", "http://localhost/callback"), server)
+
+	if _, err := provider.Exchange(context.Background(), "bad-code"); err == nil {
+		t.Error("Expected error for rejected code")
+	}
+}
+
+func TestOAuthProvider_AuthCodeURL_IncludesStateAndRedirect(t *testing.T) {
+	provider := NewGoogleProvider("client", "secret", "http://localhost/callback")
+
+	parsed, err := url.Parse(provider.AuthCodeURL("state-value"))
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+
+	query := parsed.Query()
+	if query.Get("state") != "state-value" {
+		t.Errorf("Expected state to be passed, got %s", query.Get("state"))
+	}
+
+	if query.Get("redirect_uri") != "http://localhost/callback" {
+		t.Errorf("Expected redirect_uri to be passed, got %s", query.Get("redirect_uri"))
+	}
+
+	if query.Get("client_id") != "client" {
+		t.Errorf("Expected client_id to be passed, got %s", query.Get("client_id"))
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Logo is a fetched feed logo image
+type Logo struct {
+	ContentType string
+	Data        []byte
+}
+
+type logoEntry struct {
+	feedID    uuid.UUID
+	logo      Logo
+	expiresAt time.Time
+}
+
+// LogosCache is an in-process LRU cache of feed logos with a TTL
+type LogosCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  *list.List
+	index    map[uuid.UUID]*list.Element
+
+	now func() time.Time
+}
+
+// NewLogosCache creates a cache holding at most capacity logos for ttl each
+func NewLogosCache(capacity int, ttl time.Duration) *LogosCache {
+	return &LogosCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  list.New(),
+		index:    make(map[uuid.UUID]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get returns the cached logo of a feed, if present and not expired
+func (c *LogosCache) Get(feedID uuid.UUID) (Logo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.index[feedID]
+	if !ok {
+		return Logo{}, false
+	}
+
+	cached := element.Value.(*logoEntry)
+	if c.now().After(cached.expiresAt) {
+		c.remove(element)
+		return Logo{}, false
+	}
+
+	c.entries.MoveToFront(element)
+	return cached.logo, true
+}
+
+// Set stores a feed's logo, evicting the least recently used logo when full
+func (c *LogosCache) Set(feedID uuid.UUID, logo Logo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.index[feedID]; ok {
+		cached := element.Value.(*logoEntry)
+		cached.logo = logo
+		cached.expiresAt = c.now().Add(c.ttl)
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.index[feedID] = c.entries.PushFront(&logoEntry{feedID: feedID, logo: logo, expiresAt: c.now().Add(c.ttl)})
+
+	for c.entries.Len() > c.capacity {
+		c.remove(c.entries.Back())
+	}
+}
+
+// Len returns the number of stored logos
+func (c *LogosCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *LogosCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*logoEntry).feedID)
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLogosCache_GetAfterSet_Hits(t *testing.T) {
+	c := NewLogosCache(10, time.Hour)
+	feedID := uuid.New()
+
+	if _, ok := c.Get(feedID); ok {
+		t.Fatal("Expected miss on empty cache")
+	}
+
+	c.Set(feedID, Logo{ContentType: "image/png", Data: []byte("png")})
+
+	logo, ok := c.Get(feedID)
+	if !ok {
+		t.Fatal("Expected hit after Set")
+	}
+	if logo.ContentType != "image/png" || string(logo.Data) != "png" {
+		t.Errorf("Expected the stored logo, got %+v", logo)
+	}
+}
+
+func TestLogosCache_Expired_Misses(t *testing.T) {
+	c := NewLogosCache(10, time.Hour)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	feedID := uuid.New()
+	c.Set(feedID, Logo{ContentType: "image/png"})
+
+	now = now.Add(2 * time.Hour)
+
+	if _, ok := c.Get(feedID); ok {
+		t.Error("Expected miss after TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestLogosCache_Full_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLogosCache(2, time.Hour)
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	c.Set(first, Logo{})
+	c.Set(second, Logo{})
+	c.Get(first)
+	c.Set(third, Logo{})
+
+	if _, ok := c.Get(second); ok {
+		t.Error("Expected least recently used logo to be evicted")
+	}
+	if _, ok := c.Get(first); !ok {
+		t.Error("Expected recently used logo to be kept")
+	}
+	if _, ok := c.Get(third); !ok {
+		t.Error("Expected newest logo to be kept")
+	}
+}
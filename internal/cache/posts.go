@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// PostsKey identifies one page of a user's posts
+type PostsKey struct {
+	UserID uuid.UUID
+	// Cursor is the page cursor in Unix nanoseconds, 0 for the first page
+	Cursor int64
+	Limit  int
+}
+
+// entryKey ties a PostsKey to the user's generation when it was stored
+type entryKey struct {
+	PostsKey
+	generation uint64
+}
+
+type entry struct {
+	key       entryKey
+	posts     []database.Post
+	expiresAt time.Time
+}
+
+// PostsCache is an in-process LRU cache of post pages with a short TTL.
+//
+// Invalidating a user bumps their generation instead of searching for their
+// entries: older entries can no longer be looked up and are evicted as the
+// cache fills or they expire.
+type PostsCache struct {
+	mu          sync.Mutex
+	capacity    int
+	ttl         time.Duration
+	entries     *list.List
+	index       map[entryKey]*list.Element
+	generations map[uuid.UUID]uint64
+
+	now func() time.Time
+}
+
+// NewPostsCache creates a cache holding at most capacity pages for ttl each
+func NewPostsCache(capacity int, ttl time.Duration) *PostsCache {
+	return &PostsCache{
+		capacity:    capacity,
+		ttl:         ttl,
+		entries:     list.New(),
+		index:       make(map[entryKey]*list.Element),
+		generations: make(map[uuid.UUID]uint64),
+		now:         time.Now,
+	}
+}
+
+// Get returns the cached page for key, if present and not expired
+func (c *PostsCache) Get(key PostsKey) ([]database.Post, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.index[entryKey{PostsKey: key, generation: c.generations[key.UserID]}]
+	if !ok {
+		return nil, false
+	}
+
+	cached := element.Value.(*entry)
+	if c.now().After(cached.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.entries.MoveToFront(element)
+	return cached.posts, true
+}
+
+// Set stores a page, evicting the least recently used page when full
+func (c *PostsCache) Set(key PostsKey, posts []database.Post) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := entryKey{PostsKey: key, generation: c.generations[key.UserID]}
+	if element, ok := c.index[k]; ok {
+		cached := element.Value.(*entry)
+		cached.posts = posts
+		cached.expiresAt = c.now().Add(c.ttl)
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.index[k] = c.entries.PushFront(&entry{key: k, posts: posts, expiresAt: c.now().Add(c.ttl)})
+
+	for c.entries.Len() > c.capacity {
+		c.remove(c.entries.Back())
+	}
+}
+
+// InvalidateUsers drops every cached page of the given users
+func (c *PostsCache) InvalidateUsers(userIDs ...uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, userID := range userIDs {
+		c.generations[userID]++
+	}
+}
+
+// Len returns the number of stored pages, including invalidated ones not yet evicted
+func (c *PostsCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *PostsCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*entry).key)
+}
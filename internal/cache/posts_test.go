@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func testPosts(titles ...string) []database.Post {
+	posts := make([]database.Post, 0, len(titles))
+	for _, title := range titles {
+		posts = append(posts, database.Post{ID: uuid.New(), Title: title})
+	}
+	return posts
+}
+
+func TestPostsCache_GetAfterSet_Hits(t *testing.T) {
+	c := NewPostsCache(10, time.Minute)
+	key := PostsKey{UserID: uuid.New(), Limit: 20}
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Expected miss on empty cache")
+	}
+
+	c.Set(key, testPosts("a", "b"))
+
+	posts, ok := c.Get(key)
+	if !ok {
+		t.Fatal("Expected hit after Set")
+	}
+
+	if len(posts) != 2 {
+		t.Errorf("Expected 2 posts, got %d", len(posts))
+	}
+}
+
+func TestPostsCache_DifferentPage_Misses(t *testing.T) {
+	c := NewPostsCache(10, time.Minute)
+	userID := uuid.New()
+	c.Set(PostsKey{UserID: userID, Limit: 20}, testPosts("a"))
+
+	if _, ok := c.Get(PostsKey{UserID: userID, Limit: 50}); ok {
+		t.Error("Expected miss for a different limit")
+	}
+
+	if _, ok := c.Get(PostsKey{UserID: userID, Cursor: 1, Limit: 20}); ok {
+		t.Error("Expected miss for a different cursor")
+	}
+}
+
+func TestPostsCache_Expired_Misses(t *testing.T) {
+	c := NewPostsCache(10, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	key := PostsKey{UserID: uuid.New(), Limit: 20}
+	c.Set(key, testPosts("a"))
+
+	now = now.Add(2 * time.Minute)
+
+	if _, ok := c.Get(key); ok {
+		t.Error("Expected miss after TTL")
+	}
+
+	if c.Len() != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestPostsCache_Full_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewPostsCache(2, time.Minute)
+	first := PostsKey{UserID: uuid.New(), Limit: 20}
+	second := PostsKey{UserID: uuid.New(), Limit: 20}
+	third := PostsKey{UserID: uuid.New(), Limit: 20}
+
+	c.Set(first, testPosts("a"))
+	c.Set(second, testPosts("b"))
+	c.Get(first)
+	c.Set(third, testPosts("c"))
+
+	if _, ok := c.Get(second); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+
+	if _, ok := c.Get(first); !ok {
+		t.Error("Expected recently used entry to be kept")
+	}
+}
+
+func TestPostsCache_InvalidateUsers_OnlyAffectsThoseUsers(t *testing.T) {
+	c := NewPostsCache(10, time.Minute)
+	follower := PostsKey{UserID: uuid.New(), Limit: 20}
+	other := PostsKey{UserID: uuid.New(), Limit: 20}
+	c.Set(follower, testPosts("a"))
+	c.Set(other, testPosts("b"))
+
+	c.InvalidateUsers(follower.UserID)
+
+	if _, ok := c.Get(follower); ok {
+		t.Error("Expected invalidated user's page to miss")
+	}
+
+	if _, ok := c.Get(other); !ok {
+		t.Error("Expected other user's page to still hit")
+	}
+
+	c.Set(follower, testPosts("c"))
+	if posts, ok := c.Get(follower); !ok || posts[0].Title != "c" {
+		t.Error("Expected fresh page to be cached after invalidation")
+	}
+}
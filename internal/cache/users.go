@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+type userEntry struct {
+	user      database.User
+	expiresAt time.Time
+}
+
+// UsersCache is an in-process LRU cache of users with a short TTL, so authenticated
+// requests don't have to load their user every time. A changed user row is picked up
+// once its cached copy expires.
+type UsersCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  *list.List
+	index    map[uuid.UUID]*list.Element
+
+	now func() time.Time
+}
+
+// UsersCacheOption configures a UsersCache created by NewUsersCache
+type UsersCacheOption func(*UsersCache)
+
+// WithUsersCacheClock makes the cache read the time from now instead of time.Now,
+// so tests can expire entries without waiting for the TTL
+func WithUsersCacheClock(now func() time.Time) UsersCacheOption {
+	return func(c *UsersCache) {
+		c.now = now
+	}
+}
+
+// NewUsersCache creates a cache holding at most capacity users for ttl each
+func NewUsersCache(capacity int, ttl time.Duration, options ...UsersCacheOption) *UsersCache {
+	c := &UsersCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  list.New(),
+		index:    make(map[uuid.UUID]*list.Element),
+		now:      time.Now,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Get returns the cached user, if present and not expired
+func (c *UsersCache) Get(userID uuid.UUID) (database.User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.index[userID]
+	if !ok {
+		return database.User{}, false
+	}
+
+	cached := element.Value.(*userEntry)
+	if c.now().After(cached.expiresAt) {
+		c.remove(element)
+		return database.User{}, false
+	}
+
+	c.entries.MoveToFront(element)
+	return cached.user, true
+}
+
+// Set stores a user, evicting the least recently used user when full
+func (c *UsersCache) Set(user database.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.index[user.ID]; ok {
+		cached := element.Value.(*userEntry)
+		cached.user = user
+		cached.expiresAt = c.now().Add(c.ttl)
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.index[user.ID] = c.entries.PushFront(&userEntry{user: user, expiresAt: c.now().Add(c.ttl)})
+
+	for c.entries.Len() > c.capacity {
+		c.remove(c.entries.Back())
+	}
+}
+
+// Len returns the number of stored users
+func (c *UsersCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *UsersCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*userEntry).user.ID)
+}
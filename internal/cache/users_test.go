@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestUsersCache_GetAfterSet_Hits(t *testing.T) {
+	c := NewUsersCache(10, time.Minute)
+	user := database.User{ID: uuid.New(), Name: "Ada"}
+
+	if _, ok := c.Get(user.ID); ok {
+		t.Fatal("Expected miss on empty cache")
+	}
+
+	c.Set(user)
+
+	cached, ok := c.Get(user.ID)
+	if !ok {
+		t.Fatal("Expected hit after Set")
+	}
+	if cached.Name != "Ada" {
+		t.Errorf("Expected the stored user, got %+v", cached)
+	}
+}
+
+func TestUsersCache_Expired_Misses(t *testing.T) {
+	now := time.Now()
+	c := NewUsersCache(10, time.Minute, WithUsersCacheClock(func() time.Time { return now }))
+	user := database.User{ID: uuid.New()}
+	c.Set(user)
+
+	now = now.Add(2 * time.Minute)
+
+	if _, ok := c.Get(user.ID); ok {
+		t.Error("Expected miss after TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestUsersCache_Full_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewUsersCache(2, time.Minute)
+	first, second, third := database.User{ID: uuid.New()}, database.User{ID: uuid.New()}, database.User{ID: uuid.New()}
+
+	c.Set(first)
+	c.Set(second)
+	c.Get(first.ID)
+	c.Set(third)
+
+	if _, ok := c.Get(second.ID); ok {
+		t.Error("Expected least recently used user to be evicted")
+	}
+	if _, ok := c.Get(first.ID); !ok {
+		t.Error("Expected recently used user to be kept")
+	}
+}
@@ -0,0 +1,112 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: bookmarks.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createBookmark = `-- name: CreateBookmark :exec
+INSERT INTO bookmarks (user_id, post_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type CreateBookmarkParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+// Bookmarking a post twice keeps the first bookmark
+func (q *Queries) CreateBookmark(ctx context.Context, arg CreateBookmarkParams) error {
+	_, err := q.db.ExecContext(ctx, createBookmark, arg.UserID, arg.PostID, arg.CreatedAt)
+	return err
+}
+
+const deleteBookmark = `-- name: DeleteBookmark :exec
+DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2
+`
+
+type DeleteBookmarkParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams) error {
+	_, err := q.db.ExecContext(ctx, deleteBookmark, arg.UserID, arg.PostID)
+	return err
+}
+
+const getBookmarksForUser = `-- name: GetBookmarksForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content, bookmarks.created_at AS bookmarked_at
+FROM bookmarks JOIN posts ON posts.id = bookmarks.post_id
+WHERE bookmarks.user_id = $1 AND bookmarks.created_at < $2
+ORDER BY bookmarks.created_at DESC, bookmarks.post_id DESC
+LIMIT $3
+`
+
+type GetBookmarksForUserParams struct {
+	UserID           uuid.UUID
+	BookmarkedBefore time.Time
+	RowLimit         int32
+}
+
+type GetBookmarksForUserRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
+	FullContent          sql.NullString
+	BookmarkedAt         time.Time
+}
+
+// The page of the user's posts bookmarked before bookmarked_before, most recently bookmarked first
+func (q *Queries) GetBookmarksForUser(ctx context.Context, arg GetBookmarksForUserParams) ([]GetBookmarksForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBookmarksForUser, arg.UserID, arg.BookmarkedBefore, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBookmarksForUserRow
+	for rows.Next() {
+		var i GetBookmarksForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+			&i.BookmarkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
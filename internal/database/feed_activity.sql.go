@@ -0,0 +1,111 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: feed_activity.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteHourlyFeedActivityBefore = `-- name: DeleteHourlyFeedActivityBefore :execrows
+DELETE FROM feed_activity
+WHERE granularity = 'hour' AND bucket_start < $1
+`
+
+// Hour buckets are recorded alongside day buckets, so old ones carry no extra information
+func (q *Queries) DeleteHourlyFeedActivityBefore(ctx context.Context, bucketStartBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteHourlyFeedActivityBefore, bucketStartBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFeedActivity = `-- name: GetFeedActivity :many
+SELECT bucket_start, post_count FROM feed_activity
+WHERE feed_id = $1 AND granularity = $2 AND bucket_start >= $3
+ORDER BY bucket_start ASC
+`
+
+type GetFeedActivityParams struct {
+	FeedID      uuid.UUID
+	Granularity string
+	BucketStart time.Time
+}
+
+type GetFeedActivityRow struct {
+	BucketStart time.Time
+	PostCount   int32
+}
+
+func (q *Queries) GetFeedActivity(ctx context.Context, arg GetFeedActivityParams) ([]GetFeedActivityRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedActivity, arg.FeedID, arg.Granularity, arg.BucketStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedActivityRow
+	for rows.Next() {
+		var i GetFeedActivityRow
+		if err := rows.Scan(&i.BucketStart, &i.PostCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementFeedActivity = `-- name: IncrementFeedActivity :exec
+INSERT INTO feed_activity (feed_id, granularity, bucket_start, post_count)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (feed_id, granularity, bucket_start)
+DO UPDATE SET post_count = feed_activity.post_count + EXCLUDED.post_count
+`
+
+type IncrementFeedActivityParams struct {
+	FeedID      uuid.UUID
+	Granularity string
+	BucketStart time.Time
+	PostCount   int32
+}
+
+func (q *Queries) IncrementFeedActivity(ctx context.Context, arg IncrementFeedActivityParams) error {
+	_, err := q.db.ExecContext(ctx, incrementFeedActivity,
+		arg.FeedID,
+		arg.Granularity,
+		arg.BucketStart,
+		arg.PostCount,
+	)
+	return err
+}
+
+const mergeFeedActivity = `-- name: MergeFeedActivity :exec
+INSERT INTO feed_activity (feed_id, granularity, bucket_start, post_count)
+SELECT $1::uuid, source.granularity, source.bucket_start, source.post_count
+FROM feed_activity AS source
+WHERE source.feed_id = $2::uuid
+ON CONFLICT (feed_id, granularity, bucket_start)
+DO UPDATE SET post_count = feed_activity.post_count + EXCLUDED.post_count
+`
+
+type MergeFeedActivityParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+// Adds from_feed_id's buckets onto to_feed_id's
+func (q *Queries) MergeFeedActivity(ctx context.Context, arg MergeFeedActivityParams) error {
+	_, err := q.db.ExecContext(ctx, mergeFeedActivity, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
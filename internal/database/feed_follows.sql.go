@@ -7,15 +7,27 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countFeedFollowsByUser = `-- name: CountFeedFollowsByUser :one
+SELECT COUNT(*) FROM feed_follows WHERE user_id = $1
+`
+
+func (q *Queries) CountFeedFollowsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollowsByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
 INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, user_id, feed_id
+RETURNING id, created_at, updated_at, user_id, feed_id, alias, category, notify
 `
 
 type CreateFeedFollowParams struct {
@@ -41,12 +53,20 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.Alias,
+		&i.Category,
+		&i.Notify,
 	)
 	return i, err
 }
 
 const deleteFeedFollow = `-- name: DeleteFeedFollow :exec
-DELETE FROM feed_follows WHERE id=$1 AND user_id=$2
+WITH deleted AS (
+    DELETE FROM feed_follows WHERE feed_follows.id = $1 AND feed_follows.user_id = $2
+    RETURNING feed_follows.feed_id
+)
+UPDATE feeds SET follower_count = GREATEST(follower_count - 1, 0)
+FROM deleted WHERE feeds.id = deleted.feed_id
 `
 
 type DeleteFeedFollowParams struct {
@@ -54,13 +74,66 @@ type DeleteFeedFollowParams struct {
 	UserID uuid.UUID
 }
 
+// Also decrements the feed's cached follower count when a follow was deleted
 func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error {
 	_, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.ID, arg.UserID)
 	return err
 }
 
+const deleteFeedFollowsAlreadyOnFeed = `-- name: DeleteFeedFollowsAlreadyOnFeed :execrows
+DELETE FROM feed_follows AS duplicate
+WHERE duplicate.feed_id = $1
+  AND EXISTS (
+      SELECT 1 FROM feed_follows AS canonical
+      WHERE canonical.feed_id = $2 AND canonical.user_id = duplicate.user_id
+  )
+`
+
+type DeleteFeedFollowsAlreadyOnFeedParams struct {
+	FromFeedID uuid.UUID
+	ToFeedID   uuid.UUID
+}
+
+// Drops follows of from_feed_id whose user already follows to_feed_id
+func (q *Queries) DeleteFeedFollowsAlreadyOnFeed(ctx context.Context, arg DeleteFeedFollowsAlreadyOnFeedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowsAlreadyOnFeed, arg.FromFeedID, arg.ToFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFeedFollowsByFeed = `-- name: DeleteFeedFollowsByFeed :many
+DELETE FROM feed_follows WHERE feed_id = $1
+RETURNING user_id
+`
+
+// Returns the users who followed the feed
+func (q *Queries) DeleteFeedFollowsByFeed(ctx context.Context, feedID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteFeedFollowsByFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedFollows = `-- name: GetFeedFollows :many
-SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows WHERE user_id=$1
+SELECT id, created_at, updated_at, user_id, feed_id, alias, category, notify FROM feed_follows WHERE user_id=$1
 `
 
 func (q *Queries) GetFeedFollows(ctx context.Context, userID uuid.UUID) ([]FeedFollow, error) {
@@ -78,6 +151,130 @@ func (q *Queries) GetFeedFollows(ctx context.Context, userID uuid.UUID) ([]FeedF
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.Alias,
+			&i.Category,
+			&i.Notify,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedFollowsPaginated = `-- name: GetFeedFollowsPaginated :many
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.alias, feed_follows.category, feed_follows.notify, feeds.name AS feed_name, feeds.last_post_at AS feed_last_post_at
+FROM feed_follows JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+  AND feed_follows.created_at < $2
+  AND ($3::text IS NULL OR feed_follows.category = $3)
+ORDER BY feed_follows.created_at DESC
+LIMIT $4
+`
+
+type GetFeedFollowsPaginatedParams struct {
+	UserID        uuid.UUID
+	CreatedBefore time.Time
+	Category      sql.NullString
+	RowLimit      int32
+}
+
+type GetFeedFollowsPaginatedRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         uuid.UUID
+	FeedID         uuid.UUID
+	Alias          sql.NullString
+	Category       sql.NullString
+	Notify         bool
+	FeedName       string
+	FeedLastPostAt sql.NullTime
+}
+
+// A page of the user's follows, newest first, optionally limited to one category
+func (q *Queries) GetFeedFollowsPaginated(ctx context.Context, arg GetFeedFollowsPaginatedParams) ([]GetFeedFollowsPaginatedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowsPaginated,
+		arg.UserID,
+		arg.CreatedBefore,
+		arg.Category,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFollowsPaginatedRow
+	for rows.Next() {
+		var i GetFeedFollowsPaginatedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Alias,
+			&i.Category,
+			&i.Notify,
+			&i.FeedName,
+			&i.FeedLastPostAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedFollowsWithFeedName = `-- name: GetFeedFollowsWithFeedName :many
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.alias, feed_follows.category, feed_follows.notify, feeds.name AS feed_name
+FROM feed_follows JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+`
+
+type GetFeedFollowsWithFeedNameRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	Alias     sql.NullString
+	Category  sql.NullString
+	Notify    bool
+	FeedName  string
+}
+
+func (q *Queries) GetFeedFollowsWithFeedName(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsWithFeedNameRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowsWithFeedName, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFollowsWithFeedNameRow
+	for rows.Next() {
+		var i GetFeedFollowsWithFeedNameRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Alias,
+			&i.Category,
+			&i.Notify,
+			&i.FeedName,
 		); err != nil {
 			return nil, err
 		}
@@ -93,22 +290,85 @@ func (q *Queries) GetFeedFollows(ctx context.Context, userID uuid.UUID) ([]FeedF
 }
 
 const getFollowersByFeedID = `-- name: GetFollowersByFeedID :many
-SELECT user_id FROM feed_follows WHERE feed_id =$1
+SELECT user_id, notify FROM feed_follows WHERE feed_id = $1
 `
 
-func (q *Queries) GetFollowersByFeedID(ctx context.Context, feedID uuid.UUID) ([]uuid.UUID, error) {
+type GetFollowersByFeedIDRow struct {
+	UserID uuid.UUID
+	Notify bool
+}
+
+// notify is false for followers who muted the feed's notifications
+func (q *Queries) GetFollowersByFeedID(ctx context.Context, feedID uuid.UUID) ([]GetFollowersByFeedIDRow, error) {
 	rows, err := q.db.QueryContext(ctx, getFollowersByFeedID, feedID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []uuid.UUID
+	var items []GetFollowersByFeedIDRow
 	for rows.Next() {
-		var user_id uuid.UUID
-		if err := rows.Scan(&user_id); err != nil {
+		var i GetFollowersByFeedIDRow
+		if err := rows.Scan(&i.UserID, &i.Notify); err != nil {
 			return nil, err
 		}
-		items = append(items, user_id)
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadCountsForUser = `-- name: GetUnreadCountsForUser :many
+SELECT feed_follows.id AS feed_follow_id, feed_follows.feed_id, feed_follows.alias,
+       feeds.name AS feed_name,
+       COUNT(posts.id) FILTER (WHERE post_reads.post_id IS NULL) AS unread_count
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN posts ON posts.feed_id = feed_follows.feed_id
+  AND (NOT $1::boolean OR posts.published_at >= feed_follows.created_at)
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $2
+GROUP BY feed_follows.id, feeds.name
+ORDER BY feed_follows.created_at
+`
+
+type GetUnreadCountsForUserParams struct {
+	AfterFollowOnly bool
+	UserID          uuid.UUID
+}
+
+type GetUnreadCountsForUserRow struct {
+	FeedFollowID uuid.UUID
+	FeedID       uuid.UUID
+	Alias        sql.NullString
+	FeedName     string
+	UnreadCount  int64
+}
+
+// One row per followed feed, counting its posts without a read by the user
+func (q *Queries) GetUnreadCountsForUser(ctx context.Context, arg GetUnreadCountsForUserParams) ([]GetUnreadCountsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadCountsForUser, arg.AfterFollowOnly, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadCountsForUserRow
+	for rows.Next() {
+		var i GetUnreadCountsForUserRow
+		if err := rows.Scan(
+			&i.FeedFollowID,
+			&i.FeedID,
+			&i.Alias,
+			&i.FeedName,
+			&i.UnreadCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -118,3 +378,69 @@ func (q *Queries) GetFollowersByFeedID(ctx context.Context, feedID uuid.UUID) ([
 	}
 	return items, nil
 }
+
+const moveFeedFollows = `-- name: MoveFeedFollows :execrows
+UPDATE feed_follows SET feed_id = $1 WHERE feed_id = $2
+`
+
+type MoveFeedFollowsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveFeedFollows, arg.ToFeedID, arg.FromFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateFeedFollow = `-- name: UpdateFeedFollow :one
+UPDATE feed_follows SET
+    alias = CASE WHEN $1::boolean THEN $2 ELSE alias END,
+    category = CASE WHEN $3::boolean THEN $4 ELSE category END,
+    notify = CASE WHEN $5::boolean THEN $6::boolean ELSE notify END,
+    updated_at = $7
+WHERE id = $8 AND user_id = $9
+RETURNING id, created_at, updated_at, user_id, feed_id, alias, category, notify
+`
+
+type UpdateFeedFollowParams struct {
+	SetAlias    bool
+	Alias       sql.NullString
+	SetCategory bool
+	Category    sql.NullString
+	SetNotify   bool
+	Notify      bool
+	UpdatedAt   time.Time
+	ID          uuid.UUID
+	UserID      uuid.UUID
+}
+
+// Sets the alias, category and/or notify; a field is left unchanged unless its set_ flag is true
+func (q *Queries) UpdateFeedFollow(ctx context.Context, arg UpdateFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedFollow,
+		arg.SetAlias,
+		arg.Alias,
+		arg.SetCategory,
+		arg.Category,
+		arg.SetNotify,
+		arg.Notify,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.UserID,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Alias,
+		&i.Category,
+		&i.Notify,
+	)
+	return i, err
+}
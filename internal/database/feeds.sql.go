@@ -16,7 +16,8 @@ import (
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, description, logo_url, priority)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, created_at, updated_at, name, url, user_id, description, logo_url, priority
+ON CONFLICT (url) DO NOTHING
+RETURNING id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length
 `
 
 type CreateFeedParams struct {
@@ -31,6 +32,7 @@ type CreateFeedParams struct {
 	Priority    int32
 }
 
+// Returns no row when a feed with the same URL already exists, e.g. one created concurrently
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
 	row := q.db.QueryRowContext(ctx, createFeed,
 		arg.ID,
@@ -54,12 +56,119 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Description,
 		&i.LogoUrl,
 		&i.Priority,
+		&i.LastBodyHash,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailureCount,
+		&i.LastFetchError,
+		&i.FollowerCount,
+		&i.LastPostAt,
+		&i.FetchFullContent,
+		&i.HttpEtag,
+		&i.HttpLastModified,
+		&i.HttpContentLength,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const feedNameTakenByUser = `-- name: FeedNameTakenByUser :one
+SELECT EXISTS (
+    SELECT 1 FROM feeds
+    WHERE user_id = $1
+      AND lower(name) = lower($2)
+      AND id <> $3
+)
+`
+
+type FeedNameTakenByUserParams struct {
+	UserID    uuid.UUID
+	Name      string
+	ExcludeID uuid.UUID
+}
+
+// Names are compared case-insensitively; exclude_id skips the feed being renamed
+func (q *Queries) FeedNameTakenByUser(ctx context.Context, arg FeedNameTakenByUserParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, feedNameTakenByUser, arg.UserID, arg.Name, arg.ExcludeID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const getFeedByID = `-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length FROM feeds WHERE id = $1
+`
+
+func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByID, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.Description,
+		&i.LogoUrl,
+		&i.Priority,
+		&i.LastBodyHash,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailureCount,
+		&i.LastFetchError,
+		&i.FollowerCount,
+		&i.LastPostAt,
+		&i.FetchFullContent,
+		&i.HttpEtag,
+		&i.HttpLastModified,
+		&i.HttpContentLength,
+	)
+	return i, err
+}
+
+const getFeedByURL = `-- name: GetFeedByURL :one
+SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length FROM feeds WHERE url = $1
+`
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.Description,
+		&i.LogoUrl,
+		&i.Priority,
+		&i.LastBodyHash,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailureCount,
+		&i.LastFetchError,
+		&i.FollowerCount,
+		&i.LastPostAt,
+		&i.FetchFullContent,
+		&i.HttpEtag,
+		&i.HttpLastModified,
+		&i.HttpContentLength,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -81,6 +190,17 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Description,
 			&i.LogoUrl,
 			&i.Priority,
+			&i.LastBodyHash,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailureCount,
+			&i.LastFetchError,
+			&i.FollowerCount,
+			&i.LastPostAt,
+			&i.FetchFullContent,
+			&i.HttpEtag,
+			&i.HttpLastModified,
+			&i.HttpContentLength,
 		); err != nil {
 			return nil, err
 		}
@@ -96,7 +216,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getFeedsByPriority = `-- name: GetFeedsByPriority :many
-SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority FROM feeds ORDER BY priority DESC, updated_at ASC
+SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length FROM feeds ORDER BY priority DESC, updated_at ASC
 `
 
 func (q *Queries) GetFeedsByPriority(ctx context.Context) ([]Feed, error) {
@@ -118,6 +238,168 @@ func (q *Queries) GetFeedsByPriority(ctx context.Context) ([]Feed, error) {
 			&i.Description,
 			&i.LogoUrl,
 			&i.Priority,
+			&i.LastBodyHash,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailureCount,
+			&i.LastFetchError,
+			&i.FollowerCount,
+			&i.LastPostAt,
+			&i.FetchFullContent,
+			&i.HttpEtag,
+			&i.HttpLastModified,
+			&i.HttpContentLength,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsDueForFetch = `-- name: GetFeedsDueForFetch :many
+SELECT id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length FROM feeds
+WHERE next_fetch_at IS NULL OR next_fetch_at <= $1
+ORDER BY priority DESC, next_fetch_at ASC NULLS FIRST
+`
+
+func (q *Queries) GetFeedsDueForFetch(ctx context.Context, nextFetchAt sql.NullTime) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsDueForFetch, nextFetchAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.Description,
+			&i.LogoUrl,
+			&i.Priority,
+			&i.LastBodyHash,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailureCount,
+			&i.LastFetchError,
+			&i.FollowerCount,
+			&i.LastPostAt,
+			&i.FetchFullContent,
+			&i.HttpEtag,
+			&i.HttpLastModified,
+			&i.HttpContentLength,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsWithStats = `-- name: GetFeedsWithStats :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.description, feeds.logo_url, feeds.priority, feeds.last_body_hash, feeds.next_fetch_at, feeds.fetch_interval_seconds, feeds.fetch_failure_count, feeds.last_fetch_error, feeds.follower_count, feeds.last_post_at, feeds.fetch_full_content, feeds.http_etag, feeds.http_last_modified, feeds.http_content_length,
+       COUNT(feed_follows.id) AS follow_count,
+       COALESCE(BOOL_OR(feed_follows.user_id = $1::uuid), false)::boolean AS is_followed
+FROM feeds
+LEFT JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feeds.created_at < $2
+  AND ($3::text IS NULL
+       OR feeds.name ILIKE $3
+       OR feeds.description ILIKE $3)
+GROUP BY feeds.id
+ORDER BY feeds.created_at DESC, feeds.id DESC
+LIMIT $4
+`
+
+type GetFeedsWithStatsParams struct {
+	UserID        uuid.NullUUID
+	CreatedAt     time.Time
+	SearchPattern sql.NullString
+	RowLimit      int32
+}
+
+type GetFeedsWithStatsRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	Description          sql.NullString
+	LogoUrl              sql.NullString
+	Priority             int32
+	LastBodyHash         sql.NullString
+	NextFetchAt          sql.NullTime
+	FetchIntervalSeconds int32
+	FetchFailureCount    int32
+	LastFetchError       sql.NullString
+	FollowerCount        int32
+	LastPostAt           sql.NullTime
+	FetchFullContent     bool
+	HttpEtag             sql.NullString
+	HttpLastModified     sql.NullString
+	HttpContentLength    sql.NullInt64
+	FollowCount          int64
+	IsFollowed           bool
+}
+
+// A page of feeds, newest first, with how many users follow each and whether
+// user_id does (always false when user_id is NULL), optionally limited to feeds
+// whose name or description matches search_pattern (an ILIKE pattern)
+func (q *Queries) GetFeedsWithStats(ctx context.Context, arg GetFeedsWithStatsParams) ([]GetFeedsWithStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithStats,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.SearchPattern,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsWithStatsRow
+	for rows.Next() {
+		var i GetFeedsWithStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.Description,
+			&i.LogoUrl,
+			&i.Priority,
+			&i.LastBodyHash,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.FetchFailureCount,
+			&i.LastFetchError,
+			&i.FollowerCount,
+			&i.LastPostAt,
+			&i.FetchFullContent,
+			&i.HttpEtag,
+			&i.HttpLastModified,
+			&i.HttpContentLength,
+			&i.FollowCount,
+			&i.IsFollowed,
 		); err != nil {
 			return nil, err
 		}
@@ -131,3 +413,254 @@ func (q *Queries) GetFeedsByPriority(ctx context.Context) ([]Feed, error) {
 	}
 	return items, nil
 }
+
+const incrementFeedFollowerCount = `-- name: IncrementFeedFollowerCount :exec
+UPDATE feeds SET follower_count = follower_count + 1 WHERE id = $1
+`
+
+func (q *Queries) IncrementFeedFollowerCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, incrementFeedFollowerCount, id)
+	return err
+}
+
+const reconcileFeedFollowerCounts = `-- name: ReconcileFeedFollowerCounts :execrows
+UPDATE feeds SET follower_count = counts.follower_count
+FROM (
+    SELECT f.id, COUNT(feed_follows.id)::integer AS follower_count
+    FROM feeds AS f LEFT JOIN feed_follows ON feed_follows.feed_id = f.id
+    GROUP BY f.id
+) AS counts
+WHERE feeds.id = counts.id AND feeds.follower_count <> counts.follower_count
+`
+
+// Recomputes every feed's cached follower count from feed_follows and
+// corrects the ones that drifted; returns how many feeds were corrected
+func (q *Queries) ReconcileFeedFollowerCounts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reconcileFeedFollowerCounts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordFeedFetchFailure = `-- name: RecordFeedFetchFailure :exec
+UPDATE feeds
+SET fetch_failure_count = fetch_failure_count + 1, last_fetch_error = $2
+WHERE id = $1
+`
+
+type RecordFeedFetchFailureParams struct {
+	ID             uuid.UUID
+	LastFetchError sql.NullString
+}
+
+func (q *Queries) RecordFeedFetchFailure(ctx context.Context, arg RecordFeedFetchFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFetchFailure, arg.ID, arg.LastFetchError)
+	return err
+}
+
+const resetFeedFetchFailures = `-- name: ResetFeedFetchFailures :exec
+UPDATE feeds
+SET fetch_failure_count = 0, last_fetch_error = NULL
+WHERE id = $1 AND fetch_failure_count > 0
+`
+
+func (q *Queries) ResetFeedFetchFailures(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, resetFeedFetchFailures, id)
+	return err
+}
+
+const updateFeed = `-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $1,
+    description = $2,
+    priority = $3,
+    fetch_full_content = $4,
+    updated_at = $5
+WHERE id = $6
+  AND user_id = $7
+  AND ($8::timestamp IS NULL OR updated_at = $8)
+RETURNING id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length
+`
+
+type UpdateFeedParams struct {
+	Name              string
+	Description       sql.NullString
+	Priority          int32
+	FetchFullContent  bool
+	UpdatedAt         time.Time
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	ExpectedUpdatedAt sql.NullTime
+}
+
+// When expected_updated_at is set the update only applies if nobody changed the feed since
+func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeed,
+		arg.Name,
+		arg.Description,
+		arg.Priority,
+		arg.FetchFullContent,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.UserID,
+		arg.ExpectedUpdatedAt,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.Description,
+		&i.LogoUrl,
+		&i.Priority,
+		&i.LastBodyHash,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailureCount,
+		&i.LastFetchError,
+		&i.FollowerCount,
+		&i.LastPostAt,
+		&i.FetchFullContent,
+		&i.HttpEtag,
+		&i.HttpLastModified,
+		&i.HttpContentLength,
+	)
+	return i, err
+}
+
+const updateFeedHTTPValidators = `-- name: UpdateFeedHTTPValidators :exec
+UPDATE feeds
+SET http_etag = $1,
+    http_last_modified = $2,
+    http_content_length = $3
+WHERE id = $4
+`
+
+type UpdateFeedHTTPValidatorsParams struct {
+	HttpEtag          sql.NullString
+	HttpLastModified  sql.NullString
+	HttpContentLength sql.NullInt64
+	ID                uuid.UUID
+}
+
+func (q *Queries) UpdateFeedHTTPValidators(ctx context.Context, arg UpdateFeedHTTPValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedHTTPValidators,
+		arg.HttpEtag,
+		arg.HttpLastModified,
+		arg.HttpContentLength,
+		arg.ID,
+	)
+	return err
+}
+
+const updateFeedLastBodyHash = `-- name: UpdateFeedLastBodyHash :exec
+UPDATE feeds SET last_body_hash = $2 WHERE id = $1
+`
+
+type UpdateFeedLastBodyHashParams struct {
+	ID           uuid.UUID
+	LastBodyHash sql.NullString
+}
+
+func (q *Queries) UpdateFeedLastBodyHash(ctx context.Context, arg UpdateFeedLastBodyHashParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedLastBodyHash, arg.ID, arg.LastBodyHash)
+	return err
+}
+
+const updateFeedLastPostAt = `-- name: UpdateFeedLastPostAt :exec
+UPDATE feeds SET last_post_at = $1
+WHERE id = $2 AND (last_post_at IS NULL OR last_post_at < $1)
+`
+
+type UpdateFeedLastPostAtParams struct {
+	LastPostAt sql.NullTime
+	ID         uuid.UUID
+}
+
+// Only moves last_post_at forward, so the row is left alone when nothing newer arrived
+func (q *Queries) UpdateFeedLastPostAt(ctx context.Context, arg UpdateFeedLastPostAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedLastPostAt, arg.LastPostAt, arg.ID)
+	return err
+}
+
+const updateFeedPriority = `-- name: UpdateFeedPriority :one
+UPDATE feeds
+SET priority = $1,
+    updated_at = $2
+WHERE id = $3
+  AND user_id = $4
+RETURNING id, created_at, updated_at, name, url, user_id, description, logo_url, priority, last_body_hash, next_fetch_at, fetch_interval_seconds, fetch_failure_count, last_fetch_error, follower_count, last_post_at, fetch_full_content, http_etag, http_last_modified, http_content_length
+`
+
+type UpdateFeedPriorityParams struct {
+	Priority  int32
+	UpdatedAt time.Time
+	ID        uuid.UUID
+	UserID    uuid.UUID
+}
+
+func (q *Queries) UpdateFeedPriority(ctx context.Context, arg UpdateFeedPriorityParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedPriority,
+		arg.Priority,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.Description,
+		&i.LogoUrl,
+		&i.Priority,
+		&i.LastBodyHash,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.FetchFailureCount,
+		&i.LastFetchError,
+		&i.FollowerCount,
+		&i.LastPostAt,
+		&i.FetchFullContent,
+		&i.HttpEtag,
+		&i.HttpLastModified,
+		&i.HttpContentLength,
+	)
+	return i, err
+}
+
+const updateFeedSchedule = `-- name: UpdateFeedSchedule :exec
+UPDATE feeds SET next_fetch_at = $2, fetch_interval_seconds = $3 WHERE id = $1
+`
+
+type UpdateFeedScheduleParams struct {
+	ID                   uuid.UUID
+	NextFetchAt          sql.NullTime
+	FetchIntervalSeconds int32
+}
+
+func (q *Queries) UpdateFeedSchedule(ctx context.Context, arg UpdateFeedScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedSchedule, arg.ID, arg.NextFetchAt, arg.FetchIntervalSeconds)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :exec
+UPDATE feeds SET url = $2 WHERE id = $1
+`
+
+type UpdateFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedURL, arg.ID, arg.Url)
+	return err
+}
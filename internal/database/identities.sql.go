@@ -0,0 +1,155 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: identities.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countIdentitiesByUser = `-- name: CountIdentitiesByUser :one
+SELECT COUNT(*) FROM identities WHERE user_id = $1
+`
+
+func (q *Queries) CountIdentitiesByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countIdentitiesByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createIdentity = `-- name: CreateIdentity :one
+INSERT INTO identities (id, user_id, provider, provider_user_id, email, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, provider, provider_user_id, email, created_at
+`
+
+type CreateIdentityParams struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	Provider       string
+	ProviderUserID string
+	Email          sql.NullString
+	CreatedAt      time.Time
+}
+
+func (q *Queries) CreateIdentity(ctx context.Context, arg CreateIdentityParams) (Identity, error) {
+	row := q.db.QueryRowContext(ctx, createIdentity,
+		arg.ID,
+		arg.UserID,
+		arg.Provider,
+		arg.ProviderUserID,
+		arg.Email,
+		arg.CreatedAt,
+	)
+	var i Identity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.ProviderUserID,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteIdentity = `-- name: DeleteIdentity :exec
+DELETE FROM identities WHERE id = $1 AND user_id = $2
+`
+
+type DeleteIdentityParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteIdentity(ctx context.Context, arg DeleteIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdentity, arg.ID, arg.UserID)
+	return err
+}
+
+const getIdentityByProvider = `-- name: GetIdentityByProvider :one
+SELECT id, user_id, provider, provider_user_id, email, created_at FROM identities WHERE provider = $1 AND provider_user_id = $2
+`
+
+type GetIdentityByProviderParams struct {
+	Provider       string
+	ProviderUserID string
+}
+
+func (q *Queries) GetIdentityByProvider(ctx context.Context, arg GetIdentityByProviderParams) (Identity, error) {
+	row := q.db.QueryRowContext(ctx, getIdentityByProvider, arg.Provider, arg.ProviderUserID)
+	var i Identity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.ProviderUserID,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getIdentityForUser = `-- name: GetIdentityForUser :one
+SELECT id, user_id, provider, provider_user_id, email, created_at FROM identities WHERE id = $1 AND user_id = $2
+`
+
+type GetIdentityForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetIdentityForUser(ctx context.Context, arg GetIdentityForUserParams) (Identity, error) {
+	row := q.db.QueryRowContext(ctx, getIdentityForUser, arg.ID, arg.UserID)
+	var i Identity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.ProviderUserID,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listIdentitiesByUser = `-- name: ListIdentitiesByUser :many
+SELECT id, user_id, provider, provider_user_id, email, created_at FROM identities WHERE user_id = $1 ORDER BY created_at ASC
+`
+
+func (q *Queries) ListIdentitiesByUser(ctx context.Context, userID uuid.UUID) ([]Identity, error) {
+	rows, err := q.db.QueryContext(ctx, listIdentitiesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Identity
+	for rows.Next() {
+		var i Identity
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Provider,
+			&i.ProviderUserID,
+			&i.Email,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
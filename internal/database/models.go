@@ -11,16 +11,40 @@ import (
 	"github.com/google/uuid"
 )
 
+type Bookmark struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
 type Feed struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Name        string
-	Url         string
-	UserID      uuid.UUID
-	Description sql.NullString
-	LogoUrl     sql.NullString
-	Priority    int32
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	Description          sql.NullString
+	LogoUrl              sql.NullString
+	Priority             int32
+	LastBodyHash         sql.NullString
+	NextFetchAt          sql.NullTime
+	FetchIntervalSeconds int32
+	FetchFailureCount    int32
+	LastFetchError       sql.NullString
+	FollowerCount        int32
+	LastPostAt           sql.NullTime
+	FetchFullContent     bool
+	HttpEtag             sql.NullString
+	HttpLastModified     sql.NullString
+	HttpContentLength    sql.NullInt64
+}
+
+type FeedActivity struct {
+	FeedID      uuid.UUID
+	Granularity string
+	BucketStart time.Time
+	PostCount   int32
 }
 
 type FeedFollow struct {
@@ -29,25 +53,68 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Alias     sql.NullString
+	Category  sql.NullString
+	Notify    bool
 }
 
-type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+type Identity struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	Provider       string
+	ProviderUserID string
+	Email          sql.NullString
+	CreatedAt      time.Time
 }
 
-type RefreshToken struct {
+type Notification struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	Type      string
+	PostCount int32
+	CreatedAt time.Time
+	AckedAt   sql.NullTime
+}
+
+type Post struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
+	FullContent          sql.NullString
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type RefreshToken struct {
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	TokenHash         string
+	ExpiresAt         time.Time
+	CreatedAt         time.Time
+	PreviousTokenHash sql.NullString
+	SessionID         uuid.UUID
+	DeviceLabel       string
+	UserAgentHash     string
+	IpPrefix          string
+}
+
+type RotatedRefreshToken struct {
 	TokenHash string
+	UserID    uuid.UUID
+	SessionID uuid.UUID
 	ExpiresAt time.Time
-	CreatedAt time.Time
 }
 
 type User struct {
@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notifications.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const ackNotifications = `-- name: AckNotifications :execrows
+UPDATE notifications SET acked_at = $1
+WHERE user_id = $2 AND id = ANY($3::uuid[]) AND acked_at IS NULL
+`
+
+type AckNotificationsParams struct {
+	AckedAt sql.NullTime
+	UserID  uuid.UUID
+	Ids     []uuid.UUID
+}
+
+func (q *Queries) AckNotifications(ctx context.Context, arg AckNotificationsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, ackNotifications, arg.AckedAt, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createNotifications = `-- name: CreateNotifications :exec
+INSERT INTO notifications (id, user_id, feed_id, type, post_count, created_at)
+SELECT unnest($1::uuid[]), unnest($2::uuid[]),
+    $3, $4, $5, $6
+`
+
+type CreateNotificationsParams struct {
+	Ids       []uuid.UUID
+	UserIds   []uuid.UUID
+	FeedID    uuid.UUID
+	Type      string
+	PostCount int32
+	CreatedAt time.Time
+}
+
+// One notification per user, ids[i] being the id of user_ids[i]'s notification
+func (q *Queries) CreateNotifications(ctx context.Context, arg CreateNotificationsParams) error {
+	_, err := q.db.ExecContext(ctx, createNotifications,
+		pq.Array(arg.Ids),
+		pq.Array(arg.UserIds),
+		arg.FeedID,
+		arg.Type,
+		arg.PostCount,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const deleteStaleNotifications = `-- name: DeleteStaleNotifications :execrows
+DELETE FROM notifications
+WHERE acked_at IS NOT NULL OR created_at < $1
+`
+
+// Acknowledged notifications are never listed again; unacknowledged ones expire
+func (q *Queries) DeleteStaleNotifications(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStaleNotifications, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listUnackedNotifications = `-- name: ListUnackedNotifications :many
+SELECT notifications.id, notifications.feed_id, feeds.name AS feed_name, notifications.type,
+    notifications.post_count, notifications.created_at
+FROM notifications JOIN feeds ON notifications.feed_id = feeds.id
+WHERE notifications.user_id = $1 AND notifications.acked_at IS NULL
+ORDER BY notifications.created_at
+LIMIT $2
+`
+
+type ListUnackedNotificationsParams struct {
+	UserID   uuid.UUID
+	RowLimit int32
+}
+
+type ListUnackedNotificationsRow struct {
+	ID        uuid.UUID
+	FeedID    uuid.UUID
+	FeedName  string
+	Type      string
+	PostCount int32
+	CreatedAt time.Time
+}
+
+func (q *Queries) ListUnackedNotifications(ctx context.Context, arg ListUnackedNotificationsParams) ([]ListUnackedNotificationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnackedNotifications, arg.UserID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnackedNotificationsRow
+	for rows.Next() {
+		var i ListUnackedNotificationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.FeedName,
+			&i.Type,
+			&i.PostCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
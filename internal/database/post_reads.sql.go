@@ -0,0 +1,130 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: post_reads.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const getReadPostIDs = `-- name: GetReadPostIDs :many
+SELECT post_id FROM post_reads
+WHERE user_id = $1 AND post_id = ANY($2::uuid[])
+`
+
+type GetReadPostIDsParams struct {
+	UserID  uuid.UUID
+	PostIds []uuid.UUID
+}
+
+// The ids among post_ids the user has marked read
+func (q *Queries) GetReadPostIDs(ctx context.Context, arg GetReadPostIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getReadPostIDs, arg.UserID, pq.Array(arg.PostIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var post_id uuid.UUID
+		if err := rows.Scan(&post_id); err != nil {
+			return nil, err
+		}
+		items = append(items, post_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isFollowedPost = `-- name: IsFollowedPost :one
+SELECT EXISTS (
+    SELECT 1 FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+    WHERE feed_follows.user_id = $1 AND posts.id = $2
+)
+`
+
+type IsFollowedPostParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+// Whether the post is in one of the user's followed feeds
+func (q *Queries) IsFollowedPost(ctx context.Context, arg IsFollowedPostParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isFollowedPost, arg.UserID, arg.PostID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const markPostRead = `-- name: MarkPostRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT $1, posts.id, $2
+FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $3
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = post_reads.read_at
+`
+
+type MarkPostReadParams struct {
+	UserID uuid.UUID
+	ReadAt time.Time
+	PostID uuid.UUID
+}
+
+// Marks one post read, only if it is in one of the user's followed feeds
+func (q *Queries) MarkPostRead(ctx context.Context, arg MarkPostReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.ReadAt, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPostsRead = `-- name: MarkPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT $1, posts.id, $2
+FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = ANY($3::uuid[])
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type MarkPostsReadParams struct {
+	UserID  uuid.UUID
+	ReadAt  time.Time
+	PostIds []uuid.UUID
+}
+
+func (q *Queries) MarkPostsRead(ctx context.Context, arg MarkPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostsRead, arg.UserID, arg.ReadAt, pq.Array(arg.PostIds))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unmarkPostRead = `-- name: UnmarkPostRead :execrows
+DELETE FROM post_reads WHERE user_id = $1 AND post_id = $2
+`
+
+type UnmarkPostReadParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) UnmarkPostRead(ctx context.Context, arg UnmarkPostReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unmarkPostRead, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -13,23 +13,43 @@ import (
 	"github.com/google/uuid"
 )
 
+const countPostsByFeedSince = `-- name: CountPostsByFeedSince :one
+SELECT COUNT(*) FROM posts WHERE feed_id = $1 AND published_at >= $2
+`
+
+type CountPostsByFeedSinceParams struct {
+	FeedID      uuid.UUID
+	PublishedAt time.Time
+}
+
+func (q *Queries) CountPostsByFeedSince(ctx context.Context, arg CountPostsByFeedSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsByFeedSince, arg.FeedID, arg.PublishedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
 INSERT INTO posts (id, created_at, updated_at,
-                   title, url, description, published_at, feed_id)
+                   title, url, description, published_at, feed_id,
+                   description_truncated, full_description)
 VALUES ($1, $2, $3, $4,
-        $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+        $5, $6, $7, $8,
+        $9, $10)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, description_truncated, full_description, full_content
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -42,6 +62,8 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.DescriptionTruncated,
+		arg.FullDescription,
 	)
 	var i Post
 	err := row.Scan(
@@ -53,25 +75,150 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.DescriptionTruncated,
+		&i.FullDescription,
+		&i.FullContent,
+	)
+	return i, err
+}
+
+const deleteOrphanedPosts = `-- name: DeleteOrphanedPosts :execrows
+DELETE FROM posts
+WHERE posts.id IN (
+    SELECT orphan.id FROM posts AS orphan
+    LEFT JOIN feeds ON feeds.id = orphan.feed_id
+    WHERE feeds.id IS NULL
+    LIMIT $1
+)
+`
+
+// Deletes up to batch_size posts whose feed no longer exists
+func (q *Queries) DeleteOrphanedPosts(ctx context.Context, batchSize int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrphanedPosts, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePostsByFeed = `-- name: DeletePostsByFeed :execrows
+DELETE FROM posts WHERE feed_id = $1
+`
+
+func (q *Queries) DeletePostsByFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsByFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFollowedPostContent = `-- name: GetFollowedPostContent :one
+SELECT posts.id, posts.description, posts.description_truncated, posts.full_description, posts.full_content
+FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE posts.id = $1 AND feed_follows.user_id = $2
+`
+
+type GetFollowedPostContentParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type GetFollowedPostContentRow struct {
+	ID                   uuid.UUID
+	Description          sql.NullString
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
+	FullContent          sql.NullString
+}
+
+// The stored content of a post, only if it is in one of the user's followed feeds
+func (q *Queries) GetFollowedPostContent(ctx context.Context, arg GetFollowedPostContentParams) (GetFollowedPostContentRow, error) {
+	row := q.db.QueryRowContext(ctx, getFollowedPostContent, arg.ID, arg.UserID)
+	var i GetFollowedPostContentRow
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.DescriptionTruncated,
+		&i.FullDescription,
+		&i.FullContent,
 	)
 	return i, err
 }
 
+const getPostsByFeed = `-- name: GetPostsByFeed :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, description_truncated, full_description, full_content FROM posts
+WHERE feed_id = $1 AND published_at < $2
+ORDER BY published_at DESC, id DESC
+LIMIT $3
+`
+
+type GetPostsByFeedParams struct {
+	FeedID      uuid.UUID
+	PublishedAt time.Time
+	Limit       int32
+}
+
+func (q *Queries) GetPostsByFeed(ctx context.Context, arg GetPostsByFeedParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByFeed, arg.FeedID, arg.PublishedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id from posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content from posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
 WHERE feed_follows.user_id = $1 AND posts.published_at < $2
-ORDER BY posts.published_at DESC
-LIMIT $3
+  AND (NOT $3::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $4
 `
 
 type GetPostsForUserParams struct {
 	UserID      uuid.UUID
 	PublishedAt time.Time
-	Limit       int32
+	UnreadOnly  bool
+	RowLimit    int32
 }
 
+// With unread_only, posts the user has read are left out
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.PublishedAt, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.UnreadOnly,
+		arg.RowLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +235,9 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
 		); err != nil {
 			return nil, err
 		}
@@ -101,3 +251,409 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getPostsForUserAfterFollow = `-- name: GetPostsForUserAfterFollow :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content from posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND posts.published_at < $2
+  AND posts.published_at >= feed_follows.created_at
+  AND (NOT $3::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $4
+`
+
+type GetPostsForUserAfterFollowParams struct {
+	UserID      uuid.UUID
+	PublishedAt time.Time
+	UnreadOnly  bool
+	RowLimit    int32
+}
+
+func (q *Queries) GetPostsForUserAfterFollow(ctx context.Context, arg GetPostsForUserAfterFollowParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserAfterFollow,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.UnreadOnly,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserOldestFirst = `-- name: GetPostsForUserOldestFirst :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND posts.published_at > $2
+  AND (NOT $3::boolean OR posts.published_at >= feed_follows.created_at)
+  AND (NOT $4::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at ASC, posts.id ASC
+LIMIT $5
+`
+
+type GetPostsForUserOldestFirstParams struct {
+	UserID          uuid.UUID
+	PublishedAt     time.Time
+	AfterFollowOnly bool
+	UnreadOnly      bool
+	RowLimit        int32
+}
+
+// GetPostsForUser/GetPostsForUserAfterFollow in chronological order: the page after
+// published_at, oldest first
+func (q *Queries) GetPostsForUserOldestFirst(ctx context.Context, arg GetPostsForUserOldestFirstParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserOldestFirst,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.AfterFollowOnly,
+		arg.UnreadOnly,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserWithFeed = `-- name: GetPostsForUserWithFeed :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content,
+       feeds.name AS feed_name, feeds.url AS feed_url, feeds.logo_url AS feed_logo_url,
+       feed_follows.alias AS feed_alias
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.published_at < $2
+  AND (NOT $3::boolean OR posts.published_at >= feed_follows.created_at)
+  AND (NOT $4::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $5
+`
+
+type GetPostsForUserWithFeedParams struct {
+	UserID          uuid.UUID
+	PublishedAt     time.Time
+	AfterFollowOnly bool
+	UnreadOnly      bool
+	RowLimit        int32
+}
+
+type GetPostsForUserWithFeedRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
+	FullContent          sql.NullString
+	FeedName             string
+	FeedUrl              string
+	FeedLogoUrl          sql.NullString
+	FeedAlias            sql.NullString
+}
+
+// Same page as GetPostsForUser/GetPostsForUserAfterFollow, joined with each post's feed
+func (q *Queries) GetPostsForUserWithFeed(ctx context.Context, arg GetPostsForUserWithFeedParams) ([]GetPostsForUserWithFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserWithFeed,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.AfterFollowOnly,
+		arg.UnreadOnly,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserWithFeedRow
+	for rows.Next() {
+		var i GetPostsForUserWithFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedLogoUrl,
+			&i.FeedAlias,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserWithFeedOldestFirst = `-- name: GetPostsForUserWithFeedOldestFirst :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content,
+       feeds.name AS feed_name, feeds.url AS feed_url, feeds.logo_url AS feed_logo_url,
+       feed_follows.alias AS feed_alias
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.published_at > $2
+  AND (NOT $3::boolean OR posts.published_at >= feed_follows.created_at)
+  AND (NOT $4::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at ASC, posts.id ASC
+LIMIT $5
+`
+
+type GetPostsForUserWithFeedOldestFirstParams struct {
+	UserID          uuid.UUID
+	PublishedAt     time.Time
+	AfterFollowOnly bool
+	UnreadOnly      bool
+	RowLimit        int32
+}
+
+type GetPostsForUserWithFeedOldestFirstRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
+	FullContent          sql.NullString
+	FeedName             string
+	FeedUrl              string
+	FeedLogoUrl          sql.NullString
+	FeedAlias            sql.NullString
+}
+
+// Same page as GetPostsForUserOldestFirst, joined with each post's feed
+func (q *Queries) GetPostsForUserWithFeedOldestFirst(ctx context.Context, arg GetPostsForUserWithFeedOldestFirstParams) ([]GetPostsForUserWithFeedOldestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserWithFeedOldestFirst,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.AfterFollowOnly,
+		arg.UnreadOnly,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserWithFeedOldestFirstRow
+	for rows.Next() {
+		var i GetPostsForUserWithFeedOldestFirstRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedLogoUrl,
+			&i.FeedAlias,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrendingPostCandidates = `-- name: GetTrendingPostCandidates :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.description_truncated, posts.full_description, posts.full_content,
+       feeds.name AS feed_name, feeds.url AS feed_url, feeds.logo_url AS feed_logo_url,
+       feeds.follower_count AS feed_follower_count
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feeds.follower_count > 0
+  AND posts.published_at >= $1
+  AND posts.published_at <= $2
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $3
+`
+
+type GetTrendingPostCandidatesParams struct {
+	PublishedSince  time.Time
+	PublishedBefore time.Time
+	RowLimit        int32
+}
+
+type GetTrendingPostCandidatesRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          time.Time
+	FeedID               uuid.UUID
+	DescriptionTruncated bool
+	FullDescription      sql.NullString
+	FullContent          sql.NullString
+	FeedName             string
+	FeedUrl              string
+	FeedLogoUrl          sql.NullString
+	FeedFollowerCount    int32
+}
+
+// The newest posts published in [published_since, published_before] from feeds with
+// followers, joined with their feed; the handler ranks them by trending score
+func (q *Queries) GetTrendingPostCandidates(ctx context.Context, arg GetTrendingPostCandidatesParams) ([]GetTrendingPostCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTrendingPostCandidates, arg.PublishedSince, arg.PublishedBefore, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrendingPostCandidatesRow
+	for rows.Next() {
+		var i GetTrendingPostCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.DescriptionTruncated,
+			&i.FullDescription,
+			&i.FullContent,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedLogoUrl,
+			&i.FeedFollowerCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const movePosts = `-- name: MovePosts :execrows
+UPDATE posts SET feed_id = $1 WHERE feed_id = $2
+`
+
+type MovePostsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MovePosts(ctx context.Context, arg MovePostsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePosts, arg.ToFeedID, arg.FromFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updatePostFullContent = `-- name: UpdatePostFullContent :exec
+UPDATE posts SET full_content = $2 WHERE id = $1
+`
+
+type UpdatePostFullContentParams struct {
+	ID          uuid.UUID
+	FullContent sql.NullString
+}
+
+// Stores the article content extracted for a post of a feed with fetch_full_content
+func (q *Queries) UpdatePostFullContent(ctx context.Context, arg UpdatePostFullContentParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostFullContent, arg.ID, arg.FullContent)
+	return err
+}
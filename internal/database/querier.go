@@ -0,0 +1,137 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Querier interface {
+	AckNotifications(ctx context.Context, arg AckNotificationsParams) (int64, error)
+	CountFeedFollowsByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountIdentitiesByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountPostsByFeedSince(ctx context.Context, arg CountPostsByFeedSinceParams) (int64, error)
+	// Bookmarking a post twice keeps the first bookmark
+	CreateBookmark(ctx context.Context, arg CreateBookmarkParams) error
+	// Returns no row when a feed with the same URL already exists, e.g. one created concurrently
+	CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error)
+	CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (FeedFollow, error)
+	CreateIdentity(ctx context.Context, arg CreateIdentityParams) (Identity, error)
+	// One notification per user, ids[i] being the id of user_ids[i]'s notification
+	CreateNotifications(ctx context.Context, arg CreateNotificationsParams) error
+	CreatePost(ctx context.Context, arg CreatePostParams) (Post, error)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateRotatedRefreshToken(ctx context.Context, arg CreateRotatedRefreshTokenParams) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams) error
+	DeleteExpiredRotatedRefreshTokens(ctx context.Context, arg DeleteExpiredRotatedRefreshTokensParams) error
+	DeleteFeed(ctx context.Context, id uuid.UUID) error
+	// Also decrements the feed's cached follower count when a follow was deleted
+	DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error
+	// Drops follows of from_feed_id whose user already follows to_feed_id
+	DeleteFeedFollowsAlreadyOnFeed(ctx context.Context, arg DeleteFeedFollowsAlreadyOnFeedParams) (int64, error)
+	// Returns the users who followed the feed
+	DeleteFeedFollowsByFeed(ctx context.Context, feedID uuid.UUID) ([]uuid.UUID, error)
+	// Hour buckets are recorded alongside day buckets, so old ones carry no extra information
+	DeleteHourlyFeedActivityBefore(ctx context.Context, bucketStartBefore time.Time) (int64, error)
+	DeleteIdentity(ctx context.Context, arg DeleteIdentityParams) error
+	// Deletes up to batch_size posts whose feed no longer exists
+	DeleteOrphanedPosts(ctx context.Context, batchSize int32) (int64, error)
+	DeletePostsByFeed(ctx context.Context, feedID uuid.UUID) (int64, error)
+	DeleteRefreshToken(ctx context.Context, userID uuid.UUID) error
+	DeleteRefreshTokenByHash(ctx context.Context, arg DeleteRefreshTokenByHashParams) (int64, error)
+	DeleteSessionForUser(ctx context.Context, arg DeleteSessionForUserParams) (int64, error)
+	// Acknowledged notifications are never listed again; unacknowledged ones expire
+	DeleteStaleNotifications(ctx context.Context, createdBefore time.Time) (int64, error)
+	// Names are compared case-insensitively; exclude_id skips the feed being renamed
+	FeedNameTakenByUser(ctx context.Context, arg FeedNameTakenByUserParams) (bool, error)
+	// The page of the user's posts bookmarked before bookmarked_before, most recently bookmarked first
+	GetBookmarksForUser(ctx context.Context, arg GetBookmarksForUserParams) ([]GetBookmarksForUserRow, error)
+	GetFeedActivity(ctx context.Context, arg GetFeedActivityParams) ([]GetFeedActivityRow, error)
+	GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error)
+	GetFeedByURL(ctx context.Context, url string) (Feed, error)
+	GetFeedFollows(ctx context.Context, userID uuid.UUID) ([]FeedFollow, error)
+	// A page of the user's follows, newest first, optionally limited to one category
+	GetFeedFollowsPaginated(ctx context.Context, arg GetFeedFollowsPaginatedParams) ([]GetFeedFollowsPaginatedRow, error)
+	GetFeedFollowsWithFeedName(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsWithFeedNameRow, error)
+	GetFeeds(ctx context.Context) ([]Feed, error)
+	GetFeedsByPriority(ctx context.Context) ([]Feed, error)
+	GetFeedsDueForFetch(ctx context.Context, nextFetchAt sql.NullTime) ([]Feed, error)
+	// A page of feeds, newest first, with how many users follow each and whether
+	// user_id does (always false when user_id is NULL), optionally limited to feeds
+	// whose name or description matches search_pattern (an ILIKE pattern)
+	GetFeedsWithStats(ctx context.Context, arg GetFeedsWithStatsParams) ([]GetFeedsWithStatsRow, error)
+	// The stored content of a post, only if it is in one of the user's followed feeds
+	GetFollowedPostContent(ctx context.Context, arg GetFollowedPostContentParams) (GetFollowedPostContentRow, error)
+	// notify is false for followers who muted the feed's notifications
+	GetFollowersByFeedID(ctx context.Context, feedID uuid.UUID) ([]GetFollowersByFeedIDRow, error)
+	GetIdentityByProvider(ctx context.Context, arg GetIdentityByProviderParams) (Identity, error)
+	GetIdentityForUser(ctx context.Context, arg GetIdentityForUserParams) (Identity, error)
+	GetPlatformStats(ctx context.Context) (GetPlatformStatsRow, error)
+	GetPostsByFeed(ctx context.Context, arg GetPostsByFeedParams) ([]Post, error)
+	// With unread_only, posts the user has read are left out
+	GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error)
+	GetPostsForUserAfterFollow(ctx context.Context, arg GetPostsForUserAfterFollowParams) ([]Post, error)
+	// GetPostsForUser/GetPostsForUserAfterFollow in chronological order: the page after
+	// published_at, oldest first
+	GetPostsForUserOldestFirst(ctx context.Context, arg GetPostsForUserOldestFirstParams) ([]Post, error)
+	// Same page as GetPostsForUser/GetPostsForUserAfterFollow, joined with each post's feed
+	GetPostsForUserWithFeed(ctx context.Context, arg GetPostsForUserWithFeedParams) ([]GetPostsForUserWithFeedRow, error)
+	// Same page as GetPostsForUserOldestFirst, joined with each post's feed
+	GetPostsForUserWithFeedOldestFirst(ctx context.Context, arg GetPostsForUserWithFeedOldestFirstParams) ([]GetPostsForUserWithFeedOldestFirstRow, error)
+	// The ids among post_ids the user has marked read
+	GetReadPostIDs(ctx context.Context, arg GetReadPostIDsParams) ([]uuid.UUID, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetRefreshTokensByUserID(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error)
+	GetRotatedRefreshToken(ctx context.Context, tokenHash string) (RotatedRefreshToken, error)
+	// The newest posts published in [published_since, published_before] from feeds with
+	// followers, joined with their feed; the handler ranks them by trending score
+	GetTrendingPostCandidates(ctx context.Context, arg GetTrendingPostCandidatesParams) ([]GetTrendingPostCandidatesRow, error)
+	// One row per followed feed, counting its posts without a read by the user
+	GetUnreadCountsForUser(ctx context.Context, arg GetUnreadCountsForUserParams) ([]GetUnreadCountsForUserRow, error)
+	// Case-insensitive, so accounts registered before emails were lowercased are still found
+	GetUserByEmail(ctx context.Context, email sql.NullString) (User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	IncrementFeedActivity(ctx context.Context, arg IncrementFeedActivityParams) error
+	IncrementFeedFollowerCount(ctx context.Context, id uuid.UUID) error
+	// Whether the post is in one of the user's followed feeds
+	IsFollowedPost(ctx context.Context, arg IsFollowedPostParams) (bool, error)
+	ListIdentitiesByUser(ctx context.Context, userID uuid.UUID) ([]Identity, error)
+	ListUnackedNotifications(ctx context.Context, arg ListUnackedNotificationsParams) ([]ListUnackedNotificationsRow, error)
+	// Serializes concurrent transactions acting on behalf of the same user
+	LockUserForUpdate(ctx context.Context, id uuid.UUID) error
+	// Marks one post read, only if it is in one of the user's followed feeds
+	MarkPostRead(ctx context.Context, arg MarkPostReadParams) (int64, error)
+	MarkPostsRead(ctx context.Context, arg MarkPostsReadParams) (int64, error)
+	// Adds from_feed_id's buckets onto to_feed_id's
+	MergeFeedActivity(ctx context.Context, arg MergeFeedActivityParams) error
+	MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) (int64, error)
+	MovePosts(ctx context.Context, arg MovePostsParams) (int64, error)
+	// Recomputes every feed's cached follower count from feed_follows and
+	// corrects the ones that drifted; returns how many feeds were corrected
+	ReconcileFeedFollowerCounts(ctx context.Context) (int64, error)
+	RecordFeedFetchFailure(ctx context.Context, arg RecordFeedFetchFailureParams) error
+	ResetFeedFetchFailures(ctx context.Context, id uuid.UUID) error
+	UnmarkPostRead(ctx context.Context, arg UnmarkPostReadParams) (int64, error)
+	// When expected_updated_at is set the update only applies if nobody changed the feed since
+	UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error)
+	// Sets the alias, category and/or notify; a field is left unchanged unless its set_ flag is true
+	UpdateFeedFollow(ctx context.Context, arg UpdateFeedFollowParams) (FeedFollow, error)
+	UpdateFeedHTTPValidators(ctx context.Context, arg UpdateFeedHTTPValidatorsParams) error
+	UpdateFeedLastBodyHash(ctx context.Context, arg UpdateFeedLastBodyHashParams) error
+	// Only moves last_post_at forward, so the row is left alone when nothing newer arrived
+	UpdateFeedLastPostAt(ctx context.Context, arg UpdateFeedLastPostAtParams) error
+	UpdateFeedPriority(ctx context.Context, arg UpdateFeedPriorityParams) (Feed, error)
+	UpdateFeedSchedule(ctx context.Context, arg UpdateFeedScheduleParams) error
+	UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) error
+	// Stores the article content extracted for a post of a feed with fetch_full_content
+	UpdatePostFullContent(ctx context.Context, arg UpdatePostFullContentParams) error
+}
+
+var _ Querier = (*Queries)(nil)
@@ -7,23 +7,30 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, token_hash, expires_at, created_at
+INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at, previous_token_hash, session_id, device_label,
+                            user_agent_hash, ip_prefix)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, user_id, token_hash, expires_at, created_at, previous_token_hash, session_id, device_label, user_agent_hash, ip_prefix
 `
 
 type CreateRefreshTokenParams struct {
-	ID        uuid.UUID
-	UserID    uuid.UUID
-	TokenHash string
-	ExpiresAt time.Time
-	CreatedAt time.Time
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	TokenHash         string
+	ExpiresAt         time.Time
+	CreatedAt         time.Time
+	PreviousTokenHash sql.NullString
+	SessionID         uuid.UUID
+	DeviceLabel       string
+	UserAgentHash     string
+	IpPrefix          string
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
@@ -33,6 +40,11 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		arg.TokenHash,
 		arg.ExpiresAt,
 		arg.CreatedAt,
+		arg.PreviousTokenHash,
+		arg.SessionID,
+		arg.DeviceLabel,
+		arg.UserAgentHash,
+		arg.IpPrefix,
 	)
 	var i RefreshToken
 	err := row.Scan(
@@ -41,10 +53,52 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.PreviousTokenHash,
+		&i.SessionID,
+		&i.DeviceLabel,
+		&i.UserAgentHash,
+		&i.IpPrefix,
 	)
 	return i, err
 }
 
+const createRotatedRefreshToken = `-- name: CreateRotatedRefreshToken :exec
+INSERT INTO rotated_refresh_tokens (token_hash, user_id, session_id, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (token_hash) DO NOTHING
+`
+
+type CreateRotatedRefreshTokenParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateRotatedRefreshToken(ctx context.Context, arg CreateRotatedRefreshTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createRotatedRefreshToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.SessionID,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteExpiredRotatedRefreshTokens = `-- name: DeleteExpiredRotatedRefreshTokens :exec
+DELETE FROM rotated_refresh_tokens WHERE user_id = $1 AND expires_at < $2
+`
+
+type DeleteExpiredRotatedRefreshTokensParams struct {
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+func (q *Queries) DeleteExpiredRotatedRefreshTokens(ctx context.Context, arg DeleteExpiredRotatedRefreshTokensParams) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredRotatedRefreshTokens, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
 const deleteRefreshToken = `-- name: DeleteRefreshToken :exec
 DELETE FROM refresh_tokens WHERE user_id = $1
 `
@@ -54,8 +108,42 @@ func (q *Queries) DeleteRefreshToken(ctx context.Context, userID uuid.UUID) erro
 	return err
 }
 
+const deleteRefreshTokenByHash = `-- name: DeleteRefreshTokenByHash :execrows
+DELETE FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2
+`
+
+type DeleteRefreshTokenByHashParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+}
+
+func (q *Queries) DeleteRefreshTokenByHash(ctx context.Context, arg DeleteRefreshTokenByHashParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRefreshTokenByHash, arg.TokenHash, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSessionForUser = `-- name: DeleteSessionForUser :execrows
+DELETE FROM refresh_tokens WHERE session_id = $1 AND user_id = $2
+`
+
+type DeleteSessionForUserParams struct {
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+}
+
+func (q *Queries) DeleteSessionForUser(ctx context.Context, arg DeleteSessionForUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSessionForUser, arg.SessionID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getRefreshTokenByHash = `-- name: GetRefreshTokenByHash :one
-SELECT id, user_id, token_hash, expires_at, created_at FROM refresh_tokens WHERE token_hash = $1
+SELECT id, user_id, token_hash, expires_at, created_at, previous_token_hash, session_id, device_label, user_agent_hash, ip_prefix FROM refresh_tokens WHERE token_hash = $1
 `
 
 func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
@@ -67,6 +155,65 @@ func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (
 		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.PreviousTokenHash,
+		&i.SessionID,
+		&i.DeviceLabel,
+		&i.UserAgentHash,
+		&i.IpPrefix,
+	)
+	return i, err
+}
+
+const getRefreshTokensByUserID = `-- name: GetRefreshTokensByUserID :many
+SELECT id, user_id, token_hash, expires_at, created_at, previous_token_hash, session_id, device_label, user_agent_hash, ip_prefix FROM refresh_tokens WHERE user_id = $1 ORDER BY created_at DESC
+`
+
+func (q *Queries) GetRefreshTokensByUserID(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, getRefreshTokensByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.PreviousTokenHash,
+			&i.SessionID,
+			&i.DeviceLabel,
+			&i.UserAgentHash,
+			&i.IpPrefix,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRotatedRefreshToken = `-- name: GetRotatedRefreshToken :one
+SELECT token_hash, user_id, session_id, expires_at FROM rotated_refresh_tokens WHERE token_hash = $1
+`
+
+func (q *Queries) GetRotatedRefreshToken(ctx context.Context, tokenHash string) (RotatedRefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRotatedRefreshToken, tokenHash)
+	var i RotatedRefreshToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.SessionID,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
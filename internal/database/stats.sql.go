@@ -0,0 +1,37 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: stats.sql
+
+package database
+
+import (
+	"context"
+)
+
+const getPlatformStats = `-- name: GetPlatformStats :one
+SELECT
+    (SELECT COUNT(*) FROM users) AS users,
+    (SELECT COUNT(*) FROM feeds) AS feeds,
+    (SELECT COUNT(*) FROM posts) AS posts,
+    (SELECT COUNT(*) FROM feed_follows) AS follows
+`
+
+type GetPlatformStatsRow struct {
+	Users   int64
+	Feeds   int64
+	Posts   int64
+	Follows int64
+}
+
+func (q *Queries) GetPlatformStats(ctx context.Context) (GetPlatformStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getPlatformStats)
+	var i GetPlatformStatsRow
+	err := row.Scan(
+		&i.Users,
+		&i.Feeds,
+		&i.Posts,
+		&i.Follows,
+	)
+	return i, err
+}
@@ -50,9 +50,10 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, name, email, password_hash FROM users WHERE email = $1
+SELECT id, created_at, updated_at, name, email, password_hash FROM users WHERE lower(email) = lower($1)
 `
 
+// Case-insensitive, so accounts registered before emails were lowercased are still found
 func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
 	var i User
@@ -84,3 +85,13 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 	)
 	return i, err
 }
+
+const lockUserForUpdate = `-- name: LockUserForUpdate :exec
+SELECT id FROM users WHERE id = $1 FOR UPDATE
+`
+
+// Serializes concurrent transactions acting on behalf of the same user
+func (q *Queries) LockUserForUpdate(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockUserForUpdate, id)
+	return err
+}
@@ -0,0 +1,115 @@
+package feedfetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// FakeFetcher serves canned feeds without any network access, for tests
+type FakeFetcher struct {
+	mu     sync.Mutex
+	feeds  map[string]*ParsedFeed
+	errors map[string]error
+	calls  map[string]int
+	// heads holds the validators Head returns; URLs without any act like servers
+	// that don't support HEAD
+	heads     map[string]Validators
+	headCalls map[string]int
+}
+
+// NewFakeFetcher creates a fetcher that knows no feeds yet
+func NewFakeFetcher() *FakeFetcher {
+	return &FakeFetcher{
+		feeds:     make(map[string]*ParsedFeed),
+		errors:    make(map[string]error),
+		calls:     make(map[string]int),
+		heads:     make(map[string]Validators),
+		headCalls: make(map[string]int),
+	}
+}
+
+// SetFeed makes Fetch return feed for url
+func (f *FakeFetcher) SetFeed(url string, feed *ParsedFeed) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.feeds[url] = feed
+	delete(f.errors, url)
+}
+
+// SetError makes Fetch fail with err for url
+func (f *FakeFetcher) SetError(url string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors[url] = err
+	delete(f.feeds, url)
+}
+
+// SetHead makes Head return validators for url
+func (f *FakeFetcher) SetHead(url string, validators Validators) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.heads[url] = validators
+}
+
+// HeadCalls returns how many HEAD requests were made for url
+func (f *FakeFetcher) HeadCalls(url string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.headCalls[url]
+}
+
+// Calls returns how many times url was fetched
+func (f *FakeFetcher) Calls(url string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[url]
+}
+
+// Fetch returns the feed or error set for url. Unknown URLs fail like a 404.
+func (f *FakeFetcher) Fetch(ctx context.Context, url string) (*ParsedFeed, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[url]++
+
+	if err, ok := f.errors[url]; ok {
+		return nil, err
+	}
+	if feed, ok := f.feeds[url]; ok {
+		return feed, nil
+	}
+	return nil, fmt.Errorf("no feed at %s", url)
+}
+
+// FetchIfChanged is Fetch, except that a feed set with BodyHash lastBodyHash is
+// returned marked Unchanged and without its items
+func (f *FakeFetcher) FetchIfChanged(ctx context.Context, url, lastBodyHash string) (*ParsedFeed, error) {
+	feed, err := f.Fetch(ctx, url)
+	if err != nil || lastBodyHash == "" || feed.BodyHash != lastBodyHash {
+		return feed, err
+	}
+	return &ParsedFeed{BodyHash: feed.BodyHash, FinalURL: feed.FinalURL, Validators: feed.Validators, Unchanged: true}, nil
+}
+
+// Head returns the validators set for url. Other URLs fail like a server answering
+// 405 Method Not Allowed.
+func (f *FakeFetcher) Head(ctx context.Context, url string) (Validators, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.headCalls[url]++
+
+	if validators, ok := f.heads[url]; ok {
+		return validators, nil
+	}
+	return Validators{}, gofeed.HTTPError{StatusCode: http.StatusMethodNotAllowed, Status: "405 Method Not Allowed"}
+}
@@ -0,0 +1,265 @@
+package feedfetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	jsonfeed "github.com/mmcdole/gofeed/json"
+)
+
+const (
+	// DefaultTimeout bounds how long fetching a feed may take
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxBodyBytes caps the size of a feed body (5 MB)
+	DefaultMaxBodyBytes = 5 << 20
+	// DefaultMaxIdleConnsPerHost is how many idle connections to one host are kept for reuse
+	DefaultMaxIdleConnsPerHost = 10
+	// DefaultMaxRedirects is how many redirects a feed fetch follows
+	DefaultMaxRedirects = 5
+)
+
+// ErrMalformedFeed marks a response that was fetched but isn't a valid feed,
+// as opposed to a network or HTTP error
+var ErrMalformedFeed = errors.New("malformed feed")
+
+// ParsedFeed is a parsed feed together with the hash of the body it was parsed from
+type ParsedFeed struct {
+	*gofeed.Feed
+	// BodyHash is the hex-encoded SHA-256 hash of the response body. Servers that
+	// ignore conditional requests resend the same body, which this detects.
+	BodyHash string
+	// FinalURL is the URL the feed was served from, after following redirects
+	FinalURL string
+	// Validators are the response's ETag, Last-Modified and Content-Length, which a
+	// later HEAD request is compared to
+	Validators Validators
+	// Unchanged is set by FetchIfChanged when the body hashed to the last body hash.
+	// The body wasn't parsed then, so Feed is nil.
+	Unchanged bool
+}
+
+// FeedFetcher downloads and parses feeds
+type FeedFetcher interface {
+	Fetch(ctx context.Context, url string) (*ParsedFeed, error)
+}
+
+// BodyHashFetcher is implemented by fetchers that can skip parsing a body identical to
+// the last one fetched, for servers that ignore conditional requests and resend it
+type BodyHashFetcher interface {
+	FetchIfChanged(ctx context.Context, url, lastBodyHash string) (*ParsedFeed, error)
+}
+
+// GofeedFetcher fetches feeds over HTTP and parses them with gofeed. It is safe for
+// concurrent use and meant to be shared, so connections are reused across fetches.
+type GofeedFetcher struct {
+	Client *http.Client
+	// Timeout bounds each fetch, including reading the body (0 = only the caller's context)
+	Timeout time.Duration
+	// MaxBodyBytes rejects larger responses (0 = unlimited)
+	MaxBodyBytes int64
+	// MaxRedirects fails fetches that redirect more often (0 = the client's own policy)
+	MaxRedirects int
+	// CheckURL rejects redirect targets that must not be fetched, e.g. ones on private
+	// hosts. Callers check the URL they pass to Fetch themselves (nil = any target).
+	CheckURL func(ctx context.Context, rawURL string) error
+}
+
+// NewGofeedFetcher creates a fetcher with a pooling transport, the default timeout,
+// the default body size limit and the default redirect limit
+func NewGofeedFetcher() *GofeedFetcher {
+	return &GofeedFetcher{
+		Client:       &http.Client{Transport: NewTransport()},
+		Timeout:      DefaultTimeout,
+		MaxBodyBytes: DefaultMaxBodyBytes,
+		MaxRedirects: DefaultMaxRedirects,
+	}
+}
+
+// NewTransport returns the HTTP transport used to fetch feeds. It is a copy of
+// http.DefaultTransport that keeps more idle connections per host, since many
+// feeds are served by the same few hosts and are fetched concurrently.
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	return transport
+}
+
+// Fetch downloads and parses the feed at url
+func (f *GofeedFetcher) Fetch(ctx context.Context, url string) (*ParsedFeed, error) {
+	return f.FetchIfChanged(ctx, url, "")
+}
+
+// FetchIfChanged downloads the feed at url and parses it unless the body hashes to
+// lastBodyHash, in which case the returned feed is marked Unchanged (""= always parse)
+func (f *GofeedFetcher) FetchIfChanged(ctx context.Context, url, lastBodyHash string) (*ParsedFeed, error) {
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Gofeed/1.0")
+
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, gofeed.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	var reader io.Reader = resp.Body
+	if f.MaxBodyBytes > 0 {
+		reader = io.LimitReader(resp.Body, f.MaxBodyBytes+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if f.MaxBodyBytes > 0 && int64(len(body)) > f.MaxBodyBytes {
+		return nil, fmt.Errorf("feed exceeds maximum size of %d bytes", f.MaxBodyBytes)
+	}
+
+	bodyHash := HashBody(body)
+	if lastBodyHash != "" && bodyHash == lastBodyHash {
+		return &ParsedFeed{
+			BodyHash:   bodyHash,
+			FinalURL:   resp.Request.URL.String(),
+			Validators: validatorsFromResponse(resp),
+			Unchanged:  true,
+		}, nil
+	}
+
+	feed, err := parseFeed(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedFeed, err)
+	}
+
+	// gofeed can parse a truncated document into a feed without items and no error,
+	// which would look exactly like a feed that has nothing published
+	if len(feed.Items) == 0 && !isWellFormed(body) {
+		return nil, fmt.Errorf("%w: document is truncated or not well-formed", ErrMalformedFeed)
+	}
+
+	return &ParsedFeed{
+		Feed:       feed,
+		BodyHash:   bodyHash,
+		FinalURL:   resp.Request.URL.String(),
+		Validators: validatorsFromResponse(resp),
+	}, nil
+}
+
+// client returns f.Client, limited to MaxRedirects redirects when that is set and
+// running CheckURL on each redirect
+func (f *GofeedFetcher) client() *http.Client {
+	if f.MaxRedirects <= 0 && f.CheckURL == nil {
+		return f.Client
+	}
+
+	limited := *f.Client
+	checkRedirect := f.Client.CheckRedirect
+	limited.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if f.MaxRedirects > 0 && len(via) > f.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", f.MaxRedirects)
+		}
+		if f.CheckURL != nil {
+			if err := f.CheckURL(req.Context(), req.URL.String()); err != nil {
+				return err
+			}
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		return nil
+	}
+	return &limited
+}
+
+// utf8BOM is the byte order mark some publishers put before their JSON
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// parseFeed parses a feed body. When contentType says the body is JSON it is parsed
+// as a JSON Feed directly instead of relying on gofeed's format sniffing, falling
+// back to sniffing if that fails (e.g. an XML feed mislabelled as JSON).
+func parseFeed(body []byte, contentType string) (*gofeed.Feed, error) {
+	if isJSONContentType(contentType) {
+		if feed, err := parseJSONFeed(body); err == nil {
+			return feed, nil
+		}
+	}
+	return gofeed.NewParser().Parse(bytes.NewReader(body))
+}
+
+// parseJSONFeed parses body with gofeed's JSON Feed parser and translates it into a gofeed.Feed
+func parseJSONFeed(body []byte) (*gofeed.Feed, error) {
+	jsonFeed, err := (&jsonfeed.Parser{}).Parse(bytes.NewReader(bytes.TrimPrefix(body, utf8BOM)))
+	if err != nil {
+		return nil, err
+	}
+	return (&gofeed.DefaultJSONTranslator{}).Translate(jsonFeed)
+}
+
+// isJSONContentType reports whether a Content-Type header names a JSON document,
+// such as application/feed+json or plain application/json
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isWellFormed reports whether body is a complete XML or JSON document
+func isWellFormed(body []byte) bool {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, utf8BOM))
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return json.Valid(trimmed)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	// Feeds declare all sorts of encodings; only the structure matters here
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		_, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// HashBody returns the hex-encoded SHA-256 hash of a feed response body.
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
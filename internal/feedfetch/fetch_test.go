@@ -0,0 +1,455 @@
+package feedfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRSSBody = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Feed</title>
+    <link>https://example.com</link>
+    <description>A feed for tests</description>
+    <item>
+      <title>First post</title>
+      <link>https://example.com/first</link>
+      <description>Hello</description>
+    </item>
+  </channel>
+</rss>`
+
+func newFeedServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchFeed_NewBody_ParsesFeed(t *testing.T) {
+	server := newFeedServer(t, testRSSBody)
+
+	feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(feed.Items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(feed.Items))
+	}
+
+	if feed.BodyHash != HashBody([]byte(testRSSBody)) {
+		t.Errorf("Expected body hash %s, got %s", HashBody([]byte(testRSSBody)), feed.BodyHash)
+	}
+}
+
+func TestFetchFeed_IdenticalBody_SameHash(t *testing.T) {
+	server := newFeedServer(t, testRSSBody)
+	fetcher := NewGofeedFetcher()
+
+	first, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error on first fetch, got %v", err)
+	}
+
+	second, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error on second fetch, got %v", err)
+	}
+
+	if first.BodyHash != second.BodyHash {
+		t.Errorf("Expected identical hashes, got %s and %s", first.BodyHash, second.BodyHash)
+	}
+}
+
+func TestFetchFeed_IdenticalBody_SkipsParsing(t *testing.T) {
+	// Not a feed: parsing it would fail with ErrMalformedFeed
+	body := "<html>not a feed</html>"
+	server := newFeedServer(t, body)
+
+	parsed, err := NewGofeedFetcher().FetchIfChanged(context.Background(), server.URL, HashBody([]byte(body)))
+	if err != nil {
+		t.Fatalf("Expected the body not to be parsed, got %v", err)
+	}
+	if !parsed.Unchanged || parsed.Feed != nil {
+		t.Errorf("Expected an unchanged feed without items, got %+v", parsed)
+	}
+}
+
+func TestFetchIfChanged_NewBody_ParsesFeed(t *testing.T) {
+	server := newFeedServer(t, testRSSBody)
+
+	parsed, err := NewGofeedFetcher().FetchIfChanged(context.Background(), server.URL, HashBody([]byte("older body")))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if parsed.Unchanged || parsed.Feed == nil || len(parsed.Items) != 1 {
+		t.Errorf("Expected the parsed feed with 1 item, got %+v", parsed)
+	}
+}
+
+func TestFetchFeed_OversizedBody_ReturnsError(t *testing.T) {
+	server := newFeedServer(t, testRSSBody)
+	fetcher := NewGofeedFetcher()
+	fetcher.MaxBodyBytes = 10
+
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err == nil {
+		t.Error("Expected error for oversized body")
+	}
+}
+
+func TestFetchFeed_NonSuccessStatus_ReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Error("Expected error for 404 response")
+	}
+}
+
+const emptyRSSBody = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Quiet Feed</title>
+    <link>https://example.com</link>
+    <description>Nothing published yet</description>
+  </channel>
+</rss>`
+
+func TestFetchFeed_EmptyValidFeed_ReturnsNoError(t *testing.T) {
+	server := newFeedServer(t, emptyRSSBody)
+
+	feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error for an empty feed, got %v", err)
+	}
+
+	if len(feed.Items) != 0 {
+		t.Errorf("Expected parsed feed without items, got %d", len(feed.Items))
+	}
+}
+
+func TestFetchFeed_TruncatedFeed_ReturnsMalformedError(t *testing.T) {
+	// Cut off before the first item closes
+	server := newFeedServer(t, testRSSBody[:strings.Index(testRSSBody, "<item>")+20])
+
+	_, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+
+	if !errors.Is(err, ErrMalformedFeed) {
+		t.Errorf("Expected ErrMalformedFeed, got %v", err)
+	}
+}
+
+func TestFetchFeed_NotAFeed_ReturnsMalformedError(t *testing.T) {
+	server := newFeedServer(t, "<html><body>Not found</body></html>")
+
+	_, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+
+	if !errors.Is(err, ErrMalformedFeed) {
+		t.Errorf("Expected ErrMalformedFeed, got %v", err)
+	}
+}
+
+func TestFetchFeed_NetworkError_IsNotMalformed(t *testing.T) {
+	server := newFeedServer(t, testRSSBody)
+	server.Close()
+
+	_, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+
+	if err == nil || errors.Is(err, ErrMalformedFeed) {
+		t.Errorf("Expected a network error, got %v", err)
+	}
+}
+
+func TestIsWellFormed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{"Complete RSS", emptyRSSBody, true},
+		{"Truncated RSS", emptyRSSBody[:len(emptyRSSBody)-20], false},
+		{"Complete JSON feed", `{"version": "https://jsonfeed.org/version/1.1", "items": []}`, true},
+		{"Truncated JSON feed", `{"version": "https://jsonfeed.org/version/1.1", "items": [`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isWellFormed([]byte(tc.body)); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+const testJSONFeedBody = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON Test Feed",
+  "home_page_url": "https://example.com",
+  "items": [
+    {"id": "1", "title": "First post", "url": "https://example.com/first", "content_text": "Hello"},
+    {"id": "2", "title": "Second post", "url": "https://example.com/second", "content_text": "World"}
+  ]
+}`
+
+func newFeedServerWithType(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchFeed_JSONFeed_ParsedByContentType(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"Plain JSON", "application/json", testJSONFeedBody},
+		{"JSON Feed type", "application/feed+json; charset=utf-8", testJSONFeedBody},
+		// Sniffing doesn't recognise JSON behind a byte order mark
+		{"Byte order mark", "application/json", "\xef\xbb\xbf" + testJSONFeedBody},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFeedServerWithType(t, tc.contentType, tc.body)
+
+			feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if feed.FeedType != "json" {
+				t.Errorf("Expected a JSON feed, got %q", feed.FeedType)
+			}
+			if feed.Title != "JSON Test Feed" {
+				t.Errorf("Expected title %q, got %q", "JSON Test Feed", feed.Title)
+			}
+			if len(feed.Items) != 2 || feed.Items[0].Link != "https://example.com/first" {
+				t.Errorf("Expected 2 items starting with the first post, got %+v", feed.Items)
+			}
+		})
+	}
+}
+
+func TestFetchFeed_XMLServedAsJSON_FallsBackToSniffing(t *testing.T) {
+	server := newFeedServerWithType(t, "application/json", testRSSBody)
+
+	feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if feed.FeedType != "rss" || len(feed.Items) != 1 {
+		t.Errorf("Expected an RSS feed with 1 item, got %q with %d items", feed.FeedType, len(feed.Items))
+	}
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		expected    bool
+	}{
+		{"application/json", true},
+		{"application/feed+json", true},
+		{"Application/JSON; charset=utf-8", true},
+		{"application/rss+xml", false},
+		{"text/xml", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		if got := isJSONContentType(tc.contentType); got != tc.expected {
+			t.Errorf("Expected %v for %q, got %v", tc.expected, tc.contentType, got)
+		}
+	}
+}
+
+func TestFetch_SharedFetcher_ReusesConnection(t *testing.T) {
+	var newConns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(testRSSBody))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	fetcher := NewGofeedFetcher()
+	for i := 0; i < 5; i++ {
+		if _, err := fetcher.Fetch(context.Background(), server.URL); err != nil {
+			t.Fatalf("Expected fetch %d to succeed, got %v", i, err)
+		}
+	}
+
+	if got := newConns.Load(); got != 1 {
+		t.Errorf("Expected 5 sequential fetches to share 1 connection, got %d connections", got)
+	}
+}
+
+func TestFetch_Timeout_AppliesPerFetch(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	fetcher := NewGofeedFetcher()
+	fetcher.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := fetcher.Fetch(context.Background(), server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the fetch to give up after its timeout, took %v", elapsed)
+	}
+}
+
+func BenchmarkFetch_SharedFetcher(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(testRSSBody))
+	}))
+	b.Cleanup(server.Close)
+
+	fetcher := NewGofeedFetcher()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fetcher.Fetch(context.Background(), server.URL); err != nil {
+			b.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// newRedirectingServer serves testRSSBody at /feed.xml and redirects /hop/N to /hop/N-1,
+// with /hop/0 redirecting to /feed.xml
+func newRedirectingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/feed.xml" {
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(testRSSBody))
+			return
+		}
+		var hop int
+		if _, err := fmt.Sscanf(r.URL.Path, "/hop/%d", &hop); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		target := "/feed.xml"
+		if hop > 0 {
+			target = fmt.Sprintf("/hop/%d", hop-1)
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchFeed_Redirect_RecordsFinalURL(t *testing.T) {
+	server := newRedirectingServer(t)
+
+	feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL+"/hop/1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if feed.FinalURL != server.URL+"/feed.xml" {
+		t.Errorf("Expected final URL %s/feed.xml, got %s", server.URL, feed.FinalURL)
+	}
+}
+
+func TestFetchFeed_NoRedirect_FinalURLIsRequestURL(t *testing.T) {
+	server := newFeedServer(t, testRSSBody)
+
+	feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL+"/feed.xml")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if feed.FinalURL != server.URL+"/feed.xml" {
+		t.Errorf("Expected final URL %s/feed.xml, got %s", server.URL, feed.FinalURL)
+	}
+}
+
+func TestFetchFeed_MaxRedirects(t *testing.T) {
+	server := newRedirectingServer(t)
+
+	testCases := []struct {
+		name         string
+		maxRedirects int
+		hops         int
+		expectError  bool
+	}{
+		{"Within the limit", 3, 1, false},
+		{"At the limit", 3, 2, false},
+		{"Over the limit", 2, 2, true},
+		{"No limit uses the client's policy", 0, 5, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewGofeedFetcher()
+			fetcher.MaxRedirects = tc.maxRedirects
+
+			// /hop/N takes N+1 redirects to reach the feed
+			_, err := fetcher.Fetch(context.Background(), fmt.Sprintf("%s/hop/%d", server.URL, tc.hops))
+			if tc.expectError && err == nil {
+				t.Error("Expected an error")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchFeed_CheckURL_RunsOnEveryRedirect(t *testing.T) {
+	server := newRedirectingServer(t)
+
+	var checked []string
+	fetcher := NewGofeedFetcher()
+	fetcher.CheckURL = func(ctx context.Context, rawURL string) error {
+		checked = append(checked, strings.TrimPrefix(rawURL, server.URL))
+		if strings.HasSuffix(rawURL, "/feed.xml") {
+			return errors.New("host not allowed")
+		}
+		return nil
+	}
+
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/hop/1")
+	if err == nil || !strings.Contains(err.Error(), "host not allowed") {
+		t.Errorf("Expected the rejected redirect to fail the fetch, got %v", err)
+	}
+	if fmt.Sprint(checked) != "[/hop/0 /feed.xml]" {
+		t.Errorf("Expected both redirects to be checked, got %v", checked)
+	}
+}
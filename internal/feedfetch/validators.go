@@ -0,0 +1,92 @@
+package feedfetch
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Validators are the response headers that tell whether a feed body changed
+// between two requests without downloading it
+type Validators struct {
+	ETag         string
+	LastModified string
+	// ContentLength is -1 when unknown, e.g. for chunked or compressed responses
+	ContentLength int64
+}
+
+// HeadFetcher is implemented by fetchers that can request just a feed's headers, for
+// servers that support HEAD but ignore conditional GET requests
+type HeadFetcher interface {
+	Head(ctx context.Context, url string) (Validators, error)
+}
+
+// validatorsFromResponse returns the validators of an HTTP response
+func validatorsFromResponse(resp *http.Response) Validators {
+	return Validators{
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+		ContentLength: resp.ContentLength,
+	}
+}
+
+// Unchanged reports whether v, from a HEAD request, describes the same body as last,
+// from the GET request that body was stored from. An ETag or Last-Modified must be
+// known for both, since equal lengths alone say little; every validator known for
+// both must agree. ETags are compared weakly, as servers mark them weak when they
+// compress the body.
+func (v Validators) Unchanged(last Validators) bool {
+	compared := false
+	if v.ETag != "" && last.ETag != "" {
+		if strings.TrimPrefix(v.ETag, "W/") != strings.TrimPrefix(last.ETag, "W/") {
+			return false
+		}
+		compared = true
+	}
+	if v.LastModified != "" && last.LastModified != "" {
+		if v.LastModified != last.LastModified {
+			return false
+		}
+		compared = true
+	}
+	if v.ContentLength >= 0 && last.ContentLength >= 0 && v.ContentLength != last.ContentLength {
+		return false
+	}
+	return compared
+}
+
+// Head requests the headers of the feed at url and returns its validators.
+// Servers that don't support HEAD answer with an error status, which is returned
+// as a gofeed.HTTPError like for Fetch.
+func (f *GofeedFetcher) Head(ctx context.Context, url string) (Validators, error) {
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return Validators{}, err
+	}
+	req.Header.Set("User-Agent", "Gofeed/1.0")
+
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return Validators{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Validators{}, gofeed.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	return validatorsFromResponse(resp), nil
+}
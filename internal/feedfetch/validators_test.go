@@ -0,0 +1,110 @@
+package feedfetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const testLastModified = "Wed, 14 Oct 2026 08:00:00 GMT"
+
+// newHeadServer serves testRSSBody with an ETag and Last-Modified and answers HEAD
+// requests with the same headers
+func newHeadServer(t *testing.T, etag string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", testLastModified)
+		w.Header().Set("Content-Length", strconv.Itoa(len(testRSSBody)))
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write([]byte(testRSSBody))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch_RecordsValidators(t *testing.T) {
+	server := newHeadServer(t, `"v1"`)
+
+	feed, err := NewGofeedFetcher().Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := Validators{ETag: `"v1"`, LastModified: testLastModified, ContentLength: int64(len(testRSSBody))}
+	if feed.Validators != expected {
+		t.Errorf("Expected validators %+v, got %+v", expected, feed.Validators)
+	}
+}
+
+func TestHead_MatchesValidatorsOfFetch(t *testing.T) {
+	server := newHeadServer(t, `"v1"`)
+	fetcher := NewGofeedFetcher()
+
+	feed, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	validators, err := fetcher.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !validators.Unchanged(feed.Validators) {
+		t.Errorf("Expected HEAD validators %+v to match fetch %+v", validators, feed.Validators)
+	}
+}
+
+func TestHead_MethodNotAllowed_ReturnsHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte(testRSSBody))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewGofeedFetcher().Head(context.Background(), server.URL)
+
+	var httpErr gofeed.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected a 405 HTTP error, got %v", err)
+	}
+}
+
+func TestValidatorsUnchanged(t *testing.T) {
+	last := Validators{ETag: `"v1"`, LastModified: testLastModified, ContentLength: 120}
+
+	testCases := []struct {
+		name     string
+		head     Validators
+		last     Validators
+		expected bool
+	}{
+		{"all match", last, last, true},
+		{"weak etag matches strong", Validators{ETag: `W/"v1"`, ContentLength: -1}, last, true},
+		{"etag differs", Validators{ETag: `"v2"`, LastModified: testLastModified, ContentLength: 120}, last, false},
+		{"last modified differs", Validators{LastModified: "Thu, 15 Oct 2026 08:00:00 GMT", ContentLength: 120}, last, false},
+		{"length differs", Validators{ETag: `"v1"`, ContentLength: 96}, last, false},
+		{"unknown length ignored", Validators{ETag: `"v1"`, ContentLength: -1}, last, true},
+		{"only length known", Validators{ContentLength: 120}, last, false},
+		{"nothing stored", last, Validators{ContentLength: -1}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.head.Unchanged(tc.last); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/scraper"
+)
+
+// feedActivityWindow is the bucket size and number of buckets returned per granularity
+type feedActivityWindow struct {
+	size    time.Duration
+	buckets int
+}
+
+// feedActivityWindows must match the granularities the scraper records
+var feedActivityWindows = map[string]feedActivityWindow{
+	"hour": {size: time.Hour, buckets: scraper.HourlyActivityBuckets},
+	"day":  {size: 24 * time.Hour, buckets: 30},
+}
+
+type feedActivityBucket struct {
+	Start time.Time    `json:"start"`
+	Count models.Count `json:"count"`
+}
+
+type feedActivityResponse struct {
+	FeedID      uuid.UUID            `json:"feed_id"`
+	Granularity string               `json:"granularity"`
+	Buckets     []feedActivityBucket `json:"buckets"`
+}
+
+// HandlerGetFeedActivity returns how many posts were ingested for a feed over time
+// @Summary     Get feed activity
+// @Description Returns the number of posts ingested for a feed per hour (last 48 hours) or per day (last 30 days)
+// @Tags        feeds
+// @Accept      json
+// @Produce     json
+// @Param       feedID       path      string  true   "Feed ID"
+// @Param       granularity  query     string  false  "hour (default) or day"
+// @Param       bigint       query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200          {object}  object  "Activity time series, oldest bucket first"
+// @Failure     400          {object}  object  "Invalid input"
+// @Failure     404          {object}  object  "Feed not found"
+// @Failure     500          {object}  object  "Server error"
+// @Router      /v1/feed/{feedID}/activity [get]
+func (cfg *Config) HandlerGetFeedActivity(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
+		return
+	}
+
+	granularity := r.URL.Query().Get("granularity")
+	if granularity == "" {
+		granularity = "hour"
+	}
+
+	window, ok := feedActivityWindows[granularity]
+	if !ok {
+		models.RespondWithError(w, http.StatusBadRequest, "granularity must be hour or day")
+		return
+	}
+
+	if _, err := cfg.DB.GetFeedByID(r.Context(), feedID); err != nil {
+		if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+			models.RespondWithError(w, http.StatusNotFound, "Feed not found")
+			return
+		}
+		respondWithDBError(w, err, "Get feed")
+		return
+	}
+
+	since := activityWindowStart(time.Now(), window)
+
+	rows, err := cfg.DB.GetFeedActivity(r.Context(), database.GetFeedActivityParams{
+		FeedID:      feedID,
+		Granularity: granularity,
+		BucketStart: since,
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Get feed activity")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, feedActivityResponse{
+		FeedID:      feedID,
+		Granularity: granularity,
+		Buckets:     fillActivityBuckets(r, rows, since, window),
+	})
+}
+
+// activityWindowStart returns the start of the oldest bucket in the window ending at now
+func activityWindowStart(now time.Time, window feedActivityWindow) time.Time {
+	current := now.UTC().Truncate(window.size)
+	return current.Add(-time.Duration(window.buckets-1) * window.size)
+}
+
+// fillActivityBuckets expands the stored rows into one bucket per interval starting at since,
+// so buckets without ingested posts are reported as zero instead of missing
+func fillActivityBuckets(r *http.Request, rows []database.GetFeedActivityRow, since time.Time, window feedActivityWindow) []feedActivityBucket {
+	counts := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		counts[row.BucketStart.UTC()] += int64(row.PostCount)
+	}
+
+	buckets := make([]feedActivityBucket, 0, window.buckets)
+	for i := 0; i < window.buckets; i++ {
+		start := since.Add(time.Duration(i) * window.size)
+		buckets = append(buckets, feedActivityBucket{Start: start, Count: models.NewCount(r, counts[start])})
+	}
+
+	return buckets
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestActivityWindowStart(t *testing.T) {
+	now := time.Date(2024, 3, 5, 14, 37, 0, 0, time.UTC)
+
+	since := activityWindowStart(now, feedActivityWindow{size: time.Hour, buckets: 3})
+
+	expected := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	if !since.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, since)
+	}
+}
+
+func TestFillActivityBuckets_ZeroFillsMissingBuckets(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	window := feedActivityWindow{size: 24 * time.Hour, buckets: 4}
+	rows := []database.GetFeedActivityRow{
+		{BucketStart: since, PostCount: 2},
+		{BucketStart: since.Add(48 * time.Hour), PostCount: 5},
+	}
+
+	buckets := fillActivityBuckets(httptest.NewRequest(http.MethodGet, "/", nil), rows, since, window)
+
+	expectedCounts := []int64{2, 0, 5, 0}
+	if len(buckets) != len(expectedCounts) {
+		t.Fatalf("Expected %d buckets, got %d", len(expectedCounts), len(buckets))
+	}
+
+	for i, bucket := range buckets {
+		if bucket.Count.Value != expectedCounts[i] {
+			t.Errorf("Expected bucket %d count %d, got %d", i, expectedCounts[i], bucket.Count.Value)
+		}
+		if !bucket.Start.Equal(since.Add(time.Duration(i) * window.size)) {
+			t.Errorf("Expected bucket %d to start at %v, got %v", i, since.Add(time.Duration(i)*window.size), bucket.Start)
+		}
+	}
+}
+
+func TestHandlerGetFeedActivity_InvalidGranularity_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/x/activity?granularity=week", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", uuid.NewString())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerGetFeedActivity(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -6,21 +6,38 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// DefaultRefreshTokenTTL is how long a refresh token stays valid unless configured
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+	// refreshTokenCookie carries the refresh token in cookie mode
+	refreshTokenCookie = "refresh_token"
+	// refreshTokenCookiePath limits the cookie to the auth endpoints that need it
+	refreshTokenCookiePath = "/v1/auth"
+)
+
 // HandlerRegister handles new user registration (sign up).
 //
 // Flow:
-//  1. Parse and validate request body (name, email, password)
+//  1. Parse and validate request body (name, email, password); the email must
+//     parse as an address and is stored lowercased, the password must satisfy
+//     the password policy
 //  2. Hash password using bcrypt for secure storage
 //  3. Create user record in database
 //  4. Generate JWT token for immediate authentication
@@ -33,8 +50,9 @@ import (
 //
 // HTTP Status Codes:
 //   - 201 Created: User successfully registered
-//   - 400 Bad Request: Invalid input or duplicate email
-//   - 500 Internal Server Error: Hash generation or token creation failed
+//   - 400 Bad Request: Invalid input, malformed email or weak password
+//   - 409 Conflict: Email already registered
+//   - 500 Internal Server Error: Hash generation, user creation or token creation failed
 //
 // @Summary     Register a new user
 // @Description Creates a new user account with email and password
@@ -43,14 +61,23 @@ import (
 // @Produce     json
 // @Param       user  body      object  true  "User registration data" schema(parameters)
 // @Success     201   {object}  object  "User registered successfully"
-// @Failure     400   {object}  object  "Invalid input or duplicate email"
+// @Failure     400   {object}  object  "Malformed JSON"
+// @Failure     422   {object}  object  "Missing field, invalid email or password"
+// @Failure     409   {object}  object  "Email already registered"
 // @Failure     500   {object}  object  "Server error"
 // @Router      /v1/auth/register [post]
 func (cfg *Config) HandlerRegister(w http.ResponseWriter, r *http.Request) {
+	cfg.respondToRegistration(w, r, cfg.DB)
+}
+
+// respondToRegistration creates the account described by the request body in store
+// and responds with a token pair for its first session
+func (cfg *Config) respondToRegistration(w http.ResponseWriter, r *http.Request, store database.Querier) {
 	type parameters struct {
-		Name     string `json:"name"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Name        string `json:"name"`
+		Email       string `json:"email"`
+		Password    string `json:"password"`
+		DeviceLabel string `json:"device_label"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -62,8 +89,19 @@ func (cfg *Config) HandlerRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
-	if params.Email == "" || params.Password == "" || params.Name == "" {
-		models.RespondWithError(w, http.StatusBadRequest, "Name, email and password are required")
+	if field := firstEmptyField("name", params.Name, "email", params.Email, "password", params.Password); field != "" {
+		models.RespondWithValidationError(w, field, "Name, email and password are required")
+		return
+	}
+
+	email, err := normalizeEmail(params.Email)
+	if err != nil {
+		models.RespondWithValidationError(w, "email", err.Error())
+		return
+	}
+
+	if err := cfg.validatePassword(params.Password); err != nil {
+		models.RespondWithValidationError(w, "password", err.Error())
 		return
 	}
 
@@ -76,13 +114,13 @@ func (cfg *Config) HandlerRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create user in database
-	user, err := cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
+	user, err := store.CreateUser(r.Context(), database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 		Name:      params.Name,
 		Email: sql.NullString{
-			String: params.Email,
+			String: email,
 			Valid:  true,
 		},
 		PasswordHash: sql.NullString{
@@ -91,49 +129,32 @@ func (cfg *Config) HandlerRegister(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 	if err != nil {
-		// Database constraint errors (e.g., duplicate email) will be caught here
-		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Could not create user: %v", err))
+		respondWithCreateUserError(w, err)
 		return
 	}
 
-	// Generate JWT token for immediate authentication
-	accessToken, err := auth.GenerateJWT(user.ID, user.Email.String)
+	// Issue tokens for immediate authentication
+	pair, err := cfg.issueTokenPair(r.Context(), user, cfg.startSession(store, user, newSessionClient(r, params.DeviceLabel)))
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate token")
+		cfg.respondWithTokenPairError(w, err)
 		return
 	}
 
-	refreshToken, err := auth.GenerateRefreshToken()
-	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate refresh token")
-		return
-	}
+	cfg.respondWithTokenPair(w, http.StatusCreated, pair)
+}
 
-	_, errSaveRefreshTokenDb := cfg.DB.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		TokenHash: auth.HashRefreshToken(refreshToken),
-		ExpiresAt: time.Now().Add(24 * time.Hour * 7).UTC(),
-		CreatedAt: time.Now().UTC(),
-	})
-	if errSaveRefreshTokenDb != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to save refresh token")
+// respondWithCreateUserError answers a failed CreateUser: a unique violation means the
+// email is taken (409), anything else is a 500. The database error is only logged,
+// so constraint names never reach the client.
+func respondWithCreateUserError(w http.ResponseWriter, err error) {
+	if errors.Is(apperr.Classify(err), apperr.ErrConflict) {
+		logger.Logger.Warn().Err(err).Msg("Create user rejected")
+		models.RespondWithError(w, http.StatusConflict, "Email already registered")
 		return
 	}
 
-	// Return user data and authentication token
-	type response struct {
-		User         models.User `json:"user"`
-		AccessToken  string      `json:"access_token"`
-		RefreshToken string      `json:"refresh_token"`
-		ExpiresIn    int64       `json:"expires_in"`
-	}
-
-	models.RespondWithJSON(w, http.StatusCreated, response{
-		User:         models.DatabaseUserToUser(user),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	})
+	logger.ErrorErr(err, "Create user failed")
+	models.RespondWithError(w, http.StatusInternalServerError, "Failed to create user")
 }
 
 // HandlerLogin handles user authentication (sign in).
@@ -149,11 +170,13 @@ func (cfg *Config) HandlerRegister(w http.ResponseWriter, r *http.Request) {
 //   - Uses constant-time password comparison (bcrypt)
 //   - Returns generic error message to prevent user enumeration
 //   - Implements secure password verification flow
+//   - Every login starts its own session, so logging in on one device doesn't
+//     log out the others
 //
 // HTTP Status Codes:
 //   - 200 OK: Authentication successful
 //   - 400 Bad Request: Missing required fields
-//   - 401 Unauthorized: Invalid credentials
+//   - 401 Unauthorized: Invalid credentials, or the account only has social login
 //   - 500 Internal Server Error: Token generation failed
 //
 // @Summary     Login user
@@ -163,13 +186,15 @@ func (cfg *Config) HandlerRegister(w http.ResponseWriter, r *http.Request) {
 // @Produce     json
 // @Param       credentials  body      object  true  "Login credentials"
 // @Success     200          {object}  object  "Login successful"
-// @Failure     400          {object}  object  "Invalid input"
-// @Failure     401          {object}  object  "Invalid credentials"
+// @Failure     400          {object}  object  "Malformed JSON"
+// @Failure     401          {object}  object  "Invalid credentials or no password set"
+// @Failure     422          {object}  object  "Email or password missing"
 // @Router      /v1/auth/login [post]
 func (cfg *Config) HandlerLogin(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string `json:"email"`
+		Password    string `json:"password"`
+		DeviceLabel string `json:"device_label"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -181,79 +206,102 @@ func (cfg *Config) HandlerLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
-	if params.Email == "" || params.Password == "" {
-		models.RespondWithError(w, http.StatusBadRequest, "Email and password are required")
+	if field := firstEmptyField("email", params.Email, "password", params.Password); field != "" {
+		models.RespondWithValidationError(w, field, "Email and password are required")
 		return
 	}
 
 	// Find user by email
 	user, err := cfg.DB.GetUserByEmail(r.Context(), sql.NullString{
-		String: params.Email,
+		String: strings.ToLower(strings.TrimSpace(params.Email)),
 		Valid:  true,
 	})
-	if err != nil {
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
 		// Return generic error to prevent user enumeration attacks
 		models.RespondWithError(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
+	if err != nil {
+		respondWithDBError(w, err, "Get user")
+		return
+	}
 
 	// Verify password using constant-time comparison
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(params.Password))
+	err = checkPassword(user, params.Password)
+	if errors.Is(err, errNoPassword) {
+		models.RespondWithError(w, http.StatusUnauthorized, "This account has no password; use social login")
+		return
+	}
 	if err != nil {
 		// Return same generic error for invalid password
 		models.RespondWithError(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
-	// Generate JWT token
-	token, err := auth.GenerateJWT(user.ID, user.Email.String)
+	// Return user data and authentication tokens for a new session; other devices stay logged in
+	pair, err := cfg.issueTokenPair(r.Context(), user, cfg.startSession(cfg.DB, user, newSessionClient(r, params.DeviceLabel)))
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate token")
+		cfg.respondWithTokenPairError(w, err)
 		return
 	}
 
-	refreshToken, errRefreshToken := auth.GenerateRefreshToken()
-	if errRefreshToken != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate refresh token")
-		return
-	}
+	cfg.respondWithTokenPair(w, http.StatusOK, pair)
+}
 
-	errDeleteGenerateRefreshToken := cfg.deleteAndGenerateRefreshTokenFromDB(r.Context(), &user, refreshToken)
-	if errDeleteGenerateRefreshToken != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, errDeleteGenerateRefreshToken.Error())
-		return
-	}
+// errNoPassword is returned when logging in with a password to an account that
+// was created through OAuth and never had one
+var errNoPassword = errors.New("account has no password")
 
-	// Return user data and authentication token
-	type response struct {
-		User         models.User `json:"user"`
-		AccessToken  string      `json:"access_token"`
-		RefreshToken string      `json:"refresh_token"`
+// checkPassword compares password with the user's bcrypt hash, returning
+// errNoPassword if the user has no password at all
+func checkPassword(user database.User, password string) error {
+	if !user.PasswordHash.Valid {
+		return errNoPassword
 	}
-
-	models.RespondWithJSON(w, http.StatusOK, response{
-		User:         models.DatabaseUserToUser(user),
-		AccessToken:  token,
-		RefreshToken: refreshToken,
-	})
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password))
 }
 
+// HandlerLogout ends the session of the presented refresh token (from the body, or
+// the refresh token cookie in cookie mode). The user's other sessions are kept.
 // @Summary     Logout user
-// @Description Logout user and invalidate refresh token
+// @Description Logout the current session by invalidating its refresh token; other sessions stay active
 // @Tags        auth
 // @Accept      json
 // @Produce     json
 // @Security    Bearer
-// @Success     200  {object}  object  "Logout successful"
-// @Failure     500  {object}  object  "Server error"
-// @Router      /v1/auth/logout [get]
+// @Param       refresh_token  body      object  false  "Refresh token of the session to end"
+// @Success     200            {object}  object  "Logout successful"
+// @Failure     400            {object}  object  "Malformed JSON"
+// @Failure     422            {object}  object  "Refresh token missing"
+// @Failure     500            {object}  object  "Server error"
+// @Router      /v1/auth/logout [post]
 func (cfg *Config) HandlerLogout(w http.ResponseWriter, r *http.Request, user database.User) {
-	err := cfg.DB.DeleteRefreshToken(r.Context(), user.ID)
-	if err != nil {
+	type parameters struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	params := parameters{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
+		return
+	}
+
+	refreshToken := cfg.refreshTokenFromRequest(r, params.RefreshToken)
+	if refreshToken == "" {
+		models.RespondWithValidationError(w, "refresh_token", "Refresh token is required")
+		return
+	}
+
+	if err := endSession(r.Context(), cfg.DB, user.ID, refreshToken); err != nil {
 		models.RespondWithError(w, http.StatusInternalServerError, "Failed to delete refresh token")
 		return
 	}
 
+	if cfg.RefreshTokenCookie {
+		clearRefreshTokenCookie(w)
+	}
+
 	models.RespondWithJSON(w, http.StatusOK, struct {
 		Message string `json:"message"`
 	}{
@@ -264,7 +312,8 @@ func (cfg *Config) HandlerLogout(w http.ResponseWriter, r *http.Request, user da
 // HandlerRefreshToken handles issuing a new JWT access token using a valid refresh token.
 //
 // Flow:
-//  1. Parse and validate refresh token from request body
+//  1. Read the refresh token from the request body, or from the refresh token
+//     cookie when the body has none and cookie mode is enabled
 //  2. Hash the provided refresh token for secure comparison
 //  3. Retrieve the corresponding refresh token record from the database
 //  4. Check if the refresh token is expired
@@ -281,17 +330,20 @@ func (cfg *Config) HandlerLogout(w http.ResponseWriter, r *http.Request, user da
 // HTTP Status Codes:
 //   - 200 OK: New tokens successfully issued
 //   - 400 Bad Request: Missing or invalid refresh token, or expired token
-//   - 401 Unauthorized: Invalid token
+//   - 401 Unauthorized: Invalid token, or one already rotated by a concurrent refresh.
+//     Presenting a token that has already been rotated ends the session it was issued to.
 //   - 500 Internal Server Error: Database or token generation failure
 //
 // @Summary     Refresh access token
-// @Description Get new access token using refresh token
+// @Description Get new access token using refresh token. In cookie mode the refresh token cookie is used when the body has no token.
 // @Tags        auth
 // @Accept      json
 // @Produce     json
-// @Param       refresh_token  body      object  true  "Refresh token"
+// @Param       refresh_token  body      object  false  "Refresh token"
 // @Success     200            {object}  object  "New tokens issued"
-// @Failure     400            {object}  object  "Invalid or expired token"
+// @Failure     400            {object}  object  "Malformed JSON or expired token"
+// @Failure     401            {object}  object  "Token unknown or already used"
+// @Failure     422            {object}  object  "Refresh token missing"
 // @Router      /v1/auth/refresh [post]
 func (cfg *Config) HandlerRefreshToken(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
@@ -301,92 +353,220 @@ func (cfg *Config) HandlerRefreshToken(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
 
+	// Cookie clients may send no body at all
 	err := decoder.Decode(&params)
-	if err != nil {
+	if err != nil && !errors.Is(err, io.EOF) {
 		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing JSON: %v", err))
 		return
 	}
 
-	if params.RefreshToken == "" {
-		models.RespondWithError(w, http.StatusBadRequest, "Refresh token is required")
+	presentedRefreshToken := cfg.refreshTokenFromRequest(r, params.RefreshToken)
+	if presentedRefreshToken == "" {
+		models.RespondWithValidationError(w, "refresh_token", "Refresh token is required")
 		return
 	}
 
-	hashedRefreshTokenPayload := auth.HashRefreshToken(params.RefreshToken)
+	hashedRefreshTokenPayload := auth.HashRefreshToken(presentedRefreshToken)
 	if hashedRefreshTokenPayload == "" {
-		models.RespondWithError(w, http.StatusBadRequest, "Refresh token is required")
+		models.RespondWithValidationError(w, "refresh_token", "Refresh token is required")
+		return
 	}
 
 	refreshTokenObject, errGetRefreshTokenFromDb := cfg.DB.GetRefreshTokenByHash(r.Context(), hashedRefreshTokenPayload)
+	if errors.Is(apperr.Classify(errGetRefreshTokenFromDb), apperr.ErrNotFound) {
+		cfg.handleRefreshTokenReuse(r.Context(), hashedRefreshTokenPayload)
+		models.RespondWithError(w, http.StatusUnauthorized, errRefreshTokenReused.Error())
+		return
+	}
 	if errGetRefreshTokenFromDb != nil {
 		models.RespondWithError(w, http.StatusInternalServerError, "Failed to get refresh token from DB")
 		return
 	}
 
-	if time.Now().UTC().After(refreshTokenObject.ExpiresAt) {
+	if cfg.refreshTokenExpired(refreshTokenObject, time.Now()) {
 		models.RespondWithError(w, http.StatusBadRequest, "Refresh token is expired")
 		return
 	}
 
+	if err := cfg.checkRefreshTokenBinding(refreshTokenObject, r); err != nil {
+		cfg.endMismatchedSession(r.Context(), cfg.DB, refreshTokenObject, presentedRefreshToken)
+		models.RespondWithError(w, http.StatusUnauthorized, "Refresh token was issued to a different client; please log in again")
+		return
+	}
+
 	user, errFindUser := cfg.DB.GetUserByID(r.Context(), refreshTokenObject.UserID)
 	if errFindUser != nil {
 		models.RespondWithError(w, http.StatusInternalServerError, "Failed to find user")
 		return
 	}
 
-	accessToken, err := auth.GenerateJWT(user.ID, user.Email.String)
+	pair, err := cfg.issueTokenPair(r.Context(), user, func(ctx context.Context, refreshToken string) error {
+		return cfg.rotateRefreshTokenInDB(ctx, refreshTokenObject, refreshToken)
+	})
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate token")
+		cfg.respondWithTokenPairError(w, err)
 		return
 	}
 
-	refreshToken, err := auth.GenerateRefreshToken()
+	cfg.respondWithTokenPair(w, http.StatusOK, pair)
+}
+
+// refreshTokenSaver stores a newly generated refresh token
+type refreshTokenSaver func(ctx context.Context, refreshToken string) error
+
+// maxDeviceLabelBytes caps the device label stored with a session
+const maxDeviceLabelBytes = 128
+
+// deviceLabel names the device a session belongs to: the label the client sent,
+// or else its User-Agent
+func deviceLabel(r *http.Request, label string) string {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		label = strings.TrimSpace(r.UserAgent())
+	}
+	label, _ = textutil.TruncateUTF8(label, maxDeviceLabelBytes)
+	return label
+}
+
+// startSession returns a refreshTokenSaver that stores the refresh token as a new
+// session of the user from client, leaving the user's other sessions untouched
+func (cfg *Config) startSession(store database.Querier, user database.User, client sessionClient) refreshTokenSaver {
+	return func(ctx context.Context, refreshToken string) error {
+		params := cfg.newRefreshTokenParams(user.ID, refreshToken)
+		params.DeviceLabel = client.DeviceLabel
+		params.UserAgentHash = client.UserAgentHash
+		params.IpPrefix = client.IPPrefix
+		if _, err := store.CreateRefreshToken(ctx, params); err != nil {
+			return fmt.Errorf("failed to save refresh token: %v", err)
+		}
+		return nil
+	}
+}
+
+// endMismatchedSession ends the session of a bound refresh token presented by another
+// client: the token may have been stolen, so its owner has to log in again
+func (cfg *Config) endMismatchedSession(ctx context.Context, store database.Querier, token database.RefreshToken, refreshToken string) {
+	cfg.Logger.Warn().
+		Str("user_id", token.UserID.String()).
+		Str("session_id", token.SessionID.String()).
+		Msg("Refresh token presented by a different client, ending its session")
+
+	if err := endSession(ctx, store, token.UserID, refreshToken); err != nil {
+		cfg.Logger.Error().Err(err).Str("user_id", token.UserID.String()).Msg("Failed to end mismatched session")
+	}
+}
+
+// endSession deletes the user's refresh token matching refreshToken. Ending a
+// session that no longer exists is not an error, so logging out twice is harmless.
+func endSession(ctx context.Context, store database.Querier, userID uuid.UUID, refreshToken string) error {
+	_, err := store.DeleteRefreshTokenByHash(ctx, database.DeleteRefreshTokenByHashParams{
+		TokenHash: auth.HashRefreshToken(refreshToken),
+		UserID:    userID,
+	})
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate refresh token")
-		return
+		return fmt.Errorf("failed to delete refresh token: %v", err)
 	}
+	return nil
+}
 
-	errGenerateRefToken := cfg.deleteAndGenerateRefreshTokenFromDB(r.Context(), &user, refreshToken)
-	if errGenerateRefToken != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, errGenerateRefToken.Error())
-		return
+// issueTokenPair generates an access token and a refresh token for the user, stores
+// the refresh token with save and returns the response body shared by register,
+// login and refresh
+func (cfg *Config) issueTokenPair(ctx context.Context, user database.User, save refreshTokenSaver) (models.AuthResponse, error) {
+	accessToken, err := auth.GenerateJWT(user.ID, user.Email.String)
+	if err != nil {
+		return models.AuthResponse{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	refreshToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return models.AuthResponse{}, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	type response struct {
-		User         models.User `json:"user"`
-		AccessToken  string      `json:"access_token"`
-		RefreshToken string      `json:"refresh_token"`
+	if err := save(ctx, refreshToken); err != nil {
+		return models.AuthResponse{}, err
 	}
 
-	models.RespondWithJSON(w, http.StatusOK, response{
+	return models.AuthResponse{
 		User:         models.DatabaseUserToUser(user),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		ExpiresIn:    int64(auth.AccessTokenTTL() / time.Second),
+	}, nil
+}
+
+// respondWithTokenPair sends a token pair, handing the refresh token over with issueRefreshToken
+func (cfg *Config) respondWithTokenPair(w http.ResponseWriter, status int, pair models.AuthResponse) {
+	pair.RefreshToken = cfg.issueRefreshToken(w, pair.RefreshToken)
+	models.RespondWithJSON(w, status, pair)
+}
+
+// respondWithTokenPairError responds to an issueTokenPair failure
+func (cfg *Config) respondWithTokenPairError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errRefreshTokenReused) {
+		models.RespondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	cfg.Logger.Error().Err(err).Msg("Failed to issue tokens")
+	models.RespondWithError(w, http.StatusInternalServerError, "Failed to issue tokens")
+}
+
+// issueRefreshToken hands a new refresh token to the client.
+// In cookie mode it is set as an HttpOnly cookie and left out of the JSON body
+// (an empty string is returned), so scripts injected into the page can't read it.
+func (cfg *Config) issueRefreshToken(w http.ResponseWriter, refreshToken string) string {
+	if !cfg.RefreshTokenCookie {
+		return refreshToken
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    refreshToken,
+		Path:     refreshTokenCookiePath,
+		MaxAge:   int(cfg.refreshTokenTTL() / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
 	})
+	return ""
+}
 
+// clearRefreshTokenCookie removes the refresh token cookie from the browser
+func clearRefreshTokenCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookie,
+		Path:     refreshTokenCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
 }
 
-// deleteAndGenerateRefreshTokenFromDB deletes any existing refresh token for the user
-// and creates a new refresh token record in the database within a transaction.
-//
-// Parameters:
-//   - context: The context for database operations
-//   - user: The user for whom the refresh token is being managed
-//   - refreshTokenString: The new refresh token string to be hashed and stored
-//
-// Returns:
-//   - error: Any error encountered during the process, or nil if successful
-//
-// Transaction Management:
-//   - Begins a new database transaction
-//   - Deletes existing refresh token for the user
-//   - Inserts the new refresh token record
-//   - Commits the transaction if all operations succeed
-//   - Rolls back the transaction in case of any errors
-func (cfg *Config) deleteAndGenerateRefreshTokenFromDB(context context.Context, user *database.User, refreshTokenString string) error {
-	tx, errorTx := cfg.DBConn.BeginTx(context, nil)
-	if errorTx != nil {
-		return fmt.Errorf("failed to start transaction: %v", errorTx)
+// refreshTokenFromRequest returns the refresh token from the body, falling back
+// to the refresh token cookie in cookie mode
+func (cfg *Config) refreshTokenFromRequest(r *http.Request, bodyToken string) string {
+	if bodyToken != "" || !cfg.RefreshTokenCookie {
+		return bodyToken
+	}
+
+	cookie, err := r.Cookie(refreshTokenCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// errRefreshTokenReused is returned when a presented refresh token no longer exists,
+// because it was already rotated by another request or revoked
+var errRefreshTokenReused = errors.New("refresh token is invalid or already used")
+
+// rotateRefreshTokenInDB swaps the presented refresh token for newRefreshToken in one transaction
+func (cfg *Config) rotateRefreshTokenInDB(ctx context.Context, presented database.RefreshToken, newRefreshToken string) error {
+	tx, errTx := cfg.DBConn.BeginTx(ctx, nil)
+	if errTx != nil {
+		return fmt.Errorf("failed to start transaction: %v", errTx)
 	}
 
 	defer func() {
@@ -395,27 +575,139 @@ func (cfg *Config) deleteAndGenerateRefreshTokenFromDB(context context.Context,
 		}
 	}()
 
-	qtx := cfg.DB.WithTx(tx)
+	replacement := cfg.newRefreshTokenParams(presented.UserID, newRefreshToken)
+	if err := rotateRefreshToken(ctx, cfg.DB.WithTx(tx), presented, replacement); err != nil {
+		return err
+	}
 
-	errDeleteRefreshTokenDb := qtx.DeleteRefreshToken(context, user.ID)
-	if errDeleteRefreshTokenDb != nil {
-		return fmt.Errorf("failed to delete refresh token: %v", errDeleteRefreshTokenDb)
+	if errTxCommit := tx.Commit(); errTxCommit != nil {
+		return fmt.Errorf("failed to commit transaction: %v", errTxCommit)
 	}
+	return nil
+}
 
-	_, errSaveRefreshTokenDb := qtx.CreateRefreshToken(context, database.CreateRefreshTokenParams{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		TokenHash: auth.HashRefreshToken(refreshTokenString),
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour).UTC(),
-		CreatedAt: time.Now().UTC(),
+// handleRefreshTokenReuse ends the session of an unknown refresh token that turns out
+// to be one that was already rotated, and closes the user's live connections
+func (cfg *Config) handleRefreshTokenReuse(ctx context.Context, presentedHash string) {
+	userID, revoked, err := revokeReusedRefreshTokens(ctx, cfg.DB, presentedHash)
+	if err != nil {
+		cfg.Logger.Error().Err(err).Msg("Failed to revoke refresh tokens after reuse")
+		return
+	}
+	if !revoked {
+		return
+	}
+
+	cfg.Logger.Warn().Str("user_id", userID.String()).Msg("Rotated refresh token was reused, ended its session")
+	// Access tokens don't name their session, so the hub can't tell which of the user's
+	// sockets the replayed session opened. All are closed with 4001 and the clients of
+	// the sessions that remain reconnect after refreshing, while the thief can't.
+	if cfg.Hub != nil {
+		cfg.Hub.DisconnectUser(userID, realtime.CloseUnauthorized)
+	}
+}
+
+// revokeReusedRefreshTokens checks whether presentedHash belongs to a refresh token that
+// its session has already rotated, however many rotations ago. Only the legitimate
+// client or a thief can hold such a token, and one of them is replaying it, so the
+// session is ended and both have to log in again; the user's other sessions are kept.
+// It reports the affected user and whether a session was revoked.
+func revokeReusedRefreshTokens(ctx context.Context, store database.Querier, presentedHash string) (uuid.UUID, bool, error) {
+	rotated, err := store.GetRotatedRefreshToken(ctx, presentedHash)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to look up rotated refresh token: %v", err)
+	}
+
+	deleted, err := store.DeleteSessionForUser(ctx, database.DeleteSessionForUserParams{
+		SessionID: rotated.SessionID,
+		UserID:    rotated.UserID,
 	})
-	if errSaveRefreshTokenDb != nil {
-		return fmt.Errorf("failed to save refresh token: %v", errSaveRefreshTokenDb)
+	if err != nil {
+		return rotated.UserID, false, fmt.Errorf("failed to delete session: %v", err)
 	}
+	return rotated.UserID, deleted > 0, nil
+}
 
-	if errTxCommit := tx.Commit(); errTxCommit != nil {
-		return fmt.Errorf("failed to commit transaction: %v", errTxCommit)
+// rotateRefreshToken is a compare-and-swap on the presented token: it is deleted by
+// hash and the replacement is only stored if exactly that one row was removed.
+// Two concurrent refreshes with the same token both reach the DELETE, but the row
+// lock makes the second wait for the first to commit and then delete nothing, so
+// it fails with errRefreshTokenReused instead of minting a second token.
+func rotateRefreshToken(ctx context.Context, store database.Querier, presented database.RefreshToken, replacement database.CreateRefreshTokenParams) error {
+	deleted, err := store.DeleteRefreshTokenByHash(ctx, database.DeleteRefreshTokenByHashParams{
+		TokenHash: presented.TokenHash,
+		UserID:    presented.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete refresh token: %v", err)
+	}
+	if deleted != 1 {
+		return errRefreshTokenReused
+	}
+
+	// The replacement continues the same session and remembers what it replaced,
+	// so a replay of the old token is detectable
+	replacement.SessionID = presented.SessionID
+	replacement.DeviceLabel = presented.DeviceLabel
+	replacement.UserAgentHash = presented.UserAgentHash
+	replacement.IpPrefix = presented.IpPrefix
+	replacement.PreviousTokenHash = sql.NullString{String: presented.TokenHash, Valid: true}
+	if _, err := store.CreateRefreshToken(ctx, replacement); err != nil {
+		return fmt.Errorf("failed to save refresh token: %v", err)
 	}
 
+	// Every rotated token of the session is remembered until it would have expired
+	err = store.CreateRotatedRefreshToken(ctx, database.CreateRotatedRefreshTokenParams{
+		TokenHash: presented.TokenHash,
+		UserID:    presented.UserID,
+		SessionID: presented.SessionID,
+		ExpiresAt: presented.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record rotated refresh token: %v", err)
+	}
+	err = store.DeleteExpiredRotatedRefreshTokens(ctx, database.DeleteExpiredRotatedRefreshTokensParams{
+		UserID:    presented.UserID,
+		ExpiresAt: replacement.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune rotated refresh tokens: %v", err)
+	}
 	return nil
 }
+
+// refreshTokenTTL returns the configured refresh token lifetime, falling back to the default
+func (cfg *Config) refreshTokenTTL() time.Duration {
+	if cfg.RefreshTokenTTL <= 0 {
+		return DefaultRefreshTokenTTL
+	}
+	return cfg.RefreshTokenTTL
+}
+
+// newRefreshTokenParams builds the row for a new refresh token in a new session.
+// Every refresh token is created through it, so they all expire after the
+// configured lifetime.
+func (cfg *Config) newRefreshTokenParams(userID uuid.UUID, refreshToken string) database.CreateRefreshTokenParams {
+	now := time.Now().UTC()
+	return database.CreateRefreshTokenParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: auth.HashRefreshToken(refreshToken),
+		ExpiresAt: now.Add(cfg.refreshTokenTTL()),
+		CreatedAt: now,
+		SessionID: uuid.New(),
+	}
+}
+
+// refreshTokenExpired reports whether token is no longer usable at now. Besides its
+// stored expires_at, a token expires once it is older than the configured lifetime,
+// so lowering JWT_REFRESH_TTL also shortens tokens issued before the change.
+func (cfg *Config) refreshTokenExpired(token database.RefreshToken, now time.Time) bool {
+	if now.After(token.ExpiresAt) {
+		return true
+	}
+	return now.After(token.CreatedAt.Add(cfg.refreshTokenTTL()))
+}
@@ -0,0 +1,598 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestIssueRefreshToken_BodyMode_ReturnsToken(t *testing.T) {
+	cfg := &Config{}
+	w := httptest.NewRecorder()
+
+	if got := cfg.issueRefreshToken(w, "refresh-123"); got != "refresh-123" {
+		t.Errorf("Expected refresh token in body, got %q", got)
+	}
+
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("Expected no cookies, got %d", len(cookies))
+	}
+}
+
+func TestIssueRefreshToken_CookieMode_SetsSecureCookie(t *testing.T) {
+	cfg := &Config{RefreshTokenCookie: true}
+	w := httptest.NewRecorder()
+
+	if got := cfg.issueRefreshToken(w, "refresh-123"); got != "" {
+		t.Errorf("Expected refresh token to be left out of the body, got %q", got)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected 1 cookie, got %d", len(cookies))
+	}
+
+	cookie := cookies[0]
+	if cookie.Name != refreshTokenCookie || cookie.Value != "refresh-123" {
+		t.Errorf("Expected %s=refresh-123, got %s=%s", refreshTokenCookie, cookie.Name, cookie.Value)
+	}
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode {
+		t.Errorf("Expected HttpOnly, Secure, SameSite=Strict cookie, got %+v", cookie)
+	}
+	if cookie.Path != refreshTokenCookiePath {
+		t.Errorf("Expected path %s, got %s", refreshTokenCookiePath, cookie.Path)
+	}
+}
+
+func TestRefreshTokenFromRequest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		cookieMode bool
+		bodyToken  string
+		cookie     string
+		expected   string
+	}{
+		{"Body token", false, "from-body", "", "from-body"},
+		{"Body token wins over cookie", true, "from-body", "from-cookie", "from-body"},
+		{"Cookie used when body is empty", true, "", "from-cookie", "from-cookie"},
+		{"Cookie ignored outside cookie mode", false, "", "from-cookie", ""},
+		{"Nothing sent", true, "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{RefreshTokenCookie: tc.cookieMode}
+			req := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: refreshTokenCookie, Value: tc.cookie})
+			}
+
+			if got := cfg.refreshTokenFromRequest(req, tc.bodyToken); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandlerRefreshToken_NoToken_ReturnsUnprocessableEntity(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"Empty body", ""},
+		{"Empty JSON object", "{}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{RefreshTokenCookie: true}
+			req := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			cfg.HandlerRefreshToken(w, req)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Expected status 422, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestClearRefreshTokenCookie_ExpiresCookie(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	clearRefreshTokenCookie(w)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != refreshTokenCookie || cookies[0].MaxAge >= 0 {
+		t.Errorf("Expected an expired %s cookie, got %+v", refreshTokenCookie, cookies)
+	}
+}
+
+func TestRotateRefreshToken_ConcurrentRefreshes_OnlyOneSucceeds(t *testing.T) {
+	presented := database.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		TokenHash: "presented-hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	store := newFakeQuerier()
+	store.addRefreshTokens(presented)
+	cfg := &Config{}
+
+	start := make(chan struct{})
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			replacement := cfg.newRefreshTokenParams(presented.UserID, uuid.NewString())
+			errs[i] = rotateRefreshToken(context.Background(), store, presented, replacement)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded, reused := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, errRefreshTokenReused):
+			reused++
+		default:
+			t.Fatalf("Expected success or errRefreshTokenReused, got %v", err)
+		}
+	}
+	if succeeded != 1 || reused != 1 {
+		t.Errorf("Expected 1 success and 1 reuse, got %d and %d", succeeded, reused)
+	}
+	if len(store.refreshTokens) != 1 {
+		t.Errorf("Expected exactly 1 stored refresh token, got %d", len(store.refreshTokens))
+	}
+	if _, ok := store.refreshTokens[presented.TokenHash]; ok {
+		t.Error("Expected the presented token to be deleted")
+	}
+}
+
+func TestRotateRefreshToken_UnknownToken_ReturnsReused(t *testing.T) {
+	store := newFakeQuerier()
+	presented := database.RefreshToken{UserID: uuid.New(), TokenHash: "missing"}
+
+	cfg := &Config{}
+
+	err := rotateRefreshToken(context.Background(), store, presented, cfg.newRefreshTokenParams(presented.UserID, uuid.NewString()))
+	if !errors.Is(err, errRefreshTokenReused) {
+		t.Fatalf("Expected errRefreshTokenReused, got %v", err)
+	}
+	if len(store.refreshTokens) != 0 {
+		t.Errorf("Expected no new token to be stored, got %d", len(store.refreshTokens))
+	}
+}
+
+func TestRevokeReusedRefreshTokens_StolenTokenReplayedAfterRotations_RevokesSession(t *testing.T) {
+	cfg := &Config{}
+	ctx := context.Background()
+	userID := uuid.New()
+	otherSession := cfg.newRefreshTokenParams(userID, "laptop")
+	otherUser := cfg.newRefreshTokenParams(uuid.New(), "other-device")
+
+	// The attacker copies the token before the legitimate client rotates it twice
+	stolen := database.RefreshToken(cfg.newRefreshTokenParams(userID, "refresh-1"))
+	store := newFakeQuerier()
+	store.addRefreshTokens(stolen, database.RefreshToken(otherSession), database.RefreshToken(otherUser))
+	if err := rotateRefreshToken(ctx, store, stolen, cfg.newRefreshTokenParams(userID, "refresh-2")); err != nil {
+		t.Fatalf("Expected the first rotation to succeed, got %v", err)
+	}
+	second := store.refreshTokens[auth.HashRefreshToken("refresh-2")]
+	if err := rotateRefreshToken(ctx, store, second, cfg.newRefreshTokenParams(userID, "refresh-3")); err != nil {
+		t.Fatalf("Expected the second rotation to succeed, got %v", err)
+	}
+
+	current := auth.HashRefreshToken("refresh-3")
+	if store.refreshTokens[current].SessionID != stolen.SessionID {
+		t.Fatalf("Expected the rotated token to continue session %v, got %+v", stolen.SessionID, store.refreshTokens[current])
+	}
+
+	// The attacker replays the stolen token, two rotations behind
+	revokedUser, revoked, err := revokeReusedRefreshTokens(ctx, store, stolen.TokenHash)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !revoked || revokedUser != userID {
+		t.Fatalf("Expected a session of user %v to be revoked, got revoked=%v for %v", userID, revoked, revokedUser)
+	}
+
+	if _, ok := store.refreshTokens[current]; ok {
+		t.Error("Expected the current refresh token of the session to be revoked")
+	}
+	if _, ok := store.refreshTokens[otherSession.TokenHash]; !ok {
+		t.Error("Expected the user's other sessions to be kept")
+	}
+	if _, ok := store.refreshTokens[otherUser.TokenHash]; !ok {
+		t.Error("Expected other users' refresh tokens to be kept")
+	}
+}
+
+func TestRotateRefreshToken_PrunesExpiredRotatedTokens(t *testing.T) {
+	cfg := &Config{}
+	userID := uuid.New()
+	store := newFakeQuerier()
+	store.rotatedTokens["expired"] = database.RotatedRefreshToken{TokenHash: "expired", UserID: userID, ExpiresAt: time.Now().Add(-time.Hour)}
+	store.rotatedTokens["live"] = database.RotatedRefreshToken{TokenHash: "live", UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+	presented := database.RefreshToken(cfg.newRefreshTokenParams(userID, "refresh-1"))
+	store.addRefreshTokens(presented)
+
+	if err := rotateRefreshToken(context.Background(), store, presented, cfg.newRefreshTokenParams(userID, "refresh-2")); err != nil {
+		t.Fatalf("Expected rotation to succeed, got %v", err)
+	}
+
+	if _, ok := store.rotatedTokens["expired"]; ok {
+		t.Error("Expected the expired rotated token to be pruned")
+	}
+	for _, hash := range []string{"live", presented.TokenHash} {
+		if _, ok := store.rotatedTokens[hash]; !ok {
+			t.Errorf("Expected rotated token %s to be kept", hash)
+		}
+	}
+}
+
+func TestRevokeReusedRefreshTokens_UnknownToken_RevokesNothing(t *testing.T) {
+	cfg := &Config{}
+	current := cfg.newRefreshTokenParams(uuid.New(), "refresh-1")
+	store := newFakeQuerier()
+	store.addRefreshTokens(database.RefreshToken(current))
+
+	_, revoked, err := revokeReusedRefreshTokens(context.Background(), store, auth.HashRefreshToken("never-issued"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if revoked {
+		t.Error("Expected a never-issued token not to revoke anything")
+	}
+	if len(store.refreshTokens) != 1 {
+		t.Errorf("Expected the stored token to be kept, got %d tokens", len(store.refreshTokens))
+	}
+}
+
+func TestRefreshTokenCreation_UsesConfiguredTTL(t *testing.T) {
+	ttl := 36 * time.Hour
+	cfg := &Config{RefreshTokenTTL: ttl, RefreshTokenCookie: true}
+	userID := uuid.New()
+
+	// Register and login store newRefreshTokenParams directly
+	params := cfg.newRefreshTokenParams(userID, "refresh-123")
+	if got := params.ExpiresAt.Sub(params.CreatedAt); got != ttl {
+		t.Errorf("Expected new token lifetime %v, got %v", ttl, got)
+	}
+	if params.UserID != userID {
+		t.Errorf("Expected user %v, got %v", userID, params.UserID)
+	}
+
+	// Refresh stores the replacement it is given
+	presented := database.RefreshToken{UserID: userID, TokenHash: "presented-hash"}
+	store := newFakeQuerier()
+	store.addRefreshTokens(presented)
+	if err := rotateRefreshToken(context.Background(), store, presented, cfg.newRefreshTokenParams(userID, "refresh-456")); err != nil {
+		t.Fatalf("Expected rotation to succeed, got %v", err)
+	}
+	for _, token := range store.refreshTokens {
+		if got := token.ExpiresAt.Sub(token.CreatedAt); got != ttl {
+			t.Errorf("Expected rotated token lifetime %v, got %v", ttl, got)
+		}
+	}
+
+	// The cookie lives exactly as long as the token
+	w := httptest.NewRecorder()
+	cfg.issueRefreshToken(w, "refresh-456")
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected 1 cookie, got %d", len(cookies))
+	}
+	if got := time.Duration(cookies[0].MaxAge) * time.Second; got != ttl {
+		t.Errorf("Expected cookie max age %v, got %v", ttl, got)
+	}
+}
+
+func TestRefreshTokenTTL_Unset_UsesDefault(t *testing.T) {
+	cfg := &Config{}
+
+	if got := cfg.refreshTokenTTL(); got != DefaultRefreshTokenTTL {
+		t.Errorf("Expected %v, got %v", DefaultRefreshTokenTTL, got)
+	}
+}
+
+func TestRefreshTokenExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &Config{RefreshTokenTTL: 24 * time.Hour}
+
+	testCases := []struct {
+		name     string
+		token    database.RefreshToken
+		expected bool
+	}{
+		{"Fresh token", database.RefreshToken{CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(23 * time.Hour)}, false},
+		{"Past expires_at", database.RefreshToken{CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}, true},
+		{"Issued under a longer TTL", database.RefreshToken{CreatedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(120 * time.Hour)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cfg.refreshTokenExpired(tc.token, now); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIssueTokenPair_IncludesExpiresIn(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	testCases := []struct {
+		name     string
+		ttl      string
+		expected int64
+	}{
+		{"Default lifetime", "", 900},
+		{"Configured lifetime", "1h", 3600},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("JWT_ACCESS_TOKEN_TTL", tc.ttl)
+			cfg := &Config{}
+			rec := httptest.NewRecorder()
+
+			pair, err := cfg.issueTokenPair(context.Background(), database.User{ID: uuid.New()}, saveNothing)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			cfg.respondWithTokenPair(rec, http.StatusOK, pair)
+
+			var body models.AuthResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("Expected a JSON body, got %v", err)
+			}
+			if body.ExpiresIn != tc.expected {
+				t.Errorf("Expected expires_in %d, got %d", tc.expected, body.ExpiresIn)
+			}
+			if body.ExpiresIn != int64(auth.AccessTokenTTL().Seconds()) {
+				t.Errorf("Expected expires_in to match the token lifetime, got %d", body.ExpiresIn)
+			}
+		})
+	}
+}
+
+// saveNothing is a refreshTokenSaver that stores nothing
+func saveNothing(ctx context.Context, refreshToken string) error {
+	return nil
+}
+
+func TestIssueTokenPair_SameJSONShapeForAllEndpoints(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	user := database.User{ID: uuid.New(), Name: "Test", Email: sql.NullString{String: "test@example.com", Valid: true}}
+
+	// Each endpoint stores its refresh token differently; the response must not differ
+	presented := database.RefreshToken{UserID: user.ID, TokenHash: "presented-hash"}
+	rotation := newFakeQuerier()
+	rotation.addRefreshTokens(presented)
+	cfg := &Config{}
+
+	endpoints := []struct {
+		name   string
+		status int
+		save   refreshTokenSaver
+	}{
+		{"Register", http.StatusCreated, saveNothing},
+		{"Login", http.StatusOK, saveNothing},
+		{"Refresh", http.StatusOK, func(ctx context.Context, refreshToken string) error {
+			return rotateRefreshToken(ctx, rotation, presented, cfg.newRefreshTokenParams(user.ID, refreshToken))
+		}},
+	}
+
+	var expectedKeys []string
+	for _, endpoint := range endpoints {
+		pair, err := cfg.issueTokenPair(context.Background(), user, endpoint.save)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", endpoint.name, err)
+		}
+
+		rec := httptest.NewRecorder()
+		cfg.respondWithTokenPair(rec, endpoint.status, pair)
+
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: expected a JSON body, got %v", endpoint.name, err)
+		}
+
+		keys := make([]string, 0, len(body))
+		for key := range body {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if expectedKeys == nil {
+			expectedKeys = keys
+			if strings.Join(keys, ",") != "access_token,expires_in,refresh_token,user" {
+				t.Errorf("Expected access_token, expires_in, refresh_token and user, got %v", keys)
+			}
+			continue
+		}
+		if strings.Join(keys, ",") != strings.Join(expectedKeys, ",") {
+			t.Errorf("%s: expected keys %v, got %v", endpoint.name, expectedKeys, keys)
+		}
+	}
+}
+
+func TestIssueTokenPair_ReusedRefreshToken_Unauthorized(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg := &Config{}
+
+	_, err := cfg.issueTokenPair(context.Background(), database.User{ID: uuid.New()}, func(ctx context.Context, refreshToken string) error {
+		return errRefreshTokenReused
+	})
+	if !errors.Is(err, errRefreshTokenReused) {
+		t.Fatalf("Expected errRefreshTokenReused, got %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	cfg.respondWithTokenPairError(rec, err)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	withPassword := database.User{PasswordHash: sql.NullString{String: string(hash), Valid: true}}
+	socialOnly := database.User{PasswordHash: sql.NullString{}}
+
+	if err := checkPassword(withPassword, "correct horse"); err != nil {
+		t.Errorf("Expected the right password to be accepted, got %v", err)
+	}
+	if err := checkPassword(withPassword, "wrong"); err == nil || errors.Is(err, errNoPassword) {
+		t.Errorf("Expected a mismatch error for the wrong password, got %v", err)
+	}
+	if err := checkPassword(socialOnly, "anything"); !errors.Is(err, errNoPassword) {
+		t.Errorf("Expected errNoPassword for a NULL password hash, got %v", err)
+	}
+}
+
+// registerRequest builds a POST /v1/auth/register request for email
+func registerRequest(email string) *http.Request {
+	body := `{"name":"Ada","email":"` + email + `","password":"correct-horse-1"}`
+	return httptest.NewRequest(http.MethodPost, "/v1/auth/register", strings.NewReader(body))
+}
+
+func TestHandlerRegister_DuplicateEmail_ReturnsConflict(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg := &Config{}
+	store := newFakeQuerier()
+
+	first := httptest.NewRecorder()
+	cfg.respondToRegistration(first, registerRequest("ada@example.com"), store)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected first registration to return 201, got %d: %s", first.Code, first.Body.String())
+	}
+
+	second := httptest.NewRecorder()
+	cfg.respondToRegistration(second, registerRequest("Ada@Example.com"), store)
+	if second.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", second.Code)
+	}
+	if strings.Contains(second.Body.String(), "users_email_key") || strings.Contains(second.Body.String(), "duplicate key") {
+		t.Errorf("Expected no database details in the response, got %s", second.Body.String())
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(second.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body["error"] != "Email already registered" {
+		t.Errorf("Expected error %q, got %q", "Email already registered", body["error"])
+	}
+	if len(store.users) != 1 {
+		t.Errorf("Expected 1 user, got %d", len(store.users))
+	}
+}
+
+func TestHandlerRegister_CreateFailure_ReturnsInternalServerError(t *testing.T) {
+	cfg := &Config{}
+	store := newFakeQuerier()
+	store.createUserErr = &pq.Error{Code: "08006", Message: "connection failure"}
+	rec := httptest.NewRecorder()
+
+	cfg.respondToRegistration(rec, registerRequest("ada@example.com"), store)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "connection failure") {
+		t.Errorf("Expected no database details in the response, got %s", rec.Body.String())
+	}
+}
+
+func TestHandlerRegister_MalformedVersusInvalidBody(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedField  string
+	}{
+		{"Malformed JSON", `{"name":"Ada",`, http.StatusBadRequest, ""},
+		{"Wrong JSON type", `{"name":42}`, http.StatusBadRequest, ""},
+		{"Empty name", `{"name":"","email":"ada@example.com","password":"correct-horse-1"}`, http.StatusUnprocessableEntity, "name"},
+		{"Missing email", `{"name":"Ada","password":"correct-horse-1"}`, http.StatusUnprocessableEntity, "email"},
+		{"Invalid email", `{"name":"Ada","email":"ada","password":"correct-horse-1"}`, http.StatusUnprocessableEntity, "email"},
+		{"Weak password", `{"name":"Ada","email":"ada@example.com","password":"horse"}`, http.StatusUnprocessableEntity, "password"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			store := newFakeQuerier()
+			req := httptest.NewRequest(http.MethodPost, "/v1/auth/register", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			cfg.respondToRegistration(rec, req, store)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body["field"] != tc.expectedField {
+				t.Errorf("Expected field %q, got %q", tc.expectedField, body["field"])
+			}
+			if body["error"] == "" {
+				t.Error("Expected an error message")
+			}
+			if len(store.users) != 0 {
+				t.Errorf("Expected no user to be created, got %d", len(store.users))
+			}
+		})
+	}
+}
+
+func TestFirstEmptyField(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fields   []string
+		expected string
+	}{
+		{"All set", []string{"email", "a@example.com", "password", "secret"}, ""},
+		{"First empty", []string{"email", "", "password", ""}, "email"},
+		{"Later empty", []string{"email", "a@example.com", "password", ""}, "password"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := firstEmptyField(tc.fields...); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+type bookmarksResponse struct {
+	Bookmarks  []models.Bookmark `json:"bookmarks"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// HandlerCreateBookmark saves a post for later
+// @Summary     Bookmark a post
+// @Description Save a post for later. Any post can be bookmarked, and it stays bookmarked after unfollowing its feed. Bookmarking a post again keeps the original bookmark.
+// @Tags        bookmarks
+// @Security    Bearer
+// @Param       postID  path  string  true  "Post ID"
+// @Success     204     "Post bookmarked"
+// @Failure     400     {object}  object  "Invalid ID"
+// @Failure     404     {object}  object  "Post not found"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/posts/{postID}/bookmark [post]
+func (cfg *Config) HandlerCreateBookmark(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToBookmark(w, r, user, cfg.DB, true)
+}
+
+// HandlerDeleteBookmark removes a post from the user's bookmarks
+// @Summary     Remove a bookmark
+// @Description Remove a post from your bookmarks. Posts that aren't bookmarked are left as they are.
+// @Tags        bookmarks
+// @Security    Bearer
+// @Param       postID  path  string  true  "Post ID"
+// @Success     204     "Bookmark removed"
+// @Failure     400     {object}  object  "Invalid ID"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/posts/{postID}/bookmark [delete]
+func (cfg *Config) HandlerDeleteBookmark(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToBookmark(w, r, user, cfg.DB, false)
+}
+
+// respondToBookmark bookmarks the post in the URL for the user, or removes the bookmark.
+// Both are idempotent.
+func respondToBookmark(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier, bookmark bool) {
+	postID, err := uuid.Parse(chi.URLParam(r, "postID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid post ID: %v", err))
+		return
+	}
+
+	if bookmark {
+		// An unknown post violates the foreign key and is reported as not found
+		err = store.CreateBookmark(r.Context(), database.CreateBookmarkParams{
+			UserID:    user.ID,
+			PostID:    postID,
+			CreatedAt: time.Now().UTC(),
+		})
+	} else {
+		err = store.DeleteBookmark(r.Context(), database.DeleteBookmarkParams{
+			UserID: user.ID,
+			PostID: postID,
+		})
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Bookmark post")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusNoContent, struct{}{})
+}
+
+// HandlerGetBookmarks returns the user's bookmarked posts with cursor-based pagination
+// @Summary     List bookmarks
+// @Description Get your bookmarked posts, most recently bookmarked first, with cursor-based pagination
+// @Tags        bookmarks
+// @Produce     json
+// @Security    Bearer
+// @Param       limit   query     int     false  "Number of bookmarks to return (max 100)"  default(20)
+// @Param       cursor  query     string  false  "Cursor for pagination (next_cursor of the previous page)"
+// @Success     200     {object}  object  "List of bookmarks"
+// @Failure     400     {object}  object  "Invalid parameters"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/bookmarks [get]
+func (cfg *Config) HandlerGetBookmarks(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithBookmarks(w, r, user, cfg.DB)
+}
+
+// respondWithBookmarks writes a page of the user's bookmarks from store
+func respondWithBookmarks(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier) {
+	limit, cursor, err := parsePostsPagination(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid cursor format")
+		return
+	}
+
+	rows, err := store.GetBookmarksForUser(r.Context(), database.GetBookmarksForUserParams{
+		UserID:           user.ID,
+		BookmarkedBefore: cursor,
+		RowLimit:         int32(limit),
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Get bookmarks")
+		return
+	}
+
+	response := bookmarksResponse{Bookmarks: make([]models.Bookmark, 0, len(rows))}
+	for _, row := range rows {
+		response.Bookmarks = append(response.Bookmarks, models.DatabaseBookmarkToBookmark(row))
+	}
+	// Several bookmarks can share a second, so the cursor keeps the fraction to not skip any
+	if len(rows) > 0 {
+		response.NextCursor = rows[len(rows)-1].BookmarkedAt.Format(time.RFC3339Nano)
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
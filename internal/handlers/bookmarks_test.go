@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// bookmarkRequest builds a POST or DELETE /v1/posts/{postID}/bookmark request
+func bookmarkRequest(method string, postID uuid.UUID) *http.Request {
+	req := httptest.NewRequest(method, "/v1/posts/"+postID.String()+"/bookmark", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("postID", postID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// bookmarkPost bookmarks or unbookmarks a post and fails the test unless it succeeds
+func bookmarkPost(t *testing.T, store *fakeQuerier, user database.User, method string, postID uuid.UUID) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	respondToBookmark(rec, bookmarkRequest(method, postID), user, store, method == http.MethodPost)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 for %s, got %d: %s", method, rec.Code, rec.Body.String())
+	}
+}
+
+// listBookmarkTitles pages through all of the user's bookmarks and returns their post titles
+func listBookmarkTitles(t *testing.T, store *fakeQuerier, user database.User) []string {
+	t.Helper()
+
+	var titles []string
+	query := "?limit=2"
+	for page := 0; page < 10; page++ {
+		rec := httptest.NewRecorder()
+		respondWithBookmarks(rec, httptest.NewRequest(http.MethodGet, "/v1/bookmarks"+query, nil), user, store)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response bookmarksResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Bookmarks) == 0 {
+			break
+		}
+		for _, bookmark := range response.Bookmarks {
+			titles = append(titles, bookmark.Post.Title)
+		}
+		query = "?limit=2&cursor=" + url.QueryEscape(response.NextCursor)
+	}
+	return titles
+}
+
+func TestBookmarks_BookmarkListAndRemove(t *testing.T) {
+	var posts []database.Post
+	for i := 0; i < 3; i++ {
+		posts = append(posts, database.Post{ID: uuid.New(), FeedID: uuid.New(), Title: fmt.Sprintf("Post %d", i), PublishedAt: time.Now()})
+	}
+	store := newFakeQuerier()
+	store.posts = posts
+	user, other := database.User{ID: uuid.New()}, database.User{ID: uuid.New()}
+
+	for _, post := range posts {
+		bookmarkPost(t, store, user, http.MethodPost, post.ID)
+	}
+	// Bookmarking again keeps the first bookmark, so Post 0 stays the oldest
+	bookmarkPost(t, store, user, http.MethodPost, posts[0].ID)
+	bookmarkPost(t, store, other, http.MethodPost, posts[1].ID)
+
+	expected := []string{"Post 2", "Post 1", "Post 0"}
+	if titles := listBookmarkTitles(t, store, user); fmt.Sprint(titles) != fmt.Sprint(expected) {
+		t.Errorf("Expected bookmarks %v, got %v", expected, titles)
+	}
+	if titles := listBookmarkTitles(t, store, other); fmt.Sprint(titles) != "[Post 1]" {
+		t.Errorf("Expected only the other user's own bookmark, got %v", titles)
+	}
+
+	// Removing is idempotent too
+	bookmarkPost(t, store, user, http.MethodDelete, posts[1].ID)
+	bookmarkPost(t, store, user, http.MethodDelete, posts[1].ID)
+
+	expected = []string{"Post 2", "Post 0"}
+	if titles := listBookmarkTitles(t, store, user); fmt.Sprint(titles) != fmt.Sprint(expected) {
+		t.Errorf("Expected bookmarks %v after removing one, got %v", expected, titles)
+	}
+	if titles := listBookmarkTitles(t, store, other); fmt.Sprint(titles) != "[Post 1]" {
+		t.Errorf("Expected the other user's bookmark to remain, got %v", titles)
+	}
+}
+
+func TestRespondToBookmark_UnknownPost_ReturnsNotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	respondToBookmark(rec, bookmarkRequest(http.MethodPost, uuid.New()), database.User{ID: uuid.New()}, newFakeQuerier(), true)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestRespondToBookmark_InvalidPostID_ReturnsBadRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/posts/not-a-uuid/bookmark", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("postID", "not-a-uuid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	respondToBookmark(rec, req, database.User{ID: uuid.New()}, newFakeQuerier(), true)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -1,28 +1,90 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
 	"github.com/rs/zerolog"
 )
 
+// PostImporter stores the posts of a feed fetched outside the scrape loop
+type PostImporter interface {
+	ImportFeedPosts(ctx context.Context, feed database.Feed, parsedFeed *feedfetch.ParsedFeed) []database.Post
+}
+
 // Config holds the dependencies for all handlers
 type Config struct {
 	DB     *database.Queries
 	DBConn *sql.DB
 	Logger zerolog.Logger
 	Hub    *realtime.Hub
+	// FeedFetcher downloads and parses user-submitted feeds
+	FeedFetcher feedfetch.FeedFetcher
+	// PostImporter stores a new feed's posts as it is created (nil = left to the scraper)
+	PostImporter PostImporter
+
+	// FeedPreviewMaxItems caps the items returned by the feed preview endpoint (0 = default of 10)
+	FeedPreviewMaxItems int
+	// PostsAfterFollowOnly hides posts published before the user followed their feed
+	PostsAfterFollowOnly bool
+	// PublicFeedMaxPages limits how many pages back anonymous clients can browse a feed (0 = unlimited)
+	PublicFeedMaxPages int
+	// MaxFeedFollowsPerUser caps how many feeds a user may follow (0 = unlimited)
+	MaxFeedFollowsPerUser int
+	// MaxTitleBytes caps feed names, longer ones are truncated (0 = default of textutil.DefaultMaxTitleBytes)
+	MaxTitleBytes int
+	// UniqueFeedNamesPerUser rejects a feed name the user already uses for another feed they created
+	UniqueFeedNamesPerUser bool
+	// FeedDomainAllowlist limits new feeds to these hosts; "*.example.com" matches subdomains (empty = any host)
+	FeedDomainAllowlist []string
+	// FeedDomainBlocklist rejects new feeds from these hosts, taking precedence over the allowlist
+	FeedDomainBlocklist []string
+	// BlockPrivateFeedHosts rejects new feeds whose host is or resolves to a loopback, private or link-local address
+	BlockPrivateFeedHosts bool
+	// HostResolver resolves feed hosts for BlockPrivateFeedHosts (nil = net.DefaultResolver)
+	HostResolver HostResolver
+	// RefreshTokenTTL is the maximum lifetime of a refresh token (0 = default of 7 days)
+	RefreshTokenTTL time.Duration
+	// MinPasswordLength is the shortest password accepted at registration (0 = default of 8)
+	MinPasswordLength int
+	// RefreshTokenCookie sends refresh tokens as an HttpOnly cookie instead of in the JSON body
+	RefreshTokenCookie bool
+	// RefreshTokenBinding rejects refreshes from another User-Agent and/or network than the login's
+	RefreshTokenBinding RefreshTokenBinding
+	// OAuthProviders holds the configured OAuth login providers by name
+	OAuthProviders map[string]*auth.OAuthProvider
+	// PostsCache caches pages of users' posts (nil = disabled)
+	PostsCache *cache.PostsCache
+	// FeedLogoCache caches fetched feed logos (nil = disabled)
+	FeedLogoCache *cache.LogosCache
+	// FeedLogoMaxBytes is the largest feed logo served (0 = default of 256 KiB)
+	FeedLogoMaxBytes int64
+
+	stats statsCache
 }
 
 // NewConfig creates a new handler config
 // Constructor pattern - used to create Config instances
 func NewConfig(queries *database.Queries, db *sql.DB, logger zerolog.Logger, hub *realtime.Hub) *Config {
 	return &Config{
-		DB:     queries,
-		DBConn: db,
-		Logger: logger,
-		Hub:    hub,
+		DB:          queries,
+		DBConn:      db,
+		Logger:      logger,
+		Hub:         hub,
+		FeedFetcher: feedfetch.NewGofeedFetcher(),
+	}
+}
+
+// feedFetcher returns the configured fetcher, falling back to the gofeed default
+func (cfg *Config) feedFetcher() feedfetch.FeedFetcher {
+	if cfg.FeedFetcher == nil {
+		return feedfetch.NewGofeedFetcher()
 	}
+	return cfg.FeedFetcher
 }
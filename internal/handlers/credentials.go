@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// DefaultMinPasswordLength is the shortest password accepted unless configured
+const DefaultMinPasswordLength = 8
+
+var (
+	// errInvalidEmail is returned for an email that isn't a bare address such as user@example.com
+	errInvalidEmail = errors.New("email must be a valid address such as user@example.com")
+	// errPasswordNoLetter is returned for a password without any letter
+	errPasswordNoLetter = errors.New("password must contain at least one letter")
+	// errPasswordNoDigit is returned for a password without any digit
+	errPasswordNoDigit = errors.New("password must contain at least one digit")
+	// errPasswordTooShort is returned for a password under the minimum length
+	errPasswordTooShort = errors.New("password is too short")
+)
+
+// normalizeEmail checks that email is a single bare address and returns it
+// lowercased, so addresses differing only in case belong to the same account
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	// ParseAddress also accepts display names ("Name <user@example.com>")
+	if err != nil || address.Address != email {
+		return "", errInvalidEmail
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+// minPasswordLength returns the configured minimum password length, falling back to the default
+func (cfg *Config) minPasswordLength() int {
+	if cfg.MinPasswordLength <= 0 {
+		return DefaultMinPasswordLength
+	}
+	return cfg.MinPasswordLength
+}
+
+// validatePassword enforces the password policy: at least minPasswordLength
+// characters, with at least one letter and one digit
+func (cfg *Config) validatePassword(password string) error {
+	if minLength := cfg.minPasswordLength(); len([]rune(password)) < minLength {
+		return fmt.Errorf("%w: it must be at least %d characters", errPasswordTooShort, minLength)
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter {
+		return errPasswordNoLetter
+	}
+	if !hasDigit {
+		return errPasswordNoDigit
+	}
+	return nil
+}
+
+// firstEmptyField takes alternating field names and values and returns the name of
+// the first empty value, or "" when every field is set
+func firstEmptyField(namesAndValues ...string) string {
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		if namesAndValues[i+1] == "" {
+			return namesAndValues[i]
+		}
+	}
+	return ""
+}
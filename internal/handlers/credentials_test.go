@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		email    string
+		expected string
+		valid    bool
+	}{
+		{"Plain address", "user@example.com", "user@example.com", true},
+		{"Mixed case is lowercased", "Foo@X.com", "foo@x.com", true},
+		{"Surrounding spaces", "  user@example.com ", "user@example.com", true},
+		{"No at sign", "notanemail", "", false},
+		{"No domain", "user@", "", false},
+		{"No local part", "@example.com", "", false},
+		{"Display name", "User <user@example.com>", "", false},
+		{"Two addresses", "a@example.com, b@example.com", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizeEmail(tc.email)
+			if !tc.valid {
+				if !errors.Is(err, errInvalidEmail) {
+					t.Errorf("Expected errInvalidEmail, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	testCases := []struct {
+		name      string
+		minLength int
+		password  string
+		expected  error
+	}{
+		{"Letters and digits", 0, "hunter22", nil},
+		{"Unicode letters count", 0, "pässwörd1", nil},
+		{"Too short for the default", 0, "abc123", errPasswordTooShort},
+		{"Single character", 0, "a", errPasswordTooShort},
+		{"Configured minimum", 12, "hunter22", errPasswordTooShort},
+		{"Meets configured minimum", 4, "ab12", nil},
+		{"No digit", 0, "passwordonly", errPasswordNoDigit},
+		{"No letter", 0, "1234567890", errPasswordNoLetter},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{MinPasswordLength: tc.minLength}
+			err := cfg.validatePassword(tc.password)
+			if tc.expected == nil && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tc.expected != nil && !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestHandlerRegister_InvalidCredentials_ReturnsSpecificMessage(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		expectedMessage string
+	}{
+		{"Malformed email", `{"name":"A","email":"notanemail","password":"hunter22"}`, "valid address"},
+		{"Short password", `{"name":"A","email":"a@example.com","password":"a1"}`, "at least 8 characters"},
+		{"Password without digit", `{"name":"A","email":"a@example.com","password":"password"}`, "digit"},
+		{"Password without letter", `{"name":"A","email":"a@example.com","password":"12345678"}`, "letter"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			req := httptest.NewRequest(http.MethodPost, "/v1/auth/register", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			// Rejected before any database access
+			cfg.HandlerRegister(rec, req)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected status 422, got %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tc.expectedMessage) {
+				t.Errorf("Expected message containing %q, got %s", tc.expectedMessage, rec.Body.String())
+			}
+		})
+	}
+}
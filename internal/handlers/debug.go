@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+)
+
+// dbPoolStats is the subset of sql.DBStats useful for spotting connection leaks
+type dbPoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+}
+
+type debugStatsResponse struct {
+	Goroutines int         `json:"goroutines"`
+	DB         dbPoolStats `json:"db"`
+	// Realtime is null when realtime updates are disabled
+	Realtime *realtime.HubStats `json:"realtime"`
+}
+
+// HandlerDebugStats returns process diagnostics for catching goroutine and connection leaks
+// @Summary     Get debug stats
+// @Description Admin only. Returns the number of goroutines, database connection pool stats and WebSocket hub connection counts.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Success     200  {object}  object  "Diagnostics"
+// @Failure     401  {object}  object  "Unauthorized"
+// @Failure     403  {object}  object  "Admin access required"
+// @Router      /v1/admin/debug/stats [get]
+func (cfg *Config) HandlerDebugStats(w http.ResponseWriter, r *http.Request, user database.User) {
+	dbStats := cfg.DBConn.Stats()
+
+	response := debugStatsResponse{
+		Goroutines: runtime.NumGoroutine(),
+		DB: dbPoolStats{
+			MaxOpenConnections: dbStats.MaxOpenConnections,
+			OpenConnections:    dbStats.OpenConnections,
+			InUse:              dbStats.InUse,
+			Idle:               dbStats.Idle,
+			WaitCount:          dbStats.WaitCount,
+			WaitDurationMs:     dbStats.WaitDuration.Milliseconds(),
+		},
+	}
+
+	if cfg.Hub != nil {
+		hubStats := cfg.Hub.Stats()
+		response.Realtime = &hubStats
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
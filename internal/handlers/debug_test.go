@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+	"github.com/rs/zerolog"
+)
+
+func TestHandlerDebugStats_ReturnsNumericFields(t *testing.T) {
+	// sql.Open doesn't connect, so pool stats are available without a database
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to open database handle: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &Config{DBConn: db, Hub: realtime.NewHub(zerolog.Nop())}
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/debug/stats", nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerDebugStats(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if goroutines, ok := body["goroutines"].(float64); !ok || goroutines < 1 {
+		t.Errorf("Expected a positive goroutine count, got %v", body["goroutines"])
+	}
+
+	sections := map[string][]string{
+		"db":       {"max_open_connections", "open_connections", "in_use", "idle", "wait_count", "wait_duration_ms"},
+		"realtime": {"connections", "users", "dropped_signals"},
+	}
+	for section, fields := range sections {
+		values, ok := body[section].(map[string]any)
+		if !ok {
+			t.Errorf("Expected %s object, got %v", section, body[section])
+			continue
+		}
+		for _, field := range fields {
+			if _, ok := values[field].(float64); !ok {
+				t.Errorf("Expected numeric %s.%s, got %v", section, field, values[field])
+			}
+		}
+	}
+}
+
+func TestHandlerDebugStats_RealtimeDisabled_ReturnsNullRealtime(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to open database handle: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &Config{DBConn: db}
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/debug/stats", nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerDebugStats(rec, req, database.User{ID: uuid.New()})
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if realtimeStats, ok := body["realtime"]; !ok || realtimeStats != nil {
+		t.Errorf("Expected realtime to be null, got %v", realtimeStats)
+	}
+}
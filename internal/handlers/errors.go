@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// clientError is a status and message that are safe to return to API clients
+type clientError struct {
+	Status  int
+	Message string
+}
+
+// classifyDBError maps a database error to a client error.
+// The message never includes the underlying error, so table, column and
+// constraint names stay server-side.
+func classifyDBError(err error, operation string) clientError {
+	err = apperr.Classify(err)
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return clientError{Status: http.StatusNotFound, Message: operation + " failed: not found"}
+	case errors.Is(err, apperr.ErrConflict):
+		return clientError{Status: http.StatusConflict, Message: operation + " failed: already exists"}
+	case errors.Is(err, apperr.ErrForeignKey):
+		return clientError{Status: http.StatusNotFound, Message: operation + " failed: referenced resource does not exist"}
+	}
+
+	return clientError{Status: http.StatusInternalServerError, Message: operation + " failed"}
+}
+
+// respondWithDBError logs the full database error and responds with a safe client error
+func respondWithDBError(w http.ResponseWriter, err error, operation string) {
+	clientErr := classifyDBError(err, operation)
+	if clientErr.Status >= http.StatusInternalServerError {
+		logger.ErrorErr(err, operation+" failed")
+	} else {
+		logger.Logger.Warn().Err(err).Msg(operation + " rejected")
+	}
+
+	models.RespondWithError(w, clientErr.Status, clientErr.Message)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+)
+
+func TestClassifyDBError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"Unique violation", &pq.Error{Code: "23505"}, http.StatusConflict},
+		{"Foreign key violation", &pq.Error{Code: "23503"}, http.StatusNotFound},
+		{"Wrapped unique violation", fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), http.StatusConflict},
+		{"No rows", sql.ErrNoRows, http.StatusNotFound},
+		{"Other pq error", &pq.Error{Code: "42P01"}, http.StatusInternalServerError},
+		{"Generic error", errors.New("connection refused"), http.StatusInternalServerError},
+		{"Already classified", apperr.Classify(sql.ErrNoRows), http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyDBError(tc.err, "Create feed"); got.Status != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, got.Status)
+			}
+		})
+	}
+}
+
+func TestRespondWithDBError_DoesNotLeakSQLDetails(t *testing.T) {
+	testCases := []error{
+		&pq.Error{
+			Code:       "23505",
+			Message:    `duplicate key value violates unique constraint "feeds_url_key"`,
+			Constraint: "feeds_url_key",
+			Table:      "feeds",
+		},
+		&pq.Error{
+			Code:       "23503",
+			Message:    `insert or update on table "feed_follows" violates foreign key constraint "feed_follows_feed_id_fkey"`,
+			Constraint: "feed_follows_feed_id_fkey",
+			Table:      "feed_follows",
+		},
+		&pq.Error{
+			Code:    "42703",
+			Message: `column "last_body_hash" does not exist`,
+		},
+	}
+
+	forbidden := []string{"duplicate key", "constraint", "feeds_url_key", "feed_follows", "column", "last_body_hash"}
+
+	for _, dbErr := range testCases {
+		rec := httptest.NewRecorder()
+
+		respondWithDBError(rec, dbErr, "Create feed")
+
+		body := rec.Body.String()
+		for _, text := range forbidden {
+			if strings.Contains(body, text) {
+				t.Errorf("Expected response to omit %q, got %s", text, body)
+			}
+		}
+	}
+}
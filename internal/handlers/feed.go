@@ -1,31 +1,76 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
 	"github.com/mmcdole/gofeed"
 )
 
+const (
+	// defaultFeedPreviewMaxItems is how many items the preview endpoint returns by default
+	defaultFeedPreviewMaxItems = 10
+	// feedPreviewMaxInspectedItems caps how many of a feed's items are sorted for the
+	// preview, so a feed declaring tens of thousands of items can't make it expensive
+	feedPreviewMaxInspectedItems = 1000
+	// feedPreviewExcerptLength is the maximum excerpt length in characters
+	feedPreviewExcerptLength = 280
+	// maxCreatedFeedPreviewPosts caps the posts returned when a feed is added
+	maxCreatedFeedPreviewPosts = 5
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+type feedPreviewItem struct {
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	PublishedAt *time.Time `json:"published_at"`
+	Excerpt     string     `json:"excerpt"`
+}
+
+type feedPreviewResponse struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// ItemCount is the number of items in the feed, which may exceed len(Items)
+	ItemCount models.Count      `json:"item_count"`
+	Items     []feedPreviewItem `json:"items"`
+}
+
 // HandlerCreateFeed creates a new RSS feed
 // @Summary     Create RSS feed
-// @Description Creates a new RSS feed and automatically follows it
+// @Description Creates a new RSS feed and automatically follows it. If a feed with the same URL already exists, it is followed instead. The response includes up to 5 of the feed's newest posts.
 // @Tags        feeds
 // @Accept      json
 // @Produce     json
 // @Security    Bearer
-// @Param       feed  body      object  true  "Feed data"
-// @Success     201   {object}  object  "Feed created"
-// @Failure     400   {object}  object  "Invalid input"
-// @Failure     500   {object}  object  "Server error"
+// @Param       feed    body      object  true   "Feed data"
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     201     {object}  object  "Feed created"
+// @Success     200     {object}  object  "Existing feed with this URL followed"
+// @Failure     400     {object}  object  "Malformed JSON or URL is not a feed"
+// @Failure     403     {object}  object  "Feed follow limit reached or feed domain not allowed"
+// @Failure     409     {object}  object  "Feed already followed or feed name already used"
+// @Failure     422     {object}  object  "Invalid name, invalid URL or private feed host"
+// @Failure     429     {object}  object  "Too many feeds created, retry after the Retry-After header's seconds"
+// @Failure     500     {object}  object  "Server error"
 // @Router      /v1/feed [post]
 func (cfg *Config) HandlerCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
 	type parameters struct {
@@ -41,102 +86,1038 @@ func (cfg *Config) HandlerCreateFeed(w http.ResponseWriter, r *http.Request, use
 		return
 	}
 
-	gf := gofeed.NewParser()
-	parsedFeed, errParseUrl := gf.ParseURL(params.URL)
-	if errParseUrl != nil {
-		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request URL: %v", errParseUrl))
+	name, err := cfg.feedName(params.Name)
+	if err != nil {
+		models.RespondWithValidationError(w, "name", err.Error())
 		return
 	}
 
-	// Extract metadata from parsed feed
-	description := parsedFeed.Description
-	logoUrl := ""
-	if parsedFeed.Image != nil {
-		logoUrl = parsedFeed.Image.URL
+	feedURL, err := normalizeFeedURL(params.URL)
+	if err != nil {
+		models.RespondWithValidationError(w, "url", fmt.Sprintf("Invalid request URL: %v", err))
+		return
+	}
+
+	if err := cfg.checkFeedDomain(feedURL); err != nil {
+		models.RespondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if err := cfg.checkFeedHost(r.Context(), feedURL); err != nil {
+		models.RespondWithValidationError(w, "url", fmt.Sprintf("Invalid request URL: %v", err))
+		return
 	}
 
-	tx, errTx := cfg.DBConn.BeginTx(r.Context(), nil)
-	if errTx != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error starting transaction: %v", errTx))
+	existingFeed, parsedFeed, resolvedURL, err := findOrParseFeed(r.Context(), cfg.DB, cfg.feedFetcher(), feedURL)
+	if errors.Is(err, errFeedParse) {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request URL: %v", err))
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Get feed by URL")
+		return
+	}
+
+	err = cfg.checkRedirectedFeedURL(r.Context(), feedURL, resolvedURL)
+	if errors.Is(err, errFeedDomainNotAllowed) {
+		models.RespondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		models.RespondWithValidationError(w, "url", fmt.Sprintf("Invalid request URL: feed redirects to %s: %v", resolvedURL, err))
 		return
 	}
 
+	feed, feedFollow, feedCreated, err := cfg.addFeedForUser(r.Context(), user.ID, name, resolvedURL, existingFeed, parsedFeed)
+	if err != nil {
+		cfg.respondWithAddFeedError(w, err)
+		return
+	}
+	if !feedCreated {
+		// Created concurrently by someone else; its stored posts are the preview
+		parsedFeed = nil
+	}
+
+	posts := cfg.createdFeedPreviewPosts(r.Context(), feed, parsedFeed)
+
+	cfg.invalidatePostsCache(user.ID)
+
+	type response struct {
+		Feed       models.Feed       `json:"feed"`
+		FeedFollow models.FeedFollow `json:"feed_follow"`
+		Posts      []models.Post     `json:"posts"`
+	}
+
+	// A feed with this URL already existed, so only the follow is new
+	status := http.StatusCreated
+	if !feedCreated {
+		status = http.StatusOK
+	}
+
+	models.RespondWithJSON(w, status, response{
+		Feed:       models.DatabaseFeedToFeed(r, feed),
+		FeedFollow: models.DatabaseFeedFollowToFeedFollow(feedFollow),
+		Posts:      posts,
+	})
+}
+
+// dbOpError records which database operation failed, so the error can be reported
+// with respondWithDBError after being returned from a helper
+type dbOpError struct {
+	Op  string
+	Err error
+}
+
+func (e *dbOpError) Error() string { return e.Op + ": " + e.Err.Error() }
+
+func (e *dbOpError) Unwrap() error { return e.Err }
+
+// addFeedForUser follows a feed for the user in one transaction, see addFeed.
+// feedCreated is false when an existing feed with feedURL was followed.
+// Returns errFeedFollowLimitReached, errFeedNameTaken or a *dbOpError.
+func (cfg *Config) addFeedForUser(ctx context.Context, userID uuid.UUID, name, feedURL string, existingFeed *database.Feed, parsedFeed *feedfetch.ParsedFeed) (feed database.Feed, feedFollow database.FeedFollow, feedCreated bool, err error) {
+	tx, err := cfg.DBConn.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Feed{}, database.FeedFollow{}, false, &dbOpError{Op: "Start transaction", Err: err}
+	}
+
 	defer func() {
 		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
 			logger.ErrorErr(err, "Failed to rollback transaction")
 		}
 	}()
 
-	qtx := cfg.DB.WithTx(tx)
+	feed, feedFollow, feedCreated, err = cfg.addFeed(ctx, cfg.DB.WithTx(tx), userID, name, feedURL, existingFeed, parsedFeed)
+	if err != nil {
+		return database.Feed{}, database.FeedFollow{}, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.Feed{}, database.FeedFollow{}, false, &dbOpError{Op: "Commit transaction", Err: err}
+	}
+
+	return feed, feedFollow, feedCreated, nil
+}
+
+// addFeed follows a feed for the user. A known feed (existingFeed) was already
+// validated when it was first created, so it is just followed; otherwise the feed
+// is created from parsedFeed, named name, first. Feed URLs are unique: when another
+// request created the same URL in the meantime, that feed is followed instead and
+// feedCreated is false.
+func (cfg *Config) addFeed(ctx context.Context, store database.Querier, userID uuid.UUID, name, feedURL string, existingFeed *database.Feed, parsedFeed *feedfetch.ParsedFeed) (database.Feed, database.FeedFollow, bool, error) {
+	// Checked inside the transaction so concurrent creates can't both slip under the cap
+	if err := enforceFeedFollowLimit(ctx, store, userID, cfg.MaxFeedFollowsPerUser); err != nil {
+		if errors.Is(err, errFeedFollowLimitReached) {
+			return database.Feed{}, database.FeedFollow{}, false, err
+		}
+		return database.Feed{}, database.FeedFollow{}, false, &dbOpError{Op: "Check feed follow limit", Err: err}
+	}
+
+	var feed database.Feed
+	feedCreated := false
+	if existingFeed != nil {
+		feed = *existingFeed
+	} else {
+		if cfg.UniqueFeedNamesPerUser {
+			if err := enforceUniqueFeedName(ctx, store, userID, name, uuid.Nil); err != nil {
+				if errors.Is(err, errFeedNameTaken) {
+					return database.Feed{}, database.FeedFollow{}, false, err
+				}
+				return database.Feed{}, database.FeedFollow{}, false, &dbOpError{Op: "Check feed name", Err: err}
+			}
+		}
 
-	// Add new feed to database with metadata
-	var descriptionNullStr, logoUrlNullStr sql.NullString
+		// Add new feed to database with metadata
+		var descriptionNullStr, logoUrlNullStr sql.NullString
 
-	if description != "" {
-		descriptionNullStr = sql.NullString{String: description, Valid: true}
+		if parsedFeed.Description != "" {
+			descriptionNullStr = sql.NullString{String: parsedFeed.Description, Valid: true}
+		}
+		if parsedFeed.Image != nil && parsedFeed.Image.URL != "" {
+			logoUrlNullStr = sql.NullString{String: parsedFeed.Image.URL, Valid: true}
+		}
+
+		createdFeed, err := store.CreateFeed(ctx, database.CreateFeedParams{
+			ID:          uuid.New(),
+			Name:        name,
+			CreatedAt:   time.Now().UTC(),
+			UpdatedAt:   time.Now().UTC(),
+			Url:         feedURL,
+			UserID:      userID,
+			Description: descriptionNullStr,
+			LogoUrl:     logoUrlNullStr,
+			Priority:    3, // Default priority
+		})
+		switch {
+		case err == nil:
+			feed = createdFeed
+			feedCreated = true
+		case errors.Is(apperr.Classify(err), apperr.ErrNotFound):
+			feed, err = store.GetFeedByURL(ctx, feedURL)
+			if err != nil {
+				return database.Feed{}, database.FeedFollow{}, false, &dbOpError{Op: "Get feed by URL", Err: err}
+			}
+		default:
+			return database.Feed{}, database.FeedFollow{}, false, &dbOpError{Op: "Create feed", Err: err}
+		}
 	}
-	if logoUrl != "" {
-		logoUrlNullStr = sql.NullString{String: logoUrl, Valid: true}
+
+	feedFollow, err := createFeedFollow(ctx, store, userID, feed.ID)
+	if err != nil {
+		return database.Feed{}, database.FeedFollow{}, false, err
 	}
+	feed.FollowerCount++
 
-	feed, errCreateFeed := qtx.CreateFeed(r.Context(), database.CreateFeedParams{
-		ID:          uuid.New(),
-		Name:        params.Name,
-		CreatedAt:   time.Now().UTC(),
-		UpdatedAt:   time.Now().UTC(),
-		Url:         params.URL,
-		UserID:      user.ID,
-		Description: descriptionNullStr,
-		LogoUrl:     logoUrlNullStr,
-		Priority:    3, // Default priority
+	return feed, feedFollow, feedCreated, nil
+}
+
+// respondWithAddFeedError reports an error returned by addFeedForUser
+func (cfg *Config) respondWithAddFeedError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errFeedFollowLimitReached):
+		cfg.respondWithFollowLimitError(w, err)
+	case errors.Is(err, errFeedNameTaken):
+		respondWithFeedNameError(w, err)
+	default:
+		var opErr *dbOpError
+		if errors.As(err, &opErr) {
+			respondWithDBError(w, opErr.Err, opErr.Op)
+			return
+		}
+		respondWithDBError(w, err, "Add feed")
+	}
+}
+
+// createdFeedPreviewPosts returns the first posts of a feed that was just added, so the
+// client can show content without another request: for a new feed, the posts imported
+// from the body fetched to validate it; for an existing feed, its latest stored posts.
+// The feed is already created, so failures only leave the preview empty.
+func (cfg *Config) createdFeedPreviewPosts(ctx context.Context, feed database.Feed, parsedFeed *feedfetch.ParsedFeed) []models.Post {
+	var posts []database.Post
+	if parsedFeed != nil {
+		if cfg.PostImporter == nil {
+			return []models.Post{}
+		}
+		posts = cfg.PostImporter.ImportFeedPosts(ctx, feed, parsedFeed)
+	} else {
+		stored, err := cfg.DB.GetPostsByFeed(ctx, database.GetPostsByFeedParams{
+			FeedID:      feed.ID,
+			PublishedAt: time.Now().UTC(),
+			Limit:       maxCreatedFeedPreviewPosts,
+		})
+		if err != nil {
+			cfg.Logger.Warn().Err(err).Str("feed_id", feed.ID.String()).Msg("Failed to load feed preview posts")
+			return []models.Post{}
+		}
+		posts = stored
+	}
+
+	return newFeedPreviewPosts(posts, maxCreatedFeedPreviewPosts)
+}
+
+// newFeedPreviewPosts returns the newest limit posts, newest first
+func newFeedPreviewPosts(posts []database.Post, limit int) []models.Post {
+	sorted := append([]database.Post(nil), posts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return newerPost(sorted[i].PublishedAt, sorted[i].ID, sorted[j].PublishedAt, sorted[j].ID)
 	})
-	if errCreateFeed != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Create Feed failed: %v", errCreateFeed))
+
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return models.DatabaseAllPostToAllPost(sorted)
+}
+
+// feedsResponse is a page of feeds. NextCursor is empty on the last page.
+type feedsResponse struct {
+	Feeds      []models.Feed `json:"feeds"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// HandlerGetFeed returns a page of feeds, newest first
+// @Summary     Get all feeds
+// @Description Get a page of RSS feeds, newest first, with cursor-based pagination. search keeps feeds whose name or description contains it (case-insensitive). Each feed has its follower_count; authenticated requests also get is_followed.
+// @Tags        feeds
+// @Accept      json
+// @Produce     json
+// @Param       limit   query     int     false  "Number of feeds (default 20, max 100)"
+// @Param       cursor  query     string  false  "next_cursor of the previous page (RFC3339)"
+// @Param       search  query     string  false  "Text the feed name or description must contain"
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Page of feeds"
+// @Failure     400     {object}  object  "Invalid cursor"
+// @Failure     500     {object}  object  "Server error"
+// @Failure     401     {object}  object  "Invalid token"
+// @Router      /v1/feed [get]
+func (cfg *Config) HandlerGetFeed(w http.ResponseWriter, r *http.Request, user *database.User) {
+	respondWithFeedsPage(w, r, cfg.DB, user)
+}
+
+// respondWithFeedsPage responds with the page of feeds selected by the query parameters
+func respondWithFeedsPage(w http.ResponseWriter, r *http.Request, store database.Querier, user *database.User) {
+	limit, cursor, err := parsePostsPagination(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid cursor format")
 		return
 	}
 
-	feedFollow, errCreateFeedFollow := qtx.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+	var userID uuid.NullUUID
+	if user != nil {
+		userID = uuid.NullUUID{UUID: user.ID, Valid: true}
+	}
+
+	feeds, err := store.GetFeedsWithStats(r.Context(), database.GetFeedsWithStatsParams{
+		UserID:        userID,
+		CreatedAt:     cursor,
+		SearchPattern: feedSearchPattern(r.URL.Query().Get("search")),
+		RowLimit:      int32(limit),
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Get feeds")
+		return
+	}
+
+	// A full page may be followed by more feeds
+	nextCursor := ""
+	if len(feeds) == limit {
+		nextCursor = feeds[len(feeds)-1].CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, feedsResponse{
+		Feeds:      models.DatabaseAllFeedWithStatsToAllFeed(r, feeds, user != nil),
+		NextCursor: nextCursor,
+	})
+}
+
+// feedSearchPattern turns a search query into an ILIKE pattern matching text that
+// contains it, with ILIKE wildcards in the query matched literally. An empty query
+// matches every feed.
+func feedSearchPattern(search string) sql.NullString {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return sql.NullString{}
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return sql.NullString{String: "%" + escaped + "%", Valid: true}
+}
+
+// HandlerUpdateFeed updates a feed owned by the user
+// Supports optimistic concurrency: pass If-Unmodified-Since or the feed's updated_at as "version"
+// @Summary     Update a feed
+// @Description Updates the name, description, priority or fetch_full_content of a feed you created. With fetch_full_content, the article of each new post is fetched and its main content stored for GET /v1/posts/{postID}/content. Send an If-Unmodified-Since header or a version (the feed's updated_at) to reject the update if the feed changed in the meantime.
+// @Tags        feeds
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       feedID               path      string  true   "Feed ID"
+// @Param       If-Unmodified-Since  header    string  false  "Reject the update if the feed changed after this time"
+// @Param       feed                 body      object  true   "Fields to update"
+// @Param       bigint               query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200                  {object}  object  "Feed updated"
+// @Failure     400                  {object}  object  "Malformed request"
+// @Failure     403                  {object}  object  "Not the feed owner"
+// @Failure     404                  {object}  object  "Feed not found"
+// @Failure     409                  {object}  object  "Feed name already used"
+// @Failure     412                  {object}  object  "Feed was modified"
+// @Failure     422                  {object}  object  "Invalid name or priority"
+// @Failure     500                  {object}  object  "Server error"
+// @Router      /v1/feed/{feedID} [patch]
+func (cfg *Config) HandlerUpdateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondToFeedUpdate(w, r, user, cfg.DB)
+}
+
+// respondToFeedUpdate checks the requested changes against the feed read from store,
+// then applies them with updateFeedForUser
+func (cfg *Config) respondToFeedUpdate(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier) {
+	type parameters struct {
+		Name             *string    `json:"name"`
+		Description      *string    `json:"description"`
+		Priority         *int32     `json:"priority"`
+		FetchFullContent *bool      `json:"fetch_full_content"`
+		Version          *time.Time `json:"version"`
+	}
+
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
+		return
+	}
+
+	ifUnmodifiedSince, err := parseIfUnmodifiedSince(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid If-Unmodified-Since header")
+		return
+	}
+
+	params := parameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	if params.Name != nil {
+		name, err := cfg.feedName(*params.Name)
+		if err != nil {
+			models.RespondWithValidationError(w, "name", err.Error())
+			return
+		}
+		if name == "" {
+			models.RespondWithValidationError(w, "name", "name cannot be empty")
+			return
+		}
+		params.Name = &name
+	}
+	if params.Priority != nil && (*params.Priority < 1 || *params.Priority > 5) {
+		models.RespondWithValidationError(w, "priority", "priority must be between 1 and 5")
+		return
+	}
+
+	feed, err := store.GetFeedByID(r.Context(), feedID)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Get feed")
+		return
+	}
+
+	if feed.UserID != user.ID {
+		models.RespondWithError(w, http.StatusForbidden, "You can only update feeds you created")
+		return
+	}
+
+	if isStaleFeedUpdate(feed.UpdatedAt, ifUnmodifiedSince, params.Version) {
+		models.RespondWithError(w, http.StatusPreconditionFailed, "Feed was modified since the given version")
+		return
+	}
+
+	updateParams := database.UpdateFeedParams{
+		ID:               feed.ID,
+		UserID:           user.ID,
+		Name:             feed.Name,
+		Description:      feed.Description,
+		Priority:         feed.Priority,
+		FetchFullContent: feed.FetchFullContent,
+		UpdatedAt:        time.Now().UTC(),
+	}
+	if params.Name != nil {
+		updateParams.Name = *params.Name
+	}
+	if params.Description != nil {
+		updateParams.Description = sql.NullString{String: *params.Description, Valid: *params.Description != ""}
+	}
+	if params.Priority != nil {
+		updateParams.Priority = *params.Priority
+	}
+	if params.FetchFullContent != nil {
+		updateParams.FetchFullContent = *params.FetchFullContent
+	}
+	// Only conditional requests are guarded against a concurrent write between the read and the update
+	if ifUnmodifiedSince != nil || params.Version != nil {
+		updateParams.ExpectedUpdatedAt = sql.NullTime{Time: feed.UpdatedAt, Valid: true}
+	}
+
+	updatedFeed, err := cfg.updateFeedForUser(r.Context(), updateParams, cfg.UniqueFeedNamesPerUser && params.Name != nil)
+	if errors.Is(err, errFeedNameTaken) {
+		respondWithFeedNameError(w, err)
+		return
+	}
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusPreconditionFailed, "Feed was modified since the given version")
+		return
+	}
+	if err != nil {
+		var opErr *dbOpError
+		if errors.As(err, &opErr) {
+			respondWithDBError(w, opErr.Err, opErr.Op)
+			return
+		}
+		respondWithDBError(w, err, "Update feed")
+		return
+	}
+
+	w.Header().Set("Last-Modified", updatedFeed.UpdatedAt.UTC().Format(http.TimeFormat))
+	models.RespondWithJSON(w, http.StatusOK, models.DatabaseFeedToFeed(r, updatedFeed))
+}
+
+// updateFeedForUser applies params in one transaction, first checking the new name is
+// unique among the user's feeds when checkName is set. A conditional update that matched
+// no row because the feed changed meanwhile is reported as apperr.ErrNotFound.
+// Returns errFeedNameTaken, apperr.ErrNotFound or a *dbOpError.
+func (cfg *Config) updateFeedForUser(ctx context.Context, params database.UpdateFeedParams, checkName bool) (database.Feed, error) {
+	tx, err := cfg.DBConn.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Feed{}, &dbOpError{Op: "Start transaction", Err: err}
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback transaction")
+		}
+	}()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	if checkName {
+		if err := enforceUniqueFeedName(ctx, qtx, params.UserID, params.Name, params.ID); err != nil {
+			if errors.Is(err, errFeedNameTaken) {
+				return database.Feed{}, err
+			}
+			return database.Feed{}, &dbOpError{Op: "Check feed name", Err: err}
+		}
+	}
+
+	updatedFeed, err := qtx.UpdateFeed(ctx, params)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		return database.Feed{}, apperr.ErrNotFound
+	}
+	if err != nil {
+		return database.Feed{}, &dbOpError{Op: "Update feed", Err: err}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.Feed{}, &dbOpError{Op: "Commit transaction", Err: err}
+	}
+
+	return updatedFeed, nil
+}
+
+// HandlerUpdateFeedPriority sets the priority of a feed owned by the user
+// @Summary     Set feed priority
+// @Description Sets the scraping priority (1-5, higher is fetched first and more often) of a feed you created
+// @Tags        feeds
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       feedID    path      string  true   "Feed ID"
+// @Param       priority  body      object  true   "New priority"
+// @Param       bigint    query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200       {object}  object  "Feed updated"
+// @Failure     400       {object}  object  "Malformed request"
+// @Failure     403       {object}  object  "Not the feed owner"
+// @Failure     404       {object}  object  "Feed not found"
+// @Failure     422       {object}  object  "Priority missing or not between 1 and 5"
+// @Failure     500       {object}  object  "Server error"
+// @Router      /v1/feed/{feedID}/priority [put]
+func (cfg *Config) HandlerUpdateFeedPriority(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToFeedPriorityUpdate(w, r, user, cfg.DB)
+}
+
+// respondToFeedPriorityUpdate validates the requested priority and applies it if the user owns the feed
+func respondToFeedPriorityUpdate(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier) {
+	type parameters struct {
+		Priority *int32 `json:"priority"`
+	}
+
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
+		return
+	}
+
+	params := parameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if params.Priority == nil || *params.Priority < 1 || *params.Priority > 5 {
+		models.RespondWithValidationError(w, "priority", "priority must be between 1 and 5")
+		return
+	}
+
+	feed, err := store.GetFeedByID(r.Context(), feedID)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Get feed")
+		return
+	}
+
+	if feed.UserID != user.ID {
+		models.RespondWithError(w, http.StatusForbidden, "You can only update feeds you created")
+		return
+	}
+
+	updatedFeed, err := store.UpdateFeedPriority(r.Context(), database.UpdateFeedPriorityParams{
+		ID:        feed.ID,
 		UserID:    user.ID,
-		FeedID:    feed.ID,
+		Priority:  *params.Priority,
+		UpdatedAt: time.Now().UTC(),
 	})
-	if errCreateFeedFollow != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Create Feed Follow failed: %v", errCreateFeedFollow))
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Update feed priority")
+		return
+	}
+
+	w.Header().Set("Last-Modified", updatedFeed.UpdatedAt.UTC().Format(http.TimeFormat))
+	models.RespondWithJSON(w, http.StatusOK, models.DatabaseFeedToFeed(r, updatedFeed))
+}
+
+// HandlerDeleteFeed deletes a feed owned by the user, with its posts and follows
+// @Summary     Delete a feed
+// @Description Deletes a feed you created along with its posts. Everyone following it is unsubscribed.
+// @Tags        feeds
+// @Produce     json
+// @Security    Bearer
+// @Param       feedID  path      string  true  "Feed ID"
+// @Success     204     {object}  object  "Feed deleted"
+// @Failure     400     {object}  object  "Invalid feed ID"
+// @Failure     404     {object}  object  "Feed not found"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/feed/{feedID} [delete]
+func (cfg *Config) HandlerDeleteFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
+		return
+	}
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithDBError(w, err, "Start transaction")
+		return
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback transaction")
+		}
+	}()
+
+	followerIDs, err := deleteFeed(r.Context(), cfg.DB.WithTx(tx), user.ID, feedID)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Delete feed")
 		return
 	}
 
 	if err := tx.Commit(); err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error committing transaction: %v", err))
+		respondWithDBError(w, err, "Commit transaction")
 		return
 	}
 
-	type response struct {
-		Feed       models.Feed       `json:"feed"`
-		FeedFollow models.FeedFollow `json:"feed_follow"`
+	for _, followerID := range followerIDs {
+		cfg.invalidatePostsCache(followerID)
 	}
 
-	models.RespondWithJSON(w, http.StatusCreated, response{
-		Feed:       models.DatabaseFeedToFeed(feed),
-		FeedFollow: models.DatabaseFeedFollowToFeedFollow(feedFollow),
+	models.RespondWithJSON(w, http.StatusNoContent, struct{}{})
+}
+
+// deleteFeed deletes the user's feed, its posts and every follow of it, and returns
+// the users who followed it. Feeds of other users are reported as apperr.ErrNotFound, so
+// their owners' feeds can't be told apart from missing ones.
+func deleteFeed(ctx context.Context, store database.Querier, userID, feedID uuid.UUID) ([]uuid.UUID, error) {
+	feed, err := store.GetFeedByID(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	if feed.UserID != userID {
+		return nil, apperr.ErrNotFound
+	}
+
+	if _, err := store.DeletePostsByFeed(ctx, feedID); err != nil {
+		return nil, err
+	}
+	followerIDs, err := store.DeleteFeedFollowsByFeed(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteFeed(ctx, feedID); err != nil {
+		return nil, err
+	}
+	return followerIDs, nil
+}
+
+// parseIfUnmodifiedSince returns the If-Unmodified-Since header time, or nil if it isn't set
+func parseIfUnmodifiedSince(r *http.Request) (*time.Time, error) {
+	header := r.Header.Get("If-Unmodified-Since")
+	if header == "" {
+		return nil, nil
+	}
+
+	t, err := http.ParseTime(header)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// isStaleFeedUpdate reports whether the stored updated_at is newer than the client's view.
+// HTTP dates only have second precision, so the header is compared at that granularity;
+// version is the exact updated_at the client last saw.
+func isStaleFeedUpdate(storedUpdatedAt time.Time, ifUnmodifiedSince, version *time.Time) bool {
+	if ifUnmodifiedSince != nil && storedUpdatedAt.Truncate(time.Second).After(*ifUnmodifiedSince) {
+		return true
+	}
+	if version != nil && storedUpdatedAt.After(*version) {
+		return true
+	}
+	return false
+}
+
+// HandlerPreviewFeed fetches a feed and returns its latest items without storing anything
+// "Try before you follow" - requires auth so it can't be used as an open proxy
+// @Summary     Preview a feed
+// @Description Fetches and parses a feed URL and returns its latest items without creating a feed or posts. At most FEED_PREVIEW_MAX_ITEMS items are returned; item_count is the feed's total and may be larger.
+// @Tags        feeds
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       url     query     string  true   "Feed URL to preview"
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Feed preview"
+// @Failure     400     {object}  object  "Invalid or unreachable feed URL"
+// @Failure     401     {object}  object  "Unauthorized"
+// @Failure     403     {object}  object  "Feed domain not allowed"
+// @Router      /v1/feed/preview [get]
+func (cfg *Config) HandlerPreviewFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := r.URL.Query().Get("url")
+	if feedURL == "" {
+		models.RespondWithError(w, http.StatusBadRequest, "url query parameter is required")
+		return
+	}
+
+	parsedFeed, err := cfg.fetchUserFeed(r.Context(), feedURL)
+	if errors.Is(err, errFeedDomainNotAllowed) {
+		models.RespondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Could not preview feed: %v", err))
+		return
+	}
+
+	items := previewFeedItems(parsedFeed.Items, cfg.feedPreviewMaxItems())
+
+	previewItems := make([]feedPreviewItem, 0, len(items))
+	for _, item := range items {
+		previewItems = append(previewItems, feedPreviewItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			PublishedAt: item.PublishedParsed,
+			Excerpt:     excerpt(item.Description, feedPreviewExcerptLength),
+		})
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, feedPreviewResponse{
+		Title:       parsedFeed.Title,
+		Description: parsedFeed.Description,
+		ItemCount:   models.NewCount(r, int64(len(parsedFeed.Items))),
+		Items:       previewItems,
 	})
 }
 
-// HandlerGetFeed returns all feeds
-// @Summary     Get all feeds
-// @Description Get a list of all RSS feeds
+// feedValidationResponse describes a feed URL that fetched and parsed successfully
+type feedValidationResponse struct {
+	URL         string       `json:"url"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Logo        string       `json:"logo,omitempty"`
+	ItemCount   models.Count `json:"item_count"`
+	// LastPublishedAt is the newest item date, nil if no item has one
+	LastPublishedAt *time.Time `json:"last_published_at"`
+}
+
+// HandlerValidateFeed checks that a URL serves a feed and returns its metadata without
+// storing anything, so clients can confirm a feed before creating it
+// @Summary     Validate a feed URL
+// @Description Fetches and parses a feed URL and returns its title, description, logo, item count and newest item date. Nothing is stored.
 // @Tags        feeds
 // @Accept      json
 // @Produce     json
-// @Success     200  {object}  object  "List of feeds"
-// @Router      /v1/feed [get]
-func (cfg *Config) HandlerGetFeed(w http.ResponseWriter, r *http.Request) {
-	feeds, err := cfg.DB.GetFeeds(r.Context())
+// @Security    Bearer
+// @Param       feed    body      object  true   "Feed URL"
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Feed metadata"
+// @Failure     400     {object}  object  "Malformed JSON, unreachable URL or not a feed"
+// @Failure     401     {object}  object  "Unauthorized"
+// @Failure     403     {object}  object  "Feed domain not allowed"
+// @Failure     422     {object}  object  "Invalid URL or private feed host"
+// @Router      /v1/feed/validate [post]
+func (cfg *Config) HandlerValidateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		URL string `json:"url"`
+	}
+
+	params := parameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	feedURL, err := normalizeFeedURL(params.URL)
+	if err != nil {
+		models.RespondWithValidationError(w, "url", fmt.Sprintf("Invalid request URL: %v", err))
+		return
+	}
+
+	if err := cfg.checkFeedDomain(feedURL); err != nil {
+		models.RespondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if err := cfg.checkFeedHost(r.Context(), feedURL); err != nil {
+		models.RespondWithValidationError(w, "url", fmt.Sprintf("Invalid request URL: %v", err))
+		return
+	}
+
+	parsedFeed, err := cfg.feedFetcher().Fetch(r.Context(), feedURL)
+	if errors.Is(err, feedfetch.ErrMalformedFeed) {
+		models.RespondWithError(w, http.StatusBadRequest, "URL did not return an RSS, Atom or JSON feed")
+		return
+	}
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Get Feed failed: %v", err))
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Could not reach feed: %v", err))
 		return
 	}
 
-	models.RespondWithJSON(w, http.StatusOK, models.DatabaseAllFeedToAllFeed(feeds))
+	response := feedValidationResponse{
+		URL:             resolvedFeedURL(feedURL, parsedFeed),
+		Title:           parsedFeed.Title,
+		Description:     parsedFeed.Description,
+		ItemCount:       models.NewCount(r, int64(len(parsedFeed.Items))),
+		LastPublishedAt: lastPublishedAt(parsedFeed.Items),
+	}
+	if parsedFeed.Image != nil {
+		response.Logo = parsedFeed.Image.URL
+	}
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// lastPublishedAt returns the newest publication date among items, or nil if none has one
+func lastPublishedAt(items []*gofeed.Item) *time.Time {
+	var newest *time.Time
+	for _, item := range items {
+		if item.PublishedParsed != nil && (newest == nil || item.PublishedParsed.After(*newest)) {
+			newest = item.PublishedParsed
+		}
+	}
+	return newest
+}
+
+// feedPreviewMaxItems returns the configured preview item cap, falling back to the default
+func (cfg *Config) feedPreviewMaxItems() int {
+	if cfg.FeedPreviewMaxItems <= 0 {
+		return defaultFeedPreviewMaxItems
+	}
+	return cfg.FeedPreviewMaxItems
+}
+
+// previewFeedItems returns up to limit of the feed's newest items. Only the first
+// feedPreviewMaxInspectedItems items are considered.
+func previewFeedItems(feedItems []*gofeed.Item, limit int) []*gofeed.Item {
+	if len(feedItems) > feedPreviewMaxInspectedItems {
+		feedItems = feedItems[:feedPreviewMaxInspectedItems]
+	}
+	items := make([]*gofeed.Item, len(feedItems))
+	copy(items, feedItems)
+
+	// Newest first, items without a date go last
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].PublishedParsed == nil {
+			return false
+		}
+		if items[j].PublishedParsed == nil {
+			return true
+		}
+		return items[i].PublishedParsed.After(*items[j].PublishedParsed)
+	})
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// fetchUserFeed validates a user-submitted feed URL and fetches it.
+// Only http(s) URLs with a host that passes checkFeedDomain and checkFeedHost are accepted.
+func (cfg *Config) fetchUserFeed(ctx context.Context, feedURL string) (*feedfetch.ParsedFeed, error) {
+	normalizedURL, err := normalizeFeedURL(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if err := cfg.checkFeedDomain(normalizedURL); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkFeedHost(ctx, normalizedURL); err != nil {
+		return nil, err
+	}
+
+	return cfg.feedFetcher().Fetch(ctx, normalizedURL)
+}
+
+// feedNameRejectFactor is how many times the title limit a submitted feed name may
+// reach before it is rejected instead of truncated; nobody names a feed like that
+const feedNameRejectFactor = 8
+
+// feedName trims a user-supplied feed name and truncates it to MaxTitleBytes,
+// rejecting names that are grossly oversized
+func (cfg *Config) feedName(name string) (string, error) {
+	limit := cfg.MaxTitleBytes
+	if limit <= 0 {
+		limit = textutil.DefaultMaxTitleBytes
+	}
+	if len(name) > limit*feedNameRejectFactor {
+		return "", fmt.Errorf("name must be at most %d bytes", limit)
+	}
+	return textutil.TruncateTitle(name, limit), nil
+}
+
+// errFeedNameTaken is returned when the user already owns a feed with the requested name
+var errFeedNameTaken = errors.New("feed name already used")
+
+// enforceUniqueFeedName returns errFeedNameTaken if another feed owned by the user
+// (ignoring excludeFeedID) already has name, compared case-insensitively.
+// Like enforceFeedFollowLimit it must run inside the writing transaction; the user's
+// row lock keeps two concurrent requests from both claiming the same name.
+func enforceUniqueFeedName(ctx context.Context, store database.Querier, userID uuid.UUID, name string, excludeFeedID uuid.UUID) error {
+	if err := store.LockUserForUpdate(ctx, userID); err != nil {
+		return err
+	}
+
+	taken, err := store.FeedNameTakenByUser(ctx, database.FeedNameTakenByUserParams{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		ExcludeID: excludeFeedID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return errFeedNameTaken
+	}
+
+	return nil
+}
+
+// respondWithFeedNameError responds to an enforceUniqueFeedName failure
+func respondWithFeedNameError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errFeedNameTaken) {
+		models.RespondWithError(w, http.StatusConflict, "You already have a feed with this name")
+		return
+	}
+
+	respondWithDBError(w, err, "Check feed name")
+}
+
+// errFeedParse marks a submitted feed URL that couldn't be fetched or parsed
+var errFeedParse = errors.New("could not parse feed")
+
+// findOrParseFeed returns the stored feed for feedURL, or parses feedURL when it
+// isn't known yet. Exactly one of the returned feeds is set on success, and an
+// existing feed is returned without any network round trip. When feedURL redirects,
+// the returned URL is the normalized final one, under which a feed stored after an
+// earlier redirect is found and a new feed should be stored.
+func findOrParseFeed(ctx context.Context, store database.Querier, fetcher feedfetch.FeedFetcher, feedURL string) (*database.Feed, *feedfetch.ParsedFeed, string, error) {
+	existing, err := store.GetFeedByURL(ctx, feedURL)
+	if err == nil {
+		return &existing, nil, feedURL, nil
+	}
+	if err := apperr.Classify(err); !errors.Is(err, apperr.ErrNotFound) {
+		return nil, nil, "", err
+	}
+
+	parsed, err := fetcher.Fetch(ctx, feedURL)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %v", errFeedParse, err)
+	}
+
+	finalURL := resolvedFeedURL(feedURL, parsed)
+	if finalURL != feedURL {
+		existing, err := store.GetFeedByURL(ctx, finalURL)
+		if err == nil {
+			return &existing, nil, finalURL, nil
+		}
+		if err := apperr.Classify(err); !errors.Is(err, apperr.ErrNotFound) {
+			return nil, nil, "", err
+		}
+	}
+
+	return nil, parsed, finalURL, nil
+}
+
+// resolvedFeedURL returns the normalized URL parsed was served from, or feedURL when
+// the fetcher didn't record one
+func resolvedFeedURL(feedURL string, parsed *feedfetch.ParsedFeed) string {
+	if parsed.FinalURL == "" {
+		return feedURL
+	}
+	finalURL, err := normalizeFeedURL(parsed.FinalURL)
+	if err != nil {
+		return feedURL
+	}
+	return finalURL
+}
+
+// checkRedirectedFeedURL applies the domain and host checks to the URL a feed
+// redirected to, so a redirect can't bypass the checks on the submitted URL
+func (cfg *Config) checkRedirectedFeedURL(ctx context.Context, feedURL, resolvedURL string) error {
+	if resolvedURL == feedURL {
+		return nil
+	}
+	if err := cfg.checkFeedDomain(resolvedURL); err != nil {
+		return err
+	}
+	return cfg.checkFeedHost(ctx, resolvedURL)
+}
+
+// normalizeFeedURL canonicalizes a feed URL so the same feed submitted with
+// cosmetic differences (scheme/host case, default port, trailing slash,
+// fragment) maps to one stored feed
+func normalizeFeedURL(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "", errors.New("URL scheme must be http or https")
+	}
+	if parsedURL.Host == "" {
+		return "", errors.New("URL host is required")
+	}
+
+	host := strings.ToLower(parsedURL.Hostname())
+	port := parsedURL.Port()
+	if (parsedURL.Scheme == "http" && port == "80") || (parsedURL.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	parsedURL.Host = host
+
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+	parsedURL.Path = strings.TrimRight(parsedURL.Path, "/")
+	parsedURL.RawPath = ""
+
+	return parsedURL.String(), nil
+}
+
+// excerpt strips HTML tags from s and shortens it to at most maxLength characters
+func excerpt(s string, maxLength int) string {
+	text := strings.Join(strings.Fields(htmlTagPattern.ReplaceAllString(s, " ")), " ")
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxLength])) + "…"
 }
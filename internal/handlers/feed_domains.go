@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// errFeedDomainNotAllowed is returned when a feed URL's host is blocked or not allowlisted
+var errFeedDomainNotAllowed = errors.New("feeds from this domain are not allowed")
+
+// checkFeedDomain returns errFeedDomainNotAllowed if feedURL's host fails the
+// configured FeedDomainAllowlist/FeedDomainBlocklist
+func (cfg *Config) checkFeedDomain(feedURL string) error {
+	if len(cfg.FeedDomainAllowlist) == 0 && len(cfg.FeedDomainBlocklist) == 0 {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(feedURL)
+	if err != nil {
+		return err
+	}
+	if !feedDomainAllowed(parsedURL.Hostname(), cfg.FeedDomainAllowlist, cfg.FeedDomainBlocklist) {
+		return errFeedDomainNotAllowed
+	}
+	return nil
+}
+
+// feedDomainAllowed reports whether host may be used as a feed source. The
+// blocklist wins over the allowlist, and an empty allowlist allows every host.
+func feedDomainAllowed(host string, allowlist, blocklist []string) bool {
+	host = normalizeDomain(host)
+	for _, pattern := range blocklist {
+		if domainMatches(host, pattern) {
+			return false
+		}
+	}
+	if len(allowlist) == 0 {
+		return true
+	}
+	for _, pattern := range allowlist {
+		if domainMatches(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// domainMatches reports whether host matches pattern, either exactly or, for a
+// "*.example.com" pattern, as any subdomain of example.com (not example.com itself)
+func domainMatches(host, pattern string) bool {
+	pattern = normalizeDomain(pattern)
+	if pattern == "" {
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// normalizeDomain lower-cases a host name and strips a trailing root dot
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+)
+
+func TestFeedDomainAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		host      string
+		allowlist []string
+		blocklist []string
+		want      bool
+	}{
+		{"no lists allows everything", "example.com", nil, nil, true},
+		{"allowlisted host", "example.com", []string{"example.com"}, nil, true},
+		{"host not on allowlist", "other.com", []string{"example.com"}, nil, false},
+		{"allowlist is case-insensitive", "Example.COM", []string{"example.com"}, nil, true},
+		{"blocklisted host", "spam.com", nil, []string{"spam.com"}, false},
+		{"blocklist wins over allowlist", "spam.com", []string{"spam.com"}, []string{"spam.com"}, false},
+		{"wildcard allows subdomain", "blog.example.com", []string{"*.example.com"}, nil, true},
+		{"wildcard allows nested subdomain", "a.b.example.com", []string{"*.example.com"}, nil, true},
+		{"wildcard excludes apex", "example.com", []string{"*.example.com"}, nil, false},
+		{"wildcard excludes lookalike", "badexample.com", []string{"*.example.com"}, nil, false},
+		{"wildcard blocks subdomain", "feeds.spam.com", nil, []string{"*.spam.com"}, false},
+		{"trailing dot ignored", "example.com.", []string{"example.com"}, nil, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := feedDomainAllowed(tc.host, tc.allowlist, tc.blocklist); got != tc.want {
+				t.Errorf("Expected %v for %q, got %v", tc.want, tc.host, got)
+			}
+		})
+	}
+}
+
+func TestHandlerCreateFeed_BlockedDomain_ReturnsForbidden(t *testing.T) {
+	feedURL := "https://feeds.spam.com/rss"
+	fetcher := feedfetch.NewFakeFetcher()
+	cfg := &Config{FeedFetcher: fetcher, FeedDomainBlocklist: []string{"*.spam.com"}}
+
+	body := strings.NewReader(`{"name":"Spam","url":"` + feedURL + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/feed", body)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerCreateFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	if calls := fetcher.Calls(feedURL); calls != 0 {
+		t.Errorf("Expected the feed not to be fetched, got %d fetches", calls)
+	}
+}
+
+func TestHandlerPreviewFeed_BlockedDomain_ReturnsForbidden(t *testing.T) {
+	feedURL := "https://feeds.spam.com/rss"
+	fetcher := feedfetch.NewFakeFetcher()
+	cfg := &Config{FeedFetcher: fetcher, FeedDomainBlocklist: []string{"*.spam.com"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview?url="+url.QueryEscape(feedURL), nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	if calls := fetcher.Calls(feedURL); calls != 0 {
+		t.Errorf("Expected the feed not to be fetched, got %d fetches", calls)
+	}
+}
@@ -1,17 +1,136 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
 )
 
+const (
+	// maxFeedFollowAliasLength caps a feed follow alias, in characters
+	maxFeedFollowAliasLength = 100
+	// maxFeedFollowCategoryLength caps a feed follow category, in characters
+	maxFeedFollowCategoryLength = 50
+)
+
+var errFeedFollowLimitReached = errors.New("feed follow limit reached")
+
+// enforceFeedFollowLimit returns errFeedFollowLimitReached if the user already follows limit feeds.
+// It must run inside the transaction that creates the follow: locking the user's row makes
+// concurrent follow requests for the same user wait until this transaction finishes,
+// so they can't all pass the count check before any of them inserts.
+func enforceFeedFollowLimit(ctx context.Context, store database.Querier, userID uuid.UUID, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	if err := store.LockUserForUpdate(ctx, userID); err != nil {
+		return err
+	}
+
+	count, err := store.CountFeedFollowsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if count >= int64(limit) {
+		return errFeedFollowLimitReached
+	}
+
+	return nil
+}
+
+// respondWithFollowLimitError responds to an enforceFeedFollowLimit failure
+func (cfg *Config) respondWithFollowLimitError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errFeedFollowLimitReached) {
+		models.RespondWithError(w, http.StatusForbidden, fmt.Sprintf("You can follow at most %d feeds", cfg.MaxFeedFollowsPerUser))
+		return
+	}
+
+	respondWithDBError(w, err, "Check feed follow limit")
+}
+
+// createFeedFollow makes the user follow feedID and counts the new follower. It must
+// run inside a transaction so the count can't drift from the follows.
+// Returns a *dbOpError.
+func createFeedFollow(ctx context.Context, store database.Querier, userID, feedID uuid.UUID) (database.FeedFollow, error) {
+	feedFollow, err := store.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    userID,
+		FeedID:    feedID,
+	})
+	if err != nil {
+		return database.FeedFollow{}, &dbOpError{Op: "Create feed follow", Err: err}
+	}
+
+	if err := store.IncrementFeedFollowerCount(ctx, feedID); err != nil {
+		return database.FeedFollow{}, &dbOpError{Op: "Update follower count", Err: err}
+	}
+	return feedFollow, nil
+}
+
+// followExistingFeed makes the user follow a feed that already exists, in one
+// transaction with the follow limit check.
+// Returns errFeedFollowLimitReached or a *dbOpError.
+func (cfg *Config) followExistingFeed(ctx context.Context, userID, feedID uuid.UUID) (database.FeedFollow, error) {
+	tx, err := cfg.DBConn.BeginTx(ctx, nil)
+	if err != nil {
+		return database.FeedFollow{}, &dbOpError{Op: "Start transaction", Err: err}
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback transaction")
+		}
+	}()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	if err := enforceFeedFollowLimit(ctx, qtx, userID, cfg.MaxFeedFollowsPerUser); err != nil {
+		if errors.Is(err, errFeedFollowLimitReached) {
+			return database.FeedFollow{}, err
+		}
+		return database.FeedFollow{}, &dbOpError{Op: "Check feed follow limit", Err: err}
+	}
+
+	feedFollow, err := createFeedFollow(ctx, qtx, userID, feedID)
+	if err != nil {
+		return database.FeedFollow{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.FeedFollow{}, &dbOpError{Op: "Commit transaction", Err: err}
+	}
+	return feedFollow, nil
+}
+
+// respondWithNewFeedFollow follows feedID for the user and responds with the follow
+func (cfg *Config) respondWithNewFeedFollow(w http.ResponseWriter, r *http.Request, user database.User, feedID uuid.UUID) {
+	feedFollow, err := cfg.followExistingFeed(r.Context(), user.ID, feedID)
+	if err != nil {
+		cfg.respondWithAddFeedError(w, err)
+		return
+	}
+
+	cfg.invalidatePostsCache(user.ID)
+
+	models.RespondWithJSON(w, http.StatusCreated, models.DatabaseFeedFollowToFeedFollow(feedFollow))
+}
+
 // HandlerCreateFeedFollow creates a new feed follow relationship
 // User starts following a feed
 // @Summary     Follow a feed
@@ -23,6 +142,10 @@ import (
 // @Param       feed_follow  body      object  true  "Feed follow data"
 // @Success     201          {object}  object  "Feed follow created"
 // @Failure     400          {object}  object  "Invalid input"
+// @Failure     403          {object}  object  "Feed follow limit reached"
+// @Failure     404          {object}  object  "Feed not found"
+// @Failure     409          {object}  object  "Already following"
+// @Failure     500          {object}  object  "Server error"
 // @Router      /v1/feed_follows [post]
 func (cfg *Config) HandlerCreateFeedFollow(w http.ResponseWriter, r *http.Request, user database.User) {
 	type parameters struct {
@@ -37,40 +160,311 @@ func (cfg *Config) HandlerCreateFeedFollow(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Create feed follow relationship
-	feedFollow, err := cfg.DB.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		UserID:    user.ID,
-		FeedID:    params.FeedID,
-	})
+	cfg.respondWithNewFeedFollow(w, r, user, params.FeedID)
+}
+
+// HandlerFollowFeed follows the feed named in the path, so clients can follow a
+// feed through its link instead of sending a body to HandlerCreateFeedFollow
+// @Summary     Follow a feed by ID
+// @Description Start following the feed in the path; same checks as POST /v1/feed_follows
+// @Tags        feed_follows
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       feedID  path      string  true  "Feed ID"
+// @Success     201     {object}  object  "Feed follow created"
+// @Failure     400     {object}  object  "Invalid ID"
+// @Failure     403     {object}  object  "Feed follow limit reached"
+// @Failure     404     {object}  object  "Feed not found"
+// @Failure     409     {object}  object  "Already following"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/feed/{feedID}/follow [post]
+func (cfg *Config) HandlerFollowFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Create feed follow failed: %v", err))
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
 		return
 	}
 
-	models.RespondWithJSON(w, http.StatusCreated, models.DatabaseFeedFollowToFeedFollow(feedFollow))
+	cfg.respondWithNewFeedFollow(w, r, user, feedID)
 }
 
-// HandlerGetFeedFollow returns all feeds the user follows
+// feedFollowsResponse is a page of follows, shaped like the posts endpoints
+type feedFollowsResponse struct {
+	FeedFollows []models.FeedFollowWithFeed `json:"feed_follows"`
+	NextCursor  string                      `json:"next_cursor"`
+}
+
+// HandlerGetFeedFollow returns the feeds the user follows with cursor-based pagination
 // @Summary     Get followed feeds
-// @Description Get all feeds the user is following
+// @Description Get the feeds the user is following, newest follow first, with each feed's name and the user's display name for it
 // @Tags        feed_follows
 // @Accept      json
 // @Produce     json
 // @Security    Bearer
-// @Success     200  {object}  object  "List of followed feeds"
-// @Failure     500  {object}  object  "Server error"
+// @Param       limit     query     int     false  "Number of follows to return (max 100)"  default(20)
+// @Param       cursor    query     string  false  "Cursor for pagination (RFC3339 timestamp from next_cursor)"
+// @Param       category  query     string  false  "Only return follows in this category"
+// @Success     200       {object}  object  "Page of followed feeds"
+// @Failure     400       {object}  object  "Invalid parameters"
+// @Failure     500       {object}  object  "Server error"
 // @Router      /v1/feed_follows [get]
 func (cfg *Config) HandlerGetFeedFollow(w http.ResponseWriter, r *http.Request, user database.User) {
-	feedFollows, err := cfg.DB.GetFeedFollows(r.Context(), user.ID)
+	limit, cursor, err := parsePostsPagination(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid cursor format")
+		return
+	}
+
+	category, err := parseFeedFollowCategory(optionalQueryParam(r, "category"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := getFeedFollowsPage(r.Context(), cfg.DB, user.ID, limit, cursor, category)
+	if err != nil {
+		respondWithDBError(w, err, "Get feed follows")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// getFeedFollowsPage returns the user's follows created before cursor, newest first.
+// next_cursor points at the last follow, carrying sub-second precision so follows
+// created within the same second aren't skipped.
+func getFeedFollowsPage(ctx context.Context, store database.Querier, userID uuid.UUID, limit int, cursor time.Time, category sql.NullString) (feedFollowsResponse, error) {
+	rows, err := store.GetFeedFollowsPaginated(ctx, database.GetFeedFollowsPaginatedParams{
+		UserID:        userID,
+		CreatedBefore: cursor,
+		Category:      category,
+		RowLimit:      int32(limit),
+	})
+	if err != nil {
+		return feedFollowsResponse{}, err
+	}
+
+	nextCursor := ""
+	if len(rows) > 0 {
+		nextCursor = rows[len(rows)-1].CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return feedFollowsResponse{
+		FeedFollows: models.DatabaseAllFeedFollowWithFeedToAllFeedFollowWithFeed(rows),
+		NextCursor:  nextCursor,
+	}, nil
+}
+
+// optionalQueryParam returns a pointer to the query parameter, or nil if it wasn't sent
+func optionalQueryParam(r *http.Request, key string) *string {
+	if !r.URL.Query().Has(key) {
+		return nil
+	}
+	value := r.URL.Query().Get(key)
+	return &value
+}
+
+// feedUnreadCount is the unread badge of one followed feed
+type feedUnreadCount struct {
+	FeedFollowID uuid.UUID    `json:"feed_follow_id"`
+	FeedID       uuid.UUID    `json:"feed_id"`
+	DisplayName  string       `json:"display_name"`
+	Unread       models.Count `json:"unread"`
+}
+
+type unreadSummary struct {
+	Feeds []feedUnreadCount `json:"feeds"`
+	Total models.Count      `json:"total"`
+}
+
+// HandlerGetUnreadSummary returns the number of unread posts in each followed feed
+// @Summary     Get unread counts
+// @Description Get the number of posts the user hasn't marked as read, per followed feed and in total. Counted in a single query, for sidebar badges.
+// @Tags        feed_follows
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Unread counts"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/feed_follows/unread-summary [get]
+func (cfg *Config) HandlerGetUnreadSummary(w http.ResponseWriter, r *http.Request, user database.User) {
+	summary, err := getUnreadSummary(r, cfg.DB, user.ID, cfg.PostsAfterFollowOnly)
+	if err != nil {
+		respondWithDBError(w, err, "Get unread counts")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, summary)
+}
+
+// getUnreadSummary counts the user's unread posts per followed feed.
+// Posts hidden from the timeline by afterFollowOnly are not counted either.
+func getUnreadSummary(r *http.Request, store database.Querier, userID uuid.UUID, afterFollowOnly bool) (unreadSummary, error) {
+	rows, err := store.GetUnreadCountsForUser(r.Context(), database.GetUnreadCountsForUserParams{
+		UserID:          userID,
+		AfterFollowOnly: afterFollowOnly,
+	})
+	if err != nil {
+		return unreadSummary{}, err
+	}
+
+	summary := unreadSummary{Feeds: make([]feedUnreadCount, 0, len(rows))}
+	var total int64
+	for _, row := range rows {
+		summary.Feeds = append(summary.Feeds, feedUnreadCount{
+			FeedFollowID: row.FeedFollowID,
+			FeedID:       row.FeedID,
+			DisplayName:  models.FeedDisplayName(row.Alias, row.FeedName),
+			Unread:       models.NewCount(r, row.UnreadCount),
+		})
+		total += row.UnreadCount
+	}
+	summary.Total = models.NewCount(r, total)
+
+	return summary, nil
+}
+
+// HandlerUpdateFeedFollow sets or clears the user's own name and category for a followed feed,
+// and whether its new posts notify the user
+// @Summary     Update a followed feed
+// @Description Sets a personal alias, category and/or notify for a followed feed. Send an empty or null alias to clear it and fall back to the feed's name; a body without alias, category or notify clears the alias. An empty or null category clears it; category is left unchanged when omitted. Set notify to false to stop realtime signals and stored notifications for the feed's new posts while still seeing them in your posts.
+// @Tags        feed_follows
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       feedFollowID  path      string  true  "Feed Follow ID"
+// @Param       feedFollow    body      object  true  "Alias, category and/or notify"
+// @Success     200           {object}  object  "Feed follow updated"
+// @Failure     400           {object}  object  "Invalid input"
+// @Failure     404           {object}  object  "Feed follow not found"
+// @Failure     500           {object}  object  "Server error"
+// @Router      /v1/feed_follows/{feedFollowID} [patch]
+func (cfg *Config) HandlerUpdateFeedFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToFeedFollowUpdate(w, r, user, cfg.DB)
+}
+
+func respondToFeedFollowUpdate(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier) {
+	feedFollowID, err := uuid.Parse(chi.URLParam(r, "feedFollowID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed follow ID: %v", err))
+		return
+	}
+
+	// Decoded as raw fields first to tell an omitted field from an explicit null
+	fields := map[string]json.RawMessage{}
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	var rawAlias, rawCategory *string
+	if err := decodeOptionalField(fields, "alias", &rawAlias); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if err := decodeOptionalField(fields, "category", &rawCategory); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	var notify *bool
+	if err := decodeOptionalField(fields, "notify", &notify); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+	if _, ok := fields["notify"]; ok && notify == nil {
+		models.RespondWithError(w, http.StatusBadRequest, "notify must be true or false")
+		return
+	}
+
+	alias, err := parseFeedFollowAlias(rawAlias)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category, err := parseFeedFollowCategory(rawCategory)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, setAlias := fields["alias"]
+	_, setCategory := fields["category"]
+	setNotify := notify != nil
+	// Before categories, this endpoint only set the alias and an empty body cleared it
+	if !setCategory && !setNotify {
+		setAlias = true
+	}
+
+	feedFollow, err := store.UpdateFeedFollow(r.Context(), database.UpdateFeedFollowParams{
+		ID:          feedFollowID,
+		UserID:      user.ID,
+		SetAlias:    setAlias,
+		Alias:       alias,
+		SetCategory: setCategory,
+		Category:    category,
+		SetNotify:   setNotify,
+		Notify:      setNotify && *notify,
+		UpdatedAt:   time.Now().UTC(),
+	})
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Get feed follows failed: %v", err))
+		respondWithDBError(w, err, "Update feed follow")
 		return
 	}
 
-	models.RespondWithJSON(w, http.StatusOK, models.DatabaseAllFeedFollowToAllFeedFollow(feedFollows))
+	models.RespondWithJSON(w, http.StatusOK, models.DatabaseFeedFollowToFeedFollow(feedFollow))
+}
+
+// parseFeedFollowAlias validates a requested alias. A missing or blank alias clears it.
+func parseFeedFollowAlias(raw *string) (sql.NullString, error) {
+	if raw == nil {
+		return sql.NullString{}, nil
+	}
+
+	alias := strings.TrimSpace(*raw)
+	if alias == "" {
+		return sql.NullString{}, nil
+	}
+
+	if utf8.RuneCountInString(alias) > maxFeedFollowAliasLength {
+		return sql.NullString{}, fmt.Errorf("Alias must be at most %d characters", maxFeedFollowAliasLength)
+	}
+
+	return sql.NullString{String: alias, Valid: true}, nil
+}
+
+// parseFeedFollowCategory validates a category. A missing or blank category is NULL,
+// which clears it on update and means "any category" when filtering.
+func parseFeedFollowCategory(raw *string) (sql.NullString, error) {
+	if raw == nil {
+		return sql.NullString{}, nil
+	}
+
+	category := strings.TrimSpace(*raw)
+	if category == "" {
+		return sql.NullString{}, nil
+	}
+
+	if utf8.RuneCountInString(category) > maxFeedFollowCategoryLength {
+		return sql.NullString{}, fmt.Errorf("Category must be at most %d characters", maxFeedFollowCategoryLength)
+	}
+
+	return sql.NullString{String: category, Valid: true}, nil
+}
+
+// decodeOptionalField unmarshals fields[key] into dst if the field was sent
+func decodeOptionalField(fields map[string]json.RawMessage, key string, dst any) error {
+	raw, ok := fields[key]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
 }
 
 // HandlerDeleteFeedFollow deletes a feed follow relationship
@@ -101,9 +495,11 @@ func (cfg *Config) HandlerDeleteFeedFollow(w http.ResponseWriter, r *http.Reques
 		UserID: user.ID,
 	})
 	if err != nil {
-		models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Delete feed follow failed: %v", err))
+		respondWithDBError(w, err, "Delete feed follow")
 		return
 	}
 
+	cfg.invalidatePostsCache(user.ID)
+
 	models.RespondWithJSON(w, http.StatusNoContent, struct{}{})
 }
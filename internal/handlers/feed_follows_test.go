@@ -0,0 +1,448 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// fakeFollowDB emulates the row lock Postgres takes for LockUserForUpdate:
+// it is held by a transaction until that transaction commits
+type fakeFollowDB struct {
+	rowLock sync.Mutex
+	mu      sync.Mutex
+	follows int64
+}
+
+type fakeFollowTx struct {
+	database.Querier
+	db     *fakeFollowDB
+	locked bool
+}
+
+func (tx *fakeFollowTx) LockUserForUpdate(ctx context.Context, id uuid.UUID) error {
+	tx.db.rowLock.Lock()
+	tx.locked = true
+	return nil
+}
+
+func (tx *fakeFollowTx) CountFeedFollowsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	return tx.db.follows, nil
+}
+
+func (tx *fakeFollowTx) createFollow() {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.follows++
+}
+
+func (tx *fakeFollowTx) finish() {
+	if tx.locked {
+		tx.db.rowLock.Unlock()
+	}
+}
+
+func TestEnforceFeedFollowLimit_ConcurrentRequests_CapHolds(t *testing.T) {
+	const limit = 5
+	db := &fakeFollowDB{}
+	userID := uuid.New()
+
+	var wg sync.WaitGroup
+	var rejectedMu sync.Mutex
+	rejected := 0
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tx := &fakeFollowTx{db: db}
+			defer tx.finish()
+
+			err := enforceFeedFollowLimit(context.Background(), tx, userID, limit)
+			if errors.Is(err, errFeedFollowLimitReached) {
+				rejectedMu.Lock()
+				rejected++
+				rejectedMu.Unlock()
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+
+			tx.createFollow()
+		}()
+	}
+
+	wg.Wait()
+
+	if db.follows != limit {
+		t.Errorf("Expected exactly %d follows, got %d", limit, db.follows)
+	}
+
+	if rejected != 50-limit {
+		t.Errorf("Expected %d rejected requests, got %d", 50-limit, rejected)
+	}
+}
+
+func TestEnforceFeedFollowLimit_Unlimited_SkipsLock(t *testing.T) {
+	db := &fakeFollowDB{follows: 1000}
+	tx := &fakeFollowTx{db: db}
+
+	if err := enforceFeedFollowLimit(context.Background(), tx, uuid.New(), 0); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if tx.locked {
+		t.Error("Expected no lock to be taken without a limit")
+	}
+}
+
+func TestParseFeedFollowAlias(t *testing.T) {
+	alias := func(s string) *string { return &s }
+
+	testCases := []struct {
+		name     string
+		raw      *string
+		expected sql.NullString
+	}{
+		{"Set", alias("My Local News"), sql.NullString{String: "My Local News", Valid: true}},
+		{"Set trims whitespace", alias("  News  "), sql.NullString{String: "News", Valid: true}},
+		{"Null clears", nil, sql.NullString{}},
+		{"Empty clears", alias(""), sql.NullString{}},
+		{"Blank clears", alias("   "), sql.NullString{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseFeedFollowAlias(tc.raw)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseFeedFollowAlias_TooLong_ReturnsError(t *testing.T) {
+	tooLong := strings.Repeat("ş", maxFeedFollowAliasLength+1)
+
+	if _, err := parseFeedFollowAlias(&tooLong); err == nil {
+		t.Error("Expected error for an alias over the limit")
+	}
+
+	atLimit := strings.Repeat("ş", maxFeedFollowAliasLength)
+	if _, err := parseFeedFollowAlias(&atLimit); err != nil {
+		t.Errorf("Expected alias at the limit to be accepted, got %v", err)
+	}
+}
+
+func TestFeedDisplayNames_FallsBackToFeedName(t *testing.T) {
+	aliased := uuid.New()
+	plain := uuid.New()
+
+	names := feedDisplayNames([]database.GetFeedFollowsWithFeedNameRow{
+		{FeedID: aliased, FeedName: "feeds.example.com/rss", Alias: sql.NullString{String: "My Local News", Valid: true}},
+		{FeedID: plain, FeedName: "Tech Blog"},
+	})
+
+	posts := []models.Post{{FeedID: aliased}, {FeedID: plain}}
+	setPostFeedNames(posts, names)
+
+	if posts[0].FeedName != "My Local News" {
+		t.Errorf("Expected alias %q, got %q", "My Local News", posts[0].FeedName)
+	}
+
+	if posts[1].FeedName != "Tech Blog" {
+		t.Errorf("Expected feed name %q, got %q", "Tech Blog", posts[1].FeedName)
+	}
+}
+
+func TestGetUnreadSummary_DecrementsAsPostsAreRead(t *testing.T) {
+	userID := uuid.New()
+	goFeed, rustFeed := uuid.New(), uuid.New()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := newFakeQuerier()
+	store.addFeeds(database.Feed{ID: goFeed, Name: "Go Blog"}, database.Feed{ID: rustFeed, Name: "Rust Blog"})
+	store.follows = []database.FeedFollow{
+		{ID: uuid.New(), UserID: userID, FeedID: goFeed, Alias: sql.NullString{String: "Go", Valid: true}},
+		{ID: uuid.New(), UserID: userID, FeedID: rustFeed},
+	}
+	store.posts = []database.Post{
+		{ID: uuid.New(), FeedID: goFeed, PublishedAt: base},
+		{ID: uuid.New(), FeedID: goFeed, PublishedAt: base},
+		{ID: uuid.New(), FeedID: rustFeed, PublishedAt: base},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed_follows/unread-summary", nil)
+
+	assertUnread := func(expectedGo, expectedRust, expectedTotal int64) {
+		t.Helper()
+
+		summary, err := getUnreadSummary(req, store, userID, false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(summary.Feeds) != 2 {
+			t.Fatalf("Expected 2 feeds, got %d", len(summary.Feeds))
+		}
+		if got := summary.Feeds[0].Unread.Value; got != expectedGo {
+			t.Errorf("Expected %d unread in %s, got %d", expectedGo, summary.Feeds[0].DisplayName, got)
+		}
+		if got := summary.Feeds[1].Unread.Value; got != expectedRust {
+			t.Errorf("Expected %d unread in %s, got %d", expectedRust, summary.Feeds[1].DisplayName, got)
+		}
+		if summary.Total.Value != expectedTotal {
+			t.Errorf("Expected %d unread in total, got %d", expectedTotal, summary.Total.Value)
+		}
+	}
+
+	assertUnread(2, 1, 3)
+
+	if _, err := store.MarkPostsRead(context.Background(), database.MarkPostsReadParams{UserID: userID, PostIds: []uuid.UUID{store.posts[0].ID}}); err != nil {
+		t.Fatalf("Failed to mark post read: %v", err)
+	}
+	assertUnread(1, 1, 2)
+
+	if _, err := store.MarkPostsRead(context.Background(), database.MarkPostsReadParams{UserID: userID, PostIds: []uuid.UUID{store.posts[1].ID, store.posts[2].ID}}); err != nil {
+		t.Fatalf("Failed to mark posts read: %v", err)
+	}
+	assertUnread(0, 0, 0)
+}
+
+func TestGetUnreadSummary_UsesDisplayNames(t *testing.T) {
+	userID := uuid.New()
+	feedID := uuid.New()
+	store := newFakeQuerier()
+	store.addFeeds(database.Feed{ID: feedID, Name: "Go Blog"})
+	store.follows = []database.FeedFollow{
+		{ID: uuid.New(), UserID: userID, FeedID: feedID, Alias: sql.NullString{String: "Go", Valid: true}},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed_follows/unread-summary", nil)
+
+	summary, err := getUnreadSummary(req, store, userID, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(summary.Feeds) != 1 || summary.Feeds[0].DisplayName != "Go" {
+		t.Errorf("Expected the alias as display name, got %+v", summary.Feeds)
+	}
+	if summary.Feeds[0].Unread.Value != 0 {
+		t.Errorf("Expected feed without posts to have 0 unread, got %d", summary.Feeds[0].Unread.Value)
+	}
+}
+
+// newFollowPageStore returns a store where userID follows one feed per category,
+// named "Feed 0", "Feed 1" and so on in the order followed
+func newFollowPageStore(userID uuid.UUID, base time.Time, categories ...string) *fakeQuerier {
+	store := newFakeQuerier()
+	for i, category := range categories {
+		feed := database.Feed{ID: uuid.New(), Name: fmt.Sprintf("Feed %d", i)}
+		store.addFeeds(feed)
+		store.follows = append(store.follows, database.FeedFollow{
+			ID: uuid.New(),
+			// Sub-second apart, so a cursor truncated to seconds would skip follows
+			CreatedAt: base.Add(time.Duration(i) * 100 * time.Millisecond),
+			UserID:    userID,
+			FeedID:    feed.ID,
+			Category:  sql.NullString{String: category, Valid: category != ""},
+		})
+	}
+	return store
+}
+
+func TestGetFeedFollowsPage_PagesThroughAllFollows(t *testing.T) {
+	userID := uuid.New()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newFollowPageStore(userID, base, "", "", "", "", "")
+
+	var names []string
+	cursor := base.Add(time.Hour)
+	for range 10 {
+		page, err := getFeedFollowsPage(context.Background(), store, userID, 2, cursor, sql.NullString{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(page.FeedFollows) == 0 {
+			break
+		}
+		for _, follow := range page.FeedFollows {
+			names = append(names, follow.FeedName)
+		}
+
+		cursor, err = time.Parse(time.RFC3339, page.NextCursor)
+		if err != nil {
+			t.Fatalf("Expected an RFC3339 next_cursor, got %q: %v", page.NextCursor, err)
+		}
+	}
+
+	expected := []string{"Feed 4", "Feed 3", "Feed 2", "Feed 1", "Feed 0"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestGetFeedFollowsPage_CategoryFilter(t *testing.T) {
+	userID := uuid.New()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newFollowPageStore(userID, base, "tech", "news", "tech", "")
+
+	page, err := getFeedFollowsPage(context.Background(), store, userID, 20, base.Add(time.Hour), sql.NullString{String: "tech", Valid: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(page.FeedFollows) != 2 {
+		t.Fatalf("Expected 2 tech follows, got %d", len(page.FeedFollows))
+	}
+	for _, follow := range page.FeedFollows {
+		if follow.Category != "tech" {
+			t.Errorf("Expected category %q, got %q", "tech", follow.Category)
+		}
+	}
+}
+
+func TestParseFeedFollowCategory(t *testing.T) {
+	tech := "  tech "
+	blank := "   "
+	tooLong := strings.Repeat("c", maxFeedFollowCategoryLength+1)
+
+	testCases := []struct {
+		name     string
+		raw      *string
+		expected sql.NullString
+		wantErr  bool
+	}{
+		{"Missing", nil, sql.NullString{}, false},
+		{"Blank", &blank, sql.NullString{}, false},
+		{"Trimmed", &tech, sql.NullString{String: "tech", Valid: true}, false},
+		{"Too long", &tooLong, sql.NullString{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseFeedFollowCategory(tc.raw)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCreateFeedFollow_FollowTwice_SecondIsConflict(t *testing.T) {
+	userID, feedID := uuid.New(), uuid.New()
+	store := newFakeQuerier()
+	store.addFeeds(database.Feed{ID: feedID})
+
+	follow, err := createFeedFollow(context.Background(), store, userID, feedID)
+	if err != nil {
+		t.Fatalf("Expected the first follow to succeed, got %v", err)
+	}
+	if follow.UserID != userID || follow.FeedID != feedID {
+		t.Errorf("Expected a follow of feed %v by %v, got %+v", feedID, userID, follow)
+	}
+
+	_, err = createFeedFollow(context.Background(), store, userID, feedID)
+	rec := httptest.NewRecorder()
+	(&Config{}).respondWithAddFeedError(rec, err)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a repeated follow, got %d", rec.Code)
+	}
+
+	if got := store.feeds[feedID].FollowerCount; got != 1 {
+		t.Errorf("Expected follower count 1, got %d", got)
+	}
+}
+
+func TestHandlerFollowFeed_InvalidFeedID_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodPost, "/v1/feed/not-a-uuid/follow", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", "not-a-uuid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerFollowFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+// feedFollowUpdateRequest builds a PATCH /v1/feed_follows/{feedFollowID} request
+func feedFollowUpdateRequest(feedFollowID uuid.UUID, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPatch, "/v1/feed_follows/"+feedFollowID.String(), strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedFollowID", feedFollowID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandlerUpdateFeedFollow_Notify(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedNotify bool
+		expectedAlias  string
+	}{
+		{"Mute keeps the alias", `{"notify": false}`, http.StatusOK, false, "Work"},
+		{"Unmute", `{"notify": true}`, http.StatusOK, true, "Work"},
+		{"Alias only leaves notify", `{"alias": "Fun"}`, http.StatusOK, true, "Fun"},
+		{"Mute and rename", `{"notify": false, "alias": "Quiet"}`, http.StatusOK, false, "Quiet"},
+		{"Null notify", `{"notify": null}`, http.StatusBadRequest, true, "Work"},
+		{"Non-boolean notify", `{"notify": "no"}`, http.StatusBadRequest, true, "Work"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeQuerier()
+			follow := store.follow(user.ID, uuid.New())
+			store.follows[0].Alias = sql.NullString{String: "Work", Valid: true}
+			rec := httptest.NewRecorder()
+
+			respondToFeedFollowUpdate(rec, feedFollowUpdateRequest(follow.ID, tc.body), user, store)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if got := store.follows[0]; got.Notify != tc.expectedNotify || got.Alias.String != tc.expectedAlias {
+				t.Errorf("Expected notify %v and alias %q, got %v and %q", tc.expectedNotify, tc.expectedAlias, got.Notify, got.Alias.String)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var response models.FeedFollow
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Notify != tc.expectedNotify {
+				t.Errorf("Expected notify %v in the response, got %v", tc.expectedNotify, response.Notify)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// errFeedHostNotAllowed is returned when a feed URL points at a loopback, private or link-local address
+var errFeedHostNotAllowed = errors.New("feeds from private, loopback or link-local addresses are not allowed")
+
+// HostResolver looks up the addresses of a host; *net.Resolver implements it
+type HostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// hostResolver returns the configured resolver, falling back to net.DefaultResolver
+func (cfg *Config) hostResolver() HostResolver {
+	if cfg.HostResolver == nil {
+		return net.DefaultResolver
+	}
+	return cfg.HostResolver
+}
+
+// checkFeedHost returns errFeedHostNotAllowed if BlockPrivateFeedHosts is set and
+// feedURL's host is localhost or an address, or resolves to any address, that
+// isn't publicly routable. This keeps users from making the server fetch internal
+// services (SSRF); redirects are checked by the fetcher through CheckFetchURL.
+func (cfg *Config) checkFeedHost(ctx context.Context, feedURL string) error {
+	if !cfg.BlockPrivateFeedHosts {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(feedURL)
+	if err != nil {
+		return err
+	}
+	host := normalizeDomain(parsedURL.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errFeedHostNotAllowed
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return errFeedHostNotAllowed
+		}
+		return nil
+	}
+
+	addrs, err := cfg.hostResolver().LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("could not resolve feed host: %w", err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return errFeedHostNotAllowed
+		}
+	}
+	return nil
+}
+
+// CheckFetchURL applies the BlockPrivateFeedHosts check to a URL fetched outside
+// the handlers, such as a feed redirect or the article of a new post
+func (cfg *Config) CheckFetchURL(ctx context.Context, rawURL string) error {
+	return cfg.checkFeedHost(ctx, rawURL)
+}
+
+// publicIP reports whether ip may be fetched from: anything but loopback, private,
+// link-local (including cloud metadata at 169.254.169.254), multicast and unspecified addresses
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mmcdole/gofeed"
+)
+
+// stubResolver resolves hosts from a fixed table
+type stubResolver map[string][]string
+
+func (s stubResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := s[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+var testResolver = stubResolver{
+	"blog.example.com":     {"93.184.216.34"},
+	"intranet.example.com": {"10.0.0.5"},
+	"mixed.example.com":    {"93.184.216.34", "127.0.0.1"},
+}
+
+func TestCheckFeedHost(t *testing.T) {
+	testCases := []struct {
+		name    string
+		url     string
+		allowed bool
+	}{
+		{"Public host", "https://blog.example.com/feed", true},
+		{"Public address", "http://93.184.216.34/feed", true},
+		{"localhost", "http://localhost/feed", false},
+		{"localhost subdomain", "http://feeds.localhost/feed", false},
+		{"Loopback address", "http://127.0.0.1:8080/feed", false},
+		{"IPv6 loopback", "http://[::1]/feed", false},
+		{"Cloud metadata", "http://169.254.169.254/latest/meta-data", false},
+		{"Private address", "http://192.168.1.10/feed", false},
+		{"Unspecified address", "http://0.0.0.0/feed", false},
+		{"Host resolving to a private address", "https://intranet.example.com/feed", false},
+		{"Host resolving to a loopback among others", "https://mixed.example.com/feed", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{BlockPrivateFeedHosts: true, HostResolver: testResolver}
+
+			err := cfg.checkFeedHost(context.Background(), tc.url)
+			if tc.allowed && err != nil {
+				t.Errorf("Expected %s to be allowed, got %v", tc.url, err)
+			}
+			if !tc.allowed && !errors.Is(err, errFeedHostNotAllowed) {
+				t.Errorf("Expected errFeedHostNotAllowed for %s, got %v", tc.url, err)
+			}
+		})
+	}
+}
+
+func TestCheckFeedHost_UnresolvableHost_ReturnsError(t *testing.T) {
+	cfg := &Config{BlockPrivateFeedHosts: true, HostResolver: testResolver}
+
+	if err := cfg.checkFeedHost(context.Background(), "https://missing.example.com/feed"); err == nil {
+		t.Error("Expected an error for a host that doesn't resolve")
+	}
+}
+
+func TestCheckFeedHost_Disabled_AllowsEverything(t *testing.T) {
+	cfg := &Config{}
+
+	if err := cfg.checkFeedHost(context.Background(), "http://169.254.169.254/latest/meta-data"); err != nil {
+		t.Errorf("Expected no error when BlockPrivateFeedHosts is off, got %v", err)
+	}
+}
+
+func TestHandlerCreateFeed_RejectedURLs_ReturnUnprocessableEntity(t *testing.T) {
+	testCases := []struct {
+		name string
+		url  string
+	}{
+		{"File scheme", "file:///etc/passwd"},
+		{"localhost", "http://localhost/feed"},
+		{"Cloud metadata", "http://169.254.169.254/latest/meta-data"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := feedfetch.NewFakeFetcher()
+			cfg := &Config{FeedFetcher: fetcher, BlockPrivateFeedHosts: true, HostResolver: testResolver}
+
+			body := strings.NewReader(`{"name":"Internal","url":"` + tc.url + `"}`)
+			req := httptest.NewRequest(http.MethodPost, "/v1/feed", body)
+			rec := httptest.NewRecorder()
+
+			cfg.HandlerCreateFeed(rec, req, database.User{ID: uuid.New()})
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+			}
+			if calls := fetcher.Calls(tc.url); calls != 0 {
+				t.Errorf("Expected the feed not to be fetched, got %d fetches", calls)
+			}
+		})
+	}
+}
+
+func TestHandlerValidateFeed_PublicURL_IsNormalizedAndFetched(t *testing.T) {
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed("https://blog.example.com/feed", &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: "Blog"}})
+	cfg := &Config{FeedFetcher: fetcher, BlockPrivateFeedHosts: true, HostResolver: testResolver}
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerValidateFeed(rec, validateFeedRequest(" HTTPS://Blog.Example.com/feed/#latest "), database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if calls := fetcher.Calls("https://blog.example.com/feed"); calls != 1 {
+		t.Errorf("Expected the normalized URL to be fetched once, got %d fetches", calls)
+	}
+}
+
+func TestHandlerFetchFeed_RedirectToLoopback_IsRefused(t *testing.T) {
+	var internalHits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalHits.Add(1)
+	}))
+	t.Cleanup(internal.Close)
+	redirector := httptest.NewServer(http.RedirectHandler(internal.URL+"/feed.xml", http.StatusFound))
+	t.Cleanup(redirector.Close)
+
+	// blog.example.com resolves to a public address, but every connection goes to the redirector
+	transport := feedfetch.NewTransport()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if strings.HasPrefix(addr, "blog.example.com:") {
+			addr = redirector.Listener.Addr().String()
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+	fetcher := feedfetch.NewGofeedFetcher()
+	fetcher.Client = &http.Client{Transport: transport}
+	cfg := &Config{FeedFetcher: fetcher, BlockPrivateFeedHosts: true, HostResolver: testResolver}
+	fetcher.CheckURL = cfg.CheckFetchURL
+
+	testCases := []struct {
+		name    string
+		request func(rec *httptest.ResponseRecorder)
+	}{
+		{"Preview", func(rec *httptest.ResponseRecorder) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview?url="+url.QueryEscape("http://blog.example.com/feed"), nil)
+			cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+		}},
+		{"Validate", func(rec *httptest.ResponseRecorder) {
+			cfg.HandlerValidateFeed(rec, validateFeedRequest("http://blog.example.com/feed"), database.User{ID: uuid.New()})
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			tc.request(rec)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+			}
+			if hits := internalHits.Load(); hits != 0 {
+				t.Errorf("Expected the loopback redirect target not to be requested, got %d requests", hits)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+const (
+	// defaultFeedLogoMaxBytes is the largest logo served unless configured
+	defaultFeedLogoMaxBytes = 256 << 10
+	// feedLogoFetchTimeout bounds fetching one logo, including redirects and the body
+	feedLogoFetchTimeout = 5 * time.Second
+	// feedLogoMaxRedirects is how many redirects a logo fetch follows
+	feedLogoMaxRedirects = 3
+	// feedLogoCacheControl lets browsers and proxies keep a logo for a day
+	feedLogoCacheControl = "public, max-age=86400"
+)
+
+// errFeedLogoNotImage is returned when a logo URL serves something other than an image
+var errFeedLogoNotImage = errors.New("logo is not an image")
+
+// HandlerGetFeedLogo serves a feed's logo image
+// @Summary     Get feed logo
+// @Description Fetches and serves the feed's logo, or the favicon of the feed's site when it has none. Logos are cached in memory (FEED_LOGO_CACHE_SIZE) and by clients for a day.
+// @Tags        feeds
+// @Produce     image/png
+// @Param       feedID  path      string  true  "Feed ID"
+// @Success     200     {file}    binary  "Logo image"
+// @Success     304     {string}  string  "Not modified"
+// @Failure     400     {object}  object  "Invalid feed ID"
+// @Failure     404     {object}  object  "Feed not found or it has no logo"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/feed/{feedID}/logo [get]
+func (cfg *Config) HandlerGetFeedLogo(w http.ResponseWriter, r *http.Request) {
+	cfg.respondWithFeedLogo(w, r, cfg.DB)
+}
+
+// respondWithFeedLogo serves the logo of the feed in the URL, from the cache when possible
+func (cfg *Config) respondWithFeedLogo(w http.ResponseWriter, r *http.Request, store database.Querier) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
+		return
+	}
+
+	if cfg.FeedLogoCache != nil {
+		if logo, ok := cfg.FeedLogoCache.Get(feedID); ok {
+			serveFeedLogo(w, r, logo)
+			return
+		}
+	}
+
+	feed, err := store.GetFeedByID(r.Context(), feedID)
+	if err != nil {
+		if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+			models.RespondWithError(w, http.StatusNotFound, "Feed not found")
+			return
+		}
+		respondWithDBError(w, err, "Get feed")
+		return
+	}
+
+	logo, ok := cfg.fetchFeedLogo(r.Context(), feed)
+	if !ok {
+		models.RespondWithError(w, http.StatusNotFound, "Feed has no logo")
+		return
+	}
+
+	if cfg.FeedLogoCache != nil {
+		cfg.FeedLogoCache.Set(feedID, logo)
+	}
+	serveFeedLogo(w, r, logo)
+}
+
+// fetchFeedLogo fetches the first available of the feed's logo candidates
+func (cfg *Config) fetchFeedLogo(ctx context.Context, feed database.Feed) (cache.Logo, bool) {
+	for _, logoURL := range feedLogoURLs(feed) {
+		logo, err := cfg.fetchLogo(ctx, logoURL)
+		if err == nil {
+			return logo, true
+		}
+		cfg.Logger.Debug().Err(err).Str("feed_id", feed.ID.String()).Str("logo_url", logoURL).Msg("Failed to fetch feed logo")
+	}
+	return cache.Logo{}, false
+}
+
+// feedLogoURLs returns the URLs to try for a feed's logo: its logo_url, resolved
+// against the feed URL, then /favicon.ico on the feed's host
+func feedLogoURLs(feed database.Feed) []string {
+	feedURL, err := url.Parse(feed.Url)
+	if err != nil || feedURL.Host == "" {
+		return nil
+	}
+
+	var urls []string
+	if feed.LogoUrl.Valid && strings.TrimSpace(feed.LogoUrl.String) != "" {
+		if logoURL, err := feedURL.Parse(strings.TrimSpace(feed.LogoUrl.String)); err == nil {
+			urls = append(urls, logoURL.String())
+		}
+	}
+
+	favicon := url.URL{Scheme: feedURL.Scheme, Host: feedURL.Host, Path: "/favicon.ico"}
+	return append(urls, favicon.String())
+}
+
+// fetchLogo downloads an image from logoURL. The URL and every redirect must pass
+// checkFeedHost, and images larger than the configured maximum are rejected.
+func (cfg *Config) fetchLogo(ctx context.Context, logoURL string) (cache.Logo, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedLogoFetchTimeout)
+	defer cancel()
+
+	if err := cfg.checkLogoURL(ctx, logoURL); err != nil {
+		return cache.Logo{}, err
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > feedLogoMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", feedLogoMaxRedirects)
+			}
+			return cfg.checkLogoURL(req.Context(), req.URL.String())
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logoURL, nil)
+	if err != nil {
+		return cache.Logo{}, err
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return cache.Logo{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return cache.Logo{}, fmt.Errorf("logo request failed: %s", resp.Status)
+	}
+
+	maxBytes := cfg.feedLogoMaxBytes()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return cache.Logo{}, err
+	}
+	if int64(len(data)) > maxBytes {
+		return cache.Logo{}, fmt.Errorf("logo exceeds maximum size of %d bytes", maxBytes)
+	}
+
+	contentType, ok := imageContentType(resp.Header.Get("Content-Type"), data)
+	if !ok {
+		return cache.Logo{}, errFeedLogoNotImage
+	}
+	return cache.Logo{ContentType: contentType, Data: data}, nil
+}
+
+// checkLogoURL accepts http(s) URLs whose host passes checkFeedHost
+func (cfg *Config) checkLogoURL(ctx context.Context, logoURL string) error {
+	parsedURL, err := url.Parse(logoURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return errors.New("logo URL scheme must be http or https")
+	}
+	return cfg.checkFeedHost(ctx, logoURL)
+}
+
+// feedLogoMaxBytes returns the configured logo size limit, falling back to the default
+func (cfg *Config) feedLogoMaxBytes() int64 {
+	if cfg.FeedLogoMaxBytes <= 0 {
+		return defaultFeedLogoMaxBytes
+	}
+	return cfg.FeedLogoMaxBytes
+}
+
+// imageContentType returns the image media type of a logo: the declared Content-Type
+// when it is an image, otherwise the sniffed type. ok is false for non-images.
+func imageContentType(declared string, data []byte) (string, bool) {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && strings.HasPrefix(mediaType, "image/") {
+		return mediaType, true
+	}
+	if sniffed := http.DetectContentType(data); strings.HasPrefix(sniffed, "image/") {
+		return sniffed, true
+	}
+	return "", false
+}
+
+// serveFeedLogo writes a logo with caching headers. Conditional requests are
+// answered with 304 through the ETag. The CSP keeps scripts in SVG logos from running.
+func serveFeedLogo(w http.ResponseWriter, r *http.Request, logo cache.Logo) {
+	w.Header().Set("Content-Type", logo.ContentType)
+	w.Header().Set("Cache-Control", feedLogoCacheControl)
+	w.Header().Set("ETag", `"`+feedfetch.HashBody(logo.Data)+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(logo.Data))
+}
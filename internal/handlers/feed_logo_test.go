@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// testPNG is a 1x1 transparent PNG
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89\x00\x00\x00\rIDATx\x9cc\xf8\x0f\x00\x00\x01\x01\x00\x05\x18\xd8N\x00\x00\x00\x00IEND\xaeB`\x82")
+
+// imageServer serves images by path and counts the requests it gets
+type imageServer struct {
+	*httptest.Server
+	hits atomic.Int64
+}
+
+func newImageServer(t *testing.T, handler http.HandlerFunc) *imageServer {
+	t.Helper()
+
+	server := &imageServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.hits.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// feedLogoRequest builds a GET /v1/feed/{feedID}/logo request
+func feedLogoRequest(feedID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/"+feedID+"/logo", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", feedID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandlerGetFeedLogo_LogoURL_ServedAndCached(t *testing.T) {
+	server := newImageServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/logo.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(testPNG)
+	})
+	feed := database.Feed{
+		ID:      uuid.New(),
+		Url:     server.URL + "/feed.xml",
+		LogoUrl: sql.NullString{String: server.URL + "/logo.png", Valid: true},
+	}
+	store := newFakeQuerier()
+	store.addFeeds(feed)
+	cfg := &Config{FeedLogoCache: cache.NewLogosCache(10, time.Hour)}
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		cfg.respondWithFeedLogo(rec, feedLogoRequest(feed.ID.String()), store)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != "image/png" {
+			t.Errorf("Expected Content-Type image/png, got %q", contentType)
+		}
+		if cacheControl := rec.Header().Get("Cache-Control"); cacheControl != feedLogoCacheControl {
+			t.Errorf("Expected Cache-Control %q, got %q", feedLogoCacheControl, cacheControl)
+		}
+		if rec.Body.String() != string(testPNG) {
+			t.Error("Expected the logo image as the body")
+		}
+	}
+
+	if hits := server.hits.Load(); hits != 1 {
+		t.Errorf("Expected the second request to be served from the cache, got %d fetches", hits)
+	}
+}
+
+func TestHandlerGetFeedLogo_Fallbacks(t *testing.T) {
+	testCases := []struct {
+		name           string
+		logoPath       string
+		expectedStatus int
+	}{
+		{"No logo uses the favicon", "", http.StatusOK},
+		{"Missing logo uses the favicon", "/missing.png", http.StatusOK},
+		{"Non-image logo uses the favicon", "/page.html", http.StatusOK},
+		{"No favicon either", "/missing.png", http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hasFavicon := tc.expectedStatus == http.StatusOK
+			server := newImageServer(t, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/page.html":
+					w.Header().Set("Content-Type", "text/html")
+					_, _ = w.Write([]byte("<html><body>Not an image</body></html>"))
+				case r.URL.Path == "/favicon.ico" && hasFavicon:
+					// Favicons are often served without a useful Content-Type
+					w.Header().Set("Content-Type", "application/octet-stream")
+					_, _ = w.Write(testPNG)
+				default:
+					http.NotFound(w, r)
+				}
+			})
+			feed := database.Feed{ID: uuid.New(), Url: server.URL + "/blog/feed.xml"}
+			if tc.logoPath != "" {
+				feed.LogoUrl = sql.NullString{String: tc.logoPath, Valid: true}
+			}
+			cfg := &Config{}
+			store := newFakeQuerier()
+			store.addFeeds(feed)
+			rec := httptest.NewRecorder()
+
+			cfg.respondWithFeedLogo(rec, feedLogoRequest(feed.ID.String()), store)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tc.expectedStatus == http.StatusOK && rec.Header().Get("Content-Type") != "image/png" {
+				t.Errorf("Expected the sniffed Content-Type image/png, got %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestHandlerGetFeedLogo_OversizedLogo_NotServed(t *testing.T) {
+	server := newImageServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(testPNG)
+	})
+	feed := database.Feed{ID: uuid.New(), Url: server.URL + "/feed.xml", LogoUrl: sql.NullString{String: "/logo.png", Valid: true}}
+	cfg := &Config{FeedLogoMaxBytes: int64(len(testPNG) - 1)}
+	store := newFakeQuerier()
+	store.addFeeds(feed)
+	rec := httptest.NewRecorder()
+
+	cfg.respondWithFeedLogo(rec, feedLogoRequest(feed.ID.String()), store)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandlerGetFeedLogo_PrivateHost_NotFetched(t *testing.T) {
+	server := newImageServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(testPNG)
+	})
+	feed := database.Feed{ID: uuid.New(), Url: "https://blog.example.com/feed", LogoUrl: sql.NullString{String: server.URL + "/logo.png", Valid: true}}
+	cfg := &Config{BlockPrivateFeedHosts: true, HostResolver: testResolver}
+	store := newFakeQuerier()
+	store.addFeeds(feed)
+	rec := httptest.NewRecorder()
+
+	cfg.respondWithFeedLogo(rec, feedLogoRequest(feed.ID.String()), store)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if hits := server.hits.Load(); hits != 0 {
+		t.Errorf("Expected the loopback logo not to be fetched, got %d fetches", hits)
+	}
+}
+
+func TestHandlerGetFeedLogo_IfNoneMatch_ReturnsNotModified(t *testing.T) {
+	feedID := uuid.New()
+	cfg := &Config{FeedLogoCache: cache.NewLogosCache(10, time.Hour)}
+	cfg.FeedLogoCache.Set(feedID, cache.Logo{ContentType: "image/png", Data: testPNG})
+
+	first := httptest.NewRecorder()
+	cfg.respondWithFeedLogo(first, feedLogoRequest(feedID.String()), newFakeQuerier())
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag header")
+	}
+
+	req := feedLogoRequest(feedID.String())
+	req.Header.Set("If-None-Match", etag)
+	second := httptest.NewRecorder()
+	cfg.respondWithFeedLogo(second, req, newFakeQuerier())
+
+	if second.Code != http.StatusNotModified {
+		t.Errorf("Expected status 304, got %d", second.Code)
+	}
+}
+
+func TestHandlerGetFeedLogo_InvalidRequests(t *testing.T) {
+	testCases := []struct {
+		name           string
+		feedID         string
+		expectedStatus int
+	}{
+		{"Invalid ID", "not-a-uuid", http.StatusBadRequest},
+		{"Unknown feed", uuid.NewString(), http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			rec := httptest.NewRecorder()
+
+			cfg.respondWithFeedLogo(rec, feedLogoRequest(tc.feedID), newFakeQuerier())
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,1236 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
+	"github.com/mmcdole/gofeed"
+)
+
+func newStubFeedServer(t *testing.T, itemCount int) *httptest.Server {
+	t.Helper()
+
+	var items strings.Builder
+	for i := 0; i < itemCount; i++ {
+		fmt.Fprintf(&items, `<item>
+      <title>Post %d</title>
+      <link>https://example.com/posts/%d</link>
+      <description>&lt;p&gt;Body of post %d&lt;/p&gt;</description>
+      <pubDate>Mon, %02d Jan 2024 10:00:00 GMT</pubDate>
+    </item>`, i, i, i, i%28+1)
+	}
+
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Stub Feed</title>
+    <link>https://example.com</link>
+    <description>Stub feed for tests</description>
+    %s
+  </channel>
+</rss>`, items.String())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHandlerPreviewFeed_ValidFeed_ReturnsLatestItems(t *testing.T) {
+	server := newStubFeedServer(t, 3)
+	cfg := &Config{}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview?url="+url.QueryEscape(server.URL), nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response feedPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Title != "Stub Feed" {
+		t.Errorf("Expected title %q, got %q", "Stub Feed", response.Title)
+	}
+
+	if len(response.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(response.Items))
+	}
+
+	// Newest item first
+	if response.Items[0].Title != "Post 2" {
+		t.Errorf("Expected newest item %q first, got %q", "Post 2", response.Items[0].Title)
+	}
+
+	if response.Items[0].Excerpt != "Body of post 2" {
+		t.Errorf("Expected HTML-stripped excerpt, got %q", response.Items[0].Excerpt)
+	}
+}
+
+func TestHandlerPreviewFeed_ManyItems_CapsResult(t *testing.T) {
+	server := newStubFeedServer(t, defaultFeedPreviewMaxItems+5)
+	cfg := &Config{}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview?url="+url.QueryEscape(server.URL), nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+
+	var response feedPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Items) != defaultFeedPreviewMaxItems {
+		t.Errorf("Expected %d items, got %d", defaultFeedPreviewMaxItems, len(response.Items))
+	}
+	if response.ItemCount.Value != defaultFeedPreviewMaxItems+5 {
+		t.Errorf("Expected item_count %d, got %d", defaultFeedPreviewMaxItems+5, response.ItemCount.Value)
+	}
+}
+
+func TestHandlerPreviewFeed_OversizedFeed_CapsToConfiguredMax(t *testing.T) {
+	feedURL := "https://example.com/huge.xml"
+	fetcher := feedfetch.NewFakeFetcher()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := make([]*gofeed.Item, 50000)
+	for i := range items {
+		published := base.Add(time.Duration(i) * time.Minute)
+		items[i] = &gofeed.Item{Title: fmt.Sprintf("Post %d", i), PublishedParsed: &published}
+	}
+	fetcher.SetFeed(feedURL, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: "Huge", Items: items}})
+	cfg := &Config{FeedFetcher: fetcher, FeedPreviewMaxItems: 3}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview?url="+url.QueryEscape(feedURL), nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response feedPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(response.Items))
+	}
+	if response.ItemCount.Value != int64(len(items)) {
+		t.Errorf("Expected item_count %d, got %d", len(items), response.ItemCount.Value)
+	}
+	// Only the first feedPreviewMaxInspectedItems items are sorted
+	want := fmt.Sprintf("Post %d", feedPreviewMaxInspectedItems-1)
+	if response.Items[0].Title != want {
+		t.Errorf("Expected newest inspected item %q first, got %q", want, response.Items[0].Title)
+	}
+}
+
+func TestHandlerPreviewFeed_InvalidURL_ReturnsBadRequest(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		{"Missing url", ""},
+		{"Unsupported scheme", "?url=" + url.QueryEscape("file:///etc/passwd")},
+		{"Missing host", "?url=" + url.QueryEscape("http://")},
+	}
+
+	cfg := &Config{}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview"+tc.query, nil)
+			rec := httptest.NewRecorder()
+
+			cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
+
+func TestExcerpt_LongText_Truncates(t *testing.T) {
+	result := excerpt("<p>héllo wörld</p>", 5)
+
+	if result != "héllo…" {
+		t.Errorf("Expected %q, got %q", "héllo…", result)
+	}
+}
+
+func TestIsStaleFeedUpdate(t *testing.T) {
+	stored := time.Date(2024, 1, 10, 12, 0, 0, 500_000_000, time.UTC)
+	before := stored.Add(-time.Minute)
+	sameSecond := stored.Truncate(time.Second)
+	exact := stored
+
+	testCases := []struct {
+		name              string
+		ifUnmodifiedSince *time.Time
+		version           *time.Time
+		expected          bool
+	}{
+		{"No precondition", nil, nil, false},
+		{"Header older than stored", &before, nil, true},
+		{"Header within the same second", &sameSecond, nil, false},
+		{"Version older than stored", nil, &before, true},
+		{"Version matches stored", nil, &exact, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isStaleFeedUpdate(stored, tc.ifUnmodifiedSince, tc.version); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandlerUpdateFeed_InvalidIfUnmodifiedSince_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodPatch, "/v1/feed/"+uuid.NewString(), strings.NewReader(`{"name":"New"}`))
+	req.Header.Set("If-Unmodified-Since", "yesterday")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", uuid.NewString())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerUpdateFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestRespondToFeedUpdate_StalePrecondition_ReturnsPreconditionFailed(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed := database.Feed{ID: uuid.New(), UserID: user.ID, Name: "Old", UpdatedAt: updatedAt}
+	stale := updatedAt.Add(-time.Hour)
+
+	testCases := []struct {
+		name   string
+		header string
+		body   string
+	}{
+		{"If-Unmodified-Since header", stale.Format(http.TimeFormat), `{"name":"New"}`},
+		{"Version", "", `{"name":"New","version":"` + stale.Format(time.RFC3339) + `"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeQuerier()
+			store.addFeeds(feed)
+			req := httptest.NewRequest(http.MethodPatch, "/v1/feed/"+feed.ID.String(), strings.NewReader(tc.body))
+			if tc.header != "" {
+				req.Header.Set("If-Unmodified-Since", tc.header)
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("feedID", feed.ID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rec := httptest.NewRecorder()
+
+			(&Config{}).respondToFeedUpdate(rec, req, user, store)
+
+			if rec.Code != http.StatusPreconditionFailed {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+			}
+			if store.feeds[feed.ID].Name != "Old" {
+				t.Errorf("Expected the feed to stay unchanged, got name %q", store.feeds[feed.ID].Name)
+			}
+		})
+	}
+}
+
+func TestFindOrParseFeed_ExistingFeed_SkipsFetch(t *testing.T) {
+	existing := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	store := newFakeQuerier()
+	store.addFeeds(existing)
+	fetcher := feedfetch.NewFakeFetcher()
+
+	feed, parsed, _, err := findOrParseFeed(context.Background(), store, fetcher, existing.Url)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fetcher.Calls(existing.Url) != 0 {
+		t.Errorf("Expected no fetch for an existing feed, got %d", fetcher.Calls(existing.Url))
+	}
+
+	if feed == nil || feed.ID != existing.ID || parsed != nil {
+		t.Errorf("Expected existing feed %s only, got %v and %v", existing.ID, feed, parsed)
+	}
+}
+
+func TestFindOrParseFeed_NewFeed_FetchesOnce(t *testing.T) {
+	feedURL := "https://example.com/feed.xml"
+	store := newFakeQuerier()
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feedURL, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: "Parsed"}})
+
+	feed, parsed, _, err := findOrParseFeed(context.Background(), store, fetcher, feedURL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fetcher.Calls(feedURL) != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetcher.Calls(feedURL))
+	}
+
+	if feed != nil || parsed == nil {
+		t.Errorf("Expected only a parsed feed, got %v and %v", feed, parsed)
+	}
+}
+
+func TestFindOrParseFeed_ParseFailure_ReturnsErrFeedParse(t *testing.T) {
+	feedURL := "https://example.com/feed.xml"
+	store := newFakeQuerier()
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetError(feedURL, errors.New("connection refused"))
+
+	_, _, _, err := findOrParseFeed(context.Background(), store, fetcher, feedURL)
+
+	if !errors.Is(err, errFeedParse) {
+		t.Errorf("Expected errFeedParse, got %v", err)
+	}
+}
+
+func TestNormalizeFeedURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://example.com/feed.xml", "https://example.com/feed.xml"},
+		{"HTTPS://Example.COM/feed.xml", "https://example.com/feed.xml"},
+		{"https://example.com:443/feed/", "https://example.com/feed"},
+		{"http://example.com:8080/feed#top", "http://example.com:8080/feed"},
+		{" https://example.com/ ", "https://example.com"},
+		{"https://example.com/feed?format=rss", "https://example.com/feed?format=rss"},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeFeedURL(tt.input)
+		if err != nil {
+			t.Errorf("normalizeFeedURL(%q): expected no error, got %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("normalizeFeedURL(%q): expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestNormalizeFeedURL_InvalidURL_ReturnsError(t *testing.T) {
+	for _, input := range []string{"ftp://example.com/feed", "not a url", "https://"} {
+		if _, err := normalizeFeedURL(input); err == nil {
+			t.Errorf("normalizeFeedURL(%q): expected error, got nil", input)
+		}
+	}
+}
+
+func TestHandlerPreviewFeed_FakeFetcher_UsesConfiguredFetcher(t *testing.T) {
+	feedURL := "https://example.com/feed.xml"
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feedURL, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{
+		Title: "Fake Feed",
+		Items: []*gofeed.Item{{Title: "Only post", Link: "https://example.com/only"}},
+	}})
+	cfg := &Config{FeedFetcher: fetcher}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/preview?url="+url.QueryEscape(feedURL), nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerPreviewFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	if fetcher.Calls(feedURL) != 1 {
+		t.Errorf("Expected 1 fetch through the fake, got %d", fetcher.Calls(feedURL))
+	}
+
+	var response feedPreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Title != "Fake Feed" || len(response.Items) != 1 {
+		t.Errorf("Expected fake feed with 1 item, got %q with %d items", response.Title, len(response.Items))
+	}
+}
+
+func TestEnforceUniqueFeedName(t *testing.T) {
+	owner := uuid.New()
+	otherUser := uuid.New()
+	ownedFeed := database.Feed{ID: uuid.New(), UserID: owner, Name: "Local News"}
+	store := newFakeQuerier()
+	store.addFeeds(ownedFeed)
+
+	testCases := []struct {
+		name      string
+		userID    uuid.UUID
+		feedName  string
+		excludeID uuid.UUID
+		expected  error
+	}{
+		{"Same name", owner, "Local News", uuid.Nil, errFeedNameTaken},
+		{"Different case and spacing", owner, "  local news ", uuid.Nil, errFeedNameTaken},
+		{"New name", owner, "Tech", uuid.Nil, nil},
+		{"Renaming the feed to itself", owner, "Local News", ownedFeed.ID, nil},
+		{"Other user may reuse the name", otherUser, "Local News", uuid.Nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := enforceUniqueFeedName(context.Background(), store, tc.userID, tc.feedName, tc.excludeID)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	if store.userLocks != len(testCases) {
+		t.Errorf("Expected the user's row to be locked on every check, got %d locks", store.userLocks)
+	}
+}
+
+func TestRespondWithFeedNameError_Taken_ReturnsConflict(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	respondWithFeedNameError(rec, errFeedNameTaken)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, rec.Code)
+	}
+}
+
+// fakePostImporter returns a fixed set of "imported" posts
+type fakePostImporter struct {
+	posts []database.Post
+	calls int
+}
+
+func (f *fakePostImporter) ImportFeedPosts(ctx context.Context, feed database.Feed, parsedFeed *feedfetch.ParsedFeed) []database.Post {
+	f.calls++
+	return f.posts
+}
+
+func TestCreatedFeedPreviewPosts_NewFeed_ReturnsCappedNewestFirst(t *testing.T) {
+	feed := database.Feed{ID: uuid.New()}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	imported := make([]database.Post, 0, maxCreatedFeedPreviewPosts+3)
+	for i := 0; i < maxCreatedFeedPreviewPosts+3; i++ {
+		imported = append(imported, database.Post{
+			ID:          uuid.New(),
+			FeedID:      feed.ID,
+			CreatedAt:   base,
+			PublishedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	importer := &fakePostImporter{posts: imported}
+	cfg := &Config{PostImporter: importer}
+
+	posts := cfg.createdFeedPreviewPosts(context.Background(), feed, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{}})
+
+	if importer.calls != 1 {
+		t.Errorf("Expected posts imported once, got %d", importer.calls)
+	}
+
+	if len(posts) != maxCreatedFeedPreviewPosts {
+		t.Fatalf("Expected %d preview posts, got %d", maxCreatedFeedPreviewPosts, len(posts))
+	}
+
+	if posts[0].ID != imported[len(imported)-1].ID {
+		t.Errorf("Expected newest post first, got %v", posts[0].PublishedAt)
+	}
+	for i := 1; i < len(posts); i++ {
+		if posts[i].PublishedAt.After(posts[i-1].PublishedAt) {
+			t.Errorf("Expected posts newest first, got %v after %v", posts[i].PublishedAt, posts[i-1].PublishedAt)
+		}
+	}
+}
+
+func TestCreatedFeedPreviewPosts_NoImporter_ReturnsEmptyList(t *testing.T) {
+	cfg := &Config{}
+
+	posts := cfg.createdFeedPreviewPosts(context.Background(), database.Feed{ID: uuid.New()}, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{}})
+
+	if posts == nil || len(posts) != 0 {
+		t.Errorf("Expected an empty, non-nil preview, got %v", posts)
+	}
+}
+
+func TestFeedName(t *testing.T) {
+	cfg := &Config{MaxTitleBytes: 10}
+
+	testCases := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr bool
+	}{
+		{"Short name", "Go Blog", "Go Blog", false},
+		{"Trimmed", "  Go Blog  ", "Go Blog", false},
+		{"Truncated", "Go Blog Weekly", "Go Blog We", false},
+		{"Truncated on character boundary", "Go Blog éé", "Go Blog é", false},
+		{"At reject limit", strings.Repeat("a", 80), strings.Repeat("a", 10), false},
+		{"Grossly oversized", strings.Repeat("a", 81), "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cfg.feedName(tc.input)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFeedName_DefaultLimit(t *testing.T) {
+	cfg := &Config{}
+
+	got, err := cfg.feedName(strings.Repeat("a", textutil.DefaultMaxTitleBytes+1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(got) != textutil.DefaultMaxTitleBytes {
+		t.Errorf("Expected %d bytes, got %d", textutil.DefaultMaxTitleBytes, len(got))
+	}
+}
+
+func TestHandlerCreateFeed_OversizedName_ReturnsUnprocessableEntity(t *testing.T) {
+	cfg := &Config{}
+	body := fmt.Sprintf(`{"name":%q,"url":"https://example.com/feed.xml"}`, strings.Repeat("a", 1<<20))
+	req := httptest.NewRequest(http.MethodPost, "/v1/feed", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerCreateFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+func TestHandlerUpdateFeed_OversizedName_ReturnsUnprocessableEntity(t *testing.T) {
+	cfg := &Config{}
+	body := fmt.Sprintf(`{"name":%q}`, strings.Repeat("a", 1<<20))
+	req := httptest.NewRequest(http.MethodPatch, "/v1/feed/"+uuid.NewString(), strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", uuid.NewString())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerUpdateFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+// validateFeedRequest builds a POST /v1/feed/validate request for feedURL
+func validateFeedRequest(feedURL string) *http.Request {
+	body := fmt.Sprintf(`{"url":%q}`, feedURL)
+	return httptest.NewRequest(http.MethodPost, "/v1/feed/validate", strings.NewReader(body))
+}
+
+func TestHandlerValidateFeed_ValidFeed_ReturnsMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Go Blog</title>
+    <link>https://example.com</link>
+    <description>News about Go</description>
+    <image><url>https://example.com/logo.png</url><title>Go Blog</title><link>https://example.com</link></image>
+    <item><title>Older</title><pubDate>Mon, 01 Jan 2024 10:00:00 GMT</pubDate></item>
+    <item><title>Newer</title><pubDate>Fri, 05 Jan 2024 10:00:00 GMT</pubDate></item>
+    <item><title>Undated</title></item>
+  </channel>
+</rss>`))
+	}))
+	t.Cleanup(server.Close)
+	cfg := &Config{}
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerValidateFeed(rec, validateFeedRequest(server.URL), database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response feedValidationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Title != "Go Blog" || response.Description != "News about Go" {
+		t.Errorf("Expected the feed's title and description, got %+v", response)
+	}
+	if response.Logo != "https://example.com/logo.png" {
+		t.Errorf("Expected logo %q, got %q", "https://example.com/logo.png", response.Logo)
+	}
+	if response.ItemCount.Value != 3 {
+		t.Errorf("Expected item_count 3, got %d", response.ItemCount.Value)
+	}
+	expected := time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)
+	if response.LastPublishedAt == nil || !response.LastPublishedAt.Equal(expected) {
+		t.Errorf("Expected last_published_at %v, got %v", expected, response.LastPublishedAt)
+	}
+}
+
+func TestHandlerValidateFeed_InvalidFeeds_Rejected(t *testing.T) {
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>Not a feed</body></html>"))
+	}))
+	t.Cleanup(htmlServer.Close)
+	missingServer := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(missingServer.Close)
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+
+	testCases := []struct {
+		name            string
+		url             string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{"Not a feed", htmlServer.URL, http.StatusBadRequest, "URL did not return an RSS, Atom or JSON feed"},
+		{"Not found", missingServer.URL, http.StatusBadRequest, "Could not reach feed: http error: 404"},
+		{"Unreachable", closedServer.URL, http.StatusBadRequest, "Could not reach feed:"},
+		{"Invalid URL", "ftp://example.com/feed", http.StatusUnprocessableEntity, "Invalid request URL:"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			rec := httptest.NewRecorder()
+
+			cfg.HandlerValidateFeed(rec, validateFeedRequest(tc.url), database.User{ID: uuid.New()})
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !strings.HasPrefix(body["error"], tc.expectedMessage) {
+				t.Errorf("Expected error starting with %q, got %q", tc.expectedMessage, body["error"])
+			}
+		})
+	}
+}
+
+func TestNewFeedPreviewPosts_EqualTimestamps_StableOrder(t *testing.T) {
+	publishedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	posts := make([]database.Post, 4)
+	for i := range posts {
+		posts[i] = database.Post{ID: uuid.New(), Title: fmt.Sprintf("Post %d", i), PublishedAt: publishedAt}
+	}
+	reversed := make([]database.Post, len(posts))
+	for i, post := range posts {
+		reversed[len(posts)-1-i] = post
+	}
+
+	first := newFeedPreviewPosts(posts, len(posts))
+	second := newFeedPreviewPosts(reversed, len(posts))
+
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Fatalf("Expected the same order from both calls, got %v at %d and %v", first[i].ID, i, second[i].ID)
+		}
+		if i > 0 && bytes.Compare(first[i-1].ID[:], first[i].ID[:]) < 0 {
+			t.Errorf("Expected posts sharing a timestamp in descending id order, got %v before %v", first[i-1].ID, first[i].ID)
+		}
+	}
+}
+
+func TestAddFeed_SameURLTwice_OneFeedTwoFollows(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{}
+	store := newFakeQuerier()
+	feedURL := "https://example.com/feed.xml"
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feedURL, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: "Example"}})
+
+	var feedIDs []uuid.UUID
+	for i, userID := range []uuid.UUID{uuid.New(), uuid.New()} {
+		existingFeed, parsedFeed, resolvedURL, err := findOrParseFeed(ctx, store, fetcher, feedURL)
+		if err != nil {
+			t.Fatalf("Expected lookup %d to succeed, got %v", i+1, err)
+		}
+
+		feed, follow, feedCreated, err := cfg.addFeed(ctx, store, userID, "Example", resolvedURL, existingFeed, parsedFeed)
+		if err != nil {
+			t.Fatalf("Expected create %d to succeed, got %v", i+1, err)
+		}
+		if feedCreated != (i == 0) {
+			t.Errorf("Expected feedCreated %v for create %d, got %v", i == 0, i+1, feedCreated)
+		}
+		if follow.UserID != userID || follow.FeedID != feed.ID {
+			t.Errorf("Expected a follow of feed %s by user %s, got %+v", feed.ID, userID, follow)
+		}
+		feedIDs = append(feedIDs, feed.ID)
+	}
+
+	if len(store.feeds) != 1 {
+		t.Errorf("Expected 1 feed, got %d", len(store.feeds))
+	}
+	if feedIDs[0] != feedIDs[1] {
+		t.Errorf("Expected both users to follow the same feed, got %v", feedIDs)
+	}
+	if len(store.follows) != 2 || store.feeds[feedIDs[0]].FollowerCount != 2 {
+		t.Errorf("Expected 2 follows of the feed, got %d follows and follower count %d", len(store.follows), store.feeds[feedIDs[0]].FollowerCount)
+	}
+	if fetcher.Calls(feedURL) != 1 {
+		t.Errorf("Expected the feed to be fetched only for the first create, got %d fetches", fetcher.Calls(feedURL))
+	}
+}
+
+func TestAddFeed_CreatedConcurrently_FollowsExistingFeed(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{}
+	store := newFakeQuerier()
+	feedURL := "https://example.com/feed.xml"
+	parsedFeed := &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: "Example"}}
+
+	// Both requests missed the lookup before either created the feed
+	first, _, _, err := cfg.addFeed(ctx, store, uuid.New(), "Example", feedURL, nil, parsedFeed)
+	if err != nil {
+		t.Fatalf("Expected the first create to succeed, got %v", err)
+	}
+	second, _, feedCreated, err := cfg.addFeed(ctx, store, uuid.New(), "Example", feedURL, nil, parsedFeed)
+	if err != nil {
+		t.Fatalf("Expected the second create to follow the existing feed, got %v", err)
+	}
+
+	if feedCreated || second.ID != first.ID {
+		t.Errorf("Expected the second create to reuse feed %s, got %s (created %v)", first.ID, second.ID, feedCreated)
+	}
+	if len(store.feeds) != 1 {
+		t.Errorf("Expected 1 feed, got %d", len(store.feeds))
+	}
+}
+
+// feedPriorityRequest builds a PUT /v1/feed/{feedID}/priority request
+func feedPriorityRequest(feedID uuid.UUID, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, "/v1/feed/"+feedID.String()+"/priority", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", feedID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandlerUpdateFeedPriority_InvalidRequests(t *testing.T) {
+	owner := database.User{ID: uuid.New()}
+	feed := database.Feed{ID: uuid.New(), UserID: owner.ID, Priority: 3}
+
+	testCases := []struct {
+		name           string
+		user           database.User
+		feedID         uuid.UUID
+		body           string
+		expectedStatus int
+	}{
+		{"Priority too low", owner, feed.ID, `{"priority":0}`, http.StatusUnprocessableEntity},
+		{"Priority too high", owner, feed.ID, `{"priority":6}`, http.StatusUnprocessableEntity},
+		{"Missing priority", owner, feed.ID, `{}`, http.StatusUnprocessableEntity},
+		{"Not a number", owner, feed.ID, `{"priority":"high"}`, http.StatusBadRequest},
+		{"Not the owner", database.User{ID: uuid.New()}, feed.ID, `{"priority":5}`, http.StatusForbidden},
+		{"Unknown feed", owner, uuid.New(), `{"priority":5}`, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeQuerier()
+			store.addFeeds(feed)
+			rec := httptest.NewRecorder()
+
+			respondToFeedPriorityUpdate(rec, feedPriorityRequest(tc.feedID, tc.body), tc.user, store)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+			if got := store.feeds[feed.ID].Priority; got != 3 {
+				t.Errorf("Expected the priority to stay 3, got %d", got)
+			}
+		})
+	}
+}
+
+func TestHandlerUpdateFeedPriority_ReordersFeeds(t *testing.T) {
+	owner := database.User{ID: uuid.New()}
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	busy := database.Feed{ID: uuid.New(), UserID: uuid.New(), Name: "Busy", Priority: 4, UpdatedAt: created}
+	quiet := database.Feed{ID: uuid.New(), UserID: owner.ID, Name: "Quiet", Priority: 3, UpdatedAt: created}
+	store := newFakeQuerier()
+	store.addFeeds(busy, quiet)
+	rec := httptest.NewRecorder()
+
+	respondToFeedPriorityUpdate(rec, feedPriorityRequest(quiet.ID, `{"priority":5}`), owner, store)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Priority int `json:"priority"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Expected a JSON feed, got %v", err)
+	}
+	if response.Priority != 5 {
+		t.Errorf("Expected priority 5 in the response, got %d", response.Priority)
+	}
+
+	feeds, err := store.GetFeedsByPriority(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if feeds[0].ID != quiet.ID {
+		t.Errorf("Expected the reprioritized feed to be scraped first, got %s", feeds[0].Name)
+	}
+}
+
+func TestHandlerCreateFeed_MalformedVersusInvalidBody(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedField  string
+	}{
+		{"Malformed JSON", `{"url":`, http.StatusBadRequest, ""},
+		{"Wrong JSON type", `{"url":["https://example.com/feed.xml"]}`, http.StatusBadRequest, ""},
+		{"Empty URL", `{"name":"Example","url":""}`, http.StatusUnprocessableEntity, "url"},
+		{"Unsupported scheme", `{"name":"Example","url":"ftp://example.com/feed.xml"}`, http.StatusUnprocessableEntity, "url"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			req := httptest.NewRequest(http.MethodPost, "/v1/feed", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			cfg.HandlerCreateFeed(rec, req, database.User{ID: uuid.New()})
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body["field"] != tc.expectedField {
+				t.Errorf("Expected field %q, got %q", tc.expectedField, body["field"])
+			}
+		})
+	}
+}
+
+// newFeedDeleteStore returns a store with a feed owned by owner and followed by
+// owner and follower, and another feed followed by follower
+func newFeedDeleteStore(owner, follower uuid.UUID) (*fakeQuerier, database.Feed, database.Feed) {
+	feed := database.Feed{ID: uuid.New(), UserID: owner, Name: "Go Blog"}
+	other := database.Feed{ID: uuid.New(), UserID: follower, Name: "Rust Blog"}
+	store := newFakeQuerier()
+	store.addFeeds(feed, other)
+	store.posts = []database.Post{
+		{ID: uuid.New(), FeedID: feed.ID},
+		{ID: uuid.New(), FeedID: feed.ID},
+		{ID: uuid.New(), FeedID: other.ID},
+	}
+	store.follows = []database.FeedFollow{
+		{ID: uuid.New(), UserID: owner, FeedID: feed.ID},
+		{ID: uuid.New(), UserID: follower, FeedID: feed.ID},
+		{ID: uuid.New(), UserID: follower, FeedID: other.ID},
+	}
+	return store, feed, other
+}
+
+func TestDeleteFeed_Owner_DeletesPostsAndFollows(t *testing.T) {
+	owner, follower := uuid.New(), uuid.New()
+	store, feed, other := newFeedDeleteStore(owner, follower)
+
+	followerIDs, err := deleteFeed(context.Background(), store, owner, feed.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := store.feeds[feed.ID]; ok {
+		t.Error("Expected the feed to be deleted")
+	}
+	for _, post := range store.posts {
+		if post.FeedID == feed.ID {
+			t.Errorf("Expected post %s of the deleted feed to be gone", post.ID)
+		}
+	}
+	for _, follow := range store.follows {
+		if follow.FeedID == feed.ID {
+			t.Errorf("Expected follow %s of the deleted feed to be gone", follow.ID)
+		}
+	}
+	if len(followerIDs) != 2 {
+		t.Errorf("Expected both followers to be returned, got %v", followerIDs)
+	}
+	if _, ok := store.feeds[other.ID]; !ok || len(store.posts) != 1 || len(store.follows) != 1 {
+		t.Error("Expected the other feed, its post and its follow to be kept")
+	}
+}
+
+func TestDeleteFeed_NotOwner_ReturnsNotFound(t *testing.T) {
+	owner, follower := uuid.New(), uuid.New()
+	store, feed, _ := newFeedDeleteStore(owner, follower)
+
+	testCases := []struct {
+		name   string
+		userID uuid.UUID
+		feedID uuid.UUID
+	}{
+		{"Follower of someone else's feed", follower, feed.ID},
+		{"Unknown feed", owner, uuid.New()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := deleteFeed(context.Background(), store, tc.userID, tc.feedID)
+			if !errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+				t.Errorf("Expected apperr.ErrNotFound, got %v", err)
+			}
+			if len(store.feeds) != 2 || len(store.posts) != 3 || len(store.follows) != 3 {
+				t.Error("Expected nothing to be deleted")
+			}
+		})
+	}
+}
+
+func TestHandlerDeleteFeed_InvalidID_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodDelete, "/v1/feed/not-a-uuid", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", "not-a-uuid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerDeleteFeed(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestAddFeed_RedirectingURL_StoredUnderFinalURL(t *testing.T) {
+	server := newStubFeedServer(t, 1)
+	redirector := httptest.NewServer(http.RedirectHandler(server.URL+"/feed.xml", http.StatusMovedPermanently))
+	t.Cleanup(redirector.Close)
+
+	ctx := context.Background()
+	cfg := &Config{}
+	store := newFakeQuerier()
+	fetcher := feedfetch.NewGofeedFetcher()
+	finalURL := server.URL + "/feed.xml"
+
+	var feedIDs []uuid.UUID
+	for i, submittedURL := range []string{redirector.URL + "/old-feed", finalURL, redirector.URL + "/old-feed"} {
+		existingFeed, parsedFeed, resolvedURL, err := findOrParseFeed(ctx, store, fetcher, submittedURL)
+		if err != nil {
+			t.Fatalf("Expected lookup %d to succeed, got %v", i+1, err)
+		}
+		if resolvedURL != finalURL {
+			t.Errorf("Expected lookup %d to resolve to %s, got %s", i+1, finalURL, resolvedURL)
+		}
+
+		feed, _, _, err := cfg.addFeed(ctx, store, uuid.New(), "Example", resolvedURL, existingFeed, parsedFeed)
+		if err != nil {
+			t.Fatalf("Expected create %d to succeed, got %v", i+1, err)
+		}
+		feedIDs = append(feedIDs, feed.ID)
+	}
+
+	if len(store.feeds) != 1 {
+		t.Fatalf("Expected 1 feed, got %d", len(store.feeds))
+	}
+	if _, err := store.GetFeedByURL(ctx, finalURL); err != nil {
+		t.Errorf("Expected the feed to be stored under %s, got %v", finalURL, store.feeds)
+	}
+	if feedIDs[0] != feedIDs[1] || feedIDs[1] != feedIDs[2] {
+		t.Errorf("Expected every submission to follow the same feed, got %v", feedIDs)
+	}
+}
+
+func TestCheckRedirectedFeedURL(t *testing.T) {
+	cfg := &Config{BlockPrivateFeedHosts: true, HostResolver: testResolver, FeedDomainBlocklist: []string{"blocked.example"}}
+
+	testCases := []struct {
+		name        string
+		resolvedURL string
+		expectError bool
+	}{
+		{"No redirect", "https://blog.example.com/feed", false},
+		{"Public host", "https://blog.example.com/new-feed", false},
+		{"Private host", "http://intranet/feed", true},
+		{"Blocked domain", "https://blocked.example/feed", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := cfg.checkRedirectedFeedURL(context.Background(), "https://blog.example.com/feed", tc.resolvedURL)
+			if tc.expectError && err == nil {
+				t.Error("Expected an error")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// feedsRequest builds a GET /v1/feed request with the given query string
+func feedsRequest(query string) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/v1/feed"+query, nil)
+}
+
+func TestHandlerGetFeed_LimitCapping(t *testing.T) {
+	testCases := []struct {
+		query         string
+		expectedLimit int32
+	}{
+		{"", 20},
+		{"?limit=5", 5},
+		{"?limit=500", 100},
+		{"?limit=0", 20},
+		{"?limit=abc", 20},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			store := newFakeQuerier()
+			rec := httptest.NewRecorder()
+
+			respondWithFeedsPage(rec, feedsRequest(tc.query), store, nil)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			if store.lastFeedsArg.RowLimit != tc.expectedLimit {
+				t.Errorf("Expected limit %d, got %d", tc.expectedLimit, store.lastFeedsArg.RowLimit)
+			}
+		})
+	}
+}
+
+func TestHandlerGetFeed_Search(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeQuerier()
+	store.addFeeds(
+		database.Feed{ID: uuid.New(), Name: "Go Blog", Description: sql.NullString{String: "News about Go", Valid: true}, CreatedAt: created},
+		database.Feed{ID: uuid.New(), Name: "Rust Weekly", CreatedAt: created.Add(time.Minute)},
+		database.Feed{ID: uuid.New(), Name: "100% Test Coverage", CreatedAt: created.Add(2 * time.Minute)},
+		database.Feed{ID: uuid.New(), Name: "1000 Tips", CreatedAt: created.Add(3 * time.Minute)},
+	)
+
+	testCases := []struct {
+		search   string
+		expected []string
+	}{
+		{"", []string{"1000 Tips", "100% Test Coverage", "Rust Weekly", "Go Blog"}},
+		{"rust", []string{"Rust Weekly"}},
+		{"NEWS", []string{"Go Blog"}},
+		{"100%", []string{"100% Test Coverage"}},
+		{"_", nil},
+		{"python", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.search, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			respondWithFeedsPage(rec, feedsRequest("?search="+url.QueryEscape(tc.search)), store, nil)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			var response feedsResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var names []string
+			for _, feed := range response.Feeds {
+				names = append(names, feed.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestHandlerGetFeed_NextCursor_PagesThroughFeeds(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	store := newFakeQuerier()
+	for i := 0; i < 3; i++ {
+		store.addFeeds(database.Feed{ID: uuid.New(), Name: fmt.Sprintf("Feed %d", i), CreatedAt: created.Add(time.Duration(i) * time.Millisecond)})
+	}
+
+	var names []string
+	query := "?limit=2"
+	for page := 0; page < 3; page++ {
+		rec := httptest.NewRecorder()
+		respondWithFeedsPage(rec, feedsRequest(query), store, nil)
+
+		var response feedsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		for _, feed := range response.Feeds {
+			names = append(names, feed.Name)
+		}
+		if response.NextCursor == "" {
+			break
+		}
+		query = "?limit=2&cursor=" + url.QueryEscape(response.NextCursor)
+	}
+
+	expected := []string{"Feed 2", "Feed 1", "Feed 0"}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v across pages, got %v", expected, names)
+	}
+}
+
+// decodeFeedsByName decodes a feeds page into its feeds keyed by name
+func decodeFeedsByName(t *testing.T, rec *httptest.ResponseRecorder) map[string]map[string]any {
+	t.Helper()
+	var response struct {
+		Feeds []map[string]any `json:"feeds"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	feeds := make(map[string]map[string]any)
+	for _, feed := range response.Feeds {
+		feeds[feed["name"].(string)] = feed
+	}
+	return feeds
+}
+
+func TestHandlerGetFeed_FollowerCountAndIsFollowed(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := database.User{ID: uuid.New()}
+	popular := database.Feed{ID: uuid.New(), Name: "Popular", CreatedAt: created, FollowerCount: 1}
+	quiet := database.Feed{ID: uuid.New(), Name: "Quiet", CreatedAt: created.Add(time.Minute)}
+	store := newFakeQuerier()
+	store.addFeeds(popular, quiet)
+	for _, userID := range []uuid.UUID{user.ID, uuid.New(), uuid.New()} {
+		store.follow(userID, popular.ID)
+	}
+
+	t.Run("authenticated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		respondWithFeedsPage(rec, feedsRequest(""), store, &user)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		if !store.lastFeedsArg.UserID.Valid || store.lastFeedsArg.UserID.UUID != user.ID {
+			t.Errorf("Expected the listing for user %s, got %+v", user.ID, store.lastFeedsArg.UserID)
+		}
+		feeds := decodeFeedsByName(t, rec)
+		testCases := []struct {
+			name              string
+			expectedFollowers float64
+			expectedFollowed  bool
+		}{
+			{"Popular", 3, true},
+			{"Quiet", 0, false},
+		}
+		for _, tc := range testCases {
+			feed := feeds[tc.name]
+			if feed["follower_count"] != tc.expectedFollowers {
+				t.Errorf("Expected %s to have follower_count %v, got %v", tc.name, tc.expectedFollowers, feed["follower_count"])
+			}
+			if feed["is_followed"] != tc.expectedFollowed {
+				t.Errorf("Expected %s to have is_followed %v, got %v", tc.name, tc.expectedFollowed, feed["is_followed"])
+			}
+		}
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		respondWithFeedsPage(rec, feedsRequest(""), store, nil)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		if store.lastFeedsArg.UserID.Valid {
+			t.Errorf("Expected no user for an anonymous listing, got %v", store.lastFeedsArg.UserID.UUID)
+		}
+		feeds := decodeFeedsByName(t, rec)
+		for name, feed := range feeds {
+			if _, ok := feed["is_followed"]; ok {
+				t.Errorf("Expected no is_followed on %s for an anonymous listing, got %v", name, feed["is_followed"])
+			}
+		}
+		if count := feeds["Popular"]["follower_count"]; count != float64(3) {
+			t.Errorf("Expected Popular to have follower_count 3, got %v", count)
+		}
+	})
+}
+
+func TestFeedSearchPattern(t *testing.T) {
+	testCases := []struct {
+		search   string
+		expected sql.NullString
+	}{
+		{"", sql.NullString{}},
+		{"   ", sql.NullString{}},
+		{" Go ", sql.NullString{String: "%Go%", Valid: true}},
+		{"100%", sql.NullString{String: `%100\%%`, Valid: true}},
+		{`a_b\c`, sql.NullString{String: `%a\_b\\c%`, Valid: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.search, func(t *testing.T) {
+			if got := feedSearchPattern(tc.search); got != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -1,22 +1,97 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
 )
 
+// readinessCheckTimeout bounds each dependency check run by the readiness endpoint
+const readinessCheckTimeout = 2 * time.Second
+
+// dependencyCheck is the result of a single readiness dependency check
+type dependencyCheck struct {
+	Status     string  `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type readinessResponse struct {
+	Status string                     `json:"status"`
+	Checks map[string]dependencyCheck `json:"checks"`
+}
+
+// HandlerLiveness checks if the server process is up
+// Lightweight liveness probe - never touches dependencies
+// @Summary     Liveness check
+// @Description Checks if the server process is running. Does not check dependencies.
+// @Tags        health
+// @Accept      json
+// @Produce     json
+// @Success     200  {object}  map[string]interface{}
+// @Router      /v1/live [get]
+func HandlerLiveness(w http.ResponseWriter, r *http.Request) {
+	models.RespondWithJSON(w, http.StatusOK, struct{}{})
+}
+
 // HandlerReadiness checks if the server is ready
-// Health check endpoint - verifies the server is running
+// Health check endpoint - verifies dependencies respond and reports how long each took
 // @Summary     Health check
-// @Description Checks if the server is ready to handle requests
+// @Description Checks if the server and its dependencies are ready to handle requests, reporting each check's status and duration
 // @Tags        health
 // @Accept      json
 // @Produce     json
 // @Success     200  {object}  map[string]interface{}
+// @Failure     503  {object}  map[string]interface{}
 // @Router      /v1/ready [get]
-func HandlerReadiness(w http.ResponseWriter, r *http.Request) {
-	models.RespondWithJSON(w, http.StatusOK, struct{}{})
+func (cfg *Config) HandlerReadiness(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]func(context.Context) error{
+		"database": cfg.DBConn.PingContext,
+	}
+
+	response := readinessResponse{
+		Status: "ok",
+		Checks: make(map[string]dependencyCheck, len(checks)),
+	}
+
+	for name, check := range checks {
+		result, err := runDependencyCheck(r.Context(), check)
+		if err != nil {
+			// Keep dependency error details out of the public response
+			logger.ErrorErr(err, "Readiness check failed: "+name)
+			response.Status = "unavailable"
+		}
+		response.Checks[name] = result
+	}
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+
+	models.RespondWithJSON(w, status, response)
+}
+
+// runDependencyCheck runs a single check with a timeout and measures its duration
+func runDependencyCheck(ctx context.Context, check func(context.Context) error) (dependencyCheck, error) {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	duration := time.Since(start)
+
+	result := dependencyCheck{
+		Status:     "ok",
+		DurationMs: float64(duration.Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "error"
+	}
+
+	return result, err
 }
 
 // HandlerErr is a test error handler
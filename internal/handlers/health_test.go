@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubDriver is a database/sql driver whose connections only support Ping
+type stubDriver struct{}
+
+type stubConn struct {
+	pingErr error
+}
+
+func (stubDriver) Open(name string) (driver.Conn, error) {
+	if name == "down" {
+		return &stubConn{pingErr: errors.New("connection refused")}, nil
+	}
+	return &stubConn{}, nil
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *stubConn) Close() error { return nil }
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *stubConn) Ping(ctx context.Context) error { return c.pingErr }
+
+func init() {
+	sql.Register("stubdb", stubDriver{})
+}
+
+func openStubDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("stubdb", name)
+	if err != nil {
+		t.Fatalf("Failed to open stub DB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestHandlerReadiness_HealthyDB_ReportsDurations(t *testing.T) {
+	cfg := &Config{DBConn: openStubDB(t, "up")}
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerReadiness(rec, httptest.NewRequest(http.MethodGet, "/v1/ready", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	checks, ok := response["checks"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected checks object, got %v", response["checks"])
+	}
+
+	database, ok := checks["database"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected database check, got %v", checks)
+	}
+
+	duration, ok := database["duration_ms"].(float64)
+	if !ok {
+		t.Fatalf("Expected numeric duration_ms, got %v", database["duration_ms"])
+	}
+
+	if duration < 0 {
+		t.Errorf("Expected non-negative duration, got %f", duration)
+	}
+
+	if database["status"] != "ok" {
+		t.Errorf("Expected database status ok, got %v", database["status"])
+	}
+}
+
+func TestHandlerReadiness_DBDown_ReturnsUnavailable(t *testing.T) {
+	cfg := &Config{DBConn: openStubDB(t, "down")}
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerReadiness(rec, httptest.NewRequest(http.MethodGet, "/v1/ready", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	var response readinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Checks["database"].Status != "error" {
+		t.Errorf("Expected database status error, got %s", response.Checks["database"].Status)
+	}
+
+	if response.Checks["database"].DurationMs < 0 {
+		t.Errorf("Expected non-negative duration, got %f", response.Checks["database"].DurationMs)
+	}
+}
+
+func TestHandlerLiveness_ReturnsOK(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	HandlerLiveness(rec, httptest.NewRequest(http.MethodGet, "/v1/live", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+var errLastLoginMethod = errors.New("cannot unlink the last login method")
+
+// HandlerListIdentities returns the login methods of the authenticated user
+// @Summary     List login methods
+// @Description Lists the OAuth identities linked to the user and whether a password is set
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Success     200  {object}  object  "Login methods"
+// @Failure     401  {object}  object  "Unauthorized"
+// @Failure     500  {object}  object  "Server error"
+// @Router      /v1/users/me/identities [get]
+func (cfg *Config) HandlerListIdentities(w http.ResponseWriter, r *http.Request, user database.User) {
+	identities, err := cfg.DB.ListIdentitiesByUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithDBError(w, err, "List identities")
+		return
+	}
+
+	type response struct {
+		Password   bool              `json:"password"`
+		Identities []models.Identity `json:"identities"`
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, response{
+		Password:   user.PasswordHash.Valid,
+		Identities: models.DatabaseAllIdentityToAllIdentity(identities),
+	})
+}
+
+// HandlerUnlinkIdentity removes an OAuth identity from the authenticated user
+// Refuses to remove the user's last way to log in
+// @Summary     Unlink a login method
+// @Description Unlinks an OAuth identity. Fails if it is the user's only login method.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       identityID  path      string  true  "Identity ID"
+// @Success     204         {object}  object  "Identity unlinked"
+// @Failure     400         {object}  object  "Invalid ID"
+// @Failure     404         {object}  object  "Identity not found"
+// @Failure     409         {object}  object  "Last login method"
+// @Failure     500         {object}  object  "Server error"
+// @Router      /v1/users/me/identities/{identityID} [delete]
+func (cfg *Config) HandlerUnlinkIdentity(w http.ResponseWriter, r *http.Request, user database.User) {
+	identityID, err := uuid.Parse(chi.URLParam(r, "identityID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid identity ID: %v", err))
+		return
+	}
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithDBError(w, err, "Start transaction")
+		return
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback transaction")
+		}
+	}()
+
+	err = unlinkIdentity(r.Context(), cfg.DB.WithTx(tx), user, identityID)
+	if errors.Is(err, errLastLoginMethod) {
+		models.RespondWithError(w, http.StatusConflict, "Cannot unlink your only login method")
+		return
+	}
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusNotFound, "Identity not found")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Unlink identity")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithDBError(w, err, "Commit transaction")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusNoContent, struct{}{})
+}
+
+// unlinkIdentity deletes the user's identity unless it is their last login method.
+// The user's row is locked first so two concurrent unlinks can't each see the other
+// identity as the remaining method and leave the account without any.
+func unlinkIdentity(ctx context.Context, store database.Querier, user database.User, identityID uuid.UUID) error {
+	if err := store.LockUserForUpdate(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if _, err := store.GetIdentityForUser(ctx, database.GetIdentityForUserParams{ID: identityID, UserID: user.ID}); err != nil {
+		return err
+	}
+
+	count, err := store.CountIdentitiesByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if !user.PasswordHash.Valid && count <= 1 {
+		return errLastLoginMethod
+	}
+
+	return store.DeleteIdentity(ctx, database.DeleteIdentityParams{ID: identityID, UserID: user.ID})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// loginWith resolves an OAuth login against the store and fails the test on error
+func loginWith(t *testing.T, store *fakeQuerier, provider, providerUserID, email string) database.User {
+	t.Helper()
+	user, err := resolveOAuthUser(context.Background(), store, provider, auth.OAuthIdentity{
+		ProviderUserID: providerUserID,
+		Email:          email,
+		EmailVerified:  true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return user
+}
+
+func TestResolveOAuthUser_SecondProviderSameEmail_LinksToSameUser(t *testing.T) {
+	store := newFakeQuerier()
+
+	googleUser := loginWith(t, store, "google", "g-1", "user@example.com")
+	githubUser := loginWith(t, store, "github", "gh-1", "user@example.com")
+
+	if googleUser.ID != githubUser.ID {
+		t.Errorf("Expected both providers to link to one user, got %s and %s", googleUser.ID, githubUser.ID)
+	}
+
+	if count, _ := store.CountIdentitiesByUser(context.Background(), googleUser.ID); count != 2 {
+		t.Errorf("Expected 2 identities, got %d", count)
+	}
+}
+
+func TestUnlinkIdentity_OnlyLoginMethod_Refused(t *testing.T) {
+	store := newFakeQuerier()
+	user := loginWith(t, store, "google", "g-1", "user@example.com")
+
+	err := unlinkIdentity(context.Background(), store, user, store.identities[0].ID)
+
+	if !errors.Is(err, errLastLoginMethod) {
+		t.Errorf("Expected errLastLoginMethod, got %v", err)
+	}
+
+	if len(store.identities) != 1 {
+		t.Error("Expected identity to be kept")
+	}
+}
+
+func TestUnlinkIdentity_PasswordUser_CanUnlinkOnlyIdentity(t *testing.T) {
+	existing := database.User{
+		ID:           uuid.New(),
+		Email:        sql.NullString{String: "user@example.com", Valid: true},
+		PasswordHash: sql.NullString{String: "hash", Valid: true},
+	}
+	store := newFakeQuerier()
+	store.addUsers(existing)
+	user := loginWith(t, store, "google", "g-1", "user@example.com")
+
+	if err := unlinkIdentity(context.Background(), store, user, store.identities[0].ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(store.identities) != 0 {
+		t.Errorf("Expected identity to be removed, got %d left", len(store.identities))
+	}
+}
+
+func TestUnlinkIdentity_TwoIdentities_KeepsTheLastOne(t *testing.T) {
+	store := newFakeQuerier()
+	user := loginWith(t, store, "google", "g-1", "user@example.com")
+	loginWith(t, store, "github", "gh-1", "user@example.com")
+
+	if err := unlinkIdentity(context.Background(), store, user, store.identities[0].ID); err != nil {
+		t.Fatalf("Expected first unlink to succeed, got %v", err)
+	}
+
+	if err := unlinkIdentity(context.Background(), store, user, store.identities[0].ID); !errors.Is(err, errLastLoginMethod) {
+		t.Errorf("Expected second unlink to be refused, got %v", err)
+	}
+}
+
+func TestUnlinkIdentity_OtherUsersIdentity_NotFound(t *testing.T) {
+	store := newFakeQuerier()
+	loginWith(t, store, "google", "g-1", "owner@example.com")
+	other := loginWith(t, store, "google", "g-2", "other@example.com")
+
+	err := unlinkIdentity(context.Background(), store, other, store.identities[0].ID)
+
+	if !errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		t.Errorf("Expected apperr.ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/reconcile"
+)
+
+// duplicateFeedGroup is a set of feeds whose URLs normalize to the same URL
+type duplicateFeedGroup struct {
+	URL        string
+	Canonical  database.Feed
+	Duplicates []database.Feed
+}
+
+type feedMergeResult struct {
+	Groups         int
+	FeedsDeleted   int
+	FollowsMoved   int
+	FollowsDropped int
+	PostsMoved     int
+}
+
+type feedMergeResponse struct {
+	Groups         models.Count `json:"groups"`
+	FeedsDeleted   models.Count `json:"feeds_deleted"`
+	FollowsMoved   models.Count `json:"follows_moved"`
+	FollowsDropped models.Count `json:"follows_dropped"`
+	PostsMoved     models.Count `json:"posts_moved"`
+}
+
+// HandlerMergeDuplicateFeeds merges feeds created with the same normalized URL
+// before feed creation deduplicated them
+// @Summary     Merge duplicate feeds
+// @Description Admin only. Finds feeds whose URLs normalize to the same URL, keeps the oldest, moves follows, posts and activity of the others onto it and deletes them. Runs in one transaction.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Merge summary"
+// @Failure     401     {object}  object  "Unauthorized"
+// @Failure     403     {object}  object  "Admin access required"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/admin/feeds/merge-duplicates [post]
+func (cfg *Config) HandlerMergeDuplicateFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithDBError(w, err, "Start transaction")
+		return
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback transaction")
+		}
+	}()
+
+	qtx := cfg.DB.WithTx(tx)
+
+	result, err := mergeDuplicateFeeds(r.Context(), qtx)
+	if err != nil {
+		respondWithDBError(w, err, "Merge duplicate feeds")
+		return
+	}
+
+	// Moved and dropped follows change the canonical feeds' follower counts
+	if _, err := qtx.ReconcileFeedFollowerCounts(r.Context()); err != nil {
+		respondWithDBError(w, err, "Reconcile follower counts")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithDBError(w, err, "Commit transaction")
+		return
+	}
+
+	cfg.Logger.Info().
+		Str("admin_id", user.ID.String()).
+		Int("groups", result.Groups).
+		Int("feeds_deleted", result.FeedsDeleted).
+		Msg("Merged duplicate feeds")
+
+	models.RespondWithJSON(w, http.StatusOK, newFeedMergeResponse(r, result))
+}
+
+func newFeedMergeResponse(r *http.Request, result feedMergeResult) feedMergeResponse {
+	return feedMergeResponse{
+		Groups:         models.NewCount(r, int64(result.Groups)),
+		FeedsDeleted:   models.NewCount(r, int64(result.FeedsDeleted)),
+		FollowsMoved:   models.NewCount(r, int64(result.FollowsMoved)),
+		FollowsDropped: models.NewCount(r, int64(result.FollowsDropped)),
+		PostsMoved:     models.NewCount(r, int64(result.PostsMoved)),
+	}
+}
+
+// HandlerReconcileFollowerCounts recomputes the follower counts cached on feeds
+// @Summary     Reconcile follower counts
+// @Description Admin only. Recomputes every feed's cached follower count from its follows and corrects the ones that drifted. The same job also runs periodically.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Number of feeds corrected"
+// @Failure     401     {object}  object  "Unauthorized"
+// @Failure     403     {object}  object  "Admin access required"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/admin/feeds/reconcile-follower-counts [post]
+func (cfg *Config) HandlerReconcileFollowerCounts(w http.ResponseWriter, r *http.Request, user database.User) {
+	corrected, err := cfg.DB.ReconcileFeedFollowerCounts(r.Context())
+	if err != nil {
+		respondWithDBError(w, err, "Reconcile follower counts")
+		return
+	}
+
+	cfg.Logger.Info().
+		Str("admin_id", user.ID.String()).
+		Int64("feeds_corrected", corrected).
+		Msg("Reconciled feed follower counts")
+
+	models.RespondWithJSON(w, http.StatusOK, struct {
+		FeedsCorrected models.Count `json:"feeds_corrected"`
+	}{
+		FeedsCorrected: models.NewCount(r, corrected),
+	})
+}
+
+// HandlerDeleteOrphanedPosts deletes posts whose feed no longer exists
+// @Summary     Delete orphaned posts
+// @Description Admin only. Deletes posts whose feed was deleted without cascading to them, in batches to avoid long locks.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Number of posts deleted"
+// @Failure     401     {object}  object  "Unauthorized"
+// @Failure     403     {object}  object  "Admin access required"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/admin/posts/delete-orphans [post]
+func (cfg *Config) HandlerDeleteOrphanedPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	deleted, err := reconcile.NewOrphanedPosts(cfg.DB, cfg.Logger).Run(r.Context())
+	if err != nil {
+		respondWithDBError(w, err, "Delete orphaned posts")
+		return
+	}
+
+	cfg.Logger.Info().
+		Str("admin_id", user.ID.String()).
+		Int64("posts_deleted", deleted).
+		Msg("Ran orphaned post cleanup")
+
+	models.RespondWithJSON(w, http.StatusOK, struct {
+		PostsDeleted models.Count `json:"posts_deleted"`
+	}{
+		PostsDeleted: models.NewCount(r, deleted),
+	})
+}
+
+// findDuplicateFeeds groups feeds by normalized URL and returns the groups with
+// more than one feed. The oldest feed of each group is its canonical feed.
+func findDuplicateFeeds(feeds []database.Feed) []duplicateFeedGroup {
+	byURL := make(map[string][]database.Feed)
+	for _, feed := range feeds {
+		normalizedURL, err := normalizeFeedURL(feed.Url)
+		if err != nil {
+			normalizedURL = feed.Url
+		}
+		byURL[normalizedURL] = append(byURL[normalizedURL], feed)
+	}
+
+	groups := make([]duplicateFeedGroup, 0)
+	for normalizedURL, group := range byURL {
+		if len(group) < 2 {
+			continue
+		}
+
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].CreatedAt.Equal(group[j].CreatedAt) {
+				return group[i].CreatedAt.Before(group[j].CreatedAt)
+			}
+			return group[i].ID.String() < group[j].ID.String()
+		})
+
+		groups = append(groups, duplicateFeedGroup{
+			URL:        normalizedURL,
+			Canonical:  group[0],
+			Duplicates: group[1:],
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].URL < groups[j].URL })
+	return groups
+}
+
+// mergeDuplicateFeeds repoints everything that references a duplicate feed to its
+// canonical feed, then deletes the duplicate. It must run in a transaction so a
+// failure halfway leaves no feed partially merged.
+//
+// A user following both feeds keeps only the canonical follow, since a user can
+// follow a feed once. Posts can be moved as they are: post URLs are unique, so the
+// same article is never stored under both feeds. Activity buckets are summed.
+func mergeDuplicateFeeds(ctx context.Context, store database.Querier) (feedMergeResult, error) {
+	feeds, err := store.GetFeeds(ctx)
+	if err != nil {
+		return feedMergeResult{}, err
+	}
+
+	result := feedMergeResult{}
+	for _, group := range findDuplicateFeeds(feeds) {
+		canonicalID := group.Canonical.ID
+
+		for _, duplicate := range group.Duplicates {
+			dropped, err := store.DeleteFeedFollowsAlreadyOnFeed(ctx, database.DeleteFeedFollowsAlreadyOnFeedParams{
+				FromFeedID: duplicate.ID,
+				ToFeedID:   canonicalID,
+			})
+			if err != nil {
+				return feedMergeResult{}, err
+			}
+
+			moved, err := store.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{
+				FromFeedID: duplicate.ID,
+				ToFeedID:   canonicalID,
+			})
+			if err != nil {
+				return feedMergeResult{}, err
+			}
+
+			posts, err := store.MovePosts(ctx, database.MovePostsParams{
+				FromFeedID: duplicate.ID,
+				ToFeedID:   canonicalID,
+			})
+			if err != nil {
+				return feedMergeResult{}, err
+			}
+
+			if err := store.MergeFeedActivity(ctx, database.MergeFeedActivityParams{
+				FromFeedID: duplicate.ID,
+				ToFeedID:   canonicalID,
+			}); err != nil {
+				return feedMergeResult{}, err
+			}
+
+			// Whatever still references the duplicate (its activity buckets) is cascaded
+			if err := store.DeleteFeed(ctx, duplicate.ID); err != nil {
+				return feedMergeResult{}, err
+			}
+
+			result.FeedsDeleted++
+			result.FollowsDropped += int(dropped)
+			result.FollowsMoved += int(moved)
+			result.PostsMoved += int(posts)
+		}
+
+		// Store the normalized URL so new submissions find the canonical feed.
+		// Safe only now that the duplicates holding the other spellings are gone.
+		if group.Canonical.Url != group.URL {
+			if err := store.UpdateFeedURL(ctx, database.UpdateFeedURLParams{ID: canonicalID, Url: group.URL}); err != nil {
+				return feedMergeResult{}, err
+			}
+		}
+
+		result.Groups++
+	}
+
+	return result, nil
+}
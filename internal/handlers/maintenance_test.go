@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestMergeDuplicateFeeds_RepointsFollowsAndPosts(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	canonical := database.Feed{ID: uuid.New(), Url: "https://Example.com/feed/", CreatedAt: base}
+	duplicate := database.Feed{ID: uuid.New(), Url: "https://example.com/feed", CreatedAt: base.Add(time.Hour)}
+	unrelated := database.Feed{ID: uuid.New(), Url: "https://other.example.com/feed", CreatedAt: base}
+
+	bothFeedsUser := uuid.New()
+	duplicateOnlyUser := uuid.New()
+
+	store := newFakeQuerier()
+	store.addFeeds(duplicate, canonical, unrelated)
+	store.follows = []database.FeedFollow{
+		{ID: uuid.New(), UserID: bothFeedsUser, FeedID: canonical.ID},
+		{ID: uuid.New(), UserID: bothFeedsUser, FeedID: duplicate.ID},
+		{ID: uuid.New(), UserID: duplicateOnlyUser, FeedID: duplicate.ID},
+	}
+	store.posts = []database.Post{
+		{ID: uuid.New(), Url: "https://example.com/a", FeedID: canonical.ID},
+		{ID: uuid.New(), Url: "https://example.com/b", FeedID: duplicate.ID},
+		{ID: uuid.New(), Url: "https://other.example.com/c", FeedID: unrelated.ID},
+	}
+	canonicalDay := activityKey{feedID: canonical.ID, granularity: "day", bucketStart: base}
+	store.activity[canonicalDay] = 2
+	store.activity[activityKey{feedID: duplicate.ID, granularity: "day", bucketStart: base}] = 3
+
+	result, err := mergeDuplicateFeeds(context.Background(), store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := feedMergeResult{Groups: 1, FeedsDeleted: 1, FollowsMoved: 1, FollowsDropped: 1, PostsMoved: 1}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	if len(store.feeds) != 2 {
+		t.Fatalf("Expected 2 feeds left, got %d", len(store.feeds))
+	}
+	if _, ok := store.feeds[duplicate.ID]; ok {
+		t.Error("Expected duplicate feed to be deleted")
+	}
+	if got := store.feeds[canonical.ID].Url; got != "https://example.com/feed" {
+		t.Errorf("Expected canonical URL to be normalized, got %q", got)
+	}
+
+	followers := make(map[uuid.UUID]int)
+	for _, follow := range store.follows {
+		if follow.FeedID != canonical.ID {
+			t.Errorf("Expected every follow to point at the canonical feed, got %s", follow.FeedID)
+		}
+		followers[follow.UserID]++
+	}
+	if followers[bothFeedsUser] != 1 || followers[duplicateOnlyUser] != 1 {
+		t.Errorf("Expected one follow per user, got %v", followers)
+	}
+
+	for _, post := range store.posts {
+		if post.FeedID == duplicate.ID {
+			t.Errorf("Expected post %s to be moved to the canonical feed", post.Url)
+		}
+	}
+	if store.posts[2].FeedID != unrelated.ID {
+		t.Error("Expected posts of unrelated feeds to be left alone")
+	}
+
+	if count := store.activity[canonicalDay]; count != 5 {
+		t.Errorf("Expected merged activity count 5, got %d", count)
+	}
+}
+
+func TestMergeDuplicateFeeds_NoDuplicates_ChangesNothing(t *testing.T) {
+	store := newFakeQuerier()
+	store.addFeeds(
+		database.Feed{ID: uuid.New(), Url: "https://example.com/feed"},
+		database.Feed{ID: uuid.New(), Url: "https://example.com/other"},
+	)
+
+	result, err := mergeDuplicateFeeds(context.Background(), store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != (feedMergeResult{}) || len(store.feeds) != 2 {
+		t.Errorf("Expected no changes, got %+v with %d feeds", result, len(store.feeds))
+	}
+}
+
+func TestFindDuplicateFeeds_PicksOldestAsCanonical(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newest := database.Feed{ID: uuid.New(), Url: "http://example.com:80/rss", CreatedAt: base.Add(2 * time.Hour)}
+	oldest := database.Feed{ID: uuid.New(), Url: "http://example.com/rss#latest", CreatedAt: base}
+	middle := database.Feed{ID: uuid.New(), Url: "HTTP://EXAMPLE.COM/rss", CreatedAt: base.Add(time.Hour)}
+
+	groups := findDuplicateFeeds([]database.Feed{newest, oldest, middle})
+
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 group, got %d", len(groups))
+	}
+
+	if groups[0].Canonical.ID != oldest.ID {
+		t.Errorf("Expected oldest feed as canonical, got %s", groups[0].Canonical.Url)
+	}
+
+	if len(groups[0].Duplicates) != 2 {
+		t.Errorf("Expected 2 duplicates, got %d", len(groups[0].Duplicates))
+	}
+}
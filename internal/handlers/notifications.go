@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+const (
+	// maxListedNotifications caps how many unacknowledged notifications one request returns
+	maxListedNotifications = 100
+	// maxAckBatchSize caps how many notifications can be acknowledged in one request
+	maxAckBatchSize = 100
+)
+
+// HandlerListNotifications returns the user's unacknowledged notifications, oldest first
+// Signals sent while the user had no WebSocket open are kept here until acknowledged.
+// @Summary     List notifications
+// @Description Lists up to 100 unacknowledged notifications (e.g. new posts in a followed feed), oldest first. Acknowledge them with POST /v1/notifications/ack.
+// @Tags        notifications
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Notifications"
+// @Failure     401     {object}  object  "Unauthorized"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/notifications [get]
+func (cfg *Config) HandlerListNotifications(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithNotifications(w, r, cfg.DB, user)
+}
+
+// respondWithNotifications writes the user's unacknowledged notifications from store
+func respondWithNotifications(w http.ResponseWriter, r *http.Request, store database.Querier, user database.User) {
+	rows, err := store.ListUnackedNotifications(r.Context(), database.ListUnackedNotificationsParams{
+		UserID:   user.ID,
+		RowLimit: maxListedNotifications,
+	})
+	if err != nil {
+		respondWithDBError(w, err, "List notifications")
+		return
+	}
+
+	notifications := make([]models.Notification, 0, len(rows))
+	for _, row := range rows {
+		notifications = append(notifications, models.DatabaseNotificationToNotification(r, row))
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, struct {
+		Notifications []models.Notification `json:"notifications"`
+	}{
+		Notifications: notifications,
+	})
+}
+
+// HandlerAckNotifications marks a batch of the user's notifications as read
+// Ids of other users' notifications, or ones already acknowledged, are ignored
+// @Summary     Acknowledge notifications
+// @Description Marks notifications as read so they are no longer listed. Ids of notifications that aren't the user's are ignored.
+// @Tags        notifications
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       ids  body      object  true  "Notification ids to acknowledge (max 100)"
+// @Success     200  {object}  object  "Number of notifications acknowledged"
+// @Failure     400  {object}  object  "Invalid input"
+// @Failure     500  {object}  object  "Server error"
+// @Router      /v1/notifications/ack [post]
+func (cfg *Config) HandlerAckNotifications(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToNotificationAck(w, r, cfg.DB, user)
+}
+
+// respondToNotificationAck acknowledges the notifications listed in the body through store
+func respondToNotificationAck(w http.ResponseWriter, r *http.Request, store database.Querier, user database.User) {
+	type parameters struct {
+		IDs []uuid.UUID `json:"ids"`
+	}
+
+	params := parameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	if len(params.IDs) == 0 {
+		models.RespondWithError(w, http.StatusBadRequest, "ids must not be empty")
+		return
+	}
+
+	if len(params.IDs) > maxAckBatchSize {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Cannot acknowledge more than %d notifications at once", maxAckBatchSize))
+		return
+	}
+
+	acked, err := store.AckNotifications(r.Context(), database.AckNotificationsParams{
+		AckedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		UserID:  user.ID,
+		Ids:     params.IDs,
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Acknowledge notifications")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, struct {
+		Acked models.Count `json:"acked"`
+	}{
+		Acked: models.NewCount(r, acked),
+	})
+}
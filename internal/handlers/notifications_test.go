@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// listNotifications lists the user's notifications through respondWithNotifications
+func listNotifications(t *testing.T, store *fakeQuerier, user database.User) []models.Notification {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/v1/notifications", nil)
+	rec := httptest.NewRecorder()
+
+	respondWithNotifications(rec, req, store, user)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var body struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return body.Notifications
+}
+
+func TestNotifications_ListThenAck_AckedAreNoLongerListed(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	first := store.addNotification(user.ID, 2)
+	second := store.addNotification(user.ID, 5)
+	store.addNotification(uuid.New(), 1) // someone else's
+
+	listed := listNotifications(t, store, user)
+	if len(listed) != 2 || listed[0].ID != first || listed[1].ID != second {
+		t.Fatalf("Expected the user's 2 notifications, got %+v", listed)
+	}
+	if listed[0].Count.Value != 2 || listed[0].FeedName != "Go Blog" || listed[0].Type != "NEW_POST_AVAILABLE" {
+		t.Errorf("Expected the stored notification fields, got %+v", listed[0])
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/notifications/ack", strings.NewReader(`{"ids":["`+first.String()+`"]}`))
+	rec := httptest.NewRecorder()
+	respondToNotificationAck(rec, req, store, user)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"acked":1`) {
+		t.Errorf("Expected 1 acknowledged notification, got %s", rec.Body.String())
+	}
+
+	listed = listNotifications(t, store, user)
+	if len(listed) != 1 || listed[0].ID != second {
+		t.Errorf("Expected only the unacknowledged notification, got %+v", listed)
+	}
+}
+
+func TestAckNotifications_AnotherUsersNotification_IsIgnored(t *testing.T) {
+	owner := uuid.New()
+	store := newFakeQuerier()
+	id := store.addNotification(owner, 1)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/notifications/ack", strings.NewReader(`{"ids":["`+id.String()+`"]}`))
+	rec := httptest.NewRecorder()
+	respondToNotificationAck(rec, req, store, database.User{ID: uuid.New()})
+
+	if !strings.Contains(rec.Body.String(), `"acked":0`) {
+		t.Errorf("Expected nothing acknowledged, got %s", rec.Body.String())
+	}
+	if listed := listNotifications(t, store, database.User{ID: owner}); len(listed) != 1 {
+		t.Errorf("Expected the owner's notification to stay listed, got %+v", listed)
+	}
+}
+
+func TestAckNotifications_InvalidInput_ReturnsBadRequest(t *testing.T) {
+	tooMany := make([]string, maxAckBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = `"` + uuid.NewString() + `"`
+	}
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"Not JSON", "nope"},
+		{"No ids", `{"ids":[]}`},
+		{"Too many ids", `{"ids":[` + strings.Join(tooMany, ",") + `]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/notifications/ack", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			respondToNotificationAck(rec, req, newFakeQuerier(), database.User{ID: uuid.New()})
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+const (
+	// oauthStateCookie carries the CSRF state between the start and callback requests
+	oauthStateCookie = "oauth_state"
+	// oauthStateTTL is how long the user has to complete the provider login
+	oauthStateTTL = 10 * time.Minute
+)
+
+var errOAuthEmailNotVerified = errors.New("oauth account has no verified email")
+
+// HandlerOAuthStart redirects the user to the provider's login page
+// @Summary     Start OAuth login
+// @Description Redirects to the OAuth provider's login page. Only configured providers are available.
+// @Tags        auth
+// @Param       provider  path  string  true  "google or github"
+// @Success     302  "Redirect to the provider"
+// @Failure     404  {object}  object  "Unknown or unconfigured provider"
+// @Router      /v1/auth/oauth/{provider} [get]
+func (cfg *Config) HandlerOAuthStart(w http.ResponseWriter, r *http.Request) {
+	provider, ok := cfg.OAuthProviders[chi.URLParam(r, "provider")]
+	if !ok {
+		models.RespondWithError(w, http.StatusNotFound, "Unknown or unconfigured OAuth provider")
+		return
+	}
+
+	state, err := auth.GenerateRefreshToken()
+	if err != nil {
+		models.RespondWithError(w, http.StatusInternalServerError, "Failed to generate OAuth state")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/v1/auth/oauth",
+		MaxAge:   int(oauthStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, provider.AuthCodeURL(state), http.StatusFound)
+}
+
+// HandlerOAuthCallback completes an OAuth login and issues the app's own tokens.
+//
+// Flow:
+//  1. Verify the state parameter against the cookie set by HandlerOAuthStart
+//  2. Exchange the code for the user's identity at the provider
+//  3. Find the user linked to that identity, or link/create one by verified email
+//  4. Issue a JWT and refresh token exactly like HandlerLogin
+//
+// @Summary     Complete OAuth login
+// @Description Exchanges the provider's authorization code, creates or links the user by verified email and returns tokens
+// @Tags        auth
+// @Produce     json
+// @Param       provider  path      string  true  "google or github"
+// @Param       code      query     string  true  "Authorization code"
+// @Param       state     query     string  true  "State returned by the provider"
+// @Success     200       {object}  object  "Login successful"
+// @Failure     400       {object}  object  "Invalid state or code"
+// @Failure     403       {object}  object  "Provider account has no verified email"
+// @Failure     404       {object}  object  "Unknown or unconfigured provider"
+// @Failure     502       {object}  object  "Provider error"
+// @Router      /v1/auth/oauth/{provider}/callback [get]
+func (cfg *Config) HandlerOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	provider, ok := cfg.OAuthProviders[chi.URLParam(r, "provider")]
+	if !ok {
+		models.RespondWithError(w, http.StatusNotFound, "Unknown or unconfigured OAuth provider")
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		models.RespondWithError(w, http.StatusBadRequest, "OAuth login was cancelled or denied")
+		return
+	}
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	state := query.Get("state")
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid OAuth state")
+		return
+	}
+
+	// The state is single use
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Path:     "/v1/auth/oauth",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	code := query.Get("code")
+	if code == "" {
+		models.RespondWithError(w, http.StatusBadRequest, "Authorization code is required")
+		return
+	}
+
+	identity, err := provider.Exchange(r.Context(), code)
+	if err != nil {
+		logger.ErrorErr(err, "OAuth exchange failed: "+provider.Name)
+		models.RespondWithError(w, http.StatusBadGateway, "Failed to complete OAuth login")
+		return
+	}
+
+	user, err := cfg.loginWithOAuth(r.Context(), provider.Name, identity)
+	if errors.Is(err, errOAuthEmailNotVerified) {
+		models.RespondWithError(w, http.StatusForbidden, "OAuth account must have a verified email")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "OAuth login")
+		return
+	}
+
+	pair, err := cfg.issueTokenPair(r.Context(), user, cfg.startSession(cfg.DB, user, newSessionClient(r, "")))
+	if err != nil {
+		cfg.respondWithTokenPairError(w, err)
+		return
+	}
+
+	cfg.respondWithTokenPair(w, http.StatusOK, pair)
+}
+
+// loginWithOAuth resolves the OAuth identity to a user inside a transaction
+func (cfg *Config) loginWithOAuth(ctx context.Context, provider string, identity auth.OAuthIdentity) (database.User, error) {
+	tx, err := cfg.DBConn.BeginTx(ctx, nil)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback transaction")
+		}
+	}()
+
+	user, err := resolveOAuthUser(ctx, cfg.DB.WithTx(tx), provider, identity)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	return user, tx.Commit()
+}
+
+// resolveOAuthUser returns the user linked to the provider identity.
+// An identity seen for the first time is linked to the user with the same verified
+// email, or to a new password-less user if there is none.
+func resolveOAuthUser(ctx context.Context, store database.Querier, provider string, identity auth.OAuthIdentity) (database.User, error) {
+	linked, err := store.GetIdentityByProvider(ctx, database.GetIdentityByProviderParams{
+		Provider:       provider,
+		ProviderUserID: identity.ProviderUserID,
+	})
+	if err == nil {
+		return store.GetUserByID(ctx, linked.UserID)
+	}
+	if !errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		return database.User{}, err
+	}
+
+	// Linking by an unverified email would let anyone claim an existing account
+	if identity.Email == "" || !identity.EmailVerified {
+		return database.User{}, errOAuthEmailNotVerified
+	}
+
+	email := sql.NullString{String: strings.ToLower(identity.Email), Valid: true}
+
+	user, err := store.GetUserByEmail(ctx, email)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		name := identity.Name
+		if name == "" {
+			name = identity.Email
+		}
+
+		user, err = store.CreateUser(ctx, database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			Name:      name,
+			Email:     email,
+		})
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+
+	_, err = store.CreateIdentity(ctx, database.CreateIdentityParams{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		Provider:       provider,
+		ProviderUserID: identity.ProviderUserID,
+		Email:          email,
+		CreatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestResolveOAuthUser_NewEmail_CreatesPasswordlessUser(t *testing.T) {
+	store := newFakeQuerier()
+
+	user, err := resolveOAuthUser(context.Background(), store, "google", auth.OAuthIdentity{
+		ProviderUserID: "1234",
+		Email:          "new@example.com",
+		EmailVerified:  true,
+		Name:           "New User",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if user.PasswordHash.Valid {
+		t.Error("Expected OAuth user to have no password")
+	}
+
+	if len(store.identities) != 1 || store.identities[0].UserID != user.ID {
+		t.Errorf("Expected identity linked to the new user, got %+v", store.identities)
+	}
+}
+
+func TestResolveOAuthUser_ExistingEmail_LinksInsteadOfCreating(t *testing.T) {
+	existing := database.User{
+		ID:           uuid.New(),
+		Email:        sql.NullString{String: "user@example.com", Valid: true},
+		PasswordHash: sql.NullString{String: "hash", Valid: true},
+	}
+	store := newFakeQuerier()
+	store.addUsers(existing)
+
+	user, err := resolveOAuthUser(context.Background(), store, "github", auth.OAuthIdentity{
+		ProviderUserID: "42",
+		Email:          "user@example.com",
+		EmailVerified:  true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if user.ID != existing.ID {
+		t.Errorf("Expected existing user %s, got %s", existing.ID, user.ID)
+	}
+
+	if len(store.users) != 1 {
+		t.Errorf("Expected no new user, got %d users", len(store.users))
+	}
+}
+
+func TestResolveOAuthUser_KnownIdentity_ReturnsLinkedUser(t *testing.T) {
+	existing := database.User{ID: uuid.New(), Email: sql.NullString{String: "user@example.com", Valid: true}}
+	store := newFakeQuerier()
+	store.addUsers(existing)
+	store.identities = append(store.identities, database.Identity{UserID: existing.ID, Provider: "google", ProviderUserID: "1234"})
+
+	// The provider email changed since linking; the identity still wins
+	user, err := resolveOAuthUser(context.Background(), store, "google", auth.OAuthIdentity{
+		ProviderUserID: "1234",
+		Email:          "changed@example.com",
+		EmailVerified:  true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if user.ID != existing.ID {
+		t.Errorf("Expected linked user %s, got %s", existing.ID, user.ID)
+	}
+
+	if len(store.identities) != 1 {
+		t.Errorf("Expected no new identity, got %d", len(store.identities))
+	}
+}
+
+func TestResolveOAuthUser_UnverifiedEmail_ReturnsError(t *testing.T) {
+	existing := database.User{ID: uuid.New(), Email: sql.NullString{String: "user@example.com", Valid: true}}
+	store := newFakeQuerier()
+	store.addUsers(existing)
+
+	_, err := resolveOAuthUser(context.Background(), store, "github", auth.OAuthIdentity{
+		ProviderUserID: "42",
+		Email:          "user@example.com",
+		EmailVerified:  false,
+	})
+
+	if !errors.Is(err, errOAuthEmailNotVerified) {
+		t.Errorf("Expected errOAuthEmailNotVerified, got %v", err)
+	}
+
+	if len(store.identities) != 0 {
+		t.Error("Expected unverified email not to be linked")
+	}
+}
+
+func oauthRequest(method, target, provider string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("provider", provider)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandlerOAuthStart_UnconfiguredProvider_ReturnsNotFound(t *testing.T) {
+	cfg := &Config{}
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerOAuthStart(rec, oauthRequest(http.MethodGet, "/v1/auth/oauth/google", "google"))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestHandlerOAuthStart_RedirectsWithStateCookie(t *testing.T) {
+	cfg := &Config{OAuthProviders: map[string]*auth.OAuthProvider{
+		"google": auth.NewGoogleProvider("client", "secret", "http://localhost/callback"),
+	}}
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerOAuthStart(rec, oauthRequest(http.MethodGet, "/v1/auth/oauth/google", "google"))
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Expected status %d, got %d", http.StatusFound, rec.Code)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oauthStateCookie || cookies[0].Value == "" {
+		t.Fatalf("Expected state cookie, got %v", cookies)
+	}
+
+	location, err := rec.Result().Location()
+	if err != nil {
+		t.Fatalf("Expected redirect location, got %v", err)
+	}
+
+	if location.Query().Get("state") != cookies[0].Value {
+		t.Error("Expected redirect state to match the cookie")
+	}
+}
+
+func TestHandlerOAuthCallback_StateMismatch_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{OAuthProviders: map[string]*auth.OAuthProvider{
+		"google": auth.NewGoogleProvider("client", "secret", "http://localhost/callback"),
+	}}
+	req := oauthRequest(http.MethodGet, "/v1/auth/oauth/google/callback?code=abc&state=forged", "google")
+	req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: "expected"})
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerOAuthCallback(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
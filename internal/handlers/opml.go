@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
+)
+
+// maxOPMLImportFeeds caps how many feeds one OPML import may subscribe to, since
+// every new feed is fetched while the request waits
+const maxOPMLImportFeeds = 200
+
+// errTooManyOPMLFeeds is returned when an OPML document lists more than maxOPMLImportFeeds feeds
+var errTooManyOPMLFeeds = fmt.Errorf("OPML document lists more than %d feeds", maxOPMLImportFeeds)
+
+// Outcomes of importing a single OPML feed
+const (
+	opmlFeedCreated  = "created"  // feed was new and has been created and followed
+	opmlFeedFollowed = "followed" // feed already existed and is now followed
+	opmlFeedSkipped  = "skipped"  // user already follows the feed
+	opmlFeedFailed   = "failed"   // feed was invalid or could not be added
+)
+
+// opmlOutline is an OPML <outline>; feeds carry an xmlUrl, folders only nest outlines
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Body    struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// opmlFeed is a feed listed in an OPML document
+type opmlFeed struct {
+	Name string
+	URL  string
+}
+
+// opmlFeedResult is the outcome of importing one OPML feed
+type opmlFeedResult struct {
+	URL    string `json:"url"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// FeedID is the feed now followed, or already followed when skipped; nil when the import failed
+	FeedID *uuid.UUID `json:"feed_id,omitempty"`
+	// Error says why the feed failed
+	Error string `json:"error,omitempty"`
+}
+
+// opmlImportSummary counts the outcome of each feed in an OPML import and lists
+// the outcomes in document order
+type opmlImportSummary struct {
+	Created  int
+	Followed int
+	Skipped  int
+	Failed   int
+	Feeds    []opmlFeedResult
+}
+
+type opmlImportResponse struct {
+	Created  models.Count     `json:"created"`
+	Followed models.Count     `json:"followed"`
+	Skipped  models.Count     `json:"skipped"`
+	Failed   models.Count     `json:"failed"`
+	Feeds    []opmlFeedResult `json:"feeds"`
+}
+
+func newOPMLImportResponse(r *http.Request, summary opmlImportSummary) opmlImportResponse {
+	return opmlImportResponse{
+		Created:  models.NewCount(r, int64(summary.Created)),
+		Followed: models.NewCount(r, int64(summary.Followed)),
+		Skipped:  models.NewCount(r, int64(summary.Skipped)),
+		Failed:   models.NewCount(r, int64(summary.Failed)),
+		Feeds:    summary.Feeds,
+	}
+}
+
+// feedAdder follows a feed for a user like addFeedForUser, so imports can be tested without a database
+type feedAdder func(ctx context.Context, userID uuid.UUID, name, feedURL string, existingFeed *database.Feed, parsedFeed *feedfetch.ParsedFeed) (database.Feed, database.FeedFollow, bool, error)
+
+// parseOPML returns the feeds listed in an OPML document, in document order, with
+// folders flattened and repeated URLs dropped
+func parseOPML(r io.Reader) ([]opmlFeed, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var feeds []opmlFeed
+	seen := make(map[string]bool)
+	var walk func(outlines []opmlOutline) error
+	walk = func(outlines []opmlOutline) error {
+		for _, outline := range outlines {
+			if feedURL := strings.TrimSpace(outline.XMLURL); feedURL != "" && !seen[feedURL] {
+				if len(feeds) == maxOPMLImportFeeds {
+					return errTooManyOPMLFeeds
+				}
+				seen[feedURL] = true
+				feeds = append(feeds, opmlFeed{Name: opmlFeedName(outline, feedURL), URL: feedURL})
+			}
+			if err := walk(outline.Outlines); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(doc.Body.Outlines); err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+// opmlFeedName prefers the outline's title, then its text, then the feed URL
+func opmlFeedName(outline opmlOutline, feedURL string) string {
+	if title := strings.TrimSpace(outline.Title); title != "" {
+		return title
+	}
+	if text := strings.TrimSpace(outline.Text); text != "" {
+		return text
+	}
+	return feedURL
+}
+
+// HandlerImportOPML follows every feed listed in an uploaded OPML document
+// @Summary     Import feeds from OPML
+// @Description Follow every feed listed in an OPML document, creating feeds that don't exist yet. The body may be larger than for other endpoints (OPML_IMPORT_MAX_BYTES).
+// @Tags        feed_follows
+// @Accept      xml
+// @Produce     json
+// @Security    Bearer
+// @Param       opml    body      string  true   "OPML document"
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Import summary: outcome counts and a per-feed list of url, name, status (created, followed, skipped or failed), feed_id and error"
+// @Failure     400     {object}  object  "Invalid OPML document"
+// @Failure     413     {object}  object  "OPML document too large"
+// @Router      /v1/feed_follows/import [post]
+func (cfg *Config) HandlerImportOPML(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := parseOPML(r.Body)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		models.RespondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("OPML document must be at most %d bytes", maxErr.Limit))
+		return
+	}
+	if errors.Is(err, errTooManyOPMLFeeds) {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid OPML document: %v", err))
+		return
+	}
+
+	summary := cfg.importOPMLFeeds(r.Context(), cfg.DB, cfg.addFeedForUser, user, feeds)
+	models.RespondWithJSON(w, http.StatusOK, newOPMLImportResponse(r, summary))
+}
+
+// importOPMLFeeds imports every OPML feed for the user, reporting each feed's outcome
+// rather than stopping at the first failure
+func (cfg *Config) importOPMLFeeds(ctx context.Context, store database.Querier, add feedAdder, user database.User, feeds []opmlFeed) opmlImportSummary {
+	summary := opmlImportSummary{Feeds: make([]opmlFeedResult, 0, len(feeds))}
+	for _, feed := range feeds {
+		result := cfg.importOPMLFeed(ctx, store, add, user, feed)
+		switch result.Status {
+		case opmlFeedCreated:
+			summary.Created++
+		case opmlFeedFollowed:
+			summary.Followed++
+		case opmlFeedSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+		summary.Feeds = append(summary.Feeds, result)
+	}
+
+	if summary.Created+summary.Followed > 0 {
+		cfg.invalidatePostsCache(user.ID)
+	}
+	return summary
+}
+
+// importOPMLFeed follows one OPML feed for the user, going through the same
+// checks as HandlerCreateFeed, and returns its outcome. Failure reasons are safe
+// to show to the user; database errors are only logged.
+func (cfg *Config) importOPMLFeed(ctx context.Context, store database.Querier, add feedAdder, user database.User, feed opmlFeed) opmlFeedResult {
+	log := cfg.Logger.With().Str("user_id", user.ID.String()).Str("feed_url", feed.URL).Logger()
+	result := opmlFeedResult{URL: feed.URL, Name: feed.Name, Status: opmlFeedFailed}
+
+	feedURL, err := normalizeFeedURL(feed.URL)
+	if err != nil {
+		log.Debug().Err(err).Msg("Skipping invalid OPML feed URL")
+		result.Error = fmt.Sprintf("invalid URL: %v", err)
+		return result
+	}
+	if err := cfg.checkFeedDomain(feedURL); err != nil {
+		log.Debug().Err(err).Msg("Skipping OPML feed from disallowed domain")
+		result.Error = err.Error()
+		return result
+	}
+	if err := cfg.checkFeedHost(ctx, feedURL); err != nil {
+		log.Debug().Err(err).Msg("Skipping OPML feed from disallowed host")
+		result.Error = err.Error()
+		return result
+	}
+
+	existingFeed, parsedFeed, resolvedURL, err := findOrParseFeed(ctx, store, cfg.feedFetcher(), feedURL)
+	if errors.Is(err, errFeedParse) {
+		log.Debug().Err(err).Msg("Failed to parse OPML feed")
+		result.Error = err.Error()
+		return result
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to look up OPML feed")
+		result.Error = "could not add feed"
+		return result
+	}
+	if err := cfg.checkRedirectedFeedURL(ctx, feedURL, resolvedURL); err != nil {
+		log.Debug().Err(err).Str("resolved_url", resolvedURL).Msg("Skipping OPML feed redirecting to a disallowed URL")
+		result.Error = fmt.Sprintf("feed redirects to %s: %v", resolvedURL, err)
+		return result
+	}
+
+	name := textutil.TruncateTitle(feed.Name, cfg.MaxTitleBytes)
+	created, _, feedCreated, err := add(ctx, user.ID, name, resolvedURL, existingFeed, parsedFeed)
+	err = apperr.Classify(err)
+	if errors.Is(err, apperr.ErrConflict) && existingFeed != nil {
+		result.Status = opmlFeedSkipped
+		result.FeedID = &existingFeed.ID
+		return result
+	}
+	if errors.Is(err, errFeedFollowLimitReached) || errors.Is(err, errFeedNameTaken) {
+		log.Debug().Err(err).Msg("Failed to add OPML feed")
+		result.Error = err.Error()
+		return result
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to add OPML feed")
+		result.Error = "could not add feed"
+		return result
+	}
+
+	result.FeedID = &created.ID
+	if !feedCreated {
+		result.Status = opmlFeedFollowed
+		return result
+	}
+	if cfg.PostImporter != nil {
+		cfg.PostImporter.ImportFeedPosts(ctx, created, parsedFeed)
+	}
+	result.Status = opmlFeedCreated
+	return result
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/middleware"
+	"github.com/mmcdole/gofeed"
+)
+
+// opmlWithFeeds builds an OPML document listing n feeds
+func opmlWithFeeds(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><opml version="2.0"><head><title>Feeds</title></head><body>`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `<outline type="rss" text="Feed %d" xmlUrl="https://example.com/feed/%d.xml"/>`, i, i)
+	}
+	b.WriteString(`</body></opml>`)
+	return b.String()
+}
+
+func TestParseOPML_FlattensFoldersAndDropsDuplicates(t *testing.T) {
+	doc := `<opml version="2.0"><body>
+		<outline text="Tech">
+			<outline text="Go Blog" title="The Go Blog" xmlUrl="https://go.dev/blog/feed.atom"/>
+			<outline text="Nested">
+				<outline text="Example" xmlUrl=" https://example.com/rss "/>
+			</outline>
+		</outline>
+		<outline xmlUrl="https://go.dev/blog/feed.atom"/>
+		<outline xmlUrl="https://example.org/feed"/>
+	</body></opml>`
+
+	feeds, err := parseOPML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []opmlFeed{
+		{Name: "The Go Blog", URL: "https://go.dev/blog/feed.atom"},
+		{Name: "Example", URL: "https://example.com/rss"},
+		{Name: "https://example.org/feed", URL: "https://example.org/feed"},
+	}
+	if len(feeds) != len(expected) {
+		t.Fatalf("Expected %d feeds, got %d: %+v", len(expected), len(feeds), feeds)
+	}
+	for i := range expected {
+		if feeds[i] != expected[i] {
+			t.Errorf("Expected feed %d to be %+v, got %+v", i, expected[i], feeds[i])
+		}
+	}
+}
+
+func TestParseOPML_TooManyFeeds_ReturnsError(t *testing.T) {
+	_, err := parseOPML(strings.NewReader(opmlWithFeeds(maxOPMLImportFeeds + 1)))
+	if !errors.Is(err, errTooManyOPMLFeeds) {
+		t.Errorf("Expected errTooManyOPMLFeeds, got %v", err)
+	}
+}
+
+func TestParseOPML_NotOPML_ReturnsError(t *testing.T) {
+	if _, err := parseOPML(strings.NewReader(`<rss><channel></channel></rss>`)); err == nil {
+		t.Error("Expected an error for a non-OPML document")
+	}
+}
+
+func TestHandlerImportOPML_OversizedDocument_Returns413(t *testing.T) {
+	cfg := &Config{}
+	const limit = 4096
+	handler := middleware.MaxBodyBytes(1024, map[string]int64{"/v1/feed_follows/import": limit})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg.HandlerImportOPML(w, r, database.User{})
+		}),
+	)
+
+	doc := opmlWithFeeds(100)
+	if len(doc) <= limit {
+		t.Fatalf("Expected the test document to exceed %d bytes, got %d", limit, len(doc))
+	}
+
+	testCases := []struct {
+		name          string
+		contentLength int64
+	}{
+		{"Declared length", int64(len(doc))},
+		{"Unknown length", -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/feed_follows/import", strings.NewReader(doc))
+			req.ContentLength = tc.contentLength
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("Expected status 413, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerImportOPML_InvalidDocument_Returns400(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodPost, "/v1/feed_follows/import", strings.NewReader("not xml"))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerImportOPML(rec, req, database.User{})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestImportOPMLFeeds_MixedOutcomes_ReportsEachFeed(t *testing.T) {
+	ctx := context.Background()
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	fetcher := feedfetch.NewFakeFetcher()
+	cfg := &Config{FeedFetcher: fetcher, FeedDomainBlocklist: []string{"blocked.example.com"}}
+	add := func(ctx context.Context, userID uuid.UUID, name, feedURL string, existingFeed *database.Feed, parsedFeed *feedfetch.ParsedFeed) (database.Feed, database.FeedFollow, bool, error) {
+		return cfg.addFeed(ctx, store, userID, name, feedURL, existingFeed, parsedFeed)
+	}
+
+	// Another user already added shared.xml; the user already follows followed.xml
+	shared := database.Feed{ID: uuid.New(), Url: "https://example.com/shared.xml", UserID: uuid.New()}
+	followed := database.Feed{ID: uuid.New(), Url: "https://example.com/followed.xml", UserID: uuid.New()}
+	store.addFeeds(shared, followed)
+	store.follow(user.ID, followed.ID)
+	fetcher.SetFeed("https://example.com/new.xml", &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: "New"}})
+	fetcher.SetError("https://example.com/broken.xml", errors.New("http error: 404 Not Found"))
+
+	feeds := []opmlFeed{
+		{Name: "New", URL: "https://example.com/new.xml"},
+		{Name: "Shared", URL: "https://example.com/shared.xml"},
+		{Name: "Followed", URL: "https://example.com/followed.xml"},
+		{Name: "Broken", URL: "https://example.com/broken.xml"},
+		{Name: "Invalid", URL: "ftp://example.com/feed.xml"},
+		{Name: "Blocked", URL: "https://blocked.example.com/feed.xml"},
+	}
+	summary := cfg.importOPMLFeeds(ctx, store, add, user, feeds)
+
+	if summary.Created != 1 || summary.Followed != 1 || summary.Skipped != 1 || summary.Failed != 3 {
+		t.Errorf("Expected 1 created, 1 followed, 1 skipped and 3 failed, got %+v", summary)
+	}
+	if len(summary.Feeds) != len(feeds) {
+		t.Fatalf("Expected %d feed results, got %d", len(feeds), len(summary.Feeds))
+	}
+
+	testCases := []struct {
+		status        string
+		feedID        *uuid.UUID
+		errorContains string
+	}{
+		{status: opmlFeedCreated},
+		{status: opmlFeedFollowed, feedID: &shared.ID},
+		{status: opmlFeedSkipped, feedID: &followed.ID},
+		{status: opmlFeedFailed, errorContains: "404"},
+		{status: opmlFeedFailed, errorContains: "invalid URL"},
+		{status: opmlFeedFailed, errorContains: errFeedDomainNotAllowed.Error()},
+	}
+	for i, tc := range testCases {
+		result := summary.Feeds[i]
+		if result.URL != feeds[i].URL || result.Name != feeds[i].Name {
+			t.Errorf("Expected result %d for %s (%s), got %s (%s)", i, feeds[i].URL, feeds[i].Name, result.URL, result.Name)
+		}
+		if result.Status != tc.status {
+			t.Errorf("Expected %s to be %s, got %s (%s)", result.URL, tc.status, result.Status, result.Error)
+		}
+		if tc.feedID != nil && (result.FeedID == nil || *result.FeedID != *tc.feedID) {
+			t.Errorf("Expected %s to report feed %s, got %v", result.URL, *tc.feedID, result.FeedID)
+		}
+		if tc.status == opmlFeedFailed {
+			if result.FeedID != nil {
+				t.Errorf("Expected no feed id for failed %s, got %v", result.URL, *result.FeedID)
+			}
+			if !strings.Contains(result.Error, tc.errorContains) {
+				t.Errorf("Expected the error for %s to mention %q, got %q", result.URL, tc.errorContains, result.Error)
+			}
+		} else if result.Error != "" {
+			t.Errorf("Expected no error for %s, got %q", result.URL, result.Error)
+		}
+	}
+
+	created, err := store.GetFeedByURL(ctx, "https://example.com/new.xml")
+	if err != nil {
+		t.Fatal("Expected the new feed to be stored")
+	}
+	if summary.Feeds[0].FeedID == nil || *summary.Feeds[0].FeedID != created.ID {
+		t.Errorf("Expected the created result to report feed %s, got %v", created.ID, summary.Feeds[0].FeedID)
+	}
+	if !store.isFollowed(user.ID, created.ID) || !store.isFollowed(user.ID, shared.ID) {
+		t.Error("Expected the user to follow the created and the shared feed")
+	}
+}
+
+func TestImportOPMLFeeds_FollowLimitReached_FailsRemainingFeeds(t *testing.T) {
+	ctx := context.Background()
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	fetcher := feedfetch.NewFakeFetcher()
+	cfg := &Config{FeedFetcher: fetcher, MaxFeedFollowsPerUser: 1}
+	add := func(ctx context.Context, userID uuid.UUID, name, feedURL string, existingFeed *database.Feed, parsedFeed *feedfetch.ParsedFeed) (database.Feed, database.FeedFollow, bool, error) {
+		return cfg.addFeed(ctx, store, userID, name, feedURL, existingFeed, parsedFeed)
+	}
+	for _, feedURL := range []string{"https://example.com/a.xml", "https://example.com/b.xml"} {
+		fetcher.SetFeed(feedURL, &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Title: feedURL}})
+	}
+
+	summary := cfg.importOPMLFeeds(ctx, store, add, user, []opmlFeed{
+		{Name: "A", URL: "https://example.com/a.xml"},
+		{Name: "B", URL: "https://example.com/b.xml"},
+	})
+
+	if summary.Created != 1 || summary.Failed != 1 {
+		t.Errorf("Expected 1 created and 1 failed, got %+v", summary)
+	}
+	if last := summary.Feeds[1]; last.Status != opmlFeedFailed || last.Error != errFeedFollowLimitReached.Error() {
+		t.Errorf("Expected the second feed to fail with %q, got %s (%q)", errFeedFollowLimitReached, last.Status, last.Error)
+	}
+}
@@ -1,35 +1,295 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
 )
 
+// maxMarkReadBatchSize caps how many post ids can be marked read in one request
+const maxMarkReadBatchSize = 100
+
 type postsResponse struct {
 	Posts      []models.Post `json:"posts"`
 	NextCursor string        `json:"next_cursor"`
 }
 
 // @Summary     Get user posts
-// @Description Get posts from all followed feeds with cursor-based pagination
+// @Description Get posts from all followed feeds with cursor-based pagination. Each post has is_read; filter=unread leaves out the posts you marked read. Posts are newest first by default; with order=asc they are oldest first and next_cursor pages forward in time.
 // @Tags        posts
 // @Accept      json
 // @Produce     json
 // @Security    Bearer
 // @Param       limit   query     int     false  "Number of posts to return (max 100)"  default(20)
 // @Param       cursor  query     string  false  "Cursor for pagination (RFC3339 timestamp)"
+// @Param       order   query     string  false  "Sort order by publication time"  Enums(desc, asc)  default(desc)
+// @Param       filter  query     string  false  "Set to unread to only list unread posts"  Enums(unread)
+// @Param       include query     string  false  "Set to feed to embed each post's feed"  Enums(feed)
+// @Param       tz      query     string  false  "IANA time zone for timestamps, also read from the Time-Zone header (default UTC)"
 // @Success     200     {object}  object  "List of posts"
 // @Failure     400     {object}  object  "Invalid parameters"
 // @Router      /v1/posts [get]
 func (cfg *Config) HandlerGetUserPostsForUser(w http.ResponseWriter, r *http.Request, user database.User) {
-	limitStr := r.URL.Query().Get("limit")
-	limit := 20
+	cfg.respondWithUserPosts(w, r, user, cfg.DB)
+}
+
+// respondWithUserPosts writes a page of the posts of the user's followed feeds
+func (cfg *Config) respondWithUserPosts(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier) {
+	limit, cursor, err := parsePostsPagination(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid cursor format")
+		return
+	}
+
+	oldestFirst, err := parsePostsOrder(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Oldest-first pages start at the beginning of history rather than now
+	if oldestFirst && r.URL.Query().Get("cursor") == "" {
+		cursor = time.Time{}
+	}
+
+	unreadOnly, err := parsePostsFilter(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includeFeed, err := parsePostsInclude(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loc, err := parseTimeZone(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The joined rows aren't cached; the lean list is the hot path
+	if includeFeed {
+		rows, err := getPostsForUserWithFeed(r.Context(), store, database.GetPostsForUserWithFeedParams{
+			UserID:          user.ID,
+			PublishedAt:     cursor,
+			AfterFollowOnly: cfg.PostsAfterFollowOnly,
+			UnreadOnly:      unreadOnly,
+			RowLimit:        int32(limit),
+		}, oldestFirst)
+		if err != nil {
+			respondWithDBError(w, err, "Get posts")
+			return
+		}
+
+		response := newPostsWithFeedResponse(rows)
+		if err := setPostsRead(r.Context(), store, user.ID, response.Posts); err != nil {
+			respondWithDBError(w, err, "Get read posts")
+			return
+		}
+		localizePostsResponse(&response, loc)
+
+		models.RespondWithJSON(w, http.StatusOK, response)
+		return
+	}
 
-	if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+	var posts []database.Post
+	switch {
+	case oldestFirst:
+		// Catching up on history is rare, so oldest-first pages aren't cached
+		posts, err = store.GetPostsForUserOldestFirst(r.Context(), database.GetPostsForUserOldestFirstParams{
+			UserID:          user.ID,
+			PublishedAt:     cursor,
+			AfterFollowOnly: cfg.PostsAfterFollowOnly,
+			UnreadOnly:      unreadOnly,
+			RowLimit:        int32(limit),
+		})
+		if err != nil {
+			respondWithDBError(w, err, "Get posts")
+			return
+		}
+	case unreadOnly:
+		// Unread pages change with every post marked read, so they aren't cached
+		posts, err = cfg.getPostsForUser(r.Context(), store, user.ID, cursor, limit, true)
+		if err != nil {
+			respondWithDBError(w, err, "Get posts")
+			return
+		}
+	default:
+		// The first page is keyed without its moving "now" cursor so it can be served from cache
+		cacheCursor := int64(0)
+		if r.URL.Query().Get("cursor") != "" {
+			cacheCursor = cursor.UnixNano()
+		}
+
+		var errGetPosts error
+		posts, errGetPosts = cfg.getCachedPostsForUser(r.Context(), store, cache.PostsKey{
+			UserID: user.ID,
+			Cursor: cacheCursor,
+			Limit:  limit,
+		}, cursor)
+
+		if errGetPosts != nil {
+			respondWithDBError(w, errGetPosts, "Get posts")
+			return
+		}
+	}
+
+	// Looked up on every request rather than cached with the posts so renames show up immediately
+	feedFollows, err := store.GetFeedFollowsWithFeedName(r.Context(), user.ID)
+	if err != nil {
+		respondWithDBError(w, err, "Get feed follows")
+		return
+	}
+
+	response := newPostsResponse(posts)
+	setPostFeedNames(response.Posts, feedDisplayNames(feedFollows))
+	// Read state changes too often to be cached with the posts
+	if err := setPostsRead(r.Context(), store, user.ID, response.Posts); err != nil {
+		respondWithDBError(w, err, "Get read posts")
+		return
+	}
+	localizePostsResponse(&response, loc)
+
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// feedDisplayNames maps each followed feed to the name the user sees for it
+func feedDisplayNames(feedFollows []database.GetFeedFollowsWithFeedNameRow) map[uuid.UUID]string {
+	names := make(map[uuid.UUID]string, len(feedFollows))
+	for _, feedFollow := range feedFollows {
+		names[feedFollow.FeedID] = models.FeedDisplayName(feedFollow.Alias, feedFollow.FeedName)
+	}
+	return names
+}
+
+// setPostsRead sets is_read on each of the user's posts
+func setPostsRead(ctx context.Context, store database.Querier, userID uuid.UUID, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	readIDs, err := store.GetReadPostIDs(ctx, database.GetReadPostIDsParams{UserID: userID, PostIds: postIDs})
+	if err != nil {
+		return err
+	}
+
+	read := make(map[uuid.UUID]bool, len(readIDs))
+	for _, id := range readIDs {
+		read[id] = true
+	}
+	for i := range posts {
+		isRead := read[posts[i].ID]
+		posts[i].IsRead = &isRead
+	}
+	return nil
+}
+
+// setPostFeedNames fills in each post's feed name from names
+func setPostFeedNames(posts []models.Post, names map[uuid.UUID]string) {
+	for i := range posts {
+		posts[i].FeedName = names[posts[i].FeedID]
+	}
+}
+
+// HandlerGetPostsByFeed returns posts of a single feed with cursor-based pagination
+// Public endpoint - feeds are public, so following the feed isn't required.
+// Anonymous clients can only page back PublicFeedMaxPages pages to limit scraping.
+// @Summary     Get feed posts
+// @Description Get posts of a single feed with cursor-based pagination, whether or not you follow it. An unknown feed returns an empty list. Anonymous clients are rate limited per IP and can only paginate a limited number of pages back.
+// @Tags        posts
+// @Accept      json
+// @Produce     json
+// @Param       feedID  path      string  true   "Feed ID"
+// @Param       limit   query     int     false  "Number of posts to return (max 100)"  default(20)
+// @Param       cursor  query     string  false  "Cursor for pagination (RFC3339 timestamp)"
+// @Param       tz      query     string  false  "IANA time zone for timestamps, also read from the Time-Zone header (default UTC)"
+// @Success     200     {object}  object  "List of posts"
+// @Failure     400     {object}  object  "Invalid parameters"
+// @Failure     403     {object}  object  "Pagination depth limit reached"
+// @Failure     429     {object}  object  "Rate limit exceeded"
+// @Router      /v1/feed/{feedID}/posts [get]
+func (cfg *Config) HandlerGetPostsByFeed(w http.ResponseWriter, r *http.Request, user *database.User) {
+	cfg.respondWithFeedPosts(w, r, user, cfg.DB)
+}
+
+// respondWithFeedPosts writes a page of the posts of the feed in the URL. Feeds are
+// public, so the user doesn't need to follow it, and an unknown feed simply has no posts.
+func (cfg *Config) respondWithFeedPosts(w http.ResponseWriter, r *http.Request, user *database.User, store database.Querier) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid feed ID: %v", err))
+		return
+	}
+
+	limit, cursor, err := parsePostsPagination(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, "Invalid cursor format")
+		return
+	}
+
+	loc, err := parseTimeZone(r)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Only anonymous clients paginating past the first page are depth-limited
+	if user == nil && cfg.PublicFeedMaxPages > 0 && r.URL.Query().Get("cursor") != "" {
+		newerCount, errCount := store.CountPostsByFeedSince(r.Context(), database.CountPostsByFeedSinceParams{
+			FeedID:      feedID,
+			PublishedAt: cursor,
+		})
+		if errCount != nil {
+			respondWithDBError(w, errCount, "Get feed posts")
+			return
+		}
+
+		if exceedsPageDepth(newerCount, limit, cfg.PublicFeedMaxPages) {
+			models.RespondWithError(w, http.StatusForbidden, "Pagination depth limit reached, log in to browse further back")
+			return
+		}
+	}
+
+	posts, err := store.GetPostsByFeed(r.Context(), database.GetPostsByFeedParams{
+		FeedID:      feedID,
+		PublishedAt: cursor,
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Get feed posts")
+		return
+	}
+
+	response := newPostsResponse(posts)
+	localizePostsResponse(&response, loc)
+
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// parsePostsPagination reads the limit and RFC3339 cursor query parameters.
+// The limit defaults to 20 and is capped at 100, the cursor defaults to now.
+func parsePostsPagination(r *http.Request) (int, time.Time, error) {
+	limit := 20
+	if parsedLimit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsedLimit > 0 {
 		limit = parsedLimit
 	}
 
@@ -41,33 +301,377 @@ func (cfg *Config) HandlerGetUserPostsForUser(w http.ResponseWriter, r *http.Req
 	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
 		parsedCursor, err := time.Parse(time.RFC3339, cursorStr)
 		if err != nil {
-			models.RespondWithError(w, http.StatusBadRequest, "Invalid cursor format")
-			return
+			return 0, time.Time{}, err
 		}
 		cursor = parsedCursor
 	}
 
-	posts, errGetPosts := cfg.DB.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
-		UserID:      user.ID,
-		Limit:       int32(limit),
-		PublishedAt: cursor,
-	})
-
-	if errGetPosts != nil {
-		models.RespondWithError(w, http.StatusBadRequest, errGetPosts.Error())
-		return
-	}
+	return limit, cursor, nil
+}
 
+// newPostsResponse builds a posts page, pointing next_cursor at the last post
+func newPostsResponse(posts []database.Post) postsResponse {
 	nextCursor := ""
 	if len(posts) > 0 {
 		lastPost := posts[len(posts)-1]
-		nextCursor = lastPost.PublishedAt.Format(time.RFC3339)
+		// Posts without a date are stamped with their ingestion time, so several often share
+		// a second; the cursor keeps the fraction to neither repeat nor skip them
+		nextCursor = models.PostPublishedAt(lastPost.PublishedAt, lastPost.CreatedAt).Format(time.RFC3339Nano)
 	}
 
-	response := postsResponse{
+	return postsResponse{
 		Posts:      models.DatabaseAllPostToAllPost(posts),
 		NextCursor: nextCursor,
 	}
+}
 
-	models.RespondWithJSON(w, http.StatusOK, response)
+// newPostsWithFeedResponse builds a posts page with each post's feed embedded
+func newPostsWithFeedResponse(rows []database.GetPostsForUserWithFeedRow) postsResponse {
+	nextCursor := ""
+	if len(rows) > 0 {
+		lastRow := rows[len(rows)-1]
+		nextCursor = models.PostPublishedAt(lastRow.PublishedAt, lastRow.CreatedAt).Format(time.RFC3339Nano)
+	}
+
+	posts := make([]models.Post, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, models.DatabasePostWithFeedToPost(row))
+	}
+
+	return postsResponse{
+		Posts:      posts,
+		NextCursor: nextCursor,
+	}
+}
+
+// parsePostsOrder reads the order query parameter: desc (the default) for newest
+// first or asc for oldest first. Returns whether posts are listed oldest first.
+func parsePostsOrder(r *http.Request) (bool, error) {
+	switch order := r.URL.Query().Get("order"); order {
+	case "", "desc":
+		return false, nil
+	case "asc":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Unsupported order: %s, must be asc or desc", order)
+	}
+}
+
+// parsePostsFilter reads the filter query parameter. Returns whether only unread
+// posts are listed.
+func parsePostsFilter(r *http.Request) (bool, error) {
+	switch filter := r.URL.Query().Get("filter"); filter {
+	case "":
+		return false, nil
+	case "unread":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Unsupported filter: %s", filter)
+	}
+}
+
+// getPostsForUserWithFeed loads a page of the user's posts joined with their feeds,
+// oldest first after the cursor or newest first before it
+func getPostsForUserWithFeed(ctx context.Context, store database.Querier, arg database.GetPostsForUserWithFeedParams, oldestFirst bool) ([]database.GetPostsForUserWithFeedRow, error) {
+	if !oldestFirst {
+		return store.GetPostsForUserWithFeed(ctx, arg)
+	}
+
+	oldestRows, err := store.GetPostsForUserWithFeedOldestFirst(ctx, database.GetPostsForUserWithFeedOldestFirstParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]database.GetPostsForUserWithFeedRow, 0, len(oldestRows))
+	for _, row := range oldestRows {
+		rows = append(rows, database.GetPostsForUserWithFeedRow(row))
+	}
+	return rows, nil
+}
+
+// parsePostsInclude reads the comma-separated include query parameter.
+// Returns whether the feed should be embedded in each post.
+func parsePostsInclude(r *http.Request) (bool, error) {
+	includeFeed := false
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "":
+		case "feed":
+			includeFeed = true
+		default:
+			return false, fmt.Errorf("Unsupported include: %s", include)
+		}
+	}
+	return includeFeed, nil
+}
+
+// exceedsPageDepth reports whether a cursor with newerCount posts before it is
+// more than maxPages pages of the given size away from the first page
+func exceedsPageDepth(newerCount int64, limit int, maxPages int) bool {
+	return newerCount >= int64(limit)*int64(maxPages)
+}
+
+// HandlerMarkPostsRead marks a batch of posts as read for the user
+// Only posts from feeds the user follows are marked; other ids are ignored
+// @Summary     Mark posts as read
+// @Description Mark a list of posts as read in one call. Ids of posts outside the user's followed feeds are ignored.
+// @Tags        posts
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       post_ids  body      object  true   "Post ids to mark as read (max 100)"
+// @Param       bigint    query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200       {object}  object  "Number of posts marked as read"
+// @Failure     400       {object}  object  "Invalid input"
+// @Failure     500       {object}  object  "Server error"
+// @Router      /v1/posts/read [post]
+func (cfg *Config) HandlerMarkPostsRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToMarkPostsRead(w, r, user, cfg.DB)
+}
+
+// respondToMarkPostsRead marks the posts in the request body read for the user.
+// Posts outside the user's followed feeds are skipped and not counted as marked.
+func respondToMarkPostsRead(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier) {
+	type parameters struct {
+		PostIDs []uuid.UUID `json:"post_ids"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request payload: %v", err))
+		return
+	}
+
+	if len(params.PostIDs) == 0 {
+		models.RespondWithError(w, http.StatusBadRequest, "post_ids must not be empty")
+		return
+	}
+
+	if len(params.PostIDs) > maxMarkReadBatchSize {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Cannot mark more than %d posts at once", maxMarkReadBatchSize))
+		return
+	}
+
+	marked, err := store.MarkPostsRead(r.Context(), database.MarkPostsReadParams{
+		UserID:  user.ID,
+		ReadAt:  time.Now().UTC(),
+		PostIds: params.PostIDs,
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Mark posts read")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, struct {
+		Marked models.Count `json:"marked"`
+	}{
+		Marked: models.NewCount(r, marked),
+	})
+}
+
+// postContent is the full stored content of a post, for a reader view
+type postContent struct {
+	PostID      uuid.UUID `json:"post_id"`
+	ContentType string    `json:"content_type"`
+	Content     string    `json:"content"`
+}
+
+// postContentCSP locks down the HTML variant of a post's content: no scripts,
+// styles or frames run even if sanitization missed something
+const postContentCSP = "default-src 'none'; img-src http: https:; sandbox"
+
+// HandlerMarkPostRead marks a post in a followed feed as read
+// @Summary     Mark a post as read
+// @Description Mark a single post of a followed feed as read. Marking a read post again keeps its original read time.
+// @Tags        posts
+// @Security    Bearer
+// @Param       postID  path  string  true  "Post ID"
+// @Success     204     "Post marked as read"
+// @Failure     400     {object}  object  "Invalid ID"
+// @Failure     404     {object}  object  "Post not found in followed feeds"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/posts/{postID}/read [post]
+func (cfg *Config) HandlerMarkPostRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToPostRead(w, r, user, cfg.DB, true)
+}
+
+// HandlerUnmarkPostRead marks a post in a followed feed as unread again
+// @Summary     Mark a post as unread
+// @Description Mark a single post of a followed feed as unread. Posts that aren't read are left as they are.
+// @Tags        posts
+// @Security    Bearer
+// @Param       postID  path  string  true  "Post ID"
+// @Success     204     "Post marked as unread"
+// @Failure     400     {object}  object  "Invalid ID"
+// @Failure     404     {object}  object  "Post not found in followed feeds"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/posts/{postID}/read [delete]
+func (cfg *Config) HandlerUnmarkPostRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToPostRead(w, r, user, cfg.DB, false)
+}
+
+// respondToPostRead marks the post in the URL read or unread for the user. Both are
+// idempotent; only posts outside the user's followed feeds are rejected.
+func respondToPostRead(w http.ResponseWriter, r *http.Request, user database.User, store database.Querier, read bool) {
+	postID, err := uuid.Parse(chi.URLParam(r, "postID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid post ID: %v", err))
+		return
+	}
+
+	var changed int64
+	if read {
+		changed, err = store.MarkPostRead(r.Context(), database.MarkPostReadParams{
+			UserID: user.ID,
+			ReadAt: time.Now().UTC(),
+			PostID: postID,
+		})
+	} else {
+		changed, err = store.UnmarkPostRead(r.Context(), database.UnmarkPostReadParams{
+			UserID: user.ID,
+			PostID: postID,
+		})
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Mark post read")
+		return
+	}
+
+	// Marking read only touches followed posts; unmarking a post that wasn't read changes nothing
+	if changed == 0 {
+		followed, err := store.IsFollowedPost(r.Context(), database.IsFollowedPostParams{UserID: user.ID, PostID: postID})
+		if err != nil {
+			respondWithDBError(w, err, "Mark post read")
+			return
+		}
+		if !followed {
+			models.RespondWithError(w, http.StatusNotFound, "Post not found in followed feeds")
+			return
+		}
+	}
+
+	models.RespondWithJSON(w, http.StatusNoContent, struct{}{})
+}
+
+// HandlerGetPostContent returns the full, sanitized content of a post in a followed feed
+// @Summary     Get post content
+// @Description Get the full stored description of a post, even if the posts list truncates it. For feeds with fetch_full_content, this is the content extracted from the post's article page once it has been fetched. The HTML is sanitized to an allowlist of formatting tags. Send Accept: text/html to get the HTML itself instead of JSON.
+// @Tags        posts
+// @Accept      json
+// @Produce     json
+// @Produce     html
+// @Security    Bearer
+// @Param       postID  path      string  true  "Post ID"
+// @Success     200     {object}  object  "Post content"
+// @Failure     400     {object}  object  "Invalid ID"
+// @Failure     404     {object}  object  "Post not found in followed feeds"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/posts/{postID}/content [get]
+func (cfg *Config) HandlerGetPostContent(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(chi.URLParam(r, "postID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid post ID: %v", err))
+		return
+	}
+
+	row, err := cfg.DB.GetFollowedPostContent(r.Context(), database.GetFollowedPostContentParams{
+		ID:     postID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithDBError(w, err, "Get post content")
+		return
+	}
+
+	respondWithPostContent(w, r, row)
+}
+
+// respondWithPostContent writes the post's sanitized content as JSON, or as HTML
+// when the client asks for text/html
+func respondWithPostContent(w http.ResponseWriter, r *http.Request, row database.GetFollowedPostContentRow) {
+	// Truncated posts keep their untruncated description separately when enabled, and
+	// posts of feeds with fetch_full_content have the content extracted from their article
+	raw := row.Description.String
+	if row.DescriptionTruncated && row.FullDescription.Valid {
+		raw = row.FullDescription.String
+	}
+	if row.FullContent.Valid {
+		raw = row.FullContent.String
+	}
+	content := textutil.SanitizeHTML(raw)
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		models.RespondWithJSON(w, http.StatusOK, postContent{
+			PostID:      row.ID,
+			ContentType: "text/html",
+			Content:     content,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", postContentCSP)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(content)); err != nil {
+		logger.ErrorErr(err, "Failed to write post content")
+	}
+}
+
+// getCachedPostsForUser serves a page of the user's posts from PostsCache when it is enabled
+func (cfg *Config) getCachedPostsForUser(ctx context.Context, store database.Querier, key cache.PostsKey, cursor time.Time) ([]database.Post, error) {
+	if cfg.PostsCache == nil {
+		return cfg.getPostsForUser(ctx, store, key.UserID, cursor, key.Limit, false)
+	}
+
+	if posts, ok := cfg.PostsCache.Get(key); ok {
+		return posts, nil
+	}
+
+	posts, err := cfg.getPostsForUser(ctx, store, key.UserID, cursor, key.Limit, false)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.PostsCache.Set(key, posts)
+	return posts, nil
+}
+
+// invalidatePostsCache drops the user's cached post pages after their follows change
+func (cfg *Config) invalidatePostsCache(userID uuid.UUID) {
+	if cfg.PostsCache != nil {
+		cfg.PostsCache.InvalidateUsers(userID)
+	}
+}
+
+// getPostsForUser loads a page of posts from the user's followed feeds, only the
+// unread ones with unreadOnly. When PostsAfterFollowOnly is enabled, posts published
+// before the user followed a feed are left out so a new follow doesn't flood the timeline.
+func (cfg *Config) getPostsForUser(ctx context.Context, store database.Querier, userID uuid.UUID, cursor time.Time, limit int, unreadOnly bool) ([]database.Post, error) {
+	if cfg.PostsAfterFollowOnly {
+		return store.GetPostsForUserAfterFollow(ctx, database.GetPostsForUserAfterFollowParams{
+			UserID:      userID,
+			PublishedAt: cursor,
+			UnreadOnly:  unreadOnly,
+			RowLimit:    int32(limit),
+		})
+	}
+
+	return store.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:      userID,
+		PublishedAt: cursor,
+		UnreadOnly:  unreadOnly,
+		RowLimit:    int32(limit),
+	})
+}
+
+// newerPost reports whether a post published at aPublished with id aID comes before one
+// published at bPublished with id bID in the newest-first order of the posts queries:
+// by published_at, then by id, so posts sharing a timestamp keep a repeatable order
+func newerPost(aPublished time.Time, aID uuid.UUID, bPublished time.Time, bID uuid.UUID) bool {
+	if !aPublished.Equal(bPublished) {
+		return aPublished.After(bPublished)
+	}
+	return bytes.Compare(aID[:], bID[:]) > 0
 }
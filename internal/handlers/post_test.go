@@ -0,0 +1,718 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestHandlerMarkPostsRead_InvalidBatch_ReturnsBadRequest(t *testing.T) {
+	tooMany := make([]uuid.UUID, maxMarkReadBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+
+	testCases := []struct {
+		name    string
+		postIDs []uuid.UUID
+	}{
+		{"Empty batch", []uuid.UUID{}},
+		{"Oversized batch", tooMany},
+	}
+
+	cfg := &Config{}
+	user := database.User{ID: uuid.New()}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string][]uuid.UUID{"post_ids": tc.postIDs})
+			req := httptest.NewRequest(http.MethodPost, "/v1/posts/read", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			cfg.HandlerMarkPostsRead(rec, req, user)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandlerMarkPostsRead_MalformedJSON_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodPost, "/v1/posts/read", bytes.NewReader([]byte(`{"post_ids": ["not-a-uuid"]}`)))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerMarkPostsRead(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestRespondToMarkPostsRead_UnfollowedPosts_AreIgnored(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	followed := database.Post{ID: uuid.New(), FeedID: uuid.New(), PublishedAt: published}
+	unfollowed := database.Post{ID: uuid.New(), FeedID: uuid.New(), PublishedAt: published}
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	store.followPosts(user.ID, followed)
+	store.posts = append(store.posts, unfollowed)
+
+	body, _ := json.Marshal(map[string][]uuid.UUID{"post_ids": {followed.ID, unfollowed.ID}})
+	rec := httptest.NewRecorder()
+
+	respondToMarkPostsRead(rec, httptest.NewRequest(http.MethodPost, "/v1/posts/read", bytes.NewReader(body)), user, store)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Marked int64 `json:"marked"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Marked != 1 {
+		t.Errorf("Expected 1 post marked, got %d", response.Marked)
+	}
+	if !store.reads[[2]uuid.UUID{user.ID, followed.ID}] {
+		t.Error("Expected the followed post to be read")
+	}
+	if store.reads[[2]uuid.UUID{user.ID, unfollowed.ID}] {
+		t.Error("Expected the unfollowed post to stay unread")
+	}
+}
+
+func TestExceedsPageDepth(t *testing.T) {
+	testCases := []struct {
+		name       string
+		newerCount int64
+		limit      int
+		maxPages   int
+		expected   bool
+	}{
+		{"Second page", 20, 20, 5, false},
+		{"Last allowed page", 99, 20, 5, false},
+		{"Beyond the cap", 100, 20, 5, true},
+		{"Larger pages reach the cap sooner in requests", 400, 100, 3, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exceedsPageDepth(tc.newerCount, tc.limit, tc.maxPages); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandlerGetPostsByFeed_InvalidFeedID_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/not-a-uuid/posts", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", "not-a-uuid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerGetPostsByFeed(rec, req, nil)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+// feedPostsRequest builds a GET /v1/feed/{feedID}/posts request with the given query string
+func feedPostsRequest(feedID uuid.UUID, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed/"+feedID.String()+"/posts"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("feedID", feedID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandlerGetPostsByFeed_NextCursor_PagesThroughFeed(t *testing.T) {
+	feedID := uuid.New()
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeQuerier()
+	for i := 0; i < 5; i++ {
+		store.posts = append(store.posts, database.Post{ID: uuid.New(), FeedID: feedID, Title: fmt.Sprintf("Post %d", i), PublishedAt: published.Add(time.Duration(i) * time.Minute)})
+	}
+	// Posts of other feeds are never listed
+	store.posts = append(store.posts, database.Post{ID: uuid.New(), FeedID: uuid.New(), Title: "Other feed", PublishedAt: published})
+
+	cfg := &Config{}
+	user := &database.User{ID: uuid.New()}
+	var titles []string
+	query := "?limit=2"
+	for page := 0; page < 4; page++ {
+		rec := httptest.NewRecorder()
+		cfg.respondWithFeedPosts(rec, feedPostsRequest(feedID, query), user, store)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for page %d, got %d", page+1, rec.Code)
+		}
+		var response postsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Posts) == 0 {
+			break
+		}
+		for _, post := range response.Posts {
+			titles = append(titles, post.Title)
+		}
+		query = "?limit=2&cursor=" + url.QueryEscape(response.NextCursor)
+	}
+
+	expected := []string{"Post 4", "Post 3", "Post 2", "Post 1", "Post 0"}
+	if fmt.Sprint(titles) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v across pages, got %v", expected, titles)
+	}
+}
+
+func TestHandlerGetPostsByFeed_PageDepth_LimitsAnonymousClients(t *testing.T) {
+	feedID := uuid.New()
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeQuerier()
+	for i := 0; i < 10; i++ {
+		store.posts = append(store.posts, database.Post{ID: uuid.New(), FeedID: feedID, PublishedAt: published.Add(time.Duration(i) * time.Minute)})
+	}
+	// Two pages of two posts: the second page starts before the 2 newest posts
+	secondPage := "?limit=2&cursor=" + url.QueryEscape(published.Add(8*time.Minute).Format(time.RFC3339))
+	deepPage := "?limit=2&cursor=" + url.QueryEscape(published.Add(2*time.Minute).Format(time.RFC3339))
+
+	testCases := []struct {
+		name     string
+		query    string
+		user     *database.User
+		expected int
+	}{
+		{"Anonymous first page", "?limit=2", nil, http.StatusOK},
+		{"Anonymous within the cap", secondPage, nil, http.StatusOK},
+		{"Anonymous beyond the cap", deepPage, nil, http.StatusForbidden},
+		{"Logged in beyond the cap", deepPage, &database.User{ID: uuid.New()}, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{PublicFeedMaxPages: 2}
+			rec := httptest.NewRecorder()
+
+			cfg.respondWithFeedPosts(rec, feedPostsRequest(feedID, tc.query), tc.user, store)
+
+			if rec.Code != tc.expected {
+				t.Errorf("Expected status %d, got %d: %s", tc.expected, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerGetPostsByFeed_UnknownFeed_ReturnsEmptyList(t *testing.T) {
+	store := newFakeQuerier()
+	store.posts = []database.Post{{ID: uuid.New(), FeedID: uuid.New(), PublishedAt: time.Now().Add(-time.Hour)}}
+	rec := httptest.NewRecorder()
+
+	(&Config{}).respondWithFeedPosts(rec, feedPostsRequest(uuid.New(), ""), nil, store)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var response map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if string(response["posts"]) != "[]" {
+		t.Errorf("Expected an empty posts list, got %s", response["posts"])
+	}
+	if string(response["next_cursor"]) != `""` {
+		t.Errorf("Expected no next cursor, got %s", response["next_cursor"])
+	}
+}
+
+func TestHandlerGetUserPostsForUser_Order_PagesInBothDirections(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	// All within one second, like undated posts stamped with their ingestion time
+	for i := 0; i < 5; i++ {
+		store.followPosts(user.ID, database.Post{ID: uuid.New(), FeedID: uuid.New(), Title: fmt.Sprintf("Post %d", i), PublishedAt: published.Add(time.Duration(i) * 150 * time.Millisecond)})
+	}
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"Default is newest first", "", []string{"Post 4", "Post 3", "Post 2", "Post 1", "Post 0"}},
+		{"Descending", "&order=desc", []string{"Post 4", "Post 3", "Post 2", "Post 1", "Post 0"}},
+		{"Ascending", "&order=asc", []string{"Post 0", "Post 1", "Post 2", "Post 3", "Post 4"}},
+		{"Ascending with feed", "&order=asc&include=feed", []string{"Post 0", "Post 1", "Post 2", "Post 3", "Post 4"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{}
+			var titles []string
+			query := "?limit=2" + tc.query
+			for page := 0; page < 4; page++ {
+				rec := httptest.NewRecorder()
+				cfg.respondWithUserPosts(rec, httptest.NewRequest(http.MethodGet, "/v1/posts"+query, nil), user, store)
+
+				if rec.Code != http.StatusOK {
+					t.Fatalf("Expected status 200 for page %d, got %d: %s", page+1, rec.Code, rec.Body.String())
+				}
+				var response postsResponse
+				if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if len(response.Posts) == 0 {
+					break
+				}
+				for _, post := range response.Posts {
+					titles = append(titles, post.Title)
+				}
+				query = "?limit=2" + tc.query + "&cursor=" + url.QueryEscape(response.NextCursor)
+			}
+
+			if fmt.Sprint(titles) != fmt.Sprint(tc.expected) {
+				t.Errorf("Expected %v across pages, got %v", tc.expected, titles)
+			}
+		})
+	}
+}
+
+func TestHandlerGetUserPostsForUser_InvalidOrder_ReturnsBadRequest(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	(&Config{}).respondWithUserPosts(rec, httptest.NewRequest(http.MethodGet, "/v1/posts?order=newest", nil), database.User{ID: uuid.New()}, newFakeQuerier())
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestHandlerGetUserPostsForUser_CachedPathDBError_ReturnsGenericServerError(t *testing.T) {
+	store := newFakeQuerier()
+	store.getPostsErr = errors.New("pq: connection refused to 10.0.0.5")
+	rec := httptest.NewRecorder()
+
+	(&Config{}).respondWithUserPosts(rec, httptest.NewRequest(http.MethodGet, "/v1/posts", nil), database.User{ID: uuid.New()}, store)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "10.0.0.5") {
+		t.Errorf("Expected a generic error message, got %s", rec.Body.String())
+	}
+}
+
+func TestHandlerGetUserPostsForUser_PostsAfterFollowOnly_HidesOlderPosts(t *testing.T) {
+	followedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	feedID := uuid.New()
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	store.followPosts(user.ID,
+		database.Post{ID: uuid.New(), FeedID: feedID, Title: "Before follow", PublishedAt: followedAt.Add(-time.Hour)},
+		database.Post{ID: uuid.New(), FeedID: feedID, Title: "After follow", PublishedAt: followedAt.Add(time.Hour)},
+	)
+	store.follows[0].CreatedAt = followedAt
+
+	testCases := []struct {
+		name            string
+		afterFollowOnly bool
+		query           string
+		expected        []string
+	}{
+		{"All posts", false, "", []string{"After follow", "Before follow"}},
+		{"All posts with feed", false, "?include=feed", []string{"After follow", "Before follow"}},
+		{"All posts ascending", false, "?order=asc", []string{"Before follow", "After follow"}},
+		{"After follow only", true, "", []string{"After follow"}},
+		{"After follow only with feed", true, "?include=feed", []string{"After follow"}},
+		{"After follow only ascending", true, "?order=asc", []string{"After follow"}},
+		{"After follow only unread", true, "?filter=unread", []string{"After follow"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{PostsAfterFollowOnly: tc.afterFollowOnly}
+			rec := httptest.NewRecorder()
+
+			cfg.respondWithUserPosts(rec, httptest.NewRequest(http.MethodGet, "/v1/posts"+tc.query, nil), user, store)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var response postsResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var titles []string
+			for _, post := range response.Posts {
+				titles = append(titles, post.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, titles)
+			}
+		})
+	}
+}
+
+// postReadRequest builds a POST or DELETE /v1/posts/{postID}/read request
+func postReadRequest(method string, postID uuid.UUID) *http.Request {
+	req := httptest.NewRequest(method, "/v1/posts/"+postID.String()+"/read", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("postID", postID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// getUserPostsPage requests one page of the user's posts and decodes it
+func getUserPostsPage(t *testing.T, store *fakeQuerier, user database.User, query string) postsResponse {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	(&Config{}).respondWithUserPosts(rec, httptest.NewRequest(http.MethodGet, "/v1/posts"+query, nil), user, store)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response postsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestRespondToPostRead_MarkedPost_ExcludedFromUnreadFilter(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	readPost := database.Post{ID: uuid.New(), FeedID: uuid.New(), Title: "Read", PublishedAt: published}
+	unreadPost := database.Post{ID: uuid.New(), FeedID: uuid.New(), Title: "Unread", PublishedAt: published.Add(time.Minute)}
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	store.followPosts(user.ID, readPost, unreadPost)
+
+	// Marking twice is fine, the post simply stays read
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		respondToPostRead(rec, postReadRequest(http.MethodPost, readPost.ID), user, store, true)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	for _, query := range []string{"?filter=unread", "?filter=unread&include=feed", "?filter=unread&order=asc"} {
+		response := getUserPostsPage(t, store, user, query)
+		if len(response.Posts) != 1 || response.Posts[0].ID != unreadPost.ID {
+			t.Errorf("Expected only the unread post for %s, got %v", query, response.Posts)
+		}
+	}
+
+	response := getUserPostsPage(t, store, user, "")
+	if len(response.Posts) != 2 {
+		t.Fatalf("Expected both posts without a filter, got %d", len(response.Posts))
+	}
+	for _, post := range response.Posts {
+		if post.IsRead == nil || *post.IsRead != (post.ID == readPost.ID) {
+			t.Errorf("Expected is_read %v for %s, got %v", post.ID == readPost.ID, post.Title, post.IsRead)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	respondToPostRead(rec, postReadRequest(http.MethodDelete, readPost.ID), user, store, false)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if response := getUserPostsPage(t, store, user, "?filter=unread"); len(response.Posts) != 2 {
+		t.Errorf("Expected both posts to be unread after unmarking, got %d", len(response.Posts))
+	}
+}
+
+func TestRespondToPostRead_UnfollowedPost_ReturnsNotFound(t *testing.T) {
+	store := newFakeQuerier()
+
+	for _, read := range []bool{true, false} {
+		rec := httptest.NewRecorder()
+		respondToPostRead(rec, postReadRequest(http.MethodPost, uuid.New()), database.User{ID: uuid.New()}, store, read)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d when read is %v, got %d", http.StatusNotFound, read, rec.Code)
+		}
+	}
+}
+
+func TestHandlerGetUserPostsForUser_InvalidFilter_ReturnsBadRequest(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	(&Config{}).respondWithUserPosts(rec, httptest.NewRequest(http.MethodGet, "/v1/posts?filter=starred", nil), database.User{ID: uuid.New()}, newFakeQuerier())
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestParsePostsPagination(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expectedLimit int
+		expectErr     bool
+	}{
+		{"Defaults", "", 20, false},
+		{"Capped limit", "?limit=500", 100, false},
+		{"Negative limit uses default", "?limit=-5", 20, false},
+		{"Invalid cursor", "?cursor=yesterday", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/posts"+tc.query, nil)
+
+			limit, _, err := parsePostsPagination(req)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if limit != tc.expectedLimit {
+				t.Errorf("Expected limit %d, got %d", tc.expectedLimit, limit)
+			}
+		})
+	}
+}
+
+func TestGetCachedPostsForUser_CacheHit_SkipsDatabase(t *testing.T) {
+	// No DB configured: a miss would panic, so a result proves the cache served it
+	cfg := &Config{PostsCache: cache.NewPostsCache(10, time.Minute)}
+	key := cache.PostsKey{UserID: uuid.New(), Limit: 20}
+	cfg.PostsCache.Set(key, []database.Post{{ID: uuid.New(), Title: "Cached"}})
+
+	posts, err := cfg.getCachedPostsForUser(context.Background(), nil, key, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(posts) != 1 || posts[0].Title != "Cached" {
+		t.Errorf("Expected cached posts, got %v", posts)
+	}
+}
+
+func TestInvalidatePostsCache_DropsUsersPages(t *testing.T) {
+	cfg := &Config{PostsCache: cache.NewPostsCache(10, time.Minute)}
+	key := cache.PostsKey{UserID: uuid.New(), Limit: 20}
+	cfg.PostsCache.Set(key, []database.Post{{ID: uuid.New()}})
+
+	cfg.invalidatePostsCache(key.UserID)
+
+	if _, ok := cfg.PostsCache.Get(key); ok {
+		t.Error("Expected user's cached page to be invalidated")
+	}
+}
+
+func TestParsePostsInclude(t *testing.T) {
+	testCases := []struct {
+		name        string
+		query       string
+		includeFeed bool
+		expectErr   bool
+	}{
+		{"Default is lean", "", false, false},
+		{"Feed", "?include=feed", true, false},
+		{"Feed with spaces", "?include=%20feed%20", true, false},
+		{"Unknown include", "?include=author", false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/posts"+tc.query, nil)
+
+			includeFeed, err := parsePostsInclude(req)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tc.expectErr, err)
+			}
+
+			if includeFeed != tc.includeFeed {
+				t.Errorf("Expected includeFeed %v, got %v", tc.includeFeed, includeFeed)
+			}
+		})
+	}
+}
+
+func TestPostsResponse_NestedFeed_OnlyWhenRequested(t *testing.T) {
+	feedID := uuid.New()
+	publishedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	lean, err := json.Marshal(newPostsResponse([]database.Post{{ID: uuid.New(), FeedID: feedID, PublishedAt: publishedAt}}))
+	if err != nil {
+		t.Fatalf("Failed to encode lean response: %v", err)
+	}
+
+	if bytes.Contains(lean, []byte(`"feed":`)) {
+		t.Errorf("Expected no nested feed by default, got %s", lean)
+	}
+
+	withFeed := newPostsWithFeedResponse([]database.GetPostsForUserWithFeedRow{{
+		ID:          uuid.New(),
+		FeedID:      feedID,
+		PublishedAt: publishedAt,
+		FeedName:    "Example News",
+		FeedUrl:     "https://example.com/feed.xml",
+		FeedLogoUrl: sql.NullString{String: "https://example.com/logo.png", Valid: true},
+		FeedAlias:   sql.NullString{String: "My News", Valid: true},
+	}})
+
+	post := withFeed.Posts[0]
+	if post.Feed == nil {
+		t.Fatal("Expected nested feed, got nil")
+	}
+
+	if post.Feed.ID != feedID || post.Feed.Name != "Example News" || post.Feed.LogoUrl != "https://example.com/logo.png" {
+		t.Errorf("Expected feed metadata of %s, got %+v", feedID, post.Feed)
+	}
+
+	if post.FeedName != "My News" {
+		t.Errorf("Expected alias as feed name, got %q", post.FeedName)
+	}
+
+	if withFeed.NextCursor != publishedAt.Format(time.RFC3339) {
+		t.Errorf("Expected next cursor %s, got %s", publishedAt.Format(time.RFC3339), withFeed.NextCursor)
+	}
+}
+
+func TestNewPostsResponse_ZeroPublishedAt_UsesIngestionTime(t *testing.T) {
+	ingestedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	posts := []database.Post{
+		{ID: uuid.New(), Url: "https://example.com/zero", CreatedAt: ingestedAt, UpdatedAt: ingestedAt},
+		{ID: uuid.New(), Url: "https://example.com/epoch", CreatedAt: ingestedAt, UpdatedAt: ingestedAt, PublishedAt: time.Unix(0, 0).UTC()},
+	}
+
+	body, err := json.Marshal(newPostsResponse(posts))
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+
+	if bytes.Contains(body, []byte("0001-01-01")) || bytes.Contains(body, []byte("1970-01-01")) {
+		t.Errorf("Expected no zero-value timestamps in response, got %s", body)
+	}
+
+	if response := newPostsResponse(posts); response.NextCursor != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected next cursor at ingestion time, got %s", response.NextCursor)
+	}
+
+	var response postsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, post := range response.Posts {
+		if !post.PublishedAt.Equal(ingestedAt) {
+			t.Errorf("Expected %s published at %v, got %v", post.Url, ingestedAt, post.PublishedAt)
+		}
+	}
+}
+
+func TestRespondWithPostContent_Truncated_ReturnsFullSanitizedContent(t *testing.T) {
+	row := database.GetFollowedPostContentRow{
+		ID:                   uuid.New(),
+		Description:          sql.NullString{String: "<p>Short", Valid: true},
+		DescriptionTruncated: true,
+		FullDescription:      sql.NullString{String: "<p>Short and long</p><script>alert(1)</script>", Valid: true},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts/"+row.ID.String()+"/content", nil)
+	rec := httptest.NewRecorder()
+
+	respondWithPostContent(rec, req, row)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var content postContent
+	if err := json.Unmarshal(rec.Body.Bytes(), &content); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if content.Content != "<p>Short and long</p>" {
+		t.Errorf("Expected full sanitized content, got %q", content.Content)
+	}
+	if content.PostID != row.ID || content.ContentType != "text/html" {
+		t.Errorf("Expected post %s with content type text/html, got %+v", row.ID, content)
+	}
+}
+
+func TestRespondWithPostContent_FullContent_PreferredOverDescription(t *testing.T) {
+	row := database.GetFollowedPostContentRow{
+		ID:                   uuid.New(),
+		Description:          sql.NullString{String: "<p>Summary</p>", Valid: true},
+		DescriptionTruncated: true,
+		FullDescription:      sql.NullString{String: "<p>Longer summary</p>", Valid: true},
+		FullContent:          sql.NullString{String: "<p>The whole article</p>", Valid: true},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts/"+row.ID.String()+"/content", nil)
+	rec := httptest.NewRecorder()
+
+	respondWithPostContent(rec, req, row)
+
+	var content postContent
+	if err := json.Unmarshal(rec.Body.Bytes(), &content); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if content.Content != "<p>The whole article</p>" {
+		t.Errorf("Expected the extracted article content, got %q", content.Content)
+	}
+}
+
+func TestRespondWithPostContent_AcceptHTML_ReturnsHTML(t *testing.T) {
+	row := database.GetFollowedPostContentRow{
+		ID:          uuid.New(),
+		Description: sql.NullString{String: `<p onclick="steal()">Hello</p>`, Valid: true},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts/"+row.ID.String()+"/content", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+
+	respondWithPostContent(rec, req, row)
+
+	if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Expected text/html content type, got %q", got)
+	}
+	if rec.Header().Get("Content-Security-Policy") != postContentCSP {
+		t.Error("Expected restrictive Content-Security-Policy header")
+	}
+	if rec.Body.String() != "<p>Hello</p>" {
+		t.Errorf("Expected sanitized HTML, got %q", rec.Body.String())
+	}
+}
+
+func TestHandlerGetPostContent_InvalidID_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts/not-a-uuid/content", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("postID", "not-a-uuid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerGetPostContent(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
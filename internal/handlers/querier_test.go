@@ -0,0 +1,978 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// fakeQuerier is an in-memory database.Querier shared by the handler tests. It keeps
+// the tables the handlers touch and answers each query the way its SQL does; a query
+// no test needs falls through to the nil embedded Querier and panics.
+type fakeQuerier struct {
+	database.Querier
+
+	// mu guards refreshTokens and rotatedTokens, the only tables tests use from
+	// several goroutines; it plays the part of the row lock Postgres takes on DELETE
+	mu            sync.Mutex
+	refreshTokens map[string]database.RefreshToken        // by token hash
+	rotatedTokens map[string]database.RotatedRefreshToken // by token hash
+
+	users         map[uuid.UUID]database.User
+	identities    []database.Identity
+	feeds         map[uuid.UUID]database.Feed
+	follows       []database.FeedFollow
+	posts         []database.Post
+	reads         map[[2]uuid.UUID]bool      // {user ID, post ID}
+	bookmarks     map[[2]uuid.UUID]time.Time // {user ID, post ID} to bookmarked at
+	notifications []database.Notification
+	activity      map[activityKey]int32
+
+	// createUserErr, when set, fails CreateUser
+	createUserErr error
+	// getPostsErr, when set, fails GetPostsForUser
+	getPostsErr error
+
+	userLocks       int
+	statsCalls      int
+	lastFeedsArg    database.GetFeedsWithStatsParams
+	lastTrendingArg database.GetTrendingPostCandidatesParams
+}
+
+// activityKey identifies a feed_activity bucket
+type activityKey struct {
+	feedID      uuid.UUID
+	granularity string
+	bucketStart time.Time
+}
+
+func newFakeQuerier() *fakeQuerier {
+	return &fakeQuerier{
+		refreshTokens: make(map[string]database.RefreshToken),
+		rotatedTokens: make(map[string]database.RotatedRefreshToken),
+		users:         make(map[uuid.UUID]database.User),
+		feeds:         make(map[uuid.UUID]database.Feed),
+		reads:         make(map[[2]uuid.UUID]bool),
+		bookmarks:     make(map[[2]uuid.UUID]time.Time),
+		activity:      make(map[activityKey]int32),
+	}
+}
+
+// addUsers stores users
+func (q *fakeQuerier) addUsers(users ...database.User) {
+	for _, user := range users {
+		q.users[user.ID] = user
+	}
+}
+
+// addFeeds stores feeds
+func (q *fakeQuerier) addFeeds(feeds ...database.Feed) {
+	for _, feed := range feeds {
+		q.feeds[feed.ID] = feed
+	}
+}
+
+// addRefreshTokens stores refresh tokens
+func (q *fakeQuerier) addRefreshTokens(tokens ...database.RefreshToken) {
+	for _, token := range tokens {
+		q.refreshTokens[token.TokenHash] = token
+	}
+}
+
+// follow has userID follow feedID, creating the feed if it is unknown
+func (q *fakeQuerier) follow(userID, feedID uuid.UUID) database.FeedFollow {
+	if _, ok := q.feeds[feedID]; !ok {
+		q.feeds[feedID] = database.Feed{ID: feedID}
+	}
+	follow := database.FeedFollow{ID: uuid.New(), UserID: userID, FeedID: feedID, Notify: true}
+	q.follows = append(q.follows, follow)
+	return follow
+}
+
+// followPosts stores posts and has userID follow the feed of each
+func (q *fakeQuerier) followPosts(userID uuid.UUID, posts ...database.Post) {
+	for _, post := range posts {
+		if !q.isFollowed(userID, post.FeedID) {
+			q.follow(userID, post.FeedID)
+		}
+		q.posts = append(q.posts, post)
+	}
+}
+
+// addNotification stores a new post notification for userID and returns its id
+func (q *fakeQuerier) addNotification(userID uuid.UUID, postCount int32) uuid.UUID {
+	feed := database.Feed{ID: uuid.New(), Name: "Go Blog"}
+	q.addFeeds(feed)
+	notification := database.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FeedID:    feed.ID,
+		Type:      "NEW_POST_AVAILABLE",
+		PostCount: postCount,
+		CreatedAt: time.Now().UTC(),
+	}
+	q.notifications = append(q.notifications, notification)
+	return notification.ID
+}
+
+// isFollowed reports whether userID follows feedID
+func (q *fakeQuerier) isFollowed(userID, feedID uuid.UUID) bool {
+	_, ok := q.followOf(userID, feedID)
+	return ok
+}
+
+func (q *fakeQuerier) followOf(userID, feedID uuid.UUID) (database.FeedFollow, bool) {
+	for _, follow := range q.follows {
+		if follow.UserID == userID && follow.FeedID == feedID {
+			return follow, true
+		}
+	}
+	return database.FeedFollow{}, false
+}
+
+func (q *fakeQuerier) post(id uuid.UUID) (database.Post, bool) {
+	for _, post := range q.posts {
+		if post.ID == id {
+			return post, true
+		}
+	}
+	return database.Post{}, false
+}
+
+// followedPost reports whether the post is in one of userID's followed feeds
+func (q *fakeQuerier) followedPost(userID, postID uuid.UUID) bool {
+	post, ok := q.post(postID)
+	return ok && q.isFollowed(userID, post.FeedID)
+}
+
+// newestFirst orders posts like ORDER BY published_at DESC, id DESC
+func newestFirst(a, b database.Post) bool {
+	if !a.PublishedAt.Equal(b.PublishedAt) {
+		return a.PublishedAt.After(b.PublishedAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) > 0
+}
+
+func limitRows[T any](rows []T, limit int32) []T {
+	if len(rows) > int(limit) {
+		return rows[:limit]
+	}
+	return rows
+}
+
+// Users and identities
+
+func (q *fakeQuerier) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	if q.createUserErr != nil {
+		return database.User{}, q.createUserErr
+	}
+	for _, user := range q.users {
+		if arg.Email.Valid && user.Email == arg.Email {
+			return database.User{}, &pq.Error{
+				Code:       "23505",
+				Message:    `duplicate key value violates unique constraint "users_email_key"`,
+				Constraint: "users_email_key",
+			}
+		}
+	}
+	user := database.User(arg)
+	q.users[user.ID] = user
+	return user, nil
+}
+
+func (q *fakeQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	user, ok := q.users[id]
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (q *fakeQuerier) GetUserByEmail(ctx context.Context, email sql.NullString) (database.User, error) {
+	for _, user := range q.users {
+		if email.Valid && user.Email.Valid && strings.EqualFold(user.Email.String, email.String) {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) LockUserForUpdate(ctx context.Context, id uuid.UUID) error {
+	q.userLocks++
+	return nil
+}
+
+func (q *fakeQuerier) CreateIdentity(ctx context.Context, arg database.CreateIdentityParams) (database.Identity, error) {
+	identity := database.Identity(arg)
+	q.identities = append(q.identities, identity)
+	return identity, nil
+}
+
+func (q *fakeQuerier) GetIdentityByProvider(ctx context.Context, arg database.GetIdentityByProviderParams) (database.Identity, error) {
+	for _, identity := range q.identities {
+		if identity.Provider == arg.Provider && identity.ProviderUserID == arg.ProviderUserID {
+			return identity, nil
+		}
+	}
+	return database.Identity{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetIdentityForUser(ctx context.Context, arg database.GetIdentityForUserParams) (database.Identity, error) {
+	for _, identity := range q.identities {
+		if identity.ID == arg.ID && identity.UserID == arg.UserID {
+			return identity, nil
+		}
+	}
+	return database.Identity{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) CountIdentitiesByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, identity := range q.identities {
+		if identity.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *fakeQuerier) DeleteIdentity(ctx context.Context, arg database.DeleteIdentityParams) error {
+	for i, identity := range q.identities {
+		if identity.ID == arg.ID && identity.UserID == arg.UserID {
+			q.identities = append(q.identities[:i], q.identities[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// Refresh tokens
+
+func (q *fakeQuerier) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) (database.RefreshToken, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	token := database.RefreshToken(arg)
+	q.refreshTokens[arg.TokenHash] = token
+	return token, nil
+}
+
+func (q *fakeQuerier) CreateRotatedRefreshToken(ctx context.Context, arg database.CreateRotatedRefreshTokenParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.rotatedTokens[arg.TokenHash]; !ok {
+		q.rotatedTokens[arg.TokenHash] = database.RotatedRefreshToken(arg)
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetRotatedRefreshToken(ctx context.Context, tokenHash string) (database.RotatedRefreshToken, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rotated, ok := q.rotatedTokens[tokenHash]
+	if !ok {
+		return database.RotatedRefreshToken{}, sql.ErrNoRows
+	}
+	return rotated, nil
+}
+
+func (q *fakeQuerier) DeleteExpiredRotatedRefreshTokens(ctx context.Context, arg database.DeleteExpiredRotatedRefreshTokensParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for hash, rotated := range q.rotatedTokens {
+		if rotated.UserID == arg.UserID && rotated.ExpiresAt.Before(arg.ExpiresAt) {
+			delete(q.rotatedTokens, hash)
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetRefreshTokensByUserID(ctx context.Context, userID uuid.UUID) ([]database.RefreshToken, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var tokens []database.RefreshToken
+	for _, token := range q.refreshTokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+func (q *fakeQuerier) DeleteRefreshToken(ctx context.Context, userID uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for hash, token := range q.refreshTokens {
+		if token.UserID == userID {
+			delete(q.refreshTokens, hash)
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteRefreshTokenByHash(ctx context.Context, arg database.DeleteRefreshTokenByHashParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	token, ok := q.refreshTokens[arg.TokenHash]
+	if !ok || token.UserID != arg.UserID {
+		return 0, nil
+	}
+	delete(q.refreshTokens, arg.TokenHash)
+	return 1, nil
+}
+
+func (q *fakeQuerier) DeleteSessionForUser(ctx context.Context, arg database.DeleteSessionForUserParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var deleted int64
+	for hash, token := range q.refreshTokens {
+		if token.SessionID == arg.SessionID && token.UserID == arg.UserID {
+			delete(q.refreshTokens, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Feeds
+
+func (q *fakeQuerier) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	// ON CONFLICT (url) DO NOTHING returns no row
+	if _, err := q.GetFeedByURL(ctx, arg.Url); err == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	feed := database.Feed{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Name:        arg.Name,
+		Url:         arg.Url,
+		UserID:      arg.UserID,
+		Description: arg.Description,
+		LogoUrl:     arg.LogoUrl,
+		Priority:    arg.Priority,
+	}
+	q.feeds[feed.ID] = feed
+	return feed, nil
+}
+
+func (q *fakeQuerier) GetFeedByID(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	feed, ok := q.feeds[id]
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return feed, nil
+}
+
+func (q *fakeQuerier) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	for _, feed := range q.feeds {
+		if feed.Url == url {
+			return feed, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	feeds := make([]database.Feed, 0, len(q.feeds))
+	for _, feed := range q.feeds {
+		feeds = append(feeds, feed)
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].CreatedAt.Before(feeds[j].CreatedAt) })
+	return feeds, nil
+}
+
+func (q *fakeQuerier) GetFeedsByPriority(ctx context.Context) ([]database.Feed, error) {
+	feeds, _ := q.GetFeeds(ctx)
+	sort.SliceStable(feeds, func(i, j int) bool {
+		if feeds[i].Priority != feeds[j].Priority {
+			return feeds[i].Priority > feeds[j].Priority
+		}
+		return feeds[i].UpdatedAt.Before(feeds[j].UpdatedAt)
+	})
+	return feeds, nil
+}
+
+func (q *fakeQuerier) GetFeedsWithStats(ctx context.Context, arg database.GetFeedsWithStatsParams) ([]database.GetFeedsWithStatsRow, error) {
+	q.lastFeedsArg = arg
+
+	var page []database.GetFeedsWithStatsRow
+	for _, feed := range q.feeds {
+		if !feed.CreatedAt.Before(arg.CreatedAt) {
+			continue
+		}
+		if arg.SearchPattern.Valid && !ilike(feed.Name, arg.SearchPattern.String) && !ilike(feed.Description.String, arg.SearchPattern.String) {
+			continue
+		}
+		row := database.GetFeedsWithStatsRow{ID: feed.ID, CreatedAt: feed.CreatedAt, Name: feed.Name, Description: feed.Description, FollowerCount: feed.FollowerCount}
+		for _, follow := range q.follows {
+			if follow.FeedID == feed.ID {
+				row.FollowCount++
+				row.IsFollowed = row.IsFollowed || (arg.UserID.Valid && follow.UserID == arg.UserID.UUID)
+			}
+		}
+		page = append(page, row)
+	}
+	sort.Slice(page, func(i, j int) bool {
+		if !page[i].CreatedAt.Equal(page[j].CreatedAt) {
+			return page[i].CreatedAt.After(page[j].CreatedAt)
+		}
+		return bytes.Compare(page[i].ID[:], page[j].ID[:]) > 0
+	})
+	return limitRows(page, arg.RowLimit), nil
+}
+
+// ilike reports whether text matches a Postgres ILIKE pattern with the default escape character
+func ilike(text, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(text)
+}
+
+func (q *fakeQuerier) FeedNameTakenByUser(ctx context.Context, arg database.FeedNameTakenByUserParams) (bool, error) {
+	for _, feed := range q.feeds {
+		if feed.UserID == arg.UserID && feed.ID != arg.ExcludeID && strings.EqualFold(feed.Name, arg.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *fakeQuerier) UpdateFeedPriority(ctx context.Context, arg database.UpdateFeedPriorityParams) (database.Feed, error) {
+	feed, ok := q.feeds[arg.ID]
+	if !ok || feed.UserID != arg.UserID {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	feed.Priority = arg.Priority
+	feed.UpdatedAt = arg.UpdatedAt
+	q.feeds[feed.ID] = feed
+	return feed, nil
+}
+
+func (q *fakeQuerier) UpdateFeedURL(ctx context.Context, arg database.UpdateFeedURLParams) error {
+	if feed, ok := q.feeds[arg.ID]; ok {
+		feed.Url = arg.Url
+		q.feeds[feed.ID] = feed
+	}
+	return nil
+}
+
+func (q *fakeQuerier) IncrementFeedFollowerCount(ctx context.Context, id uuid.UUID) error {
+	if feed, ok := q.feeds[id]; ok {
+		feed.FollowerCount++
+		q.feeds[id] = feed
+	}
+	return nil
+}
+
+// DeleteFeed cascades to the feed's follows, posts, activity and notifications like
+// the ON DELETE CASCADE foreign keys do
+func (q *fakeQuerier) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	delete(q.feeds, id)
+	q.DeleteFeedFollowsByFeed(ctx, id)
+	q.DeletePostsByFeed(ctx, id)
+	for key := range q.activity {
+		if key.feedID == id {
+			delete(q.activity, key)
+		}
+	}
+	kept := q.notifications[:0]
+	for _, notification := range q.notifications {
+		if notification.FeedID != id {
+			kept = append(kept, notification)
+		}
+	}
+	q.notifications = kept
+	return nil
+}
+
+func (q *fakeQuerier) MergeFeedActivity(ctx context.Context, arg database.MergeFeedActivityParams) error {
+	for key, count := range q.activity {
+		if key.feedID == arg.FromFeedID {
+			q.activity[activityKey{feedID: arg.ToFeedID, granularity: key.granularity, bucketStart: key.bucketStart}] += count
+		}
+	}
+	return nil
+}
+
+// Feed follows
+
+func (q *fakeQuerier) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.FeedFollow, error) {
+	if _, ok := q.feeds[arg.FeedID]; !ok {
+		return database.FeedFollow{}, &pq.Error{Code: "23503"}
+	}
+	if q.isFollowed(arg.UserID, arg.FeedID) {
+		return database.FeedFollow{}, &pq.Error{Code: "23505"}
+	}
+	follow := database.FeedFollow{ID: arg.ID, CreatedAt: arg.CreatedAt, UpdatedAt: arg.UpdatedAt, UserID: arg.UserID, FeedID: arg.FeedID, Notify: true}
+	q.follows = append(q.follows, follow)
+	return follow, nil
+}
+
+func (q *fakeQuerier) CountFeedFollowsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, follow := range q.follows {
+		if follow.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *fakeQuerier) UpdateFeedFollow(ctx context.Context, arg database.UpdateFeedFollowParams) (database.FeedFollow, error) {
+	for i, follow := range q.follows {
+		if follow.ID != arg.ID || follow.UserID != arg.UserID {
+			continue
+		}
+		if arg.SetAlias {
+			follow.Alias = arg.Alias
+		}
+		if arg.SetCategory {
+			follow.Category = arg.Category
+		}
+		if arg.SetNotify {
+			follow.Notify = arg.Notify
+		}
+		follow.UpdatedAt = arg.UpdatedAt
+		q.follows[i] = follow
+		return follow, nil
+	}
+	return database.FeedFollow{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetFeedFollowsWithFeedName(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsWithFeedNameRow, error) {
+	var rows []database.GetFeedFollowsWithFeedNameRow
+	for _, follow := range q.follows {
+		if follow.UserID != userID {
+			continue
+		}
+		rows = append(rows, database.GetFeedFollowsWithFeedNameRow{
+			ID:        follow.ID,
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    follow.UserID,
+			FeedID:    follow.FeedID,
+			Alias:     follow.Alias,
+			Category:  follow.Category,
+			Notify:    follow.Notify,
+			FeedName:  q.feeds[follow.FeedID].Name,
+		})
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetFeedFollowsPaginated(ctx context.Context, arg database.GetFeedFollowsPaginatedParams) ([]database.GetFeedFollowsPaginatedRow, error) {
+	var page []database.GetFeedFollowsPaginatedRow
+	for _, follow := range q.follows {
+		if follow.UserID != arg.UserID || !follow.CreatedAt.Before(arg.CreatedBefore) {
+			continue
+		}
+		if arg.Category.Valid && follow.Category != arg.Category {
+			continue
+		}
+		feed := q.feeds[follow.FeedID]
+		page = append(page, database.GetFeedFollowsPaginatedRow{
+			ID:             follow.ID,
+			CreatedAt:      follow.CreatedAt,
+			UpdatedAt:      follow.UpdatedAt,
+			UserID:         follow.UserID,
+			FeedID:         follow.FeedID,
+			Alias:          follow.Alias,
+			Category:       follow.Category,
+			Notify:         follow.Notify,
+			FeedName:       feed.Name,
+			FeedLastPostAt: feed.LastPostAt,
+		})
+	}
+	sort.Slice(page, func(i, j int) bool { return page[i].CreatedAt.After(page[j].CreatedAt) })
+	return limitRows(page, arg.RowLimit), nil
+}
+
+func (q *fakeQuerier) GetUnreadCountsForUser(ctx context.Context, arg database.GetUnreadCountsForUserParams) ([]database.GetUnreadCountsForUserRow, error) {
+	var follows []database.FeedFollow
+	for _, follow := range q.follows {
+		if follow.UserID == arg.UserID {
+			follows = append(follows, follow)
+		}
+	}
+	sort.SliceStable(follows, func(i, j int) bool { return follows[i].CreatedAt.Before(follows[j].CreatedAt) })
+
+	rows := make([]database.GetUnreadCountsForUserRow, 0, len(follows))
+	for _, follow := range follows {
+		row := database.GetUnreadCountsForUserRow{
+			FeedFollowID: follow.ID,
+			FeedID:       follow.FeedID,
+			Alias:        follow.Alias,
+			FeedName:     q.feeds[follow.FeedID].Name,
+		}
+		for _, post := range q.posts {
+			if post.FeedID != follow.FeedID || q.reads[[2]uuid.UUID{arg.UserID, post.ID}] {
+				continue
+			}
+			if arg.AfterFollowOnly && post.PublishedAt.Before(follow.CreatedAt) {
+				continue
+			}
+			row.UnreadCount++
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) DeleteFeedFollowsByFeed(ctx context.Context, feedID uuid.UUID) ([]uuid.UUID, error) {
+	var followerIDs []uuid.UUID
+	kept := q.follows[:0]
+	for _, follow := range q.follows {
+		if follow.FeedID == feedID {
+			followerIDs = append(followerIDs, follow.UserID)
+			continue
+		}
+		kept = append(kept, follow)
+	}
+	q.follows = kept
+	return followerIDs, nil
+}
+
+func (q *fakeQuerier) DeleteFeedFollowsAlreadyOnFeed(ctx context.Context, arg database.DeleteFeedFollowsAlreadyOnFeedParams) (int64, error) {
+	var deleted int64
+	kept := q.follows[:0]
+	for _, follow := range q.follows {
+		if follow.FeedID == arg.FromFeedID && q.isFollowed(follow.UserID, arg.ToFeedID) {
+			deleted++
+			continue
+		}
+		kept = append(kept, follow)
+	}
+	q.follows = kept
+	return deleted, nil
+}
+
+func (q *fakeQuerier) MoveFeedFollows(ctx context.Context, arg database.MoveFeedFollowsParams) (int64, error) {
+	var moved int64
+	for i := range q.follows {
+		if q.follows[i].FeedID == arg.FromFeedID {
+			q.follows[i].FeedID = arg.ToFeedID
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// Posts
+
+func (q *fakeQuerier) GetPostsByFeed(ctx context.Context, arg database.GetPostsByFeedParams) ([]database.Post, error) {
+	var page []database.Post
+	for _, post := range q.posts {
+		if post.FeedID == arg.FeedID && post.PublishedAt.Before(arg.PublishedAt) {
+			page = append(page, post)
+		}
+	}
+	sort.Slice(page, func(i, j int) bool { return newestFirst(page[i], page[j]) })
+	return limitRows(page, arg.Limit), nil
+}
+
+func (q *fakeQuerier) CountPostsByFeedSince(ctx context.Context, arg database.CountPostsByFeedSinceParams) (int64, error) {
+	var count int64
+	for _, post := range q.posts {
+		if post.FeedID == arg.FeedID && !post.PublishedAt.Before(arg.PublishedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *fakeQuerier) DeletePostsByFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	var deleted int64
+	kept := q.posts[:0]
+	for _, post := range q.posts {
+		if post.FeedID == feedID {
+			deleted++
+			continue
+		}
+		kept = append(kept, post)
+	}
+	q.posts = kept
+	return deleted, nil
+}
+
+func (q *fakeQuerier) MovePosts(ctx context.Context, arg database.MovePostsParams) (int64, error) {
+	var moved int64
+	for i := range q.posts {
+		if q.posts[i].FeedID == arg.FromFeedID {
+			q.posts[i].FeedID = arg.ToFeedID
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// followedPostRow is a post in one of the user's followed feeds with the follow it came through
+type followedPostRow struct {
+	post   database.Post
+	follow database.FeedFollow
+}
+
+// userPosts pages through the posts in userID's followed feeds like the GetPostsForUser
+// queries: the page before cursor newest first, or after it oldest first
+func (q *fakeQuerier) userPosts(userID uuid.UUID, cursor time.Time, limit int32, oldestFirst, afterFollowOnly, unreadOnly bool) []followedPostRow {
+	var page []followedPostRow
+	for _, post := range q.posts {
+		follow, ok := q.followOf(userID, post.FeedID)
+		if !ok {
+			continue
+		}
+		if oldestFirst && !post.PublishedAt.After(cursor) || !oldestFirst && !post.PublishedAt.Before(cursor) {
+			continue
+		}
+		if afterFollowOnly && post.PublishedAt.Before(follow.CreatedAt) {
+			continue
+		}
+		if unreadOnly && q.reads[[2]uuid.UUID{userID, post.ID}] {
+			continue
+		}
+		page = append(page, followedPostRow{post: post, follow: follow})
+	}
+	sort.Slice(page, func(i, j int) bool { return newestFirst(page[i].post, page[j].post) != oldestFirst })
+	return limitRows(page, limit)
+}
+
+func (q *fakeQuerier) userPostsOnly(rows []followedPostRow) []database.Post {
+	posts := make([]database.Post, len(rows))
+	for i, row := range rows {
+		posts[i] = row.post
+	}
+	return posts
+}
+
+func (q *fakeQuerier) userPostsWithFeed(rows []followedPostRow) []database.GetPostsForUserWithFeedRow {
+	posts := make([]database.GetPostsForUserWithFeedRow, len(rows))
+	for i, row := range rows {
+		feed := q.feeds[row.post.FeedID]
+		posts[i] = database.GetPostsForUserWithFeedRow{
+			ID:                   row.post.ID,
+			CreatedAt:            row.post.CreatedAt,
+			UpdatedAt:            row.post.UpdatedAt,
+			Title:                row.post.Title,
+			Url:                  row.post.Url,
+			Description:          row.post.Description,
+			PublishedAt:          row.post.PublishedAt,
+			FeedID:               row.post.FeedID,
+			DescriptionTruncated: row.post.DescriptionTruncated,
+			FullDescription:      row.post.FullDescription,
+			FullContent:          row.post.FullContent,
+			FeedName:             feed.Name,
+			FeedUrl:              feed.Url,
+			FeedLogoUrl:          feed.LogoUrl,
+			FeedAlias:            row.follow.Alias,
+		}
+	}
+	return posts
+}
+
+func (q *fakeQuerier) GetPostsForUser(ctx context.Context, arg database.GetPostsForUserParams) ([]database.Post, error) {
+	if q.getPostsErr != nil {
+		return nil, q.getPostsErr
+	}
+	return q.userPostsOnly(q.userPosts(arg.UserID, arg.PublishedAt, arg.RowLimit, false, false, arg.UnreadOnly)), nil
+}
+
+func (q *fakeQuerier) GetPostsForUserAfterFollow(ctx context.Context, arg database.GetPostsForUserAfterFollowParams) ([]database.Post, error) {
+	return q.userPostsOnly(q.userPosts(arg.UserID, arg.PublishedAt, arg.RowLimit, false, true, arg.UnreadOnly)), nil
+}
+
+func (q *fakeQuerier) GetPostsForUserOldestFirst(ctx context.Context, arg database.GetPostsForUserOldestFirstParams) ([]database.Post, error) {
+	return q.userPostsOnly(q.userPosts(arg.UserID, arg.PublishedAt, arg.RowLimit, true, arg.AfterFollowOnly, arg.UnreadOnly)), nil
+}
+
+func (q *fakeQuerier) GetPostsForUserWithFeed(ctx context.Context, arg database.GetPostsForUserWithFeedParams) ([]database.GetPostsForUserWithFeedRow, error) {
+	return q.userPostsWithFeed(q.userPosts(arg.UserID, arg.PublishedAt, arg.RowLimit, false, arg.AfterFollowOnly, arg.UnreadOnly)), nil
+}
+
+func (q *fakeQuerier) GetPostsForUserWithFeedOldestFirst(ctx context.Context, arg database.GetPostsForUserWithFeedOldestFirstParams) ([]database.GetPostsForUserWithFeedOldestFirstRow, error) {
+	var rows []database.GetPostsForUserWithFeedOldestFirstRow
+	for _, row := range q.userPostsWithFeed(q.userPosts(arg.UserID, arg.PublishedAt, arg.RowLimit, true, arg.AfterFollowOnly, arg.UnreadOnly)) {
+		rows = append(rows, database.GetPostsForUserWithFeedOldestFirstRow(row))
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetTrendingPostCandidates(ctx context.Context, arg database.GetTrendingPostCandidatesParams) ([]database.GetTrendingPostCandidatesRow, error) {
+	q.lastTrendingArg = arg
+
+	var posts []database.Post
+	for _, post := range q.posts {
+		if q.feeds[post.FeedID].FollowerCount > 0 && !post.PublishedAt.Before(arg.PublishedSince) && !post.PublishedAt.After(arg.PublishedBefore) {
+			posts = append(posts, post)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return newestFirst(posts[i], posts[j]) })
+
+	var rows []database.GetTrendingPostCandidatesRow
+	for _, post := range limitRows(posts, arg.RowLimit) {
+		feed := q.feeds[post.FeedID]
+		rows = append(rows, database.GetTrendingPostCandidatesRow{
+			ID:                   post.ID,
+			CreatedAt:            post.CreatedAt,
+			UpdatedAt:            post.UpdatedAt,
+			Title:                post.Title,
+			Url:                  post.Url,
+			Description:          post.Description,
+			PublishedAt:          post.PublishedAt,
+			FeedID:               post.FeedID,
+			DescriptionTruncated: post.DescriptionTruncated,
+			FullDescription:      post.FullDescription,
+			FullContent:          post.FullContent,
+			FeedName:             feed.Name,
+			FeedUrl:              feed.Url,
+			FeedLogoUrl:          feed.LogoUrl,
+			FeedFollowerCount:    feed.FollowerCount,
+		})
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetPlatformStats(ctx context.Context) (database.GetPlatformStatsRow, error) {
+	q.statsCalls++
+	return database.GetPlatformStatsRow{
+		Users:   int64(len(q.users)),
+		Feeds:   int64(len(q.feeds)),
+		Posts:   int64(len(q.posts)),
+		Follows: int64(len(q.follows)),
+	}, nil
+}
+
+// Post reads
+
+func (q *fakeQuerier) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) (int64, error) {
+	if !q.followedPost(arg.UserID, arg.PostID) {
+		return 0, nil
+	}
+	q.reads[[2]uuid.UUID{arg.UserID, arg.PostID}] = true
+	return 1, nil
+}
+
+func (q *fakeQuerier) MarkPostsRead(ctx context.Context, arg database.MarkPostsReadParams) (int64, error) {
+	var marked int64
+	for _, id := range arg.PostIds {
+		key := [2]uuid.UUID{arg.UserID, id}
+		if q.followedPost(arg.UserID, id) && !q.reads[key] {
+			q.reads[key] = true
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (q *fakeQuerier) UnmarkPostRead(ctx context.Context, arg database.UnmarkPostReadParams) (int64, error) {
+	key := [2]uuid.UUID{arg.UserID, arg.PostID}
+	if !q.reads[key] {
+		return 0, nil
+	}
+	delete(q.reads, key)
+	return 1, nil
+}
+
+func (q *fakeQuerier) IsFollowedPost(ctx context.Context, arg database.IsFollowedPostParams) (bool, error) {
+	return q.followedPost(arg.UserID, arg.PostID), nil
+}
+
+func (q *fakeQuerier) GetReadPostIDs(ctx context.Context, arg database.GetReadPostIDsParams) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, id := range arg.PostIds {
+		if q.reads[[2]uuid.UUID{arg.UserID, id}] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Bookmarks
+
+func (q *fakeQuerier) CreateBookmark(ctx context.Context, arg database.CreateBookmarkParams) error {
+	if _, ok := q.post(arg.PostID); !ok {
+		return &pq.Error{Code: "23503"}
+	}
+	key := [2]uuid.UUID{arg.UserID, arg.PostID}
+	if _, ok := q.bookmarks[key]; !ok {
+		q.bookmarks[key] = arg.CreatedAt
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteBookmark(ctx context.Context, arg database.DeleteBookmarkParams) error {
+	delete(q.bookmarks, [2]uuid.UUID{arg.UserID, arg.PostID})
+	return nil
+}
+
+func (q *fakeQuerier) GetBookmarksForUser(ctx context.Context, arg database.GetBookmarksForUserParams) ([]database.GetBookmarksForUserRow, error) {
+	var rows []database.GetBookmarksForUserRow
+	for key, bookmarkedAt := range q.bookmarks {
+		if key[0] != arg.UserID || !bookmarkedAt.Before(arg.BookmarkedBefore) {
+			continue
+		}
+		post, _ := q.post(key[1])
+		rows = append(rows, database.GetBookmarksForUserRow{ID: post.ID, Title: post.Title, PublishedAt: post.PublishedAt, FeedID: post.FeedID, BookmarkedAt: bookmarkedAt})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].BookmarkedAt.After(rows[j].BookmarkedAt) })
+	return limitRows(rows, arg.RowLimit), nil
+}
+
+// Notifications
+
+func (q *fakeQuerier) ListUnackedNotifications(ctx context.Context, arg database.ListUnackedNotificationsParams) ([]database.ListUnackedNotificationsRow, error) {
+	var rows []database.ListUnackedNotificationsRow
+	for _, notification := range q.notifications {
+		if notification.UserID != arg.UserID || notification.AckedAt.Valid {
+			continue
+		}
+		rows = append(rows, database.ListUnackedNotificationsRow{
+			ID:        notification.ID,
+			FeedID:    notification.FeedID,
+			FeedName:  q.feeds[notification.FeedID].Name,
+			Type:      notification.Type,
+			PostCount: notification.PostCount,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].CreatedAt.Before(rows[j].CreatedAt) })
+	return limitRows(rows, arg.RowLimit), nil
+}
+
+func (q *fakeQuerier) AckNotifications(ctx context.Context, arg database.AckNotificationsParams) (int64, error) {
+	var acked int64
+	for i, notification := range q.notifications {
+		for _, id := range arg.Ids {
+			if notification.ID == id && notification.UserID == arg.UserID && !notification.AckedAt.Valid {
+				q.notifications[i].AckedAt = arg.AckedAt
+				acked++
+			}
+		}
+	}
+	return acked, nil
+}
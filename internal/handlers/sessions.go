@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// HandlerListSessions returns the active sessions of the authenticated user
+// @Summary     List sessions
+// @Description Lists the user's active sessions (one per login, each with its own refresh token), most recently refreshed first
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Success     200  {object}  object  "Sessions"
+// @Failure     401  {object}  object  "Unauthorized"
+// @Failure     500  {object}  object  "Server error"
+// @Router      /v1/auth/sessions [get]
+func (cfg *Config) HandlerListSessions(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondWithSessions(w, r, cfg.DB, user)
+}
+
+// respondWithSessions writes the user's active sessions from store. Only the
+// user's own refresh tokens are loaded, and token hashes never leave the server.
+func (cfg *Config) respondWithSessions(w http.ResponseWriter, r *http.Request, store database.Querier, user database.User) {
+	tokens, err := store.GetRefreshTokensByUserID(r.Context(), user.ID)
+	if err != nil {
+		respondWithDBError(w, err, "List sessions")
+		return
+	}
+
+	type response struct {
+		Sessions []models.Session `json:"sessions"`
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, response{Sessions: cfg.activeSessions(tokens, time.Now())})
+}
+
+// activeSessions converts the refresh tokens that can still be used into sessions
+func (cfg *Config) activeSessions(tokens []database.RefreshToken, now time.Time) []models.Session {
+	sessions := make([]models.Session, 0, len(tokens))
+	for _, token := range tokens {
+		if cfg.refreshTokenExpired(token, now) {
+			continue
+		}
+		sessions = append(sessions, models.DatabaseRefreshTokenToSession(token))
+	}
+	return sessions
+}
+
+// HandlerRevokeSession ends one of the authenticated user's sessions
+// The session's refresh token is deleted, so that device can no longer refresh;
+// access tokens it already holds stay valid until they expire.
+// @Summary     Revoke a session
+// @Description Deletes a session's refresh token so the device can't refresh anymore
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       sessionID  path      string  true  "Session ID"
+// @Success     204        {object}  object  "Session revoked"
+// @Failure     400        {object}  object  "Invalid ID"
+// @Failure     404        {object}  object  "Session not found"
+// @Failure     500        {object}  object  "Server error"
+// @Router      /v1/auth/sessions/{sessionID} [delete]
+func (cfg *Config) HandlerRevokeSession(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondToSessionRevoke(w, r, cfg.DB, user)
+}
+
+// respondToSessionRevoke revokes the session named in the path through store. A
+// session of another user is reported as not found, so IDs can't be probed.
+func respondToSessionRevoke(w http.ResponseWriter, r *http.Request, store database.Querier, user database.User) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		models.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid session ID: %v", err))
+		return
+	}
+
+	err = revokeSession(r.Context(), store, user.ID, sessionID)
+	if errors.Is(err, apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if err != nil {
+		respondWithDBError(w, err, "Revoke session")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusNoContent, struct{}{})
+}
+
+// revokeSession deletes the user's session, returning apperr.ErrNotFound if it doesn't
+// exist or belongs to another user
+func revokeSession(ctx context.Context, store database.Querier, userID, sessionID uuid.UUID) error {
+	deleted, err := store.DeleteSessionForUser(ctx, database.DeleteSessionForUserParams{
+		SessionID: sessionID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return apperr.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+func TestHandlerListSessions_ReturnsOnlyOwnSessions(t *testing.T) {
+	cfg := &Config{}
+	now := time.Now().UTC()
+	user := database.User{ID: uuid.New()}
+	own := database.RefreshToken{
+		ID:          uuid.New(),
+		SessionID:   uuid.New(),
+		UserID:      user.ID,
+		TokenHash:   "own-hash",
+		DeviceLabel: "laptop",
+		CreatedAt:   now.Add(-time.Hour),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	someoneElses := database.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: uuid.New(),
+		UserID:    uuid.New(),
+		TokenHash: "other-hash",
+		CreatedAt: now.Add(-time.Hour),
+		ExpiresAt: now.Add(time.Hour),
+	}
+	store := newFakeQuerier()
+	store.addRefreshTokens(own, someoneElses)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/auth/sessions", nil)
+	rec := httptest.NewRecorder()
+	cfg.respondWithSessions(rec, req, store, user)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "hash") {
+		t.Errorf("Expected no token hashes in the response, got %s", rec.Body.String())
+	}
+
+	var body struct {
+		Sessions []models.Session `json:"sessions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %d: %+v", len(body.Sessions), body.Sessions)
+	}
+	session := body.Sessions[0]
+	if session.ID != own.SessionID || session.DeviceLabel != "laptop" {
+		t.Errorf("Expected the user's laptop session, got %+v", session)
+	}
+	if !session.CreatedAt.Equal(own.CreatedAt) || !session.ExpiresAt.Equal(own.ExpiresAt) {
+		t.Errorf("Expected created_at %v and expires_at %v, got %+v", own.CreatedAt, own.ExpiresAt, session)
+	}
+}
+
+func TestRevokeSession_OwnSession_IsDeleted(t *testing.T) {
+	userID := uuid.New()
+	session := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), UserID: userID, TokenHash: "session-hash"}
+	other := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), UserID: userID, TokenHash: "other-hash"}
+	store := newFakeQuerier()
+	store.addRefreshTokens(session, other)
+
+	if err := revokeSession(context.Background(), store, userID, session.SessionID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := store.refreshTokens[other.TokenHash]; len(store.refreshTokens) != 1 || !ok {
+		t.Errorf("Expected only the revoked session to be deleted, got %+v", store.refreshTokens)
+	}
+}
+
+func TestRevokeSession_AnotherUsersSession_NotFound(t *testing.T) {
+	owner := uuid.New()
+	session := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), UserID: owner, TokenHash: "session-hash"}
+	store := newFakeQuerier()
+	store.addRefreshTokens(session)
+
+	err := revokeSession(context.Background(), store, uuid.New(), session.SessionID)
+	if !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("Expected apperr.ErrNotFound, got %v", err)
+	}
+
+	if len(store.refreshTokens) != 1 {
+		t.Error("Expected the other user's session to be kept")
+	}
+}
+
+func TestRevokeSession_UnknownSession_NotFound(t *testing.T) {
+	store := newFakeQuerier()
+
+	if err := revokeSession(context.Background(), store, uuid.New(), uuid.New()); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("Expected apperr.ErrNotFound, got %v", err)
+	}
+}
+
+// revokeSessionRequest builds a DELETE /v1/auth/sessions/{sessionID} request
+func revokeSessionRequest(sessionID string) *http.Request {
+	req := httptest.NewRequest(http.MethodDelete, "/v1/auth/sessions/"+sessionID, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("sessionID", sessionID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandlerRevokeSession(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	own := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), UserID: user.ID, TokenHash: "own-hash"}
+	someoneElses := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), UserID: uuid.New(), TokenHash: "other-hash"}
+
+	testCases := []struct {
+		name           string
+		sessionID      string
+		expectedStatus int
+		expectedLeft   int
+	}{
+		{"Own session", own.SessionID.String(), http.StatusNoContent, 1},
+		{"Another user's session", someoneElses.SessionID.String(), http.StatusNotFound, 2},
+		{"Unknown session", uuid.NewString(), http.StatusNotFound, 2},
+		{"Invalid ID", "not-a-uuid", http.StatusBadRequest, 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeQuerier()
+			store.addRefreshTokens(own, someoneElses)
+			rec := httptest.NewRecorder()
+
+			respondToSessionRevoke(rec, revokeSessionRequest(tc.sessionID), store, user)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+			if len(store.refreshTokens) != tc.expectedLeft {
+				t.Errorf("Expected %d sessions left, got %d", tc.expectedLeft, len(store.refreshTokens))
+			}
+			kept := false
+			for _, token := range store.refreshTokens {
+				kept = kept || token.SessionID == someoneElses.SessionID
+			}
+			if !kept {
+				t.Error("Expected the other user's session to be kept")
+			}
+		})
+	}
+}
+
+func TestActiveSessions_SkipsExpiredTokens(t *testing.T) {
+	cfg := &Config{}
+	now := time.Now().UTC()
+	active := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+	expired := database.RefreshToken{ID: uuid.New(), SessionID: uuid.New(), CreatedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+
+	sessions := cfg.activeSessions([]database.RefreshToken{active, expired}, now)
+
+	if len(sessions) != 1 || sessions[0].ID != active.SessionID {
+		t.Errorf("Expected only the active session, got %+v", sessions)
+	}
+}
+
+func TestSessions_TwoDevices_RefreshAndLogoutIndependently(t *testing.T) {
+	cfg := &Config{}
+	ctx := context.Background()
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+
+	// Log in on a laptop, then on a phone
+	if err := cfg.startSession(store, user, sessionClient{DeviceLabel: "laptop"})(ctx, "laptop-1"); err != nil {
+		t.Fatalf("Expected laptop login to succeed, got %v", err)
+	}
+	if err := cfg.startSession(store, user, sessionClient{DeviceLabel: "phone"})(ctx, "phone-1"); err != nil {
+		t.Fatalf("Expected phone login to succeed, got %v", err)
+	}
+	if len(store.refreshTokens) != 2 {
+		t.Fatalf("Expected the phone login to keep the laptop session, got %d tokens", len(store.refreshTokens))
+	}
+	laptop := store.refreshTokens[auth.HashRefreshToken("laptop-1")]
+	phone := store.refreshTokens[auth.HashRefreshToken("phone-1")]
+	if laptop.SessionID == phone.SessionID {
+		t.Fatal("Expected each login to start its own session")
+	}
+
+	// The laptop refreshes; its session continues under the new token
+	if err := rotateRefreshToken(ctx, store, laptop, cfg.newRefreshTokenParams(user.ID, "laptop-2")); err != nil {
+		t.Fatalf("Expected laptop refresh to succeed, got %v", err)
+	}
+	rotated, ok := store.refreshTokens[auth.HashRefreshToken("laptop-2")]
+	if !ok || rotated.SessionID != laptop.SessionID || rotated.DeviceLabel != "laptop" {
+		t.Fatalf("Expected the refreshed token to continue the laptop session, got %+v", rotated)
+	}
+	if _, ok := store.refreshTokens[phone.TokenHash]; !ok {
+		t.Fatal("Expected the laptop refresh to leave the phone session alone")
+	}
+
+	// The phone logs out; the laptop can still refresh
+	if err := endSession(ctx, store, user.ID, "phone-1"); err != nil {
+		t.Fatalf("Expected phone logout to succeed, got %v", err)
+	}
+	if _, ok := store.refreshTokens[phone.TokenHash]; ok {
+		t.Error("Expected the phone session to be ended")
+	}
+	if err := rotateRefreshToken(ctx, store, rotated, cfg.newRefreshTokenParams(user.ID, "laptop-3")); err != nil {
+		t.Errorf("Expected the laptop to refresh after the phone logged out, got %v", err)
+	}
+	if len(store.refreshTokens) != 1 {
+		t.Errorf("Expected only the laptop session to remain, got %d tokens", len(store.refreshTokens))
+	}
+}
+
+func TestEndSession_AnotherUsersToken_IsKept(t *testing.T) {
+	cfg := &Config{}
+	owner := cfg.newRefreshTokenParams(uuid.New(), "refresh-1")
+	store := newFakeQuerier()
+	store.addRefreshTokens(database.RefreshToken(owner))
+
+	if err := endSession(context.Background(), store, uuid.New(), "refresh-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(store.refreshTokens) != 1 {
+		t.Error("Expected another user's refresh token to be kept")
+	}
+}
+
+func TestDeviceLabel(t *testing.T) {
+	testCases := []struct {
+		name      string
+		label     string
+		userAgent string
+		expected  string
+	}{
+		{"Client label", " Work laptop ", "curl/8.0", "Work laptop"},
+		{"Falls back to User-Agent", "", "curl/8.0", "curl/8.0"},
+		{"Nothing to go on", "", "", ""},
+		{"Long label is truncated", strings.Repeat("a", maxDeviceLabelBytes+10), "", strings.Repeat("a", maxDeviceLabelBytes)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/auth/login", nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+
+			if got := deviceLabel(req, tc.label); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// statsCacheTTL is how long platform stats are served before being recomputed
+const statsCacheTTL = time.Minute
+
+// statsCache holds the last computed platform stats; its zero value is ready to use
+type statsCache struct {
+	mu        sync.Mutex
+	stats     database.GetPlatformStatsRow
+	expiresAt time.Time
+}
+
+// get returns the cached stats, recomputing them once they are older than statsCacheTTL
+func (c *statsCache) get(ctx context.Context, store database.Querier, now time.Time) (database.GetPlatformStatsRow, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Before(c.expiresAt) {
+		return c.stats, nil
+	}
+
+	stats, err := store.GetPlatformStats(ctx)
+	if err != nil {
+		return database.GetPlatformStatsRow{}, err
+	}
+
+	c.stats = stats
+	c.expiresAt = now.Add(statsCacheTTL)
+	return stats, nil
+}
+
+type statsResponse struct {
+	Users   models.Count `json:"users"`
+	Feeds   models.Count `json:"feeds"`
+	Posts   models.Count `json:"posts"`
+	Follows models.Count `json:"follows"`
+}
+
+// HandlerGetStats returns aggregate platform counts
+// Public endpoint for an "about" page - contains no per-user data
+// @Summary     Get platform stats
+// @Description Returns the total number of users, feeds, posts and follows. Refreshed at most once a minute.
+// @Tags        stats
+// @Accept      json
+// @Produce     json
+// @Param       bigint  query     string  false  "Set to string to serialize counts as JSON strings"
+// @Success     200     {object}  object  "Platform counts"
+// @Failure     429     {object}  object  "Rate limit exceeded"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/stats [get]
+func (cfg *Config) HandlerGetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := cfg.stats.get(r.Context(), cfg.DB, time.Now())
+	if err != nil {
+		respondWithDBError(w, err, "Get stats")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, newStatsResponse(r, stats))
+}
+
+func newStatsResponse(r *http.Request, stats database.GetPlatformStatsRow) statsResponse {
+	return statsResponse{
+		Users:   models.NewCount(r, stats.Users),
+		Feeds:   models.NewCount(r, stats.Feeds),
+		Posts:   models.NewCount(r, stats.Posts),
+		Follows: models.NewCount(r, stats.Follows),
+	}
+}
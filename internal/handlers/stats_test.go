@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestStatsCache_ReturnsSeededCounts(t *testing.T) {
+	store := newFakeQuerier()
+	for range 3 {
+		store.addUsers(database.User{ID: uuid.New()})
+	}
+	feed := database.Feed{ID: uuid.New()}
+	store.addFeeds(feed)
+	for range 4 {
+		store.addFeeds(database.Feed{ID: uuid.New()})
+	}
+	for range 120 {
+		store.posts = append(store.posts, database.Post{ID: uuid.New(), FeedID: feed.ID})
+	}
+	for range 9 {
+		store.follow(uuid.New(), feed.ID)
+	}
+	var c statsCache
+
+	stats, err := c.get(context.Background(), store, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/stats", nil)
+	body, err := json.Marshal(newStatsResponse(req, stats))
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+
+	expected := `{"users":3,"feeds":5,"posts":120,"follows":9}`
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+}
+
+func TestStatsCache_WithinTTL_ServesCachedCounts(t *testing.T) {
+	store := newFakeQuerier()
+	store.addUsers(database.User{ID: uuid.New()})
+	var c statsCache
+	now := time.Now()
+
+	if _, err := c.get(context.Background(), store, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	store.addUsers(database.User{ID: uuid.New()})
+	stats, _ := c.get(context.Background(), store, now.Add(30*time.Second))
+	if stats.Users != 1 || store.statsCalls != 1 {
+		t.Errorf("Expected cached count 1 with one query, got %d with %d queries", stats.Users, store.statsCalls)
+	}
+
+	stats, _ = c.get(context.Background(), store, now.Add(statsCacheTTL+time.Second))
+	if stats.Users != 2 || store.statsCalls != 2 {
+		t.Errorf("Expected refreshed count 2 with two queries, got %d with %d queries", stats.Users, store.statsCalls)
+	}
+}
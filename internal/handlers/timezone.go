@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+var errInvalidTimeZone = errors.New("Invalid time zone, expected an IANA name such as Europe/Istanbul")
+
+// parseTimeZone reads the zone timestamps should be rendered in from the tz query
+// parameter, falling back to the Time-Zone header. Without either it returns UTC.
+func parseTimeZone(r *http.Request) (*time.Location, error) {
+	name := strings.TrimSpace(r.URL.Query().Get("tz"))
+	if name == "" {
+		name = strings.TrimSpace(r.Header.Get("Time-Zone"))
+	}
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	// "Local" would leak the server's own zone
+	if name == "Local" {
+		return nil, errInvalidTimeZone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errInvalidTimeZone
+	}
+	return loc, nil
+}
+
+// localizePostsResponse renders the page's timestamps, cursor included, in loc
+func localizePostsResponse(response *postsResponse, loc *time.Location) {
+	models.PostsInLocation(response.Posts, loc)
+
+	if cursor, err := time.Parse(time.RFC3339, response.NextCursor); err == nil {
+		response.NextCursor = cursor.In(loc).Format(time.RFC3339Nano)
+	}
+}
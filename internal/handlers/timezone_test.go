@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestParseTimeZone(t *testing.T) {
+	testCases := []struct {
+		name        string
+		query       string
+		header      string
+		expected    string
+		expectError bool
+	}{
+		{"Default is UTC", "", "", "UTC", false},
+		{"Query parameter", "?tz=Europe/Istanbul", "", "Europe/Istanbul", false},
+		{"Header", "", "America/New_York", "America/New_York", false},
+		{"Query wins over header", "?tz=Asia/Tokyo", "America/New_York", "Asia/Tokyo", false},
+		{"Unknown zone", "?tz=Mars/Olympus_Mons", "", "", true},
+		{"Server local zone", "?tz=Local", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/posts"+tc.query, nil)
+			if tc.header != "" {
+				req.Header.Set("Time-Zone", tc.header)
+			}
+
+			loc, err := parseTimeZone(req)
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got zone %v", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if loc.String() != tc.expected {
+				t.Errorf("Expected zone %s, got %s", tc.expected, loc)
+			}
+		})
+	}
+}
+
+func TestLocalizePostsResponse_RendersTimestampsInZone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Skipf("Time zone database unavailable: %v", err)
+	}
+
+	publishedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	response := newPostsResponse([]database.Post{
+		{ID: uuid.New(), CreatedAt: publishedAt, UpdatedAt: publishedAt, PublishedAt: publishedAt},
+	})
+
+	localizePostsResponse(&response, loc)
+
+	if got := response.Posts[0].PublishedAt.Format(time.RFC3339); got != "2024-03-01T15:00:00+03:00" {
+		t.Errorf("Expected published_at in Istanbul time, got %s", got)
+	}
+	if !response.Posts[0].PublishedAt.Equal(publishedAt) {
+		t.Error("Expected the instant to be unchanged")
+	}
+	if response.NextCursor != "2024-03-01T15:00:00+03:00" {
+		t.Errorf("Expected cursor in Istanbul time, got %s", response.NextCursor)
+	}
+}
+
+func TestLocalizePostsResponse_Default_KeepsUTC(t *testing.T) {
+	publishedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	response := newPostsResponse([]database.Post{
+		{ID: uuid.New(), CreatedAt: publishedAt, UpdatedAt: publishedAt, PublishedAt: publishedAt},
+	})
+
+	localizePostsResponse(&response, time.UTC)
+
+	if got := response.Posts[0].PublishedAt.Format(time.RFC3339); got != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected UTC published_at, got %s", got)
+	}
+	if response.NextCursor != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected UTC cursor, got %s", response.NextCursor)
+	}
+}
+
+func TestHandlerGetUserPostsForUser_InvalidTimeZone_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts?tz=Not/AZone", nil)
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerGetUserPostsForUser(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+const (
+	// refreshTokenBindUserAgent binds refresh tokens to the User-Agent that logged in
+	refreshTokenBindUserAgent = "ua"
+	// refreshTokenBindIP binds refresh tokens to the /24 (IPv4) or /64 (IPv6) network that logged in
+	refreshTokenBindIP = "ip"
+
+	ipv4BindPrefixBits = 24
+	ipv6BindPrefixBits = 64
+)
+
+// errRefreshTokenClientMismatch is returned when a bound refresh token is presented by another client
+var errRefreshTokenClientMismatch = errors.New("refresh token was issued to a different client")
+
+// RefreshTokenBinding selects what refresh tokens are bound to. The zero value binds nothing.
+type RefreshTokenBinding struct {
+	UserAgent bool
+	IP        bool
+}
+
+// ParseRefreshTokenBinding parses a comma-separated list of "ua" and "ip", e.g. "ua"
+// or "ua,ip". An empty value or "none" binds nothing.
+func ParseRefreshTokenBinding(value string) (RefreshTokenBinding, error) {
+	var binding RefreshTokenBinding
+	for _, mode := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(mode)) {
+		case "", "none":
+		case refreshTokenBindUserAgent:
+			binding.UserAgent = true
+		case refreshTokenBindIP:
+			binding.IP = true
+		default:
+			return RefreshTokenBinding{}, fmt.Errorf("unknown refresh token binding %q, expected ua or ip", mode)
+		}
+	}
+	return binding, nil
+}
+
+// sessionClient describes the client starting a session
+type sessionClient struct {
+	DeviceLabel   string
+	UserAgentHash string
+	IPPrefix      string
+}
+
+// newSessionClient captures the client of r. The fingerprint is always recorded,
+// so enabling REFRESH_TOKEN_BIND later also covers sessions started before.
+func newSessionClient(r *http.Request, label string) sessionClient {
+	return sessionClient{
+		DeviceLabel:   deviceLabel(r, label),
+		UserAgentHash: userAgentHash(r),
+		IPPrefix:      clientIPPrefix(r),
+	}
+}
+
+// checkRefreshTokenBinding returns errRefreshTokenClientMismatch if r doesn't come
+// from the client token was issued to, as far as RefreshTokenBinding checks. Tokens
+// without a recorded fingerprint predate it and are accepted.
+func (cfg *Config) checkRefreshTokenBinding(token database.RefreshToken, r *http.Request) error {
+	if cfg.RefreshTokenBinding.UserAgent && token.UserAgentHash != "" && token.UserAgentHash != userAgentHash(r) {
+		return errRefreshTokenClientMismatch
+	}
+	if cfg.RefreshTokenBinding.IP && token.IpPrefix != "" && token.IpPrefix != clientIPPrefix(r) {
+		return errRefreshTokenClientMismatch
+	}
+	return nil
+}
+
+// userAgentHash returns the SHA-256 of the request's User-Agent, so the header itself isn't stored
+func userAgentHash(r *http.Request) string {
+	sum := sha256.Sum256([]byte(r.UserAgent()))
+	return hex.EncodeToString(sum[:])
+}
+
+// clientIPPrefix returns the network of the request's remote address: /24 for IPv4
+// and /64 for IPv6, so a client moving within its network keeps its binding.
+// Returns "" when the remote address isn't an IP.
+func clientIPPrefix(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return ""
+	}
+
+	addr = addr.Unmap()
+	bits := ipv6BindPrefixBits
+	if addr.Is4() {
+		bits = ipv4BindPrefixBits
+	}
+	prefix, err := addr.WithZone("").Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// clientRequest builds a refresh request from remoteAddr with userAgent
+func clientRequest(remoteAddr, userAgent string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("User-Agent", userAgent)
+	return req
+}
+
+func TestParseRefreshTokenBinding(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    RefreshTokenBinding
+		expectError bool
+	}{
+		{"", RefreshTokenBinding{}, false},
+		{"none", RefreshTokenBinding{}, false},
+		{"ua", RefreshTokenBinding{UserAgent: true}, false},
+		{"ip", RefreshTokenBinding{IP: true}, false},
+		{"ua,ip", RefreshTokenBinding{UserAgent: true, IP: true}, false},
+		{" IP , UA ", RefreshTokenBinding{UserAgent: true, IP: true}, false},
+		{"fingerprint", RefreshTokenBinding{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			binding, err := ParseRefreshTokenBinding(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error for %q", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if binding != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, binding)
+			}
+		})
+	}
+}
+
+func TestCheckRefreshTokenBinding(t *testing.T) {
+	login := clientRequest("203.0.113.10:51000", "Firefox/128.0")
+	client := newSessionClient(login, "")
+	token := database.RefreshToken{UserAgentHash: client.UserAgentHash, IpPrefix: client.IPPrefix}
+
+	sameClient := clientRequest("203.0.113.10:52000", "Firefox/128.0")
+	sameNetwork := clientRequest("203.0.113.77:52000", "Firefox/128.0")
+	otherUserAgent := clientRequest("203.0.113.10:52000", "curl/8.0")
+	otherNetwork := clientRequest("198.51.100.10:52000", "Firefox/128.0")
+
+	none := RefreshTokenBinding{}
+	ua := RefreshTokenBinding{UserAgent: true}
+	ip := RefreshTokenBinding{IP: true}
+	both := RefreshTokenBinding{UserAgent: true, IP: true}
+
+	testCases := []struct {
+		name    string
+		binding RefreshTokenBinding
+		req     *http.Request
+		allowed bool
+	}{
+		{"No binding, other User-Agent", none, otherUserAgent, true},
+		{"No binding, other network", none, otherNetwork, true},
+		{"ua, same client", ua, sameClient, true},
+		{"ua, other User-Agent", ua, otherUserAgent, false},
+		{"ua, other network", ua, otherNetwork, true},
+		{"ip, same client", ip, sameClient, true},
+		{"ip, same network", ip, sameNetwork, true},
+		{"ip, other User-Agent", ip, otherUserAgent, true},
+		{"ip, other network", ip, otherNetwork, false},
+		{"ua,ip, same client", both, sameClient, true},
+		{"ua,ip, other User-Agent", both, otherUserAgent, false},
+		{"ua,ip, other network", both, otherNetwork, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{RefreshTokenBinding: tc.binding}
+
+			err := cfg.checkRefreshTokenBinding(token, tc.req)
+			if tc.allowed && err != nil {
+				t.Errorf("Expected the refresh to be allowed, got %v", err)
+			}
+			if !tc.allowed && err != errRefreshTokenClientMismatch {
+				t.Errorf("Expected errRefreshTokenClientMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckRefreshTokenBinding_TokenWithoutFingerprint_IsAllowed(t *testing.T) {
+	cfg := &Config{RefreshTokenBinding: RefreshTokenBinding{UserAgent: true, IP: true}}
+
+	if err := cfg.checkRefreshTokenBinding(database.RefreshToken{}, clientRequest("198.51.100.10:52000", "curl/8.0")); err != nil {
+		t.Errorf("Expected a token issued before fingerprints were recorded to be allowed, got %v", err)
+	}
+}
+
+func TestClientIPPrefix(t *testing.T) {
+	testCases := []struct {
+		remoteAddr string
+		expected   string
+	}{
+		{"203.0.113.10:51000", "203.0.113.0/24"},
+		{"[::ffff:203.0.113.10]:51000", "203.0.113.0/24"},
+		{"[2001:db8:1:2:3:4:5:6]:51000", "2001:db8:1:2::/64"},
+		{"not-an-ip", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.remoteAddr, func(t *testing.T) {
+			if got := clientIPPrefix(clientRequest(tc.remoteAddr, "")); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenBinding_RotationKeepsLoginFingerprint(t *testing.T) {
+	cfg := &Config{RefreshTokenBinding: RefreshTokenBinding{UserAgent: true}}
+	ctx := context.Background()
+	user := database.User{ID: uuid.New()}
+	store := newFakeQuerier()
+	login := clientRequest("203.0.113.10:51000", "Firefox/128.0")
+
+	if err := cfg.startSession(store, user, newSessionClient(login, ""))(ctx, "refresh-1"); err != nil {
+		t.Fatalf("Expected login to succeed, got %v", err)
+	}
+	first := store.refreshTokens[cfg.newRefreshTokenParams(user.ID, "refresh-1").TokenHash]
+	if err := rotateRefreshToken(ctx, store, first, cfg.newRefreshTokenParams(user.ID, "refresh-2")); err != nil {
+		t.Fatalf("Expected refresh to succeed, got %v", err)
+	}
+	rotated := store.refreshTokens[cfg.newRefreshTokenParams(user.ID, "refresh-2").TokenHash]
+
+	if err := cfg.checkRefreshTokenBinding(rotated, clientRequest("203.0.113.10:52000", "curl/8.0")); err == nil {
+		t.Error("Expected the rotated token to stay bound to the login's User-Agent")
+	}
+	if err := cfg.checkRefreshTokenBinding(rotated, clientRequest("203.0.113.10:52000", "Firefox/128.0")); err != nil {
+		t.Errorf("Expected the login's client to keep refreshing, got %v", err)
+	}
+}
+
+func TestEndMismatchedSession_DeletesToken(t *testing.T) {
+	cfg := &Config{}
+	user := database.User{ID: uuid.New()}
+	params := cfg.newRefreshTokenParams(user.ID, "refresh-1")
+	store := newFakeQuerier()
+	store.addRefreshTokens(database.RefreshToken(params))
+
+	cfg.endMismatchedSession(context.Background(), store, database.RefreshToken(params), "refresh-1")
+
+	if len(store.refreshTokens) != 0 {
+		t.Error("Expected the mismatched session to be ended")
+	}
+}
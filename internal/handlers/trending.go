@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+const (
+	// trendingWindow is how far back posts are considered for trending
+	trendingWindow = 72 * time.Hour
+	// maxTrendingCandidates caps how many recent posts are ranked per request
+	maxTrendingCandidates = 1000
+	// minTrendingAgeHours keeps brand new (or future-dated) posts from dividing by ~0
+	minTrendingAgeHours = 1.0
+)
+
+// trendingPost is a post with the score it was ranked by
+type trendingPost struct {
+	models.Post
+	Score float64 `json:"score"`
+}
+
+// trendingPostsResponse is a page of trending posts; next_offset is 0 on the last page
+type trendingPostsResponse struct {
+	Posts      []trendingPost `json:"posts"`
+	NextOffset int            `json:"next_offset"`
+}
+
+// HandlerGetTrendingPosts returns recent posts ranked by their feed's popularity
+// Public endpoint - scored across all feeds, so no follows are needed.
+// @Summary     Get trending posts
+// @Description Get posts from the last 72 hours ranked by follower_count / hours_since_published of their feed, with offset pagination. Scores change over time, so later pages may shift slightly.
+// @Tags        posts
+// @Accept      json
+// @Produce     json
+// @Param       limit   query     int  false  "Number of posts to return (max 100)"  default(20)
+// @Param       offset  query     int  false  "Number of ranked posts to skip"  default(0)
+// @Success     200     {object}  object  "Trending posts"
+// @Failure     400     {object}  object  "Invalid parameters"
+// @Failure     429     {object}  object  "Rate limit exceeded"
+// @Failure     500     {object}  object  "Server error"
+// @Router      /v1/posts/trending [get]
+func (cfg *Config) HandlerGetTrendingPosts(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parseTrendingPagination(r)
+	if !ok {
+		models.RespondWithError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	response, err := getTrendingPosts(r.Context(), cfg.DB, time.Now().UTC(), limit, offset)
+	if err != nil {
+		respondWithDBError(w, err, "Get trending posts")
+		return
+	}
+
+	models.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// parseTrendingPagination reads the limit (default 20, max 100) and offset query parameters
+func parseTrendingPagination(r *http.Request) (int, int, bool) {
+	limit := 20
+	if parsedLimit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsedLimit > 0 {
+		limit = min(parsedLimit, 100)
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsedOffset, err := strconv.Atoi(offsetStr)
+		if err != nil || parsedOffset < 0 {
+			return 0, 0, false
+		}
+		offset = parsedOffset
+	}
+
+	return limit, offset, true
+}
+
+// getTrendingPosts ranks the recent posts at now and returns one page of them
+func getTrendingPosts(ctx context.Context, store database.Querier, now time.Time, limit, offset int) (trendingPostsResponse, error) {
+	rows, err := store.GetTrendingPostCandidates(ctx, database.GetTrendingPostCandidatesParams{
+		PublishedSince:  now.Add(-trendingWindow),
+		PublishedBefore: now,
+		RowLimit:        maxTrendingCandidates,
+	})
+	if err != nil {
+		return trendingPostsResponse{}, err
+	}
+
+	ranked := rankTrendingPosts(rows, now)
+
+	response := trendingPostsResponse{Posts: []trendingPost{}}
+	if offset >= len(ranked) {
+		return response, nil
+	}
+	end := min(offset+limit, len(ranked))
+	response.Posts = ranked[offset:end]
+	if end < len(ranked) {
+		response.NextOffset = end
+	}
+	return response, nil
+}
+
+// rankTrendingPosts scores each post as its feed's follower count divided by the
+// hours since it was published, highest first. Ties go to the newer post, then the higher id.
+func rankTrendingPosts(rows []database.GetTrendingPostCandidatesRow, now time.Time) []trendingPost {
+	ranked := make([]trendingPost, 0, len(rows))
+	for _, row := range rows {
+		post := models.DatabaseTrendingPostToPost(row)
+		ranked = append(ranked, trendingPost{
+			Post:  post,
+			Score: trendingScore(int(row.FeedFollowerCount), post.PublishedAt, now),
+		})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return newerPost(ranked[i].PublishedAt, ranked[i].ID, ranked[j].PublishedAt, ranked[j].ID)
+	})
+	return ranked
+}
+
+// trendingScore is followerCount / hours since publishedAt, counting at least minTrendingAgeHours
+func trendingScore(followerCount int, publishedAt, now time.Time) float64 {
+	hours := max(now.Sub(publishedAt).Hours(), minTrendingAgeHours)
+	return float64(followerCount) / hours
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func trendingRow(title string, followers int32, publishedAt time.Time) database.GetTrendingPostCandidatesRow {
+	return database.GetTrendingPostCandidatesRow{
+		ID:                uuid.New(),
+		Title:             title,
+		PublishedAt:       publishedAt,
+		CreatedAt:         publishedAt,
+		FeedID:            uuid.New(),
+		FeedName:          title + " feed",
+		FeedFollowerCount: followers,
+	}
+}
+
+func TestRankTrendingPosts_NewPopularAboveOldNiche(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	rows := []database.GetTrendingPostCandidatesRow{
+		trendingRow("old niche", 2, now.Add(-48*time.Hour)),
+		trendingRow("new popular", 500, now.Add(-2*time.Hour)),
+		trendingRow("old popular", 500, now.Add(-40*time.Hour)),
+		trendingRow("new niche", 2, now.Add(-2*time.Hour)),
+	}
+
+	ranked := rankTrendingPosts(rows, now)
+
+	expected := []string{"new popular", "old popular", "new niche", "old niche"}
+	if len(ranked) != len(expected) {
+		t.Fatalf("Expected %d posts, got %d", len(expected), len(ranked))
+	}
+	for i, title := range expected {
+		if ranked[i].Title != title {
+			t.Errorf("Expected %q at rank %d, got %q", title, i, ranked[i].Title)
+		}
+	}
+}
+
+func TestTrendingScore_JustPublished_UsesMinimumAge(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := trendingScore(10, now, now); got != 10 {
+		t.Errorf("Expected score 10, got %v", got)
+	}
+	if got := trendingScore(10, now.Add(time.Hour), now); got != 10 {
+		t.Errorf("Expected future-dated post to score 10, got %v", got)
+	}
+}
+
+func TestGetTrendingPosts_Paginates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeQuerier()
+	for i := range 5 {
+		feed := database.Feed{ID: uuid.New(), FollowerCount: int32(100 - i)}
+		store.addFeeds(feed)
+		store.posts = append(store.posts, database.Post{ID: uuid.New(), FeedID: feed.ID, Title: "post", PublishedAt: now.Add(-time.Hour)})
+	}
+
+	first, err := getTrendingPosts(context.Background(), store, now, 2, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(first.Posts) != 2 || first.NextOffset != 2 {
+		t.Fatalf("Expected 2 posts and next_offset 2, got %d and %d", len(first.Posts), first.NextOffset)
+	}
+	if !store.lastTrendingArg.PublishedSince.Equal(now.Add(-trendingWindow)) || !store.lastTrendingArg.PublishedBefore.Equal(now) {
+		t.Errorf("Expected candidates from the last %v, got %v to %v", trendingWindow, store.lastTrendingArg.PublishedSince, store.lastTrendingArg.PublishedBefore)
+	}
+
+	last, err := getTrendingPosts(context.Background(), store, now, 2, 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(last.Posts) != 1 || last.NextOffset != 0 {
+		t.Errorf("Expected 1 post and next_offset 0, got %d and %d", len(last.Posts), last.NextOffset)
+	}
+
+	past, err := getTrendingPosts(context.Background(), store, now, 2, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if past.Posts == nil || len(past.Posts) != 0 {
+		t.Errorf("Expected an empty list past the end, got %v", past.Posts)
+	}
+}
+
+func TestHandlerGetTrendingPosts_InvalidOffset_ReturnsBadRequest(t *testing.T) {
+	cfg := &Config{}
+
+	for _, offset := range []string{"-1", "abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/posts/trending?offset="+offset, nil)
+		rec := httptest.NewRecorder()
+
+		cfg.HandlerGetTrendingPosts(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for offset %q, got %d", http.StatusBadRequest, offset, rec.Code)
+		}
+	}
+}
+
+func TestRankTrendingPosts_EqualScores_StableOrder(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	rows := make([]database.GetTrendingPostCandidatesRow, 4)
+	for i := range rows {
+		rows[i] = trendingRow("tied", 10, now.Add(-2*time.Hour))
+	}
+	reversed := make([]database.GetTrendingPostCandidatesRow, len(rows))
+	for i, row := range rows {
+		reversed[len(rows)-1-i] = row
+	}
+
+	first := rankTrendingPosts(rows, now)
+	second := rankTrendingPosts(reversed, now)
+
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Errorf("Expected the same order from both calls, got %v at %d and %v", first[i].ID, i, second[i].ID)
+		}
+	}
+}
@@ -28,11 +28,25 @@ var upgrader = websocket.Upgrader{
 // @Failure     400     {object}  object  "Bad request - Invalid token or connection error"
 // @Failure     401     {object}  object  "Unauthorized - Invalid or missing token"
 // @Failure     500     {object}  object  "Internal server error"
+// @Failure     503     {object}  object  "Realtime updates are disabled or connection limit reached"
 // @Router      /v1/ws [get]
-// @Note        This endpoint upgrades HTTP connection to WebSocket. Use WebSocket client libraries (e.g., gorilla/websocket) to connect. The connection remains open and receives JSON messages with new post updates in real-time.
+// @Note        This endpoint upgrades HTTP connection to WebSocket. Use WebSocket client libraries (e.g., gorilla/websocket) to connect. The connection remains open and receives JSON messages with new post updates in real-time. Server-initiated closes carry a code and reason: 4000 slow consumer, 4001 unauthorized, 4002 pong timeout, 1001 server shutdown.
 func (cfg *Config) HandlerWebsocket(w http.ResponseWriter, r *http.Request, user database.User) {
+	// Realtime can be disabled (e.g. worker-only deployments), reject before upgrading
+	if cfg.Hub == nil {
+		models.RespondWithError(w, http.StatusServiceUnavailable, "Realtime updates are disabled")
+		return
+	}
+
+	// Reserve a connection slot before upgrading so over-limit clients get a plain 503
+	if !cfg.Hub.TryAcquire(user.ID) {
+		models.RespondWithError(w, http.StatusServiceUnavailable, "Too many WebSocket connections, try again later")
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
+		cfg.Hub.Release(user.ID)
 		models.RespondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+	"github.com/rs/zerolog"
+)
+
+func TestHandlerWebsocket_RealtimeDisabled_ReturnsServiceUnavailable(t *testing.T) {
+	cfg := &Config{Hub: nil}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	rec := httptest.NewRecorder()
+
+	cfg.HandlerWebsocket(rec, req, database.User{ID: uuid.New()})
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+func TestHandlerWebsocket_ConnectionLimit_RefusesExtraConnection(t *testing.T) {
+	hub := realtime.NewHub(zerolog.Nop())
+	hub.MaxConnections = 1
+	go hub.Run()
+
+	cfg := &Config{Hub: hub}
+	user := database.User{ID: uuid.New()}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg.HandlerWebsocket(w, r, user)
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	first, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Expected first connection to succeed, got %v", err)
+	}
+	defer func() { _ = first.Close() }()
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("Expected second connection to be refused")
+	}
+
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d for refused connection, got %v", http.StatusServiceUnavailable, resp)
+	}
+}
@@ -22,6 +22,11 @@ func InitLogger() {
 	if os.Getenv("ENV") == "development" || os.Getenv("ENV") == "dev" {
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
 	}
+
+	// LOG_LEVEL (e.g. "trace" for per-post scraper detail) overrides both
+	if level, err := zerolog.ParseLevel(os.Getenv("LOG_LEVEL")); err == nil && level != zerolog.NoLevel {
+		zerolog.SetGlobalLevel(level)
+	}
 }
 
 // Info logs an info message
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// RequireJSONAccept returns a middleware that answers 406 Not Acceptable when the
+// request's Accept header rules out JSON. Requests without an Accept header, or
+// that accept application/json, application/* or */* with a non-zero quality, pass
+// through. When enabled is false the returned middleware is a no-op.
+func RequireJSONAccept(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acceptsJSON(r.Header.Values("Accept")) {
+				models.RespondWithError(w, http.StatusNotAcceptable, "This API only serves application/json")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acceptsJSON reports whether the Accept header values allow a JSON response
+func acceptsJSON(values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				continue
+			}
+			if mediaType != "application/json" && mediaType != "application/*" && mediaType != "*/*" {
+				continue
+			}
+			if q, ok := params["q"]; ok {
+				if weight, err := strconv.ParseFloat(q, 64); err != nil || weight <= 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireJSONAccept(t *testing.T) {
+	testCases := []struct {
+		name           string
+		accept         string
+		expectedStatus int
+	}{
+		{"No Accept header", "", http.StatusOK},
+		{"JSON", "application/json", http.StatusOK},
+		{"JSON with charset", "application/json; charset=utf-8", http.StatusOK},
+		{"Any type", "*/*", http.StatusOK},
+		{"Any application type", "application/*", http.StatusOK},
+		{"Browser default", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", http.StatusOK},
+		{"HTML only", "text/html", http.StatusNotAcceptable},
+		{"XML only", "application/xml", http.StatusNotAcceptable},
+		{"JSON explicitly refused", "text/html, application/json;q=0", http.StatusNotAcceptable},
+		{"Malformed", ";;;", http.StatusNotAcceptable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := RequireJSONAccept(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/feeds", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireJSONAccept_Disabled_AllowsAnyClient(t *testing.T) {
+	handler := RequireJSONAccept(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feeds", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
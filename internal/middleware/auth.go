@@ -1,12 +1,19 @@
 package middleware
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/apperr"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
 )
 
@@ -15,9 +22,38 @@ import (
 // Description: This is a custom handler type for protected endpoints.
 type AuthedHandler func(http.ResponseWriter, *http.Request, database.User)
 
+// OptionalAuthedHandler is a handler for endpoints that work with or without authentication.
+// The user is nil for anonymous requests.
+type OptionalAuthedHandler func(http.ResponseWriter, *http.Request, *database.User)
+
+// UserStore is the subset of queries needed to load the authenticated user
+type UserStore interface {
+	GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error)
+}
+
 // Config holds dependencies for middleware.
 type Config struct {
-	DB *database.Queries
+	DB UserStore
+	// AdminEmails lists the users allowed through AdminOnly (empty = nobody)
+	AdminEmails []string
+	// DegradedReadAuth lets read-only requests (GET, HEAD) through on the token's
+	// claims alone when the user can't be loaded because of a database error.
+	// Off by default: such errors fail closed with 503.
+	DegradedReadAuth bool
+	// UserCache serves authenticated users from memory for a short TTL instead of
+	// loading them on every request (nil = always load). Changes to a user's row
+	// apply once the cached copy expires.
+	UserCache *cache.UsersCache
+}
+
+// degradedAuthKey marks a request authenticated from token claims only
+type degradedAuthKey struct{}
+
+// IsDegradedAuth reports whether the request's user was built from token claims
+// alone (see Config.DegradedReadAuth), in which case only the ID and email are set
+func IsDegradedAuth(ctx context.Context) bool {
+	degraded, _ := ctx.Value(degradedAuthKey{}).(bool)
+	return degraded
 }
 
 // NewConfig creates a new middleware config.
@@ -45,37 +81,136 @@ func (cfg *Config) Auth(handler AuthedHandler) http.HandlerFunc {
 			return
 		}
 
-		// Strip the "Bearer " prefix and get the token.
-		token, err := auth.GetBearerToken(authHeader)
-		if err != nil {
-			models.RespondWithError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid authorization header: %v", err))
+		user, r, ok := cfg.authenticate(w, r, authHeader)
+		if !ok {
 			return
 		}
 
-		// Validate the JWT token.
-		// This function checks the token's:
-		// - Signature (is it signed with our secret key?).
-		// - Expiration time (has it expired?).
-		// - Claims (parses user_id, email, etc.).
-		claims, err := auth.ValidateJWT(token)
-		if err != nil {
-			models.RespondWithError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid token: %v", err))
+		// User found! Call the handler and pass the user information.
+		// Now, user.ID, user.Email, etc., can be used inside the handler.
+		handler(w, r, user)
+	}
+}
+
+// AdminOnly wraps a handler for maintenance endpoints.
+// The user must be authenticated and their email listed in AdminEmails, otherwise 403.
+func (cfg *Config) AdminOnly(handler AuthedHandler) http.HandlerFunc {
+	return cfg.Auth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		// Admin rights come from the stored user, not from claims that may be stale
+		if IsDegradedAuth(r.Context()) {
+			models.RespondWithError(w, http.StatusServiceUnavailable, "Authentication is temporarily unavailable")
 			return
 		}
-
-		// Find the user in the database with the user_id from the token.
-		user, err := cfg.DB.GetUserByID(r.Context(), claims.UserID)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				models.RespondWithError(w, http.StatusUnauthorized, "User not found")
-				return
-			}
-			models.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+		if !cfg.isAdmin(user) {
+			models.RespondWithError(w, http.StatusForbidden, "Admin access required")
 			return
 		}
 
-		// User found! Call the handler and pass the user information.
-		// Now, user.ID, user.Email, etc., can be used inside the handler.
 		handler(w, r, user)
+	})
+}
+
+// isAdmin reports whether the user's email is one of AdminEmails
+func (cfg *Config) isAdmin(user database.User) bool {
+	if !user.Email.Valid {
+		return false
+	}
+
+	for _, email := range cfg.AdminEmails {
+		if strings.EqualFold(email, user.Email.String) {
+			return true
+		}
+	}
+	return false
+}
+
+// OptionalAuth wraps a handler for endpoints that are public but behave differently for logged-in users.
+// Requests without an Authorization header are passed through with a nil user.
+// A header that is present but invalid is still rejected with 401, so clients notice expired tokens.
+func (cfg *Config) OptionalAuth(handler OptionalAuthedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			handler(w, r, nil)
+			return
+		}
+
+		user, r, ok := cfg.authenticate(w, r, authHeader)
+		if !ok {
+			return
+		}
+
+		handler(w, r, &user)
+	}
+}
+
+// authenticate validates the bearer token and loads its user, returning the request
+// to pass on. On failure it writes the error response and returns false.
+func (cfg *Config) authenticate(w http.ResponseWriter, r *http.Request, authHeader string) (database.User, *http.Request, bool) {
+	// Strip the "Bearer " prefix and get the token.
+	token, err := auth.GetBearerToken(authHeader)
+	if err != nil {
+		models.RespondWithError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid authorization header: %v", err))
+		return database.User{}, r, false
+	}
+
+	// Validate the JWT token.
+	// This function checks the token's:
+	// - Signature (is it signed with our secret key?).
+	// - Expiration time (has it expired?).
+	// - Claims (parses user_id, email, etc.).
+	claims, err := auth.ValidateJWT(token)
+	if err != nil {
+		models.RespondWithError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid token: %v", err))
+		return database.User{}, r, false
+	}
+
+	// Find the user in the database with the user_id from the token.
+	user, err := cfg.loadUser(r.Context(), claims.UserID)
+	if errors.Is(apperr.Classify(err), apperr.ErrNotFound) {
+		models.RespondWithError(w, http.StatusUnauthorized, "User not found")
+		return database.User{}, r, false
+	}
+	if err != nil {
+		if cfg.DegradedReadAuth && isReadOnlyMethod(r.Method) {
+			logger.Logger.Warn().Err(err).Str("user_id", claims.UserID.String()).Msg("Failed to load user, authenticating from token claims")
+			return claimsUser(claims), r.WithContext(context.WithValue(r.Context(), degradedAuthKey{}, true)), true
+		}
+		logger.ErrorErr(err, "Failed to load authenticated user")
+		models.RespondWithError(w, http.StatusServiceUnavailable, "Authentication is temporarily unavailable")
+		return database.User{}, r, false
+	}
+
+	return user, r, true
+}
+
+// loadUser returns the user from UserCache, loading and caching it on a miss
+func (cfg *Config) loadUser(ctx context.Context, userID uuid.UUID) (database.User, error) {
+	if cfg.UserCache == nil {
+		return cfg.DB.GetUserByID(ctx, userID)
+	}
+
+	if user, ok := cfg.UserCache.Get(userID); ok {
+		return user, nil
+	}
+
+	user, err := cfg.DB.GetUserByID(ctx, userID)
+	if err != nil {
+		return database.User{}, err
+	}
+	cfg.UserCache.Set(user)
+	return user, nil
+}
+
+// isReadOnlyMethod reports whether method is one DegradedReadAuth may let through
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// claimsUser builds a stand-in user from validated token claims
+func claimsUser(claims *auth.CustomClaims) database.User {
+	return database.User{
+		ID:    claims.UserID,
+		Email: sql.NullString{String: claims.Email, Valid: claims.Email != ""},
 	}
 }
@@ -0,0 +1,272 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/auth"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestIsAdmin(t *testing.T) {
+	cfg := &Config{AdminEmails: []string{"admin@example.com"}}
+
+	testCases := []struct {
+		name     string
+		email    sql.NullString
+		expected bool
+	}{
+		{"Listed email", sql.NullString{String: "admin@example.com", Valid: true}, true},
+		{"Different case", sql.NullString{String: "Admin@Example.com", Valid: true}, true},
+		{"Other email", sql.NullString{String: "user@example.com", Valid: true}, false},
+		{"No email", sql.NullString{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cfg.isAdmin(database.User{Email: tc.email}); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// stubUserStore serves GetUserByID from a fixed user or error
+type stubUserStore struct {
+	user database.User
+	err  error
+}
+
+func (s stubUserStore) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	return s.user, s.err
+}
+
+// authedRequest returns a request carrying a valid token for userID
+func authedRequest(t *testing.T, method string, userID uuid.UUID) *http.Request {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	token, err := auth.GenerateJWT(userID, "user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	req := httptest.NewRequest(method, "/v1/posts", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestAuth_DBError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		degraded       bool
+		method         string
+		expectedStatus int
+		expectedCalled bool
+	}{
+		{"Strict read", false, http.MethodGet, http.StatusServiceUnavailable, false},
+		{"Strict write", false, http.MethodPost, http.StatusServiceUnavailable, false},
+		{"Degraded read", true, http.MethodGet, http.StatusOK, true},
+		{"Degraded head", true, http.MethodHead, http.StatusOK, true},
+		{"Degraded write", true, http.MethodPost, http.StatusServiceUnavailable, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userID := uuid.New()
+			cfg := &Config{
+				DB:               stubUserStore{err: errors.New("connection refused")},
+				DegradedReadAuth: tc.degraded,
+			}
+
+			called := false
+			var gotUser database.User
+			var gotDegraded bool
+			handler := cfg.Auth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+				called = true
+				gotUser = user
+				gotDegraded = IsDegradedAuth(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			handler(rec, authedRequest(t, tc.method, userID))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+			if called != tc.expectedCalled {
+				t.Fatalf("Expected handler called %v, got %v", tc.expectedCalled, called)
+			}
+			if !called {
+				return
+			}
+			if gotUser.ID != userID || gotUser.Email.String != "user@example.com" {
+				t.Errorf("Expected the user from the token claims, got %+v", gotUser)
+			}
+			if !gotDegraded {
+				t.Error("Expected the request to be marked as degraded")
+			}
+		})
+	}
+}
+
+func TestAuth_UserNotFound_Unauthorized(t *testing.T) {
+	for _, degraded := range []bool{false, true} {
+		cfg := &Config{DB: stubUserStore{err: sql.ErrNoRows}, DegradedReadAuth: degraded}
+		handler := cfg.Auth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+			t.Error("Expected the handler not to be called")
+		})
+
+		rec := httptest.NewRecorder()
+		handler(rec, authedRequest(t, http.MethodGet, uuid.New()))
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 with degraded=%v, got %d", degraded, rec.Code)
+		}
+	}
+}
+
+func TestAuth_UserLoaded_NotDegraded(t *testing.T) {
+	userID := uuid.New()
+	cfg := &Config{DB: stubUserStore{user: database.User{ID: userID, Name: "Test"}}, DegradedReadAuth: true}
+
+	var gotUser database.User
+	var gotDegraded bool
+	handler := cfg.Auth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		gotUser = user
+		gotDegraded = IsDegradedAuth(r.Context())
+	})
+	handler(httptest.NewRecorder(), authedRequest(t, http.MethodGet, userID))
+
+	if gotUser.Name != "Test" || gotDegraded {
+		t.Errorf("Expected the stored user without degradation, got %+v (degraded %v)", gotUser, gotDegraded)
+	}
+}
+
+func TestAdminOnly_DegradedAuth_Refused(t *testing.T) {
+	cfg := &Config{
+		DB:               stubUserStore{err: errors.New("connection refused")},
+		DegradedReadAuth: true,
+		AdminEmails:      []string{"user@example.com"},
+	}
+	handler := cfg.AdminOnly(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		t.Error("Expected the handler not to be called")
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, authedRequest(t, http.MethodGet, uuid.New()))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", rec.Code)
+	}
+}
+
+// countingUserStore serves one mutable user and counts the loads
+type countingUserStore struct {
+	user  database.User
+	calls int
+}
+
+func (s *countingUserStore) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	s.calls++
+	if id != s.user.ID {
+		return database.User{}, sql.ErrNoRows
+	}
+	return s.user, nil
+}
+
+func TestAuth_UserCache_HitAndMiss(t *testing.T) {
+	testCases := []struct {
+		name          string
+		userCache     *cache.UsersCache
+		expectedCalls int
+	}{
+		{"Cache disabled loads every request", nil, 3},
+		{"Cache enabled loads once", cache.NewUsersCache(10, time.Minute), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &countingUserStore{user: database.User{ID: uuid.New(), Name: "Test"}}
+			cfg := &Config{DB: store, UserCache: tc.userCache}
+			handler := cfg.Auth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+				if user.Name != "Test" {
+					t.Errorf("Expected the stored user, got %+v", user)
+				}
+			})
+
+			for i := 0; i < 3; i++ {
+				rec := httptest.NewRecorder()
+				handler(rec, authedRequest(t, http.MethodGet, store.user.ID))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("Expected status 200, got %d", rec.Code)
+				}
+			}
+
+			if store.calls != tc.expectedCalls {
+				t.Errorf("Expected %d loads, got %d", tc.expectedCalls, store.calls)
+			}
+		})
+	}
+}
+
+func TestAuth_UserCache_UnknownUserNotCached(t *testing.T) {
+	store := &countingUserStore{user: database.User{ID: uuid.New()}}
+	cfg := &Config{DB: store, UserCache: cache.NewUsersCache(10, time.Minute)}
+	handler := cfg.Auth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		t.Error("Expected the handler not to be called")
+	})
+
+	unknownID := uuid.New()
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler(rec, authedRequest(t, http.MethodGet, unknownID))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rec.Code)
+		}
+	}
+
+	if store.calls != 2 {
+		t.Errorf("Expected every request for an unknown user to load it, got %d loads", store.calls)
+	}
+}
+
+func TestAdminOnly_UserCache_RoleChangeAfterTTL(t *testing.T) {
+	now := time.Now()
+	userCache := cache.NewUsersCache(10, time.Minute, cache.WithUsersCacheClock(func() time.Time { return now }))
+	store := &countingUserStore{user: database.User{ID: uuid.New(), Email: sql.NullString{String: "user@example.com", Valid: true}}}
+	cfg := &Config{DB: store, AdminEmails: []string{"admin@example.com"}, UserCache: userCache}
+	handler := cfg.AdminOnly(func(w http.ResponseWriter, r *http.Request, user database.User) {})
+
+	adminRequest := func() int {
+		rec := httptest.NewRecorder()
+		handler(rec, authedRequest(t, http.MethodPost, store.user.ID))
+		return rec.Code
+	}
+
+	if code := adminRequest(); code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 before the role change, got %d", code)
+	}
+
+	// Becoming an admin: the email is now listed in AdminEmails
+	store.user.Email = sql.NullString{String: "admin@example.com", Valid: true}
+	if code := adminRequest(); code != http.StatusForbidden {
+		t.Errorf("Expected the cached user to be served until it expires, got %d", code)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if code := adminRequest(); code != http.StatusOK {
+		t.Errorf("Expected status 200 once the cached user expired, got %d", code)
+	}
+	if store.calls != 2 {
+		t.Errorf("Expected 2 loads, got %d", store.calls)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// MaxBodyBytes rejects request bodies larger than limit with 413. overrides
+// replaces the limit for specific paths (matched exactly), so an endpoint such as
+// an upload can accept a bigger, but still bounded, body. A non-positive limit
+// leaves the body unbounded.
+//
+// Bodies that declare an oversized Content-Length are refused up front; the rest
+// are wrapped in http.MaxBytesReader, so a handler reading past the limit gets an
+// *http.MaxBytesError and should respond with 413 itself.
+func MaxBodyBytes(limit int64, overrides map[string]int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			max := limit
+			if override, ok := overrides[r.URL.Path]; ok {
+				max = override
+			}
+			if max <= 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > max {
+				models.RespondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body must be at most %d bytes", max))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readBodyHandler reads the whole body, responding 413 if it exceeds the limit
+func readBodyHandler(readErr *error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		*readErr = err
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	overrides := map[string]int64{"/v1/feed_follows/import": 100}
+
+	testCases := []struct {
+		name           string
+		path           string
+		bodySize       int
+		expectedStatus int
+	}{
+		{"Within default limit", "/v1/feeds", 10, http.StatusOK},
+		{"Over default limit", "/v1/feeds", 11, http.StatusRequestEntityTooLarge},
+		{"Override allows larger body", "/v1/feed_follows/import", 100, http.StatusOK},
+		{"Over override limit", "/v1/feed_follows/import", 101, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var readErr error
+			handler := MaxBodyBytes(10, overrides)(readBodyHandler(&readErr))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(strings.Repeat("a", tc.bodySize)))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMaxBodyBytes_UnknownLength_IsCutOffWhileReading(t *testing.T) {
+	var readErr error
+	handler := MaxBodyBytes(10, nil)(readBodyHandler(&readErr))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/feeds", strings.NewReader(strings.Repeat("a", 50)))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var maxErr *http.MaxBytesError
+	if !errors.As(readErr, &maxErr) {
+		t.Fatalf("Expected a MaxBytesError from the handler's read, got %v", readErr)
+	}
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", rec.Code)
+	}
+}
+
+func TestMaxBodyBytes_NonPositiveLimit_IsUnbounded(t *testing.T) {
+	var readErr error
+	handler := MaxBodyBytes(0, nil)(readBodyHandler(&readErr))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/feeds", strings.NewReader(strings.Repeat("a", 1<<20)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+// maxLoggedBodyBytes caps how much of each body is written to the log
+const maxLoggedBodyBytes = 2048
+
+// redactedValue replaces the value of sensitive JSON fields
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyParts marks JSON keys whose values must never be logged
+var sensitiveKeyParts = []string{"password", "token", "secret", "authorization"}
+
+// errorReader always fails with err
+type errorReader struct {
+	err error
+}
+
+func (e errorReader) Read([]byte) (int, error) { return 0, e.err }
+
+// bodyRecorder wraps a ResponseWriter and keeps a copy of the response body
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (br *bodyRecorder) WriteHeader(code int) {
+	br.status = code
+	br.ResponseWriter.WriteHeader(code)
+}
+
+func (br *bodyRecorder) Write(b []byte) (int, error) {
+	if remaining := maxLoggedBodyBytes + 1 - br.body.Len(); remaining > 0 {
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+		br.body.Write(b[:remaining])
+	}
+	return br.ResponseWriter.Write(b)
+}
+
+// LogBodies returns a middleware that logs redacted request and response bodies.
+// It is meant for debugging API integrations in development only: when enabled is
+// false or the global log level is above debug, the returned middleware is a no-op.
+// Password, token and secret fields are masked and bodies are truncated.
+func LogBodies(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled || zerolog.GlobalLevel() > zerolog.DebugLevel {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// WebSocket upgrades need the raw connection, don't wrap them
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var requestBody []byte
+			if r.Body != nil {
+				body, err := io.ReadAll(r.Body)
+				requestBody = body
+				if err != nil {
+					logger.ErrorErr(err, "Failed to read request body for logging")
+					// Replay the read error (e.g. from MaxBodyBytes) after the bytes that were read
+					r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errorReader{err: err}))
+				} else {
+					r.Body = io.NopCloser(bytes.NewReader(body))
+				}
+			}
+
+			recorder := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			logger.Logger.Debug().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", recorder.status).
+				Str("request_body", redactBody(requestBody)).
+				Str("response_body", redactBody(recorder.body.Bytes())).
+				Msg("HTTP request/response bodies")
+		})
+	}
+}
+
+// redactBody masks sensitive fields in a JSON body and truncates the result.
+// Non-JSON bodies are never logged verbatim since they can't be redacted safely.
+func redactBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Sprintf("[non-JSON body, %d bytes]", len(body))
+	}
+
+	redacted, err := json.Marshal(redactValue(payload))
+	if err != nil {
+		return fmt.Sprintf("[unloggable body, %d bytes]", len(body))
+	}
+
+	if len(redacted) > maxLoggedBodyBytes {
+		return string(redacted[:maxLoggedBodyBytes]) + "...(truncated)"
+	}
+	return string(redacted)
+}
+
+// redactValue walks a decoded JSON value and masks sensitive object keys
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(lowerKey, part) {
+			return true
+		}
+	}
+	return false
+}
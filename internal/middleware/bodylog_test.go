@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+func TestRedactBody_PasswordFields_AreMasked(t *testing.T) {
+	body := []byte(`{"email":"test@example.com","password":"hunter2","nested":{"refresh_token":"abc123"}}`)
+
+	result := redactBody(body)
+
+	if strings.Contains(result, "hunter2") {
+		t.Errorf("Expected password to be redacted, got %s", result)
+	}
+
+	if strings.Contains(result, "abc123") {
+		t.Errorf("Expected nested token to be redacted, got %s", result)
+	}
+
+	if !strings.Contains(result, "test@example.com") {
+		t.Errorf("Expected non-sensitive fields to be kept, got %s", result)
+	}
+}
+
+func TestRedactBody_NonJSON_IsNotLogged(t *testing.T) {
+	result := redactBody([]byte("password=hunter2"))
+
+	if strings.Contains(result, "hunter2") {
+		t.Errorf("Expected non-JSON body to be omitted, got %s", result)
+	}
+}
+
+func TestRedactBody_LargeBody_IsTruncated(t *testing.T) {
+	body := []byte(`{"data":"` + strings.Repeat("a", maxLoggedBodyBytes*2) + `"}`)
+
+	result := redactBody(body)
+
+	if len(result) > maxLoggedBodyBytes+len("...(truncated)") {
+		t.Errorf("Expected truncated body, got %d bytes", len(result))
+	}
+}
+
+func TestLogBodies_LogsRedactedBodies(t *testing.T) {
+	var buf bytes.Buffer
+	original := logger.Logger
+	originalLevel := zerolog.GlobalLevel()
+	logger.Logger = zerolog.New(&buf)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	defer func() {
+		logger.Logger = original
+		zerolog.SetGlobalLevel(originalLevel)
+	}()
+
+	handler := LogBodies(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"secret-jwt"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/login", strings.NewReader(`{"password":"hunter2"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	logged := buf.String()
+	if logged == "" {
+		t.Fatal("Expected bodies to be logged")
+	}
+
+	if strings.Contains(logged, "hunter2") || strings.Contains(logged, "secret-jwt") {
+		t.Errorf("Expected secrets to be redacted, got %s", logged)
+	}
+}
+
+func TestLogBodies_Disabled_DoesNotLog(t *testing.T) {
+	var buf bytes.Buffer
+	original := logger.Logger
+	originalLevel := zerolog.GlobalLevel()
+	logger.Logger = zerolog.New(&buf)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	defer func() {
+		logger.Logger = original
+		zerolog.SetGlobalLevel(originalLevel)
+	}()
+
+	handler := LogBodies(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/feed", strings.NewReader(`{"name":"x"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing logged above debug level, got %s", buf.String())
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// InFlight counts the requests currently being served, so shutdown can report
+// how many were still running when its deadline hit. The zero value is ready to use.
+type InFlight struct {
+	count atomic.Int64
+}
+
+// Middleware counts each request from the moment it enters until its handler returns.
+// Upgraded WebSocket connections stop counting once their handler has returned.
+func (f *InFlight) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.count.Add(1)
+		defer f.count.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns the number of requests being served right now
+func (f *InFlight) Count() int64 {
+	return f.count.Load()
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestInFlight_CountsConcurrentRequests(t *testing.T) {
+	const requests = 5
+	inFlight := &InFlight{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := inFlight.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/feed", nil))
+		}()
+	}
+	for i := 0; i < requests; i++ {
+		<-started
+	}
+
+	if got := inFlight.Count(); got != requests {
+		t.Errorf("Expected %d in-flight requests, got %d", requests, got)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := inFlight.Count(); got != 0 {
+		t.Errorf("Expected 0 in-flight requests after they finished, got %d", got)
+	}
+}
+
+func TestInFlight_PanickingHandler_IsNoLongerCounted(t *testing.T) {
+	inFlight := &InFlight{}
+	handler := inFlight.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/feed", nil))
+	}()
+
+	if got := inFlight.Count(); got != 0 {
+		t.Errorf("Expected 0 in-flight requests, got %d", got)
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// NoStore sets no-cache headers on every response of the wrapped routes,
+// including errors written by other middleware, so tokens and user data
+// are never stored by caches
+func NoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		models.SetNoStoreHeaders(w)
+		next.ServeHTTP(w, r)
+	})
+}
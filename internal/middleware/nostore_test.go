@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/handlers"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+func newNoStoreTestRouter() http.Handler {
+	handlerConfig := &handlers.Config{}
+	router := chi.NewRouter()
+	router.With(NoStore).Post("/v1/auth/login", handlerConfig.HandlerLogin)
+	router.Get("/v1/feed", func(w http.ResponseWriter, r *http.Request) {
+		models.RespondWithJSON(w, http.StatusOK, []models.Feed{})
+	})
+	return router
+}
+
+func TestNoStore_LoginResponse_HasNoCacheHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	// Missing password - rejected before any database access
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/login", strings.NewReader(`{"email":"user@example.com"}`))
+
+	newNoStoreTestRouter().ServeHTTP(rec, req)
+
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Expected Cache-Control no-store, got %q", rec.Header().Get("Cache-Control"))
+	}
+
+	if rec.Header().Get("Pragma") != "no-cache" {
+		t.Errorf("Expected Pragma no-cache, got %q", rec.Header().Get("Pragma"))
+	}
+}
+
+func TestNoStore_PublicFeedListing_HasNoCacheHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	newNoStoreTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/feed", nil))
+
+	if rec.Header().Get("Cache-Control") != "" || rec.Header().Get("Pragma") != "" {
+		t.Errorf("Expected no cache headers on public listing, got Cache-Control=%q Pragma=%q",
+			rec.Header().Get("Cache-Control"), rec.Header().Get("Pragma"))
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// PrettyJSON returns a middleware that indents JSON responses of requests with
+// ?pretty=true, so they are readable with curl. Status and headers are unchanged.
+// When enabled is false the returned middleware is a no-op.
+func PrettyJSON(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty"))
+			// WebSocket upgrades need the raw connection, don't wrap them
+			if !pretty || strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(models.PrettyJSONWriter(w), r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	testCases := []struct {
+		name         string
+		enabled      bool
+		query        string
+		expectedBody string
+	}{
+		{"Requested", true, "?pretty=true", "{\n  \"name\": \"Go Blog\"\n}"},
+		{"Requested with 1", true, "?pretty=1", "{\n  \"name\": \"Go Blog\"\n}"},
+		{"Not requested", true, "", `{"name":"Go Blog"}`},
+		{"Explicitly off", true, "?pretty=false", `{"name":"Go Blog"}`},
+		{"Disabled", false, "?pretty=true", `{"name":"Go Blog"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := PrettyJSON(tc.enabled)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				models.RespondWithJSON(w, http.StatusCreated, map[string]string{"name": "Go Blog"})
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/feed"+tc.query, nil))
+
+			if rec.Code != http.StatusCreated {
+				t.Errorf("Expected status %d, got %d", http.StatusCreated, rec.Code)
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Expected Content-Type application/json, got %q", contentType)
+			}
+			if rec.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package middleware
 
 import (
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
 )
@@ -37,6 +42,13 @@ func NewTokenBucket(capacity float64, refillRate float64) *TokenBucket {
 // Consume attempts to consume one token from the bucket
 // Returns true if a token was available, false otherwise
 func (tb *TokenBucket) Consume() bool {
+	ok, _ := tb.TryConsume()
+	return ok
+}
+
+// TryConsume attempts to consume one token from the bucket like Consume. When none is
+// available it also returns how long until the next one is, or 0 if it never refills.
+func (tb *TokenBucket) TryConsume() (bool, time.Duration) {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
@@ -56,10 +68,13 @@ func (tb *TokenBucket) Consume() bool {
 	// Try to consume a token
 	if tb.tokens >= 1.0 {
 		tb.tokens--
-		return true
+		return true, 0
 	}
 
-	return false
+	if tb.refillRate <= 0 {
+		return false, 0
+	}
+	return false, time.Duration((1.0 - tb.tokens) / tb.refillRate * float64(time.Second))
 }
 
 // Global rate limiter instances
@@ -99,3 +114,134 @@ func RateLimit(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// ipBucketIdleTimeout is how long an unused per-IP bucket is kept before it's swept
+const ipBucketIdleTimeout = 10 * time.Minute
+
+// IPRateLimiter keeps a separate token bucket per client IP.
+// Used for stricter limits on public endpoints that could be abused for scraping.
+type IPRateLimiter struct {
+	buckets    map[string]*TokenBucket
+	capacity   float64
+	refillRate float64
+	// idleTimeout is how long an unused bucket is kept before it's swept
+	idleTimeout time.Duration
+	lastSweep   time.Time
+	mu          sync.Mutex
+}
+
+// NewIPRateLimiter creates a per-IP rate limiter
+func NewIPRateLimiter(config RateLimitConfig) *IPRateLimiter {
+	return &IPRateLimiter{
+		buckets:     make(map[string]*TokenBucket),
+		capacity:    float64(config.BurstSize),
+		refillRate:  float64(config.RequestsPerMinute) / 60.0,
+		idleTimeout: ipBucketIdleTimeout,
+		lastSweep:   time.Now(),
+	}
+}
+
+// Allow consumes a token from the bucket of the given IP
+func (l *IPRateLimiter) Allow(ip string) bool {
+	ok, _ := l.tryConsume(ip)
+	return ok
+}
+
+// tryConsume consumes a token from the bucket of key, see TokenBucket.TryConsume
+func (l *IPRateLimiter) tryConsume(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	now := time.Now()
+	if now.Sub(l.lastSweep) > l.idleTimeout {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = NewTokenBucket(l.capacity, l.refillRate)
+		l.buckets[key] = bucket
+	}
+	l.mu.Unlock()
+
+	return bucket.TryConsume()
+}
+
+// sweep drops buckets that haven't been used for idleTimeout.
+// Must be called with l.mu held.
+func (l *IPRateLimiter) sweep(now time.Time) {
+	for ip, bucket := range l.buckets {
+		bucket.mu.Lock()
+		idle := now.Sub(bucket.lastRefill) > l.idleTimeout
+		bucket.mu.Unlock()
+
+		if idle {
+			delete(l.buckets, ip)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware rate limits requests by client IP
+func (l *IPRateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.Allow(clientIP(r)) {
+			logger.Debug("Per-IP rate limit exceeded for client")
+
+			models.RespondWithError(w, http.StatusTooManyRequests,
+				"Rate limit exceeded. Please try again later.")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserRateLimiter keeps a separate token bucket per user, for stricter limits on
+// expensive actions, which apply however many IPs the user sends requests from
+type UserRateLimiter struct {
+	buckets *IPRateLimiter
+}
+
+// NewUserRateLimiter allows each user up to limit requests per period, refilled evenly over it
+func NewUserRateLimiter(limit int, period time.Duration) *UserRateLimiter {
+	return &UserRateLimiter{buckets: &IPRateLimiter{
+		buckets:    make(map[string]*TokenBucket),
+		capacity:   float64(limit),
+		refillRate: float64(limit) / period.Seconds(),
+		// Sweeping a bucket before it has refilled would hand the user a fresh one
+		idleTimeout: max(period, ipBucketIdleTimeout),
+		lastSweep:   time.Now(),
+	}}
+}
+
+// Allow consumes a token from the user's bucket. When the user is over the limit it
+// returns false and how long until their next request is allowed.
+func (l *UserRateLimiter) Allow(userID uuid.UUID) (bool, time.Duration) {
+	return l.buckets.tryConsume(userID.String())
+}
+
+// Limit rate limits an authenticated handler per user, answering 429 with a
+// Retry-After header once the user is over the limit
+func (l *UserRateLimiter) Limit(next AuthedHandler) AuthedHandler {
+	return func(w http.ResponseWriter, r *http.Request, user database.User) {
+		ok, retryAfter := l.Allow(user.ID)
+		if !ok {
+			logger.Debug("Per-user rate limit exceeded for client")
+
+			w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+			models.RespondWithError(w, http.StatusTooManyRequests,
+				"Rate limit exceeded. Please try again later.")
+			return
+		}
+
+		next(w, r, user)
+	}
+}
+
+// clientIP returns the IP part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
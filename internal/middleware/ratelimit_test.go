@@ -1,10 +1,15 @@
 package middleware
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
 )
 
@@ -166,3 +171,109 @@ func BenchmarkTokenBucketConsume(b *testing.B) {
 		tb.Consume()
 	}
 }
+
+func TestIPRateLimiter_SeparateBucketsPerIP(t *testing.T) {
+	l := NewIPRateLimiter(RateLimitConfig{RequestsPerMinute: 1, BurstSize: 2})
+
+	for i := 0; i < 2; i++ {
+		if !l.Allow("10.0.0.1") {
+			t.Errorf("Expected request %d from first IP to succeed", i+1)
+		}
+	}
+
+	if l.Allow("10.0.0.1") {
+		t.Error("Expected first IP to be rate limited after burst")
+	}
+
+	if !l.Allow("10.0.0.2") {
+		t.Error("Expected second IP to have its own bucket")
+	}
+}
+
+func TestIPRateLimiter_Middleware_ReturnsTooManyRequests(t *testing.T) {
+	l := NewIPRateLimiter(RateLimitConfig{RequestsPerMinute: 1, BurstSize: 1})
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	codes := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/v1/feed/x/posts", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("Expected [200 429], got %v", codes)
+	}
+}
+
+func TestIPRateLimiter_Sweep_DropsIdleBuckets(t *testing.T) {
+	l := NewIPRateLimiter(RateLimitConfig{RequestsPerMinute: 60, BurstSize: 1})
+	l.Allow("10.0.0.1")
+
+	l.mu.Lock()
+	l.sweep(time.Now().Add(2 * ipBucketIdleTimeout))
+	remaining := len(l.buckets)
+	l.mu.Unlock()
+
+	if remaining != 0 {
+		t.Errorf("Expected idle buckets to be swept, got %d", remaining)
+	}
+}
+
+func TestUserRateLimiter_Limit_TooManyRequestsAfterLimit(t *testing.T) {
+	l := NewUserRateLimiter(3, time.Hour)
+	calls := 0
+	handler := l.Limit(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	})
+	user, other := database.User{ID: uuid.New()}, database.User{ID: uuid.New()}
+
+	create := func(user database.User) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/v1/feed", nil), user)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := create(user); rec.Code != http.StatusCreated {
+			t.Fatalf("Expected create %d to succeed, got %d", i+1, rec.Code)
+		}
+	}
+
+	rec := create(user)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429 after 3 creates, got %d", rec.Code)
+	}
+	// One of 3 creates per hour comes back every 20 minutes
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1190 || retryAfter > 1200 {
+		t.Errorf("Expected Retry-After of about 1200 seconds, got %q", rec.Header().Get("Retry-After"))
+	}
+	if calls != 3 {
+		t.Errorf("Expected the handler to run 3 times, got %d", calls)
+	}
+
+	if rec := create(other); rec.Code != http.StatusCreated {
+		t.Errorf("Expected another user to have their own limit, got %d", rec.Code)
+	}
+}
+
+func TestUserRateLimiter_Sweep_KeepsBucketsUntilRefilled(t *testing.T) {
+	l := NewUserRateLimiter(1, time.Hour)
+	userID := uuid.New()
+	l.Allow(userID)
+
+	// Long past the per-IP idle timeout, but the bucket hasn't refilled yet
+	l.buckets.mu.Lock()
+	l.buckets.sweep(time.Now().Add(2 * ipBucketIdleTimeout))
+	l.buckets.mu.Unlock()
+
+	if ok, _ := l.Allow(userID); ok {
+		t.Error("Expected the user to stay limited after a sweep")
+	}
+}
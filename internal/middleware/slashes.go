@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Trailing slash policies for TrailingSlashes
+const (
+	// TrailingSlashStrip serves "/v1/feed/" as "/v1/feed"
+	TrailingSlashStrip = "strip"
+	// TrailingSlashRedirect answers "/v1/feed/" with a 308 redirect to "/v1/feed"
+	TrailingSlashRedirect = "redirect"
+	// TrailingSlashStrict leaves paths alone, so "/v1/feed/" is a 404
+	TrailingSlashStrict = "strict"
+)
+
+// TrailingSlashes returns a middleware that applies policy to request paths under
+// prefix that end in a slash. It must run before routing, i.e. on the root router.
+// Redirects use 308 so clients repeat the method and body. An unknown policy is
+// treated as TrailingSlashStrip.
+func TrailingSlashes(policy, prefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy == TrailingSlashStrict {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, "/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			trimmed := *r.URL
+			trimmed.Path = strings.TrimRight(path, "/")
+			trimmed.RawPath = ""
+
+			if policy == TrailingSlashRedirect {
+				http.Redirect(w, r, trimmed.RequestURI(), http.StatusPermanentRedirect)
+				return
+			}
+
+			stripped := new(http.Request)
+			*stripped = *r
+			stripped.URL = &trimmed
+			next.ServeHTTP(w, stripped)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newSlashTestRouter mirrors the shape of the API router: routes mounted under /v1,
+// including a path parameter route and the WebSocket endpoint
+func newSlashTestRouter(policy string) http.Handler {
+	router := chi.NewRouter()
+	router.Use(TrailingSlashes(policy, "/v1/"))
+
+	v1Router := chi.NewRouter()
+	v1Router.Get("/feed", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("feeds"))
+	})
+	v1Router.Post("/feed", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	})
+	v1Router.Get("/feed/{feedID}/posts", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(chi.URLParam(r, "feedID")))
+	})
+	v1Router.Get("/ws", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ws"))
+	})
+	router.Mount("/v1", v1Router)
+
+	router.Get("/swagger/*", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("swagger"))
+	})
+	return router
+}
+
+func TestTrailingSlashes_Strip(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedBody string
+	}{
+		{"Unslashed", http.MethodGet, "/v1/feed", "", "feeds"},
+		{"Slashed", http.MethodGet, "/v1/feed/", "", "feeds"},
+		{"Several slashes", http.MethodGet, "/v1/feed//", "", "feeds"},
+		{"Slashed with query", http.MethodGet, "/v1/feed/?page=2", "", "feeds"},
+		{"Slashed POST keeps its body", http.MethodPost, "/v1/feed/", `{"name":"Go"}`, `{"name":"Go"}`},
+		{"Path parameter", http.MethodGet, "/v1/feed/abc/posts", "", "abc"},
+		{"Slashed path parameter", http.MethodGet, "/v1/feed/abc/posts/", "", "abc"},
+		{"WebSocket", http.MethodGet, "/v1/ws", "", "ws"},
+		{"Slashed WebSocket", http.MethodGet, "/v1/ws/", "", "ws"},
+		{"Outside the API prefix", http.MethodGet, "/swagger/", "", "swagger"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			newSlashTestRouter(TrailingSlashStrip).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			if rec.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body %q, got %q", tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTrailingSlashes_Redirect(t *testing.T) {
+	testCases := []struct {
+		name             string
+		method           string
+		path             string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{"Unslashed is served", http.MethodGet, "/v1/feed", http.StatusOK, ""},
+		{"Slashed GET", http.MethodGet, "/v1/feed/", http.StatusPermanentRedirect, "/v1/feed"},
+		{"Slashed POST", http.MethodPost, "/v1/feed/", http.StatusPermanentRedirect, "/v1/feed"},
+		{"Query is kept", http.MethodGet, "/v1/feed/abc/posts/?limit=5", http.StatusPermanentRedirect, "/v1/feed/abc/posts?limit=5"},
+		{"Outside the API prefix", http.MethodGet, "/swagger/", http.StatusOK, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rec := httptest.NewRecorder()
+
+			newSlashTestRouter(TrailingSlashRedirect).ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+			if location := rec.Header().Get("Location"); location != tc.expectedLocation {
+				t.Errorf("Expected Location %q, got %q", tc.expectedLocation, location)
+			}
+		})
+	}
+}
+
+func TestTrailingSlashes_Strict(t *testing.T) {
+	testCases := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/v1/feed", http.StatusOK},
+		{"/v1/feed/", http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rec := httptest.NewRecorder()
+
+			newSlashTestRouter(TrailingSlashStrict).ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
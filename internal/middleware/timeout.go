@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
+)
+
+// requestTimeoutHeader lets clients ask for a shorter deadline, in milliseconds
+const requestTimeoutHeader = "X-Request-Timeout"
+
+// RequestTimeout gives each request's context a deadline of maxTimeout, or the
+// client's X-Request-Timeout if that is shorter. Database queries and outgoing
+// fetches made with the context are cancelled once the client has stopped
+// waiting. A non-positive maxTimeout leaves only the client's deadline.
+// WebSocket upgrades are long-lived and are passed through untouched.
+func RequestTimeout(maxTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timeout, ok := requestTimeout(r.Header.Get(requestTimeoutHeader), maxTimeout)
+			if !ok {
+				models.RespondWithError(w, http.StatusBadRequest, requestTimeoutHeader+" must be a positive number of milliseconds")
+				return
+			}
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestTimeout returns the smaller of the client's requested timeout and
+// maxTimeout (0 = no deadline). ok is false if the header is malformed.
+func requestTimeout(header string, maxTimeout time.Duration) (time.Duration, bool) {
+	if header == "" {
+		return maxTimeout, true
+	}
+
+	ms, err := strconv.ParseInt(strings.TrimSpace(header), 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+
+	// Clamp before converting so huge values can't overflow the Duration
+	if maxTimeout > 0 && ms >= maxTimeout.Milliseconds() {
+		return maxTimeout, true
+	}
+	if ms > math.MaxInt64/int64(time.Millisecond) {
+		return maxTimeout, true
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
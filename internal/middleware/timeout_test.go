@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// deadlineHandler records how long the request context had left when it arrived
+func deadlineHandler(remaining *time.Duration, hasDeadline *bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		*hasDeadline = ok
+		if ok {
+			*remaining = time.Until(deadline)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestRequestTimeout_ShortClientTimeout_IsRespected(t *testing.T) {
+	var ctxErr error
+	handler := RequestTimeout(30 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			ctxErr = r.Context().Err()
+		case <-time.After(5 * time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed", nil)
+	req.Header.Set(requestTimeoutHeader, "50")
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ServeHTTP(rec, req)
+
+	if !errors.Is(ctxErr, context.DeadlineExceeded) {
+		t.Fatalf("Expected the context deadline to be exceeded, got %v", ctxErr)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the request to be cancelled after ~50ms, took %v", elapsed)
+	}
+}
+
+func TestRequestTimeout_ClientTimeoutAboveMax_IsClamped(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+	handler := RequestTimeout(time.Second)(deadlineHandler(&remaining, &hasDeadline))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/feed", nil)
+	req.Header.Set(requestTimeoutHeader, "600000")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !hasDeadline {
+		t.Fatal("Expected the request context to have a deadline")
+	}
+	if remaining > time.Second {
+		t.Errorf("Expected deadline clamped to 1s, got %v", remaining)
+	}
+}
+
+func TestRequestTimeout_NoHeader_UsesServerMax(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+	handler := RequestTimeout(time.Second)(deadlineHandler(&remaining, &hasDeadline))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/feed", nil))
+
+	if !hasDeadline {
+		t.Fatal("Expected the request context to have a deadline")
+	}
+	if remaining <= 500*time.Millisecond || remaining > time.Second {
+		t.Errorf("Expected a deadline about 1s away, got %v", remaining)
+	}
+}
+
+func TestRequestTimeout_InvalidHeader_ReturnsBadRequest(t *testing.T) {
+	for _, value := range []string{"soon", "-5", "0"} {
+		t.Run(value, func(t *testing.T) {
+			var remaining time.Duration
+			var hasDeadline bool
+			handler := RequestTimeout(time.Second)(deadlineHandler(&remaining, &hasDeadline))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/feed", nil)
+			req.Header.Set(requestTimeoutHeader, value)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequestTimeout_WebSocketUpgrade_HasNoDeadline(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+	handler := RequestTimeout(time.Second)(deadlineHandler(&remaining, &hasDeadline))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/ws", nil)
+	req.Header.Set("Upgrade", "websocket")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if hasDeadline {
+		t.Errorf("Expected no deadline on a WebSocket upgrade, got %v remaining", remaining)
+	}
+}
@@ -0,0 +1,216 @@
+// Package migrate applies the goose migrations in sql/schema at startup.
+// Versions are recorded in goose's own goose_db_version table, so running the
+// goose CLI by hand and running migrations at startup can be mixed freely.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+// advisoryLockID keeps instances starting at the same time from migrating concurrently
+const advisoryLockID = 7_243_105_518
+
+const (
+	upAnnotation   = "-- +goose Up"
+	downAnnotation = "-- +goose Down"
+)
+
+// Migration is the Up section of one goose migration file
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+}
+
+// Load reads the goose migrations (NNN_name.sql) in fsys, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	seen := make(map[int64]string, len(files))
+	for _, file := range files {
+		version, err := parseVersion(file)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		upSQL, err := parseUpSection(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: file, UpSQL: upSQL})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseVersion reads the numeric prefix of a migration file name
+func parseVersion(file string) (int64, error) {
+	prefix, _, found := strings.Cut(path.Base(file), "_")
+	if !found {
+		return 0, fmt.Errorf("migration %s: expected a NNN_name.sql file name", file)
+	}
+
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("migration %s: invalid version %q", file, prefix)
+	}
+	return version, nil
+}
+
+// parseUpSection returns the SQL between the Up and Down annotations
+func parseUpSection(content string) (string, error) {
+	_, afterUp, found := strings.Cut(content, upAnnotation)
+	if !found {
+		return "", errors.New("missing " + upAnnotation + " annotation")
+	}
+
+	upSQL, _, _ := strings.Cut(afterUp, downAnnotation)
+	return strings.TrimSpace(upSQL), nil
+}
+
+// Up applies the migrations in fsys that aren't applied yet, each in its own
+// transaction, and returns the versions it applied
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS, log zerolog.Logger) ([]int64, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// The advisory lock belongs to a session, so everything runs on one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logger.ErrorErr(err, "Failed to release migration connection")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockID); err != nil {
+			logger.ErrorErr(err, "Failed to release migration lock")
+		}
+	}()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedNow := make([]int64, 0)
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		if err := apply(ctx, conn, migration); err != nil {
+			return appliedNow, fmt.Errorf("apply %s: %w", migration.Name, err)
+		}
+
+		log.Info().Int64("version", migration.Version).Str("migration", migration.Name).Msg("Applied migration")
+		appliedNow = append(appliedNow, migration.Version)
+	}
+
+	return appliedNow, nil
+}
+
+// appliedVersions creates goose's version table if needed and returns the applied versions.
+// goose appends a row per up or down, so the latest row of a version decides its state.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]bool, error) {
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS goose_db_version (
+		id serial PRIMARY KEY,
+		version_id bigint NOT NULL,
+		is_applied boolean NOT NULL,
+		tstamp timestamp NULL DEFAULT now()
+	)`); err != nil {
+		return nil, fmt.Errorf("create version table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("read applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[int64]bool)
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		if err := rows.Scan(&version, &isApplied); err != nil {
+			return nil, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		applied[version] = isApplied
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// goose records version 0 when it creates the table
+	if len(seen) == 0 {
+		if _, err := conn.ExecContext(ctx, "INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, true)"); err != nil {
+			return nil, fmt.Errorf("initialize version table: %w", err)
+		}
+	}
+
+	return applied, nil
+}
+
+// apply runs one migration and records it in the same transaction
+func apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.ErrorErr(err, "Failed to rollback migration")
+		}
+	}()
+
+	if migration.UpSQL != "" {
+		if _, err := tx.ExecContext(ctx, migration.UpSQL); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)", migration.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/sql/schema"
+	"github.com/rs/zerolog"
+)
+
+func TestLoad_OrdersByVersionAndKeepsUpSection(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_second.sql": {Data: []byte("-- +goose Up\nCREATE TABLE b (id INT);\n\n-- +goose Down\nDROP TABLE b;\n")},
+		"002_first.sql":  {Data: []byte("-- +goose Up\n\nCREATE TABLE a (id INT);\n-- +goose Down\nDROP TABLE a;")},
+		"embed.go":       {Data: []byte("package schema")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+	}
+
+	if migrations[0].Version != 2 || migrations[1].Version != 10 {
+		t.Errorf("Expected versions [2 10], got [%d %d]", migrations[0].Version, migrations[1].Version)
+	}
+
+	if migrations[0].UpSQL != "CREATE TABLE a (id INT);" {
+		t.Errorf("Expected only the Up section, got %q", migrations[0].UpSQL)
+	}
+}
+
+func TestLoad_InvalidFiles_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"No version prefix", fstest.MapFS{"users.sql": {Data: []byte("-- +goose Up\n")}}},
+		{"Non-numeric version", fstest.MapFS{"abc_users.sql": {Data: []byte("-- +goose Up\n")}}},
+		{"Missing Up annotation", fstest.MapFS{"001_users.sql": {Data: []byte("CREATE TABLE users ();")}}},
+		{"Duplicate version", fstest.MapFS{
+			"001_users.sql":  {Data: []byte("-- +goose Up\n")},
+			"0001_feeds.sql": {Data: []byte("-- +goose Up\n")},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Load(tc.fsys); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_EmbeddedSchema_Parses(t *testing.T) {
+	migrations, err := Load(schema.FS)
+	if err != nil {
+		t.Fatalf("Expected embedded migrations to parse, got %v", err)
+	}
+
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Errorf("Expected migrations starting at version 1, got %d migrations", len(migrations))
+	}
+}
+
+// TestUp_FreshDatabase_CreatesTables needs an empty Postgres database in TEST_DB_URL
+func TestUp_FreshDatabase_CreatesTables(t *testing.T) {
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if _, err := Up(ctx, db, schema.FS, zerolog.Nop()); err != nil {
+		t.Fatalf("Expected migrations to apply, got %v", err)
+	}
+
+	for _, table := range []string{"users", "feeds", "feed_follows", "posts", "post_reads", "goose_db_version"} {
+		var exists bool
+		if err := db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", "public."+table).Scan(&exists); err != nil {
+			t.Fatalf("Failed to check table %s: %v", table, err)
+		}
+		if !exists {
+			t.Errorf("Expected table %s to exist", table)
+		}
+	}
+
+	// Everything is applied now, so a second run has nothing to do
+	applied, err := Up(ctx, db, schema.FS, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("Expected second run to succeed, got %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations applied on second run, got %v", applied)
+	}
+}
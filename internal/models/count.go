@@ -0,0 +1,50 @@
+package models
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Count is an integer count in an API response.
+// JavaScript clients lose precision above 2^53, so a client can ask for counts
+// to be serialized as strings with the ?bigint=string query option.
+type Count struct {
+	Value    int64
+	AsString bool
+}
+
+// NewCount creates a Count serialized according to the request's ?bigint option.
+// Counts are numeric unless the client sent ?bigint=string.
+func NewCount(r *http.Request, value int64) Count {
+	return Count{
+		Value:    value,
+		AsString: r.URL.Query().Get("bigint") == "string",
+	}
+}
+
+// MarshalJSON serializes the count as a JSON number or string
+func (c Count) MarshalJSON() ([]byte, error) {
+	if c.AsString {
+		return []byte(strconv.Quote(strconv.FormatInt(c.Value, 10))), nil
+	}
+	return []byte(strconv.FormatInt(c.Value, 10)), nil
+}
+
+// UnmarshalJSON reads a count serialized either way by MarshalJSON
+func (c *Count) UnmarshalJSON(data []byte) error {
+	if unquoted, err := strconv.Unquote(string(data)); err == nil {
+		value, err := strconv.ParseInt(unquoted, 10, 64)
+		if err != nil {
+			return err
+		}
+		*c = Count{Value: value, AsString: true}
+		return nil
+	}
+
+	value, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+	*c = Count{Value: value}
+	return nil
+}
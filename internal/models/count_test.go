@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCount_Default_SerializesAsNumber(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/posts/read", nil)
+
+	data, err := json.Marshal(struct {
+		Marked Count `json:"marked"`
+	}{Marked: NewCount(req, 9007199254740993)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `{"marked":9007199254740993}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestNewCount_BigintString_SerializesAsString(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/posts/read?bigint=string", nil)
+
+	data, err := json.Marshal(struct {
+		Marked Count `json:"marked"`
+	}{Marked: NewCount(req, 9007199254740993)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `{"marked":"9007199254740993"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestNewCount_UnknownOption_SerializesAsNumber(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/posts/read?bigint=hex", nil)
+
+	if NewCount(req, 5).AsString {
+		t.Error("Expected unknown bigint option to fall back to numeric")
+	}
+}
+
+func TestCount_UnmarshalJSON_RoundTrips(t *testing.T) {
+	testCases := []struct {
+		name  string
+		count Count
+	}{
+		{"Number", Count{Value: 9007199254740993}},
+		{"String", Count{Value: 9007199254740993, AsString: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.count)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var got Count
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tc.count {
+				t.Errorf("Expected %+v, got %+v", tc.count, got)
+			}
+		})
+	}
+}
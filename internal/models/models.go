@@ -1,6 +1,8 @@
 package models
 
 import (
+	"database/sql"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,15 +22,24 @@ type User struct {
 
 // Feed represents an RSS feed in the API
 type Feed struct {
-	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Name        string    `json:"name"`
-	Url         string    `json:"url"`
-	UserID      uuid.UUID `json:"user_id"`
-	Description string    `json:"description,omitempty"`
-	LogoUrl     string    `json:"logo_url,omitempty"`
-	Priority    int       `json:"priority"`
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	UserID    uuid.UUID `json:"user_id"`
+	// Description and LogoUrl come from the feed itself and are null when it has none
+	Description *string `json:"description"`
+	LogoUrl     *string `json:"logo_url"`
+	Priority    int     `json:"priority"`
+	// FollowerCount is cached on the feed; the reconciler corrects any drift
+	FollowerCount Count `json:"follower_count"`
+	// LastPostAt is when the feed's newest post was published (omitted if it has none)
+	LastPostAt *time.Time `json:"last_post_at,omitempty"`
+	// FetchFullContent is set when new posts get their article content extracted
+	FetchFullContent bool `json:"fetch_full_content"`
+	// IsFollowed tells whether the requesting user follows the feed (omitted for anonymous requests)
+	IsFollowed *bool `json:"is_followed,omitempty"`
 }
 
 // FeedFollow represents a feed follow relationship in the API
@@ -39,6 +50,20 @@ type FeedFollow struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	UserID    uuid.UUID `json:"user_id"`
 	FeedID    uuid.UUID `json:"feed_id"`
+	Alias     string    `json:"alias,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	// Notify is false when the user muted notifications for the feed's new posts
+	Notify bool `json:"notify"`
+}
+
+// FeedFollowWithFeed is a feed follow expanded with the followed feed's name
+// DisplayName is the user's alias, or the feed name when no alias is set
+type FeedFollowWithFeed struct {
+	FeedFollow
+	FeedName    string `json:"feed_name"`
+	DisplayName string `json:"display_name"`
+	// FeedLastPostAt is when the feed's newest post was published, to spot feeds gone quiet
+	FeedLastPostAt *time.Time `json:"feed_last_post_at,omitempty"`
 }
 
 type Post struct {
@@ -49,6 +74,22 @@ type Post struct {
 	Url         string    `json:"url"`
 	PublishedAt time.Time `json:"published_at"`
 	FeedID      uuid.UUID `json:"feed_id"`
+	// Description is the post's stored, possibly truncated, description and is null when it has none
+	Description *string `json:"description"`
+	// FeedName is the feed's display name for the user, set on the user's own posts only
+	FeedName string `json:"feed_name,omitempty"`
+	// Feed is only set when the client asks for it with ?include=feed
+	Feed *PostFeed `json:"feed,omitempty"`
+	// IsRead tells whether the user marked the post read, set on the user's own posts only
+	IsRead *bool `json:"is_read,omitempty"`
+}
+
+// PostFeed is the feed metadata embedded in a post
+type PostFeed struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Url     string    `json:"url"`
+	LogoUrl string    `json:"logo_url,omitempty"`
 }
 
 // DatabaseUserToUser converts a database user to an API user
@@ -63,29 +104,39 @@ func DatabaseUserToUser(dbUser database.User) User {
 	}
 }
 
-// DatabaseFeedToFeed converts a database feed to an API feed
-func DatabaseFeedToFeed(dbFeed database.Feed) Feed {
-	description := ""
-	if dbFeed.Description.Valid {
-		description = dbFeed.Description.String
+// DatabaseFeedToFeed converts a database feed to an API feed, with counts
+// serialized according to r's ?bigint option
+func DatabaseFeedToFeed(r *http.Request, dbFeed database.Feed) Feed {
+	return Feed{
+		ID:               dbFeed.ID,
+		CreatedAt:        dbFeed.CreatedAt,
+		UpdatedAt:        dbFeed.UpdatedAt,
+		Name:             dbFeed.Name,
+		Url:              dbFeed.Url,
+		UserID:           dbFeed.UserID,
+		Description:      nullStringPtr(dbFeed.Description),
+		LogoUrl:          nullStringPtr(dbFeed.LogoUrl),
+		Priority:         int(dbFeed.Priority),
+		FollowerCount:    NewCount(r, int64(dbFeed.FollowerCount)),
+		LastPostAt:       nullTimePtr(dbFeed.LastPostAt),
+		FetchFullContent: dbFeed.FetchFullContent,
 	}
+}
 
-	logoUrl := ""
-	if dbFeed.LogoUrl.Valid {
-		logoUrl = dbFeed.LogoUrl.String
+// nullStringPtr returns a pointer to s's string, or nil when s is NULL
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
 	}
+	return &s.String
+}
 
-	return Feed{
-		ID:          dbFeed.ID,
-		CreatedAt:   dbFeed.CreatedAt,
-		UpdatedAt:   dbFeed.UpdatedAt,
-		Name:        dbFeed.Name,
-		Url:         dbFeed.Url,
-		UserID:      dbFeed.UserID,
-		Description: description,
-		LogoUrl:     logoUrl,
-		Priority:    int(dbFeed.Priority),
+// nullTimePtr returns a pointer to t's time, or nil when t is NULL
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
 	}
+	return &t.Time
 }
 
 func DatabasePostToPost(dbPost database.Post) Post {
@@ -95,8 +146,70 @@ func DatabasePostToPost(dbPost database.Post) Post {
 		UpdatedAt:   dbPost.UpdatedAt,
 		Title:       dbPost.Title,
 		Url:         dbPost.Url,
-		PublishedAt: dbPost.PublishedAt,
+		PublishedAt: PostPublishedAt(dbPost.PublishedAt, dbPost.CreatedAt),
 		FeedID:      dbPost.FeedID,
+		Description: nullStringPtr(dbPost.Description),
+	}
+}
+
+// PostPublishedAt returns when a post was published, falling back to when it was
+// ingested if the stored time is the zero value or otherwise unusable (not after 1970).
+// A zero time would serialize as 0001-01-01T00:00:00Z and break cursor pagination.
+func PostPublishedAt(publishedAt, ingestedAt time.Time) time.Time {
+	if publishedAt.IsZero() || publishedAt.Unix() <= 0 {
+		return ingestedAt
+	}
+	return publishedAt
+}
+
+// PostsInLocation renders the posts' timestamps in loc
+func PostsInLocation(posts []Post, loc *time.Location) {
+	for i := range posts {
+		posts[i].CreatedAt = posts[i].CreatedAt.In(loc)
+		posts[i].UpdatedAt = posts[i].UpdatedAt.In(loc)
+		posts[i].PublishedAt = posts[i].PublishedAt.In(loc)
+	}
+}
+
+// DatabasePostWithFeedToPost converts a post joined with its feed to an API post with the feed embedded
+func DatabasePostWithFeedToPost(row database.GetPostsForUserWithFeedRow) Post {
+	return Post{
+		ID:          row.ID,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		Title:       row.Title,
+		Url:         row.Url,
+		PublishedAt: PostPublishedAt(row.PublishedAt, row.CreatedAt),
+		FeedID:      row.FeedID,
+		Description: nullStringPtr(row.Description),
+		FeedName:    FeedDisplayName(row.FeedAlias, row.FeedName),
+		Feed: &PostFeed{
+			ID:      row.FeedID,
+			Name:    row.FeedName,
+			Url:     row.FeedUrl,
+			LogoUrl: row.FeedLogoUrl.String,
+		},
+	}
+}
+
+// DatabaseTrendingPostToPost converts a trending candidate row to an API post with the feed embedded
+func DatabaseTrendingPostToPost(row database.GetTrendingPostCandidatesRow) Post {
+	return Post{
+		ID:          row.ID,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		Title:       row.Title,
+		Url:         row.Url,
+		PublishedAt: PostPublishedAt(row.PublishedAt, row.CreatedAt),
+		FeedID:      row.FeedID,
+		Description: nullStringPtr(row.Description),
+		FeedName:    row.FeedName,
+		Feed: &PostFeed{
+			ID:      row.FeedID,
+			Name:    row.FeedName,
+			Url:     row.FeedUrl,
+			LogoUrl: row.FeedLogoUrl.String,
+		},
 	}
 }
 
@@ -109,10 +222,43 @@ func DatabaseAllPostToAllPost(dbPosts []database.Post) []Post {
 }
 
 // DatabaseAllFeedToAllFeed converts multiple database feeds to API feeds
-func DatabaseAllFeedToAllFeed(dbFeeds []database.Feed) []Feed {
+func DatabaseAllFeedToAllFeed(r *http.Request, dbFeeds []database.Feed) []Feed {
 	feeds := make([]Feed, 0, len(dbFeeds)) // Initialize with capacity for performance
 	for _, feed := range dbFeeds {
-		feeds = append(feeds, DatabaseFeedToFeed(feed))
+		feeds = append(feeds, DatabaseFeedToFeed(r, feed))
+	}
+	return feeds
+}
+
+// DatabaseFeedWithStatsToFeed converts a feed listed with its stats to an API feed.
+// FollowerCount is the counted number of follows rather than the cached one, and
+// IsFollowed is only set when withIsFollowed, i.e. the listing was for a user.
+func DatabaseFeedWithStatsToFeed(r *http.Request, row database.GetFeedsWithStatsRow, withIsFollowed bool) Feed {
+	feed := DatabaseFeedToFeed(r, database.Feed{
+		ID:               row.ID,
+		CreatedAt:        row.CreatedAt,
+		UpdatedAt:        row.UpdatedAt,
+		Name:             row.Name,
+		Url:              row.Url,
+		UserID:           row.UserID,
+		Description:      row.Description,
+		LogoUrl:          row.LogoUrl,
+		Priority:         row.Priority,
+		LastPostAt:       row.LastPostAt,
+		FetchFullContent: row.FetchFullContent,
+	})
+	feed.FollowerCount = NewCount(r, row.FollowCount)
+	if withIsFollowed {
+		isFollowed := row.IsFollowed
+		feed.IsFollowed = &isFollowed
+	}
+	return feed
+}
+
+func DatabaseAllFeedWithStatsToAllFeed(r *http.Request, rows []database.GetFeedsWithStatsRow, withIsFollowed bool) []Feed {
+	feeds := make([]Feed, 0, len(rows))
+	for _, row := range rows {
+		feeds = append(feeds, DatabaseFeedWithStatsToFeed(r, row, withIsFollowed))
 	}
 	return feeds
 }
@@ -125,6 +271,9 @@ func DatabaseFeedFollowToFeedFollow(dbFeedFollow database.FeedFollow) FeedFollow
 		UpdatedAt: dbFeedFollow.UpdatedAt,
 		UserID:    dbFeedFollow.UserID,
 		FeedID:    dbFeedFollow.FeedID,
+		Alias:     dbFeedFollow.Alias.String,
+		Category:  dbFeedFollow.Category.String,
+		Notify:    dbFeedFollow.Notify,
 	}
 }
 
@@ -136,3 +285,141 @@ func DatabaseAllFeedFollowToAllFeedFollow(dbFeedFollows []database.FeedFollow) [
 	}
 	return feedFollows
 }
+
+// FeedDisplayName returns the user's alias for a feed, falling back to the feed's name
+func FeedDisplayName(alias sql.NullString, feedName string) string {
+	if alias.Valid && alias.String != "" {
+		return alias.String
+	}
+	return feedName
+}
+
+// DatabaseFeedFollowWithFeedToFeedFollowWithFeed converts an expanded database feed follow
+func DatabaseFeedFollowWithFeedToFeedFollowWithFeed(row database.GetFeedFollowsPaginatedRow) FeedFollowWithFeed {
+	return FeedFollowWithFeed{
+		FeedFollow: DatabaseFeedFollowToFeedFollow(database.FeedFollow{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			UserID:    row.UserID,
+			FeedID:    row.FeedID,
+			Alias:     row.Alias,
+			Category:  row.Category,
+			Notify:    row.Notify,
+		}),
+		FeedName:       row.FeedName,
+		DisplayName:    FeedDisplayName(row.Alias, row.FeedName),
+		FeedLastPostAt: nullTimePtr(row.FeedLastPostAt),
+	}
+}
+
+// DatabaseAllFeedFollowWithFeedToAllFeedFollowWithFeed converts multiple expanded database feed follows
+func DatabaseAllFeedFollowWithFeedToAllFeedFollowWithFeed(rows []database.GetFeedFollowsPaginatedRow) []FeedFollowWithFeed {
+	feedFollows := make([]FeedFollowWithFeed, 0, len(rows))
+	for _, row := range rows {
+		feedFollows = append(feedFollows, DatabaseFeedFollowWithFeedToFeedFollowWithFeed(row))
+	}
+	return feedFollows
+}
+
+// Identity represents an OAuth login linked to a user
+type Identity struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email,omitempty"`
+}
+
+// DatabaseIdentityToIdentity converts a database identity to an API identity
+// Note: the provider's user id is NOT included
+func DatabaseIdentityToIdentity(dbIdentity database.Identity) Identity {
+	return Identity{
+		ID:        dbIdentity.ID,
+		CreatedAt: dbIdentity.CreatedAt,
+		Provider:  dbIdentity.Provider,
+		Email:     dbIdentity.Email.String,
+	}
+}
+
+// DatabaseAllIdentityToAllIdentity converts multiple database identities to API identities
+func DatabaseAllIdentityToAllIdentity(dbIdentities []database.Identity) []Identity {
+	identities := make([]Identity, 0, len(dbIdentities))
+	for _, identity := range dbIdentities {
+		identities = append(identities, DatabaseIdentityToIdentity(identity))
+	}
+	return identities
+}
+
+// Session represents a device's login, backed by its current refresh token.
+// The ID stays the same across refreshes; CreatedAt is when the current token was issued.
+type Session struct {
+	ID          uuid.UUID `json:"id"`
+	DeviceLabel string    `json:"device_label,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// DatabaseRefreshTokenToSession converts a database refresh token to an API session
+// Note: the token hash is NOT included
+func DatabaseRefreshTokenToSession(dbToken database.RefreshToken) Session {
+	return Session{
+		ID:          dbToken.SessionID,
+		DeviceLabel: dbToken.DeviceLabel,
+		CreatedAt:   dbToken.CreatedAt,
+		ExpiresAt:   dbToken.ExpiresAt,
+	}
+}
+
+// AuthResponse is the body returned by register, login and token refresh
+type AuthResponse struct {
+	User         User   `json:"user"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// ExpiresIn is the access token lifetime in seconds, so clients can refresh ahead of expiry
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// Notification is a stored realtime signal the user hasn't acknowledged yet
+type Notification struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	Count     Count     `json:"count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DatabaseNotificationToNotification converts a stored notification to an API notification
+func DatabaseNotificationToNotification(r *http.Request, row database.ListUnackedNotificationsRow) Notification {
+	return Notification{
+		ID:        row.ID,
+		Type:      row.Type,
+		FeedID:    row.FeedID,
+		FeedName:  row.FeedName,
+		Count:     NewCount(r, int64(row.PostCount)),
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+// Bookmark is a post the user saved for later
+type Bookmark struct {
+	Post         Post      `json:"post"`
+	BookmarkedAt time.Time `json:"bookmarked_at"`
+}
+
+// DatabaseBookmarkToBookmark converts a bookmarked post row to an API bookmark
+func DatabaseBookmarkToBookmark(row database.GetBookmarksForUserRow) Bookmark {
+	return Bookmark{
+		Post: Post{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+			Title:       row.Title,
+			Url:         row.Url,
+			PublishedAt: PostPublishedAt(row.PublishedAt, row.CreatedAt),
+			FeedID:      row.FeedID,
+			Description: nullStringPtr(row.Description),
+		},
+		BookmarkedAt: row.BookmarkedAt,
+	}
+}
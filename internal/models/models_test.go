@@ -0,0 +1,143 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+func TestDatabaseFeedToFeed_LastPostAt(t *testing.T) {
+	lastPostAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		lastPostAt sql.NullTime
+		expected   string
+	}{
+		{"Feed without posts", sql.NullTime{}, ""},
+		{"Feed with posts", sql.NullTime{Time: lastPostAt, Valid: true}, `"last_post_at":"2024-03-01T12:00:00Z"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := DatabaseFeedToFeed(httptest.NewRequest("GET", "/v1/feeds", nil), database.Feed{ID: uuid.New(), LastPostAt: tc.lastPostAt})
+
+			body, err := json.Marshal(feed)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if tc.expected == "" {
+				if strings.Contains(string(body), "last_post_at") {
+					t.Errorf("Expected last_post_at to be omitted, got %s", body)
+				}
+				return
+			}
+			if !strings.Contains(string(body), tc.expected) {
+				t.Errorf("Expected %s in %s", tc.expected, body)
+			}
+		})
+	}
+}
+
+func TestDatabaseFeedToFeed_DescriptionAndLogoUrl(t *testing.T) {
+	testCases := []struct {
+		name     string
+		feed     database.Feed
+		expected []string
+	}{
+		{
+			"Feed with description and logo",
+			database.Feed{
+				ID:          uuid.New(),
+				Description: sql.NullString{String: "News about Go", Valid: true},
+				LogoUrl:     sql.NullString{String: "https://go.dev/logo.png", Valid: true},
+			},
+			[]string{`"description":"News about Go"`, `"logo_url":"https://go.dev/logo.png"`},
+		},
+		{
+			"Feed without description or logo",
+			database.Feed{ID: uuid.New()},
+			[]string{`"description":null`, `"logo_url":null`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(DatabaseFeedToFeed(httptest.NewRequest("GET", "/v1/feeds", nil), tc.feed))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(string(body), expected) {
+					t.Errorf("Expected %s in %s", expected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabaseFeedToFeed_BigintString_SerializesFollowerCountAsString(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/feeds?bigint=string", nil)
+
+	body, err := json.Marshal(DatabaseFeedToFeed(req, database.Feed{ID: uuid.New(), FollowerCount: 3}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(string(body), `"follower_count":"3"`) {
+		t.Errorf("Expected a string follower_count in %s", body)
+	}
+}
+func TestDatabaseFeedFollowWithFeedToFeedFollowWithFeed_FeedLastPostAt(t *testing.T) {
+	lastPostAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	follow := DatabaseFeedFollowWithFeedToFeedFollowWithFeed(database.GetFeedFollowsPaginatedRow{
+		ID:             uuid.New(),
+		FeedName:       "Go Blog",
+		FeedLastPostAt: sql.NullTime{Time: lastPostAt, Valid: true},
+	})
+
+	if follow.FeedLastPostAt == nil || !follow.FeedLastPostAt.Equal(lastPostAt) {
+		t.Errorf("Expected feed_last_post_at %v, got %v", lastPostAt, follow.FeedLastPostAt)
+	}
+}
+
+func TestDatabasePostToPost_Description(t *testing.T) {
+	testCases := []struct {
+		name     string
+		post     database.Post
+		expected string
+	}{
+		{
+			"Post with description",
+			database.Post{ID: uuid.New(), Description: sql.NullString{String: "Go 1.22 is released", Valid: true}},
+			`"description":"Go 1.22 is released"`,
+		},
+		{
+			"Post without description",
+			database.Post{ID: uuid.New()},
+			`"description":null`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(DatabasePostToPost(tc.post))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if !strings.Contains(string(body), tc.expected) {
+				t.Errorf("Expected %s in %s", tc.expected, body)
+			}
+		})
+	}
+}
@@ -21,9 +21,37 @@ func RespondWithError(w http.ResponseWriter, code int, message string) {
 	RespondWithJSON(w, code, errorResponse{Error: message})
 }
 
-// RespondWithJSON sends a JSON response
+// RespondWithValidationError sends a 422 response for a request that parsed fine but
+// whose field holds an invalid value. Malformed requests get a 400 from RespondWithError.
+func RespondWithValidationError(w http.ResponseWriter, field, message string) {
+	type validationErrorResponse struct {
+		Error string `json:"error"`
+		Field string `json:"field"`
+	}
+
+	RespondWithJSON(w, http.StatusUnprocessableEntity, validationErrorResponse{Error: message, Field: field})
+}
+
+// prettyJSONWriter marks a response whose JSON RespondWithJSON indents
+type prettyJSONWriter struct {
+	http.ResponseWriter
+}
+
+// PrettyJSONWriter wraps w so JSON written with RespondWithJSON is indented, for reading
+// responses while debugging
+func PrettyJSONWriter(w http.ResponseWriter) http.ResponseWriter {
+	return prettyJSONWriter{ResponseWriter: w}
+}
+
+// RespondWithJSON sends a JSON response, indented when w comes from PrettyJSONWriter
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	data, err := json.Marshal(payload)
+	var data []byte
+	var err error
+	if _, pretty := w.(prettyJSONWriter); pretty {
+		data, err = json.MarshalIndent(payload, "", "  ")
+	} else {
+		data, err = json.Marshal(payload)
+	}
 	if err != nil {
 		log.Printf("Failed to marshal JSON response: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -40,3 +68,10 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 		log.Printf("Failed to write response: %v", responseError)
 	}
 }
+
+// SetNoStoreHeaders marks the response as not cacheable by browsers or intermediaries
+// Use for responses that carry tokens or user data
+func SetNoStoreHeaders(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+}
@@ -0,0 +1,132 @@
+package readability
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+)
+
+const (
+	// DefaultTimeout bounds fetching one article, including redirects and the body
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxBytes caps the size of an article page (2 MB)
+	DefaultMaxBytes = 2 << 20
+	// DefaultMaxRedirects is how many redirects an article fetch follows
+	DefaultMaxRedirects = 5
+)
+
+// errNotHTML is returned when an article URL serves something other than an HTML page
+var errNotHTML = errors.New("article is not an HTML page")
+
+// Fetcher downloads article pages and extracts their content. It is safe for
+// concurrent use.
+type Fetcher struct {
+	Client *http.Client
+	// Timeout bounds each fetch, including reading the body (0 = only the caller's context)
+	Timeout time.Duration
+	// MaxBytes rejects larger pages (0 = unlimited)
+	MaxBytes int64
+	// MaxRedirects fails fetches that redirect more often (0 = no redirects)
+	MaxRedirects int
+	// CheckURL rejects URLs that must not be fetched, e.g. ones on private hosts. It is
+	// called for the article URL and every redirect (nil = any http(s) URL).
+	CheckURL func(ctx context.Context, rawURL string) error
+}
+
+// NewFetcher creates a fetcher with a pooling transport and the default limits
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		Client:       &http.Client{Transport: feedfetch.NewTransport()},
+		Timeout:      DefaultTimeout,
+		MaxBytes:     DefaultMaxBytes,
+		MaxRedirects: DefaultMaxRedirects,
+	}
+}
+
+// Fetch downloads the page at articleURL and returns its main content, see Extract
+func (f *Fetcher) Fetch(ctx context.Context, articleURL string) (string, error) {
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	if err := f.checkURL(ctx, articleURL); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, articleURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("article request failed: %s", resp.Status)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", errNotHTML
+	}
+
+	var reader io.Reader = resp.Body
+	if f.MaxBytes > 0 {
+		reader = io.LimitReader(resp.Body, f.MaxBytes+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	if f.MaxBytes > 0 && int64(len(body)) > f.MaxBytes {
+		return "", fmt.Errorf("article exceeds maximum size of %d bytes", f.MaxBytes)
+	}
+
+	return Extract(bytes.NewReader(body), resp.Request.URL.String())
+}
+
+// client returns a copy of the configured client that limits redirects and runs
+// CheckURL on each of them
+func (f *Fetcher) client() *http.Client {
+	client := http.Client{}
+	if f.Client != nil {
+		client = *f.Client
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > f.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", f.MaxRedirects)
+		}
+		return f.checkURL(req.Context(), req.URL.String())
+	}
+	return &client
+}
+
+// checkURL accepts http(s) URLs that pass CheckURL
+func (f *Fetcher) checkURL(ctx context.Context, rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return errors.New("article URL scheme must be http or https")
+	}
+	if f.CheckURL == nil {
+		return nil
+	}
+	return f.CheckURL(ctx, rawURL)
+}
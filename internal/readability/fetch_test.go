@@ -0,0 +1,95 @@
+package readability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newArticleServer serves articlePage at /article, a redirect to it at /moved and a
+// PDF at /paper.pdf
+func newArticleServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(articlePage))
+	})
+	mux.Handle("/moved", http.RedirectHandler("/article", http.StatusMovedPermanently))
+	mux.HandleFunc("/paper.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_Fetch_ExtractsArticle(t *testing.T) {
+	server := newArticleServer(t)
+
+	content, err := NewFetcher().Fetch(context.Background(), server.URL+"/moved")
+	if err != nil {
+		t.Fatalf("Expected content, got error %v", err)
+	}
+	if !strings.Contains(content, "The Go team announced a new release today") {
+		t.Errorf("Expected the article content, got %q", content)
+	}
+	if strings.Contains(content, "Copyright") {
+		t.Errorf("Expected the footer to be stripped, got %q", content)
+	}
+	// Relative URLs resolve against the page the redirect led to
+	if !strings.Contains(content, `src="`+server.URL+`/images/gopher.png"`) {
+		t.Errorf("Expected the image URL resolved against %s/article, got %q", server.URL, content)
+	}
+}
+
+func TestFetcher_Fetch_Rejected(t *testing.T) {
+	server := newArticleServer(t)
+	errBlocked := errors.New("blocked")
+
+	testCases := []struct {
+		name        string
+		configure   func(f *Fetcher)
+		path        string
+		expectedErr string
+	}{
+		{"not HTML", func(f *Fetcher) {}, "/paper.pdf", errNotHTML.Error()},
+		{"not found", func(f *Fetcher) {}, "/missing", "404"},
+		{"too large", func(f *Fetcher) { f.MaxBytes = 100 }, "/article", "maximum size"},
+		{"too many redirects", func(f *Fetcher) { f.MaxRedirects = 0 }, "/moved", "stopped after 0 redirects"},
+		{"URL blocked", func(f *Fetcher) {
+			f.CheckURL = func(ctx context.Context, rawURL string) error { return errBlocked }
+		}, "/article", errBlocked.Error()},
+		{"redirect blocked", func(f *Fetcher) {
+			f.CheckURL = func(ctx context.Context, rawURL string) error {
+				if strings.HasSuffix(rawURL, "/article") {
+					return errBlocked
+				}
+				return nil
+			}
+		}, "/moved", errBlocked.Error()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewFetcher()
+			tc.configure(fetcher)
+
+			_, err := fetcher.Fetch(context.Background(), server.URL+tc.path)
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("Expected an error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestFetcher_Fetch_NonHTTPURL_Rejected(t *testing.T) {
+	_, err := NewFetcher().Fetch(context.Background(), "file:///etc/passwd")
+	if err == nil || !strings.Contains(err.Error(), "scheme") {
+		t.Errorf("Expected a scheme error, got %v", err)
+	}
+}
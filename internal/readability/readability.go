@@ -0,0 +1,330 @@
+// Package readability extracts the main content of an article page, dropping the
+// navigation, sidebars, comments and other boilerplate around it. It follows the
+// scoring approach of Mozilla's Readability: paragraphs award points to their
+// containers, and the best scoring container is taken as the article.
+package readability
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// minParagraphChars is the shortest paragraph that counts towards a container's score
+	minParagraphChars = 25
+	// minSiblingScore is the lowest score a sibling of the top container needs to be kept
+	minSiblingScore = 10
+	// classWeight is added to or subtracted from a container by its class and id
+	classWeight = 25
+)
+
+// ErrNoContent is returned when a page has no recognizable article content
+var ErrNoContent = errors.New("no article content found")
+
+var (
+	// unlikelyCandidates match the class or id of boilerplate blocks, which are removed
+	unlikelyCandidates = regexp.MustCompile(`(?i)ad-break|advert|banner|breadcrumb|comment|community|cookie|disqus|footer|header|menu|modal|nav|newsletter|pager|popup|promo|related|share|sidebar|social|sponsor|subscribe|widget`)
+	// maybeCandidates keep a block matching unlikelyCandidates, e.g. "article-header"
+	maybeCandidates = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveClass   = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|story|text`)
+	negativeClass   = regexp.MustCompile(`(?i)combx|comment|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// removedTags are dropped with everything inside them before scoring
+var removedTags = map[atom.Atom]bool{
+	atom.Aside:    true,
+	atom.Button:   true,
+	atom.Embed:    true,
+	atom.Footer:   true,
+	atom.Form:     true,
+	atom.Iframe:   true,
+	atom.Input:    true,
+	atom.Link:     true,
+	atom.Meta:     true,
+	atom.Nav:      true,
+	atom.Noscript: true,
+	atom.Object:   true,
+	atom.Script:   true,
+	atom.Select:   true,
+	atom.Style:    true,
+	atom.Svg:      true,
+	atom.Template: true,
+	atom.Textarea: true,
+}
+
+// Extract returns the main content of the HTML page read from r as sanitized HTML,
+// see textutil.SanitizeHTML. Relative links and images are resolved against pageURL.
+// Returns ErrNoContent when nothing on the page looks like an article.
+func Extract(r io.Reader, pageURL string) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+
+	body := findFirst(doc, atom.Body)
+	if body == nil {
+		return "", ErrNoContent
+	}
+	removeBoilerplate(body)
+
+	top, scores := topCandidate(body)
+	if top == nil {
+		return "", ErrNoContent
+	}
+
+	base, _ := url.Parse(pageURL)
+	var b strings.Builder
+	for _, node := range articleNodes(top, scores) {
+		resolveURLs(node, base)
+		if err := html.Render(&b, node); err != nil {
+			return "", err
+		}
+	}
+
+	content := strings.TrimSpace(textutil.SanitizeHTML(b.String()))
+	if content == "" {
+		return "", ErrNoContent
+	}
+	return content, nil
+}
+
+// removeBoilerplate drops removedTags, unlikely candidates and hidden elements below n
+func removeBoilerplate(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode || child.Type == html.ElementNode && isBoilerplate(child) {
+			n.RemoveChild(child)
+		} else {
+			removeBoilerplate(child)
+		}
+		child = next
+	}
+}
+
+// isBoilerplate reports whether an element is never part of the article
+func isBoilerplate(n *html.Node) bool {
+	if removedTags[n.DataAtom] || n.DataAtom == atom.Header && !hasAncestor(n, atom.Article) {
+		return true
+	}
+	if _, hidden := attr(n, "hidden"); hidden || strings.EqualFold(attrValue(n, "aria-hidden"), "true") {
+		return true
+	}
+	if n.DataAtom == atom.Article || n.DataAtom == atom.Main || n.DataAtom == atom.Body {
+		return false
+	}
+	match := classAndID(n)
+	return unlikelyCandidates.MatchString(match) && !maybeCandidates.MatchString(match)
+}
+
+// topCandidate scores the containers of every paragraph below body and returns the
+// best one along with every container's score, or nil when no paragraph is long enough
+func topCandidate(body *html.Node) (*html.Node, map[*html.Node]float64) {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	walk(body, func(n *html.Node) {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return
+		}
+		text := innerText(n)
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphChars {
+			return
+		}
+
+		// One point for the paragraph, one per comma and one per 100 characters, up to 3
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(length/100), 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+	})
+
+	var top *html.Node
+	for _, candidate := range candidates {
+		// Containers that are mostly links are menus or link lists, not articles
+		scores[candidate] *= 1 - linkDensity(candidate)
+		if top == nil || scores[candidate] > scores[top] {
+			top = candidate
+		}
+	}
+	return top, scores
+}
+
+// initialScore rates a container by its tag and by its class and id
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	match := classAndID(n)
+	if negativeClass.MatchString(match) {
+		score -= classWeight
+	}
+	if positiveClass.MatchString(match) {
+		score += classWeight
+	}
+	return score
+}
+
+// articleNodes returns top together with the siblings that continue the article:
+// well scoring containers and long paragraphs that aren't mostly links
+func articleNodes(top *html.Node, scores map[*html.Node]float64) []*html.Node {
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := max(minSiblingScore, scores[top]*0.2)
+	var nodes []*html.Node
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling == top {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		if score, ok := scores[sibling]; ok && score >= threshold {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if sibling.DataAtom == atom.P && linkDensity(sibling) < 0.25 && utf8.RuneCountInString(innerText(sibling)) > 80 {
+			nodes = append(nodes, sibling)
+		}
+	}
+	return nodes
+}
+
+// linkDensity is the share of n's text that is inside links
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(innerText(n))
+	if length == 0 {
+		return 0
+	}
+	linkLength := 0
+	walk(n, func(link *html.Node) {
+		if link.DataAtom == atom.A {
+			linkLength += utf8.RuneCountInString(innerText(link))
+		}
+	})
+	return min(float64(linkLength)/float64(length), 1)
+}
+
+// resolveURLs makes the href and src attributes below n absolute, so links and
+// images keep working when the content is shown outside the original page
+func resolveURLs(n *html.Node, base *url.URL) {
+	if base == nil {
+		return
+	}
+	walk(n, func(element *html.Node) {
+		for i, a := range element.Attr {
+			if a.Key != "href" && a.Key != "src" {
+				continue
+			}
+			if ref, err := url.Parse(strings.TrimSpace(a.Val)); err == nil {
+				element.Attr[i].Val = base.ResolveReference(ref).String()
+			}
+		}
+	})
+}
+
+// walk calls fn for n and every element below it, in document order
+func walk(n *html.Node, fn func(*html.Node)) {
+	if n.Type == html.ElementNode {
+		fn(n)
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+// findFirst returns the first element below n with the given tag
+func findFirst(n *html.Node, tag atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == tag {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findFirst(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// hasAncestor reports whether n is inside an element with the given tag
+func hasAncestor(n *html.Node, tag atom.Atom) bool {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		if parent.DataAtom == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// innerText returns the text below n with runs of whitespace collapsed
+func innerText(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			b.WriteString(node.Data)
+			b.WriteByte(' ')
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// classAndID returns an element's class and id, for matching against the class patterns
+func classAndID(n *html.Node) string {
+	return attrValue(n, "class") + " " + attrValue(n, "id")
+}
+
+// attr returns the value of an element's attribute and whether it is set
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of an element's attribute, or "" when it isn't set
+func attrValue(n *html.Node, key string) string {
+	value, _ := attr(n, key)
+	return value
+}
@@ -0,0 +1,124 @@
+package readability
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// articlePage is a news article surrounded by the usual site chrome
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Go 1.99 released</title>
+	<style>body { font-family: sans-serif; }</style>
+	<script>trackPageView();</script>
+</head>
+<body>
+	<header class="site-header"><a href="/">Example News</a></header>
+	<nav><ul><li><a href="/world">World</a></li><li><a href="/tech">Tech</a></li></ul></nav>
+	<div id="cookie-banner">We use cookies to improve your experience. Accept all cookies?</div>
+	<main>
+		<article class="post">
+			<h1>Go 1.99 released</h1>
+			<p>The Go team announced a new release today, bringing faster builds, smaller binaries, and a long list of library improvements.</p>
+			<p>Generic methods, long requested by the community, are finally here. See the <a href="/docs/generics">design notes</a> for details, caveats, and examples.</p>
+			<img src="images/gopher.png" alt="Gopher">
+			<div class="share-buttons"><a href="https://social.example/share">Share this story on social media</a></div>
+			<p>Upgrading is recommended for all users, and the release notes list every change in detail, including a few small breaking ones.</p>
+		</article>
+	</main>
+	<aside class="sidebar"><p>Most read: ten tricks for faster Go builds, and why you should care about them.</p></aside>
+	<div class="comments"><p>First! This is the best release ever, thanks to everyone involved in it.</p></div>
+	<footer><p>Copyright Example News, all rights reserved, since the beginning of time.</p></footer>
+</body>
+</html>`
+
+func TestExtract_StripsBoilerplate(t *testing.T) {
+	content, err := Extract(strings.NewReader(articlePage), "https://news.example.com/2024/go-1-99")
+	if err != nil {
+		t.Fatalf("Expected content, got error %v", err)
+	}
+
+	for _, expected := range []string{
+		"The Go team announced a new release today",
+		"Generic methods, long requested",
+		"Upgrading is recommended for all users",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected the content to contain %q, got %q", expected, content)
+		}
+	}
+
+	for _, boilerplate := range []string{
+		"trackPageView",
+		"font-family",
+		"Example News",
+		"World",
+		"cookies",
+		"Share this story",
+		"Most read",
+		"First!",
+		"Copyright",
+	} {
+		if strings.Contains(content, boilerplate) {
+			t.Errorf("Expected %q to be stripped, got %q", boilerplate, content)
+		}
+	}
+}
+
+func TestExtract_ResolvesRelativeURLs(t *testing.T) {
+	content, err := Extract(strings.NewReader(articlePage), "https://news.example.com/2024/go-1-99")
+	if err != nil {
+		t.Fatalf("Expected content, got error %v", err)
+	}
+
+	for _, expected := range []string{
+		`href="https://news.example.com/docs/generics"`,
+		`src="https://news.example.com/2024/images/gopher.png"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected the content to contain %s, got %q", expected, content)
+		}
+	}
+}
+
+func TestExtract_PicksContentOverLinkLists(t *testing.T) {
+	page := `<html><body>
+		<div class="links">
+			<p><a href="/a">A list of links that is long enough to be a paragraph</a></p>
+			<p><a href="/b">Another list of links that is long enough to count</a></p>
+		</div>
+		<div>
+			<p>Just one paragraph of actual text, with a comma, that makes up this article.</p>
+		</div>
+	</body></html>`
+
+	content, err := Extract(strings.NewReader(page), "https://example.com/")
+	if err != nil {
+		t.Fatalf("Expected content, got error %v", err)
+	}
+	if !strings.Contains(content, "actual text") || strings.Contains(content, "list of links") {
+		t.Errorf("Expected only the article paragraph, got %q", content)
+	}
+}
+
+func TestExtract_NoArticle_ReturnsErrNoContent(t *testing.T) {
+	testCases := []struct {
+		name string
+		page string
+	}{
+		{"empty", ``},
+		{"only navigation", `<html><body><nav><a href="/">Home</a> <a href="/about">About us and our long history</a></nav></body></html>`},
+		{"short text", `<html><body><p>Hello</p></body></html>`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Extract(strings.NewReader(tc.page), "https://example.com/")
+			if !errors.Is(err, ErrNoContent) {
+				t.Errorf("Expected ErrNoContent, got %v", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package realtime
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,30 @@ const (
 	pongWait   = 60 * time.Second
 	pingPeriod = (pongWait * 9) / 10
 	writeWait  = 10 * time.Second
+	// staleAfter is how long after its last pong the Hub reaps a client whose read
+	// deadline didn't end the connection
+	staleAfter = pongWait + 30*time.Second
+)
+
+// CloseReason is the close code and reason text sent to a client the server disconnects
+type CloseReason struct {
+	Code int
+	Text string
+}
+
+// Close reasons sent before the server closes a WebSocket. Codes in the 4000-4999
+// range are application-defined, so clients can tell these cases apart.
+var (
+	// CloseNormal is sent when the connection is simply over
+	CloseNormal = CloseReason{Code: websocket.CloseNormalClosure}
+	// CloseSlowConsumer is sent when a client reads too slowly to keep up with its signals
+	CloseSlowConsumer = CloseReason{Code: 4000, Text: "slow consumer"}
+	// CloseUnauthorized is sent when the user's session ends, e.g. on logout
+	CloseUnauthorized = CloseReason{Code: 4001, Text: "unauthorized"}
+	// CloseServerShutdown is sent to every client when the server is shutting down
+	CloseServerShutdown = CloseReason{Code: websocket.CloseGoingAway, Text: "server shutdown"}
+	// ClosePongTimeout is sent to a client the Hub reaps because it stopped answering pings
+	ClosePongTimeout = CloseReason{Code: 4002, Text: "pong timeout"}
 )
 
 type Client struct {
@@ -20,15 +45,21 @@ type Client struct {
 	conn   *websocket.Conn
 	userID uuid.UUID
 	send   chan []byte
+	// closeReason is set by the Hub before it closes send, and read by WritePump after
+	closeReason CloseReason
+	// lastPong is when the client last answered a ping, in Unix nanoseconds
+	lastPong atomic.Int64
 }
 
 func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID) *Client {
-	return &Client{
+	client := &Client{
 		hub:    hub,
 		conn:   conn,
 		userID: userID,
 		send:   make(chan []byte, 256),
 	}
+	client.lastPong.Store(time.Now().UnixNano())
+	return client
 }
 
 func (c *Client) ReadPump() {
@@ -39,6 +70,7 @@ func (c *Client) ReadPump() {
 
 	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
+		c.lastPong.Store(time.Now().UnixNano())
 		_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
@@ -60,6 +92,7 @@ func (c *Client) WritePump() {
 	defer func() {
 		ticker.Stop()
 		_ = c.conn.Close()
+		c.hub.writers.Done()
 	}()
 
 	for {
@@ -68,7 +101,9 @@ func (c *Client) WritePump() {
 			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 
 			if !ok {
-				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// The Hub removed this client, tell it why before closing
+				closeMessage := websocket.FormatCloseMessage(c.closeReason.Code, c.closeReason.Text)
+				_ = c.conn.WriteMessage(websocket.CloseMessage, closeMessage)
 				return
 			}
 
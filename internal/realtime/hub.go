@@ -1,73 +1,312 @@
 package realtime
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
+// DefaultBufferSize is how many pending registrations and signal batches the Hub queues
+const DefaultBufferSize = 256
+
+// reapInterval is how often the Hub looks for clients that stopped answering pings
+const reapInterval = pongWait / 2
+
+// HubStats is a snapshot of the Hub's connection counts
+type HubStats struct {
+	Connections    int   `json:"connections"`
+	Users          int   `json:"users"`
+	DroppedSignals int64 `json:"dropped_signals"`
+}
+
 type Hub struct {
-	clients    map[uuid.UUID]*Client
+	clients    map[uuid.UUID]map[*Client]bool
 	register   chan *Client
 	unregister chan *Client
 	signal     chan map[uuid.UUID][]byte
+	disconnect chan userDisconnect
 	Logger     zerolog.Logger
+
+	// shutdown is closed by Shutdown, stopped is closed once Run has disconnected everyone
+	shutdown     chan struct{}
+	stopped      chan struct{}
+	shutdownOnce sync.Once
+	// writers tracks running WritePumps, so Shutdown can wait for close frames to go out
+	writers sync.WaitGroup
+
+	// MaxConnections caps the total number of WebSocket connections (0 = unlimited)
+	MaxConnections int
+	// MaxConnectionsPerUser caps the connections a single user may hold (0 = unlimited)
+	MaxConnectionsPerUser int
+
+	// Connection slots are counted separately from the clients map so they can be
+	// checked from HTTP handlers before upgrading, without going through Run
+	mu              sync.Mutex
+	connections     int
+	userConnections map[uuid.UUID]int
+
+	droppedSignals atomic.Int64
+
+	// reapInterval is how often Run reaps stale clients (0 = never)
+	reapInterval time.Duration
 }
 
 func NewHub(l zerolog.Logger) *Hub {
+	return NewHubWithBuffer(l, DefaultBufferSize)
+}
+
+// NewHubWithBuffer creates a Hub whose channels queue up to bufferSize items,
+// so bursts from the scraper or connecting clients don't wait for Run
+func NewHubWithBuffer(l zerolog.Logger, bufferSize int) *Hub {
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+
 	return &Hub{
-		clients:    make(map[uuid.UUID]*Client),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		signal:     make(chan map[uuid.UUID][]byte),
-		Logger:     l,
+		clients:         make(map[uuid.UUID]map[*Client]bool),
+		register:        make(chan *Client, bufferSize),
+		unregister:      make(chan *Client, bufferSize),
+		signal:          make(chan map[uuid.UUID][]byte, bufferSize),
+		disconnect:      make(chan userDisconnect, bufferSize),
+		Logger:          l,
+		shutdown:        make(chan struct{}),
+		stopped:         make(chan struct{}),
+		userConnections: make(map[uuid.UUID]int),
+		reapInterval:    reapInterval,
 	}
 }
 
 func (hub *Hub) Run() {
 	hub.Logger.Info().Msg("Realtime Hub started running.")
 
+	// A nil channel never fires, so reaping is off without a ticker
+	var reap <-chan time.Time
+	if hub.reapInterval > 0 {
+		ticker := time.NewTicker(hub.reapInterval)
+		defer ticker.Stop()
+		reap = ticker.C
+	}
+
 	for {
 		select {
 		case client := <-hub.register:
-			hub.clients[client.userID] = client
-
-			hub.Logger.Info().
-				Str("user_id", client.userID.String()).
-				Int("total_clients", len(hub.clients)).
-				Msg("Client registered successfully.")
+			hub.addClient(client)
 		case client := <-hub.unregister:
-			if _, ok := hub.clients[client.userID]; ok {
-				delete(hub.clients, client.userID)
-				close(client.send)
-
+			if hub.removeClient(client, CloseNormal) {
 				hub.Logger.Warn().
 					Str("user_id", client.userID.String()).
-					Int("total_clients", len(hub.clients)).
+					Int("total_clients", hub.Stats().Connections).
 					Msg("Client unregistered. Connection closed.")
 			}
 		case signals := <-hub.signal:
-			for userID, payload := range signals {
-				if client, ok := hub.clients[userID]; ok {
-					select {
-					case client.send <- payload:
-					default:
-						hub.Logger.Error().
-							Str("user_id", userID.String()).
-							Msg("Client send channel is full! Disconnecting misbehaving client.")
-
-						close(client.send)
-						delete(hub.clients, userID)
-					}
-				}
+			hub.dispatch(signals)
+		case d := <-hub.disconnect:
+			hub.disconnectUser(d.userID, d.reason)
+		case now := <-reap:
+			hub.reapStaleClients(now)
+		case <-hub.shutdown:
+			for userID := range hub.clients {
+				hub.disconnectUser(userID, CloseServerShutdown)
 			}
+			hub.Logger.Info().Msg("Realtime Hub stopped.")
+			close(hub.stopped)
+			return
+		}
+	}
+}
+
+// userDisconnect asks Run to disconnect all of a user's clients
+type userDisconnect struct {
+	userID uuid.UUID
+	reason CloseReason
+}
+
+// addClient registers a client so it receives its user's signals
+func (hub *Hub) addClient(client *Client) {
+	if hub.clients[client.userID] == nil {
+		hub.clients[client.userID] = make(map[*Client]bool)
+	}
+	hub.clients[client.userID][client] = true
+
+	hub.Logger.Info().
+		Str("user_id", client.userID.String()).
+		Int("total_clients", hub.Stats().Connections).
+		Msg("Client registered successfully.")
+}
+
+// dispatch queues each payload on its user's clients. A client whose send
+// channel is full can't keep up and is disconnected as a slow consumer.
+func (hub *Hub) dispatch(signals map[uuid.UUID][]byte) {
+	for userID, payload := range signals {
+		for client := range hub.clients[userID] {
+			select {
+			case client.send <- payload:
+			default:
+				hub.Logger.Error().
+					Str("user_id", userID.String()).
+					Msg("Client send channel is full! Disconnecting misbehaving client.")
+
+				hub.removeClient(client, CloseSlowConsumer)
+			}
+		}
+	}
+}
+
+// reapStaleClients removes clients that haven't answered a ping within staleAfter.
+// ReadPump's read deadline normally ends such connections; this is a safety net
+// for clients it missed, so they don't hold their slot and receive signals forever.
+func (hub *Hub) reapStaleClients(now time.Time) {
+	for userID, userClients := range hub.clients {
+		for client := range userClients {
+			lastPong := time.Unix(0, client.lastPong.Load())
+			if now.Sub(lastPong) <= staleAfter {
+				continue
+			}
+
+			hub.Logger.Warn().
+				Str("user_id", userID.String()).
+				Time("last_pong", lastPong).
+				Msg("Client stopped answering pings. Reaping stale connection.")
+
+			hub.removeClient(client, ClosePongTimeout)
 		}
 	}
 }
 
+// disconnectUser removes all of a user's clients, telling them reason
+func (hub *Hub) disconnectUser(userID uuid.UUID, reason CloseReason) {
+	for client := range hub.clients[userID] {
+		hub.removeClient(client, reason)
+	}
+}
+
+// removeClient drops a registered client, closes its send channel and frees its slot.
+// WritePump then sends reason in a close frame. Returns false if the client was already removed.
+func (hub *Hub) removeClient(client *Client, reason CloseReason) bool {
+	userClients, ok := hub.clients[client.userID]
+	if !ok || !userClients[client] {
+		return false
+	}
+
+	delete(userClients, client)
+	if len(userClients) == 0 {
+		delete(hub.clients, client.userID)
+	}
+	client.closeReason = reason
+	close(client.send)
+	hub.Release(client.userID)
+
+	return true
+}
+
+// TryAcquire reserves a connection slot for the user.
+// Returns false when the total or per-user connection limit is reached.
+// Every successful TryAcquire must be paired with a RegisterClient or a Release.
+func (hub *Hub) TryAcquire(userID uuid.UUID) bool {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.MaxConnections > 0 && hub.connections >= hub.MaxConnections {
+		return false
+	}
+	if hub.MaxConnectionsPerUser > 0 && hub.userConnections[userID] >= hub.MaxConnectionsPerUser {
+		return false
+	}
+
+	hub.connections++
+	hub.userConnections[userID]++
+	return true
+}
+
+// Release frees a connection slot reserved with TryAcquire
+func (hub *Hub) Release(userID uuid.UUID) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.userConnections[userID] == 0 {
+		return
+	}
+
+	hub.connections--
+	hub.userConnections[userID]--
+	if hub.userConnections[userID] == 0 {
+		delete(hub.userConnections, userID)
+	}
+}
+
+// Stats returns the current connection counts
+func (hub *Hub) Stats() HubStats {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return HubStats{
+		Connections:    hub.connections,
+		Users:          len(hub.userConnections),
+		DroppedSignals: hub.droppedSignals.Load(),
+	}
+}
+
+// RegisterClient adds a client to the Hub. The caller must then run the client's WritePump.
 func (hub *Hub) RegisterClient(c *Client) {
+	hub.writers.Add(1)
 	hub.register <- c
 }
 
-func (hub *Hub) SendSignal(signals map[uuid.UUID][]byte) {
-	hub.signal <- signals
+// DisconnectUser closes all of the user's connections with reason, without blocking
+// the caller; if the Hub is too busy the request is dropped like a signal.
+func (hub *Hub) DisconnectUser(userID uuid.UUID, reason CloseReason) {
+	select {
+	case hub.disconnect <- userDisconnect{userID: userID, reason: reason}:
+	default:
+		hub.Logger.Warn().
+			Str("user_id", userID.String()).
+			Msg("Hub disconnect queue is full, dropping disconnect.")
+	}
+}
+
+// Shutdown disconnects every client with CloseServerShutdown and stops Run. It
+// waits until the close frames have been written or ctx is done; Run must be running.
+func (hub *Hub) Shutdown(ctx context.Context) error {
+	hub.shutdownOnce.Do(func() { close(hub.shutdown) })
+
+	select {
+	case <-hub.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	written := make(chan struct{})
+	go func() {
+		hub.writers.Wait()
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SendSignal queues signals for delivery without blocking the caller.
+// When the Hub is too busy to keep up and the queue is full, the signals are
+// dropped and logged; clients catch up on their next fetch. Returns whether
+// the signals were queued.
+func (hub *Hub) SendSignal(signals map[uuid.UUID][]byte) bool {
+	select {
+	case hub.signal <- signals:
+		return true
+	default:
+		dropped := hub.droppedSignals.Add(1)
+		hub.Logger.Warn().
+			Int("users", len(signals)).
+			Int64("dropped_total", dropped).
+			Msg("Hub signal queue is full, dropping signal.")
+		return false
+	}
 }
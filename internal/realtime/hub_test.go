@@ -0,0 +1,264 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+)
+
+func TestTryAcquire_TotalLimit_RefusesExtraConnection(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	hub.MaxConnections = 2
+
+	if !hub.TryAcquire(uuid.New()) || !hub.TryAcquire(uuid.New()) {
+		t.Fatal("Expected first two connections to be accepted")
+	}
+
+	if hub.TryAcquire(uuid.New()) {
+		t.Error("Expected third connection to be refused")
+	}
+
+	if stats := hub.Stats(); stats.Connections != 2 || stats.Users != 2 {
+		t.Errorf("Expected 2 connections from 2 users, got %+v", stats)
+	}
+}
+
+func TestTryAcquire_PerUserLimit_RefusesExtraConnection(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	hub.MaxConnectionsPerUser = 1
+	userID := uuid.New()
+
+	if !hub.TryAcquire(userID) {
+		t.Fatal("Expected first connection to be accepted")
+	}
+
+	if hub.TryAcquire(userID) {
+		t.Error("Expected second connection for the same user to be refused")
+	}
+
+	if !hub.TryAcquire(uuid.New()) {
+		t.Error("Expected another user's connection to be accepted")
+	}
+}
+
+func TestRelease_FreesSlot(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	hub.MaxConnections = 1
+	userID := uuid.New()
+
+	hub.TryAcquire(userID)
+	hub.Release(userID)
+
+	if !hub.TryAcquire(uuid.New()) {
+		t.Error("Expected connection to be accepted after release")
+	}
+
+	// Releasing a slot that was never acquired must not go negative
+	hub.Release(uuid.New())
+	if stats := hub.Stats(); stats.Connections != 1 {
+		t.Errorf("Expected 1 connection, got %d", stats.Connections)
+	}
+}
+
+func TestSendSignal_QueueFull_DropsWithoutBlocking(t *testing.T) {
+	hub := NewHubWithBuffer(zerolog.Nop(), 1)
+	signals := map[uuid.UUID][]byte{uuid.New(): []byte(`{}`)}
+
+	if !hub.SendSignal(signals) {
+		t.Fatal("Expected first signal to be queued")
+	}
+
+	if hub.SendSignal(signals) {
+		t.Error("Expected second signal to be dropped while the queue is full")
+	}
+
+	if dropped := hub.Stats().DroppedSignals; dropped != 1 {
+		t.Errorf("Expected 1 dropped signal, got %d", dropped)
+	}
+}
+
+// newTestConnPair returns the server and client ends of a WebSocket connection
+func newTestConnPair(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	t.Helper()
+
+	serverConns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade: %v", err)
+			return
+		}
+		serverConns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	return <-serverConns, clientConn
+}
+
+// readCloseCode reads from conn until the server closes it and returns the close error
+func readCloseCode(t *testing.T, conn *websocket.Conn) *websocket.CloseError {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("Expected a close frame, got %v", err)
+		}
+		return closeErr
+	}
+}
+
+func TestDispatch_SlowConsumer_ClosedWithReason(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	userID := uuid.New()
+	serverConn, clientConn := newTestConnPair(t)
+
+	// A one-slot send channel fills after the first signal
+	client := &Client{hub: hub, conn: serverConn, userID: userID, send: make(chan []byte, 1)}
+	hub.TryAcquire(userID)
+	hub.writers.Add(1)
+	hub.addClient(client)
+
+	hub.dispatch(map[uuid.UUID][]byte{userID: []byte(`{"n":1}`)})
+	hub.dispatch(map[uuid.UUID][]byte{userID: []byte(`{"n":2}`)})
+
+	if stats := hub.Stats(); stats.Connections != 0 {
+		t.Errorf("Expected the slow client's slot to be released, got %+v", stats)
+	}
+
+	go client.WritePump()
+
+	closeErr := readCloseCode(t, clientConn)
+	if closeErr.Code != CloseSlowConsumer.Code {
+		t.Errorf("Expected close code %d, got %d", CloseSlowConsumer.Code, closeErr.Code)
+	}
+	if closeErr.Text != CloseSlowConsumer.Text {
+		t.Errorf("Expected close reason %q, got %q", CloseSlowConsumer.Text, closeErr.Text)
+	}
+}
+
+func TestShutdown_ClosesClientsWithServerShutdown(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	userID := uuid.New()
+	serverConn, clientConn := newTestConnPair(t)
+
+	client := NewClient(hub, serverConn, userID)
+	hub.TryAcquire(userID)
+	hub.writers.Add(1)
+	hub.addClient(client)
+	go client.WritePump()
+	go hub.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("Expected shutdown to complete, got %v", err)
+	}
+
+	closeErr := readCloseCode(t, clientConn)
+	if closeErr.Code != CloseServerShutdown.Code || closeErr.Text != CloseServerShutdown.Text {
+		t.Errorf("Expected close %d %q, got %d %q", CloseServerShutdown.Code, CloseServerShutdown.Text, closeErr.Code, closeErr.Text)
+	}
+}
+
+func TestDisconnectUser_ClosesOnlyThatUser(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	userID, otherID := uuid.New(), uuid.New()
+	serverConn, _ := newTestConnPair(t)
+	otherConn, _ := newTestConnPair(t)
+
+	client := NewClient(hub, serverConn, userID)
+	other := NewClient(hub, otherConn, otherID)
+	for _, c := range []*Client{client, other} {
+		hub.TryAcquire(c.userID)
+		hub.addClient(c)
+	}
+
+	hub.disconnectUser(userID, CloseUnauthorized)
+
+	if _, ok := <-client.send; ok {
+		t.Error("Expected the user's send channel to be closed")
+	}
+	if client.closeReason != CloseUnauthorized {
+		t.Errorf("Expected close reason %+v, got %+v", CloseUnauthorized, client.closeReason)
+	}
+	if stats := hub.Stats(); stats.Connections != 1 {
+		t.Errorf("Expected the other user's connection to remain, got %+v", stats)
+	}
+}
+
+func TestRun_ClientStoppedPonging_ReapedWithPongTimeout(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	hub.reapInterval = 10 * time.Millisecond
+	staleID, activeID := uuid.New(), uuid.New()
+	serverConn, clientConn := newTestConnPair(t)
+
+	// The dialed end never reads, so it never answers a ping
+	stale := NewClient(hub, serverConn, staleID)
+	stale.lastPong.Store(time.Now().Add(-staleAfter - time.Second).UnixNano())
+	active := NewClient(hub, nil, activeID)
+	for _, c := range []*Client{stale, active} {
+		hub.TryAcquire(c.userID)
+		hub.addClient(c)
+	}
+	hub.writers.Add(1)
+	go stale.WritePump()
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = hub.Shutdown(ctx)
+	})
+
+	closeErr := readCloseCode(t, clientConn)
+	if closeErr.Code != ClosePongTimeout.Code || closeErr.Text != ClosePongTimeout.Text {
+		t.Errorf("Expected close %d %q, got %d %q", ClosePongTimeout.Code, ClosePongTimeout.Text, closeErr.Code, closeErr.Text)
+	}
+	if stats := hub.Stats(); stats.Connections != 1 || stats.Users != 1 {
+		t.Errorf("Expected only the active client to remain, got %+v", stats)
+	}
+}
+
+func TestReapStaleClients_RecentPong_KeepsClient(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	userID := uuid.New()
+
+	client := NewClient(hub, nil, userID)
+	hub.TryAcquire(userID)
+	hub.addClient(client)
+
+	hub.reapStaleClients(time.Now().Add(staleAfter - time.Second))
+
+	if stats := hub.Stats(); stats.Connections != 1 {
+		t.Errorf("Expected the client to be kept, got %+v", stats)
+	}
+
+	hub.reapStaleClients(time.Now().Add(staleAfter + time.Second))
+
+	if _, ok := <-client.send; ok {
+		t.Error("Expected the stale client's send channel to be closed")
+	}
+	if client.closeReason != ClosePongTimeout {
+		t.Errorf("Expected close reason %+v, got %+v", ClosePongTimeout, client.closeReason)
+	}
+}
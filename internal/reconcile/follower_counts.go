@@ -0,0 +1,58 @@
+// Package reconcile holds background maintenance jobs that correct or clean up stored data
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+// FollowerCountStore is the query the follower count reconciler runs
+type FollowerCountStore interface {
+	ReconcileFeedFollowerCounts(ctx context.Context) (int64, error)
+}
+
+// FollowerCounts recomputes the follower counts cached on feeds.
+// Follows and unfollows keep the counts in step, but deletes that cascade
+// through feed_follows (e.g. deleting a user) don't, so they drift over time.
+type FollowerCounts struct {
+	Store  FollowerCountStore
+	Logger zerolog.Logger
+}
+
+func NewFollowerCounts(store FollowerCountStore, log zerolog.Logger) *FollowerCounts {
+	return &FollowerCounts{
+		Store:  store,
+		Logger: log,
+	}
+}
+
+// Run corrects every drifted follower count once and returns how many feeds were corrected
+func (f *FollowerCounts) Run(ctx context.Context) (int64, error) {
+	corrected, err := f.Store.ReconcileFeedFollowerCounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if corrected > 0 {
+		f.Logger.Info().Int64("feeds_corrected", corrected).Msg("Corrected drifted feed follower counts")
+	}
+
+	return corrected, nil
+}
+
+// Start runs the reconciler every interval
+func (f *FollowerCounts) Start(interval time.Duration) {
+	f.Logger.Info().Msgf("Starting follower count reconciliation with interval %v", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := f.Run(context.Background()); err != nil {
+			logger.ErrorErr(err, "Error reconciling feed follower counts")
+		}
+	}
+}
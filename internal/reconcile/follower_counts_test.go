@@ -0,0 +1,74 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// stubFollowerCountStore applies ReconcileFeedFollowerCounts to in-memory tables
+type stubFollowerCountStore struct {
+	followerCounts map[uuid.UUID]int32
+	follows        map[uuid.UUID][]uuid.UUID
+	err            error
+}
+
+func (s *stubFollowerCountStore) ReconcileFeedFollowerCounts(ctx context.Context) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	var corrected int64
+	for feedID, cached := range s.followerCounts {
+		actual := int32(len(s.follows[feedID]))
+		if cached != actual {
+			s.followerCounts[feedID] = actual
+			corrected++
+		}
+	}
+	return corrected, nil
+}
+
+func TestFollowerCountsRun_Drift_IsCorrected(t *testing.T) {
+	inSync, inflated, deflated := uuid.New(), uuid.New(), uuid.New()
+	store := &stubFollowerCountStore{
+		followerCounts: map[uuid.UUID]int32{inSync: 2, inflated: 5, deflated: 0},
+		follows: map[uuid.UUID][]uuid.UUID{
+			inSync:   {uuid.New(), uuid.New()},
+			inflated: {uuid.New()},
+			deflated: {uuid.New(), uuid.New(), uuid.New()},
+		},
+	}
+	reconciler := NewFollowerCounts(store, zerolog.Nop())
+
+	corrected, err := reconciler.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if corrected != 2 {
+		t.Errorf("Expected 2 feeds corrected, got %d", corrected)
+	}
+
+	expected := map[uuid.UUID]int32{inSync: 2, inflated: 1, deflated: 3}
+	for feedID, count := range expected {
+		if store.followerCounts[feedID] != count {
+			t.Errorf("Expected follower count %d, got %d", count, store.followerCounts[feedID])
+		}
+	}
+
+	if corrected, _ := reconciler.Run(context.Background()); corrected != 0 {
+		t.Errorf("Expected nothing left to correct, got %d", corrected)
+	}
+}
+
+func TestFollowerCountsRun_StoreError_ReturnsError(t *testing.T) {
+	store := &stubFollowerCountStore{err: errors.New("connection refused")}
+
+	if _, err := NewFollowerCounts(store, zerolog.Nop()).Run(context.Background()); err == nil {
+		t.Error("Expected error from store")
+	}
+}
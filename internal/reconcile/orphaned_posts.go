@@ -0,0 +1,76 @@
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+// DefaultOrphanBatchSize is how many orphaned posts are deleted per statement
+const DefaultOrphanBatchSize = 1000
+
+// OrphanedPostStore is the query the orphaned post cleanup runs
+type OrphanedPostStore interface {
+	DeleteOrphanedPosts(ctx context.Context, batchSize int32) (int64, error)
+}
+
+// OrphanedPosts deletes posts whose feed no longer exists.
+// posts.feed_id cascades on delete, so this is a safety net for rows left
+// behind by manual deletes or restores rather than something expected to find work.
+type OrphanedPosts struct {
+	Store  OrphanedPostStore
+	Logger zerolog.Logger
+	// BatchSize caps the rows deleted per statement so no delete holds locks for long
+	BatchSize int
+}
+
+func NewOrphanedPosts(store OrphanedPostStore, log zerolog.Logger) *OrphanedPosts {
+	return &OrphanedPosts{
+		Store:     store,
+		Logger:    log,
+		BatchSize: DefaultOrphanBatchSize,
+	}
+}
+
+// Run deletes orphaned posts batch by batch until none are left and returns how many were deleted
+func (o *OrphanedPosts) Run(ctx context.Context) (int64, error) {
+	batchSize := o.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultOrphanBatchSize
+	}
+
+	var total int64
+	for {
+		deleted, err := o.Store.DeleteOrphanedPosts(ctx, int32(batchSize))
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+
+		if deleted < int64(batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		o.Logger.Info().Int64("posts_deleted", total).Msg("Deleted orphaned posts")
+	}
+
+	return total, nil
+}
+
+// Start runs the cleanup every interval
+func (o *OrphanedPosts) Start(interval time.Duration) {
+	o.Logger.Info().Msgf("Starting orphaned post cleanup with interval %v", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := o.Run(context.Background()); err != nil {
+			logger.ErrorErr(err, "Error deleting orphaned posts")
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// stubOrphanedPostStore applies DeleteOrphanedPosts to in-memory tables
+type stubOrphanedPostStore struct {
+	feeds   map[uuid.UUID]bool
+	posts   map[uuid.UUID]uuid.UUID // post id -> feed id
+	batches []int32
+	err     error
+}
+
+func (s *stubOrphanedPostStore) DeleteOrphanedPosts(ctx context.Context, batchSize int32) (int64, error) {
+	s.batches = append(s.batches, batchSize)
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	var deleted int64
+	for postID, feedID := range s.posts {
+		if deleted == int64(batchSize) {
+			break
+		}
+		if !s.feeds[feedID] {
+			delete(s.posts, postID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestOrphanedPostsRun_SeededOrphans_AreDeletedInBatches(t *testing.T) {
+	liveFeed, deletedFeed := uuid.New(), uuid.New()
+	store := &stubOrphanedPostStore{
+		feeds: map[uuid.UUID]bool{liveFeed: true},
+		posts: make(map[uuid.UUID]uuid.UUID),
+	}
+
+	livePost := uuid.New()
+	store.posts[livePost] = liveFeed
+	for i := 0; i < 5; i++ {
+		store.posts[uuid.New()] = deletedFeed
+	}
+
+	cleanup := NewOrphanedPosts(store, zerolog.Nop())
+	cleanup.BatchSize = 2
+
+	deleted, err := cleanup.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if deleted != 5 {
+		t.Errorf("Expected 5 orphaned posts deleted, got %d", deleted)
+	}
+
+	if len(store.posts) != 1 || store.posts[livePost] != liveFeed {
+		t.Errorf("Expected only the live feed's post left, got %v", store.posts)
+	}
+
+	// 2 + 2 + 1: the short batch shows nothing is left
+	if len(store.batches) != 3 {
+		t.Errorf("Expected 3 batches, got %d", len(store.batches))
+	}
+}
+
+func TestOrphanedPostsRun_NoOrphans_RunsOneBatch(t *testing.T) {
+	store := &stubOrphanedPostStore{posts: make(map[uuid.UUID]uuid.UUID)}
+
+	deleted, err := NewOrphanedPosts(store, zerolog.Nop()).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if deleted != 0 || len(store.batches) != 1 {
+		t.Errorf("Expected one empty batch, got %d deleted in %d batches", deleted, len(store.batches))
+	}
+}
+
+func TestOrphanedPostsRun_StoreError_ReturnsError(t *testing.T) {
+	store := &stubOrphanedPostStore{err: errors.New("connection refused")}
+
+	if _, err := NewOrphanedPosts(store, zerolog.Nop()).Run(context.Background()); err == nil {
+		t.Error("Expected error from store")
+	}
+}
@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// HourlyActivityBuckets is how many hour buckets the activity endpoint returns.
+// Older hour buckets are already counted in their day bucket, so pruning deletes them.
+const HourlyActivityBuckets = 48
+
+// activityBucket is a bucket size post ingestion is counted in
+type activityBucket struct {
+	granularity string
+	size        time.Duration
+}
+
+// activityBuckets lists every bucket size recorded for a feed's ingested posts
+var activityBuckets = []activityBucket{
+	{granularity: "hour", size: time.Hour},
+	{granularity: "day", size: 24 * time.Hour},
+}
+
+// activityStore is the subset of queries needed to record feed activity
+type activityStore interface {
+	IncrementFeedActivity(ctx context.Context, arg database.IncrementFeedActivityParams) error
+}
+
+// recordActivity adds newPostCount to the feed's current hour and day buckets.
+// Each bucket is a single upserted row, so the write stays cheap however many posts arrive.
+func (s *Scraper) recordActivity(ctx context.Context, store activityStore, feedID uuid.UUID, newPostCount int, now time.Time) {
+	if newPostCount <= 0 {
+		return
+	}
+
+	for _, bucket := range activityBuckets {
+		err := store.IncrementFeedActivity(ctx, database.IncrementFeedActivityParams{
+			FeedID:      feedID,
+			Granularity: bucket.granularity,
+			BucketStart: now.UTC().Truncate(bucket.size),
+			PostCount:   int32(newPostCount),
+		})
+		if err != nil {
+			s.Logger.Error().Err(err).
+				Str("feed_id", feedID.String()).
+				Str("granularity", bucket.granularity).
+				Msg("Failed to record feed activity")
+		}
+	}
+}
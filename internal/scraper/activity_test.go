@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+type stubActivityStore struct {
+	calls []database.IncrementFeedActivityParams
+}
+
+func (s *stubActivityStore) IncrementFeedActivity(ctx context.Context, arg database.IncrementFeedActivityParams) error {
+	s.calls = append(s.calls, arg)
+	return nil
+}
+
+func TestRecordActivity_UpsertsHourAndDayBuckets(t *testing.T) {
+	store := &stubActivityStore{}
+	now := time.Date(2024, 3, 5, 14, 37, 12, 0, time.UTC)
+
+	newTestScraper().recordActivity(context.Background(), store, uuid.New(), 4, now)
+
+	expected := map[string]time.Time{
+		"hour": time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC),
+		"day":  time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+	}
+
+	if len(store.calls) != len(expected) {
+		t.Fatalf("Expected %d upserts, got %d", len(expected), len(store.calls))
+	}
+
+	for _, call := range store.calls {
+		if !call.BucketStart.Equal(expected[call.Granularity]) {
+			t.Errorf("Expected %s bucket %v, got %v", call.Granularity, expected[call.Granularity], call.BucketStart)
+		}
+		if call.PostCount != 4 {
+			t.Errorf("Expected post count 4, got %d", call.PostCount)
+		}
+	}
+}
+
+func TestRecordActivity_NoNewPosts_SkipsWrite(t *testing.T) {
+	store := &stubActivityStore{}
+
+	newTestScraper().recordActivity(context.Background(), store, uuid.New(), 0, time.Now())
+
+	if len(store.calls) != 0 {
+		t.Errorf("Expected no upserts, got %d", len(store.calls))
+	}
+}
@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+)
+
+// maxFullContentFetches caps how many articles are fetched for one scrape of a feed,
+// so a feed publishing many posts at once doesn't hold up the scrape cycle
+const maxFullContentFetches = 20
+
+// ContentFetcher fetches a post's article page and returns its main content as sanitized HTML
+type ContentFetcher interface {
+	Fetch(ctx context.Context, articleURL string) (string, error)
+}
+
+// fullContentStore is the subset of queries needed to store extracted article content
+type fullContentStore interface {
+	UpdatePostFullContent(ctx context.Context, arg database.UpdatePostFullContentParams) error
+}
+
+// storeFullContent fetches the article of each created post of a feed with
+// fetch_full_content set and stores its extracted content. Posts whose article
+// can't be fetched or has no recognizable content keep only their description.
+func (s *Scraper) storeFullContent(ctx context.Context, store fullContentStore, feed database.Feed, createdPosts []database.Post) {
+	if !feed.FetchFullContent || s.ContentFetcher == nil {
+		return
+	}
+
+	fetched := 0
+	for _, post := range createdPosts {
+		if post.Url == "" {
+			continue
+		}
+		if fetched == maxFullContentFetches {
+			s.Logger.Debug().
+				Str("feed_id", feed.ID.String()).
+				Int("skipped_posts", len(createdPosts)-maxFullContentFetches).
+				Msg("Full content fetch limit reached for feed")
+			return
+		}
+		fetched++
+
+		content, err := s.ContentFetcher.Fetch(ctx, post.Url)
+		if err != nil {
+			s.Logger.Debug().Err(err).Str("feed_id", feed.ID.String()).Str("post_url", post.Url).Msg("Failed to fetch post full content")
+			continue
+		}
+
+		err = store.UpdatePostFullContent(ctx, database.UpdatePostFullContentParams{
+			ID:          post.ID,
+			FullContent: sql.NullString{String: content, Valid: true},
+		})
+		if err != nil {
+			s.Logger.Error().Err(err).Str("post_id", post.ID.String()).Msg("Failed to store post full content")
+		}
+	}
+}
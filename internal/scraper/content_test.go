@@ -0,0 +1,108 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/readability"
+)
+
+// stubArticlePage is an article between a navigation bar and a comments section
+const stubArticlePage = `<html><body>
+	<nav><a href="/">Home</a> <a href="/archive">Archive of every post ever written</a></nav>
+	<article>
+		<h1>Full post</h1>
+		<p>This is the full text of the post, which the feed only summarizes in a sentence or two.</p>
+		<p>It goes on for a while, with details, examples, and a conclusion the summary leaves out.</p>
+	</article>
+	<div id="comments"><p>Great post, thanks for writing it up in so much detail for all of us!</p></div>
+</body></html>`
+
+// newStubArticleServer serves stubArticlePage for every path and counts the requests
+func newStubArticleServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(stubArticlePage))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestFetchAndStoreFeed_FetchFullContent_StoresExtractedArticle(t *testing.T) {
+	server, _ := newStubArticleServer(t)
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml", FetchFullContent: true}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, fakeParsedFeed("hash-1", server.URL+"/full-post"))
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	s.ContentFetcher = readability.NewFetcher()
+	store := newStubFeedStore()
+
+	if newPostCount := s.fetchAndStoreFeed(context.Background(), store, feed); newPostCount != 1 {
+		t.Fatalf("Expected 1 new post, got %d", newPostCount)
+	}
+
+	if len(store.fullContent) != 1 {
+		t.Fatalf("Expected full content stored for 1 post, got %d", len(store.fullContent))
+	}
+	for _, content := range store.fullContent {
+		if !strings.Contains(content, "This is the full text of the post") || !strings.Contains(content, "a conclusion the summary leaves out") {
+			t.Errorf("Expected the article text, got %q", content)
+		}
+		if strings.Contains(content, "Archive") || strings.Contains(content, "Great post") {
+			t.Errorf("Expected navigation and comments to be stripped, got %q", content)
+		}
+	}
+}
+
+func TestFetchAndStoreFeed_FetchFullContentOff_SkipsArticles(t *testing.T) {
+	server, requests := newStubArticleServer(t)
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, fakeParsedFeed("hash-1", server.URL+"/a", server.URL+"/b"))
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	s.ContentFetcher = readability.NewFetcher()
+	store := newStubFeedStore()
+
+	s.fetchAndStoreFeed(context.Background(), store, feed)
+
+	if *requests != 0 || len(store.fullContent) != 0 {
+		t.Errorf("Expected no article fetches for a feed without fetch_full_content, got %d requests and %d stored", *requests, len(store.fullContent))
+	}
+}
+
+func TestStoreFullContent_CapsFetchesPerFeed(t *testing.T) {
+	server, requests := newStubArticleServer(t)
+	feed := database.Feed{ID: uuid.New(), FetchFullContent: true}
+	var posts []database.Post
+	for i := 0; i < maxFullContentFetches+5; i++ {
+		posts = append(posts, database.Post{ID: uuid.New(), Url: fmt.Sprintf("%s/post/%d", server.URL, i)})
+	}
+
+	s := newTestScraper()
+	s.ContentFetcher = readability.NewFetcher()
+	store := newStubFeedStore()
+
+	s.storeFullContent(context.Background(), store, feed, posts)
+
+	if *requests != maxFullContentFetches || len(store.fullContent) != maxFullContentFetches {
+		t.Errorf("Expected %d articles fetched and stored, got %d requests and %d stored", maxFullContentFetches, *requests, len(store.fullContent))
+	}
+}
@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"context"
+	"time"
+)
+
+// pruneInterval is how often the scraping loop deletes rows nothing reads anymore
+const pruneInterval = time.Hour
+
+// DefaultNotificationRetention is how long an unacknowledged notification is kept
+const DefaultNotificationRetention = 30 * 24 * time.Hour
+
+// pruneStore is the subset of queries used to delete rows nothing reads anymore
+type pruneStore interface {
+	DeleteStaleNotifications(ctx context.Context, createdBefore time.Time) (int64, error)
+	DeleteHourlyFeedActivityBefore(ctx context.Context, bucketStartBefore time.Time) (int64, error)
+}
+
+// pruneDue reports whether the last prune is at least pruneInterval before now
+func (s *Scraper) pruneDue(now time.Time) bool {
+	return now.Sub(s.lastPruned) >= pruneInterval
+}
+
+// prune deletes acknowledged notifications, those older than NotificationRetention,
+// and hour activity buckets that have fallen out of the hourly window
+func (s *Scraper) prune(ctx context.Context, store pruneStore, now time.Time) {
+	s.lastPruned = now
+
+	deleted, err := store.DeleteStaleNotifications(ctx, now.UTC().Add(-s.notificationRetention()))
+	if err != nil {
+		s.Logger.Error().Err(err).Msg("Failed to prune notifications")
+	} else {
+		s.Logger.Debug().Int64("deleted", deleted).Msg("Pruned notifications")
+	}
+
+	deleted, err = store.DeleteHourlyFeedActivityBefore(ctx, hourlyActivityWindowStart(now))
+	if err != nil {
+		s.Logger.Error().Err(err).Msg("Failed to prune hourly feed activity")
+	} else {
+		s.Logger.Debug().Int64("deleted", deleted).Msg("Pruned hourly feed activity")
+	}
+}
+
+// hourlyActivityWindowStart returns the start of the oldest hour bucket the activity endpoint returns
+func hourlyActivityWindowStart(now time.Time) time.Time {
+	return now.UTC().Truncate(time.Hour).Add(-(HourlyActivityBuckets - 1) * time.Hour)
+}
+
+// notificationRetention returns the configured retention, falling back to the default
+func (s *Scraper) notificationRetention() time.Duration {
+	if s.NotificationRetention <= 0 {
+		return DefaultNotificationRetention
+	}
+	return s.NotificationRetention
+}
@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type stubPruneStore struct {
+	notificationCutoffs []time.Time
+	activityCutoffs     []time.Time
+}
+
+func (s *stubPruneStore) DeleteStaleNotifications(ctx context.Context, createdBefore time.Time) (int64, error) {
+	s.notificationCutoffs = append(s.notificationCutoffs, createdBefore)
+	return 0, nil
+}
+
+func (s *stubPruneStore) DeleteHourlyFeedActivityBefore(ctx context.Context, bucketStartBefore time.Time) (int64, error) {
+	s.activityCutoffs = append(s.activityCutoffs, bucketStartBefore)
+	return 0, nil
+}
+
+func TestPrune_DeletesNotificationsOlderThanRetention(t *testing.T) {
+	testCases := []struct {
+		name      string
+		retention time.Duration
+		expected  time.Duration
+	}{
+		{name: "default", retention: 0, expected: DefaultNotificationRetention},
+		{name: "configured", retention: 48 * time.Hour, expected: 48 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &stubPruneStore{}
+			now := time.Date(2024, 3, 5, 14, 37, 12, 0, time.UTC)
+			s := newTestScraper()
+			s.NotificationRetention = tc.retention
+
+			s.prune(context.Background(), store, now)
+
+			if len(store.notificationCutoffs) != 1 {
+				t.Fatalf("Expected 1 notification prune, got %d", len(store.notificationCutoffs))
+			}
+			if cutoff := store.notificationCutoffs[0]; !cutoff.Equal(now.Add(-tc.expected)) {
+				t.Errorf("Expected cutoff %v, got %v", now.Add(-tc.expected), cutoff)
+			}
+		})
+	}
+}
+
+func TestPrune_KeepsHourlyActivityWindow(t *testing.T) {
+	store := &stubPruneStore{}
+	now := time.Date(2024, 3, 5, 14, 37, 12, 0, time.UTC)
+
+	newTestScraper().prune(context.Background(), store, now)
+
+	if len(store.activityCutoffs) != 1 {
+		t.Fatalf("Expected 1 activity prune, got %d", len(store.activityCutoffs))
+	}
+	// The current hour plus the 47 before it are still returned by the activity endpoint
+	expected := time.Date(2024, 3, 3, 15, 0, 0, 0, time.UTC)
+	if cutoff := store.activityCutoffs[0]; !cutoff.Equal(expected) {
+		t.Errorf("Expected cutoff %v, got %v", expected, cutoff)
+	}
+}
+
+func TestPruneDue_WaitsForPruneInterval(t *testing.T) {
+	store := &stubPruneStore{}
+	now := time.Now()
+	s := newTestScraper()
+
+	if !s.pruneDue(now) {
+		t.Fatal("Expected the first tick to prune")
+	}
+	s.prune(context.Background(), store, now)
+
+	if s.pruneDue(now.Add(pruneInterval - time.Minute)) {
+		t.Error("Expected no prune before the interval has passed")
+	}
+	if !s.pruneDue(now.Add(pruneInterval)) {
+		t.Error("Expected a prune once the interval has passed")
+	}
+}
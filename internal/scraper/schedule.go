@@ -0,0 +1,55 @@
+package scraper
+
+import "time"
+
+const (
+	// minFetchInterval is the shortest time between two fetches of the same feed
+	minFetchInterval = time.Minute
+	// maxFetchInterval is the longest a dormant, lowest-priority feed waits between fetches
+	maxFetchInterval = 6 * time.Hour
+	// fetchIntervalGrowth slows down fetching of feeds that produced nothing new
+	fetchIntervalGrowth = 1.5
+)
+
+// nextFetchInterval adapts a feed's fetch interval to its observed activity.
+// Feeds that produced new posts are fetched twice as often, dormant feeds back off
+// gradually. The ceiling depends on priority (1-5): a priority 5 feed never waits
+// longer than a fifth of maxFetchInterval, a priority 1 feed can wait the full amount.
+func nextFetchInterval(current time.Duration, newPostCount int, priority int32) time.Duration {
+	if current < minFetchInterval {
+		current = minFetchInterval
+	}
+
+	var next time.Duration
+	if newPostCount > 0 {
+		next = current / 2
+	} else {
+		next = time.Duration(float64(current) * fetchIntervalGrowth)
+	}
+
+	if next < minFetchInterval {
+		next = minFetchInterval
+	}
+
+	if ceiling := maxIntervalForPriority(priority); next > ceiling {
+		next = ceiling
+	}
+
+	return next
+}
+
+// maxIntervalForPriority returns the longest fetch interval allowed for a priority
+func maxIntervalForPriority(priority int32) time.Duration {
+	if priority < 1 {
+		priority = 1
+	}
+	if priority > 5 {
+		priority = 5
+	}
+
+	ceiling := maxFetchInterval * time.Duration(6-priority) / 5
+	if ceiling < minFetchInterval {
+		return minFetchInterval
+	}
+	return ceiling
+}
@@ -0,0 +1,55 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextFetchInterval_ActiveFeed_ScheduledSoonerThanDormant(t *testing.T) {
+	current := 30 * time.Minute
+
+	active := nextFetchInterval(current, 5, 3)
+	dormant := nextFetchInterval(current, 0, 3)
+
+	if active >= dormant {
+		t.Errorf("Expected active feed interval %v to be shorter than dormant %v", active, dormant)
+	}
+
+	if active >= current {
+		t.Errorf("Expected active feed interval %v to shrink from %v", active, current)
+	}
+
+	if dormant <= current {
+		t.Errorf("Expected dormant feed interval %v to grow from %v", dormant, current)
+	}
+}
+
+func TestNextFetchInterval_NeverBelowMinimum(t *testing.T) {
+	interval := nextFetchInterval(minFetchInterval, 10, 5)
+
+	if interval != minFetchInterval {
+		t.Errorf("Expected %v, got %v", minFetchInterval, interval)
+	}
+}
+
+func TestNextFetchInterval_UnscheduledFeed_StartsAtMinimum(t *testing.T) {
+	interval := nextFetchInterval(0, 0, 3)
+
+	expected := time.Duration(float64(minFetchInterval) * fetchIntervalGrowth)
+	if interval != expected {
+		t.Errorf("Expected %v, got %v", expected, interval)
+	}
+}
+
+func TestNextFetchInterval_DormantFeed_CappedByPriority(t *testing.T) {
+	high := nextFetchInterval(maxFetchInterval, 0, 5)
+	low := nextFetchInterval(maxFetchInterval, 0, 1)
+
+	if high != maxFetchInterval/5 {
+		t.Errorf("Expected priority 5 ceiling %v, got %v", maxFetchInterval/5, high)
+	}
+
+	if low != maxFetchInterval {
+		t.Errorf("Expected priority 1 ceiling %v, got %v", maxFetchInterval, low)
+	}
+}
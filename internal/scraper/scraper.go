@@ -3,29 +3,90 @@ package scraper
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/logger"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/models"
 	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/textutil"
 	"github.com/rs/zerolog"
 )
 
+// maxRetryQueueSize bounds how many failed post inserts are retried per feed
+const maxRetryQueueSize = 50
+
+// postStore is the subset of database queries used to store scraped posts
+type postStore interface {
+	CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error)
+}
+
+// feedStore is the subset of database queries used while scraping a feed
+type feedStore interface {
+	postStore
+	fetchStatusStore
+	activityStore
+	followerStore
+	fullContentStore
+	UpdateFeedLastBodyHash(ctx context.Context, arg database.UpdateFeedLastBodyHashParams) error
+	UpdateFeedHTTPValidators(ctx context.Context, arg database.UpdateFeedHTTPValidatorsParams) error
+	UpdateFeedLastPostAt(ctx context.Context, arg database.UpdateFeedLastPostAtParams) error
+}
+
+// followerStore is the subset of database queries used to notify a feed's followers
+type followerStore interface {
+	GetFollowersByFeedID(ctx context.Context, feedID uuid.UUID) ([]database.GetFollowersByFeedIDRow, error)
+	CreateNotifications(ctx context.Context, arg database.CreateNotificationsParams) error
+}
+
+// newPostSignalType is the type of the signal sent when a feed gets new posts
+const newPostSignalType = "NEW_POST_AVAILABLE"
+
 type Scraper struct {
 	DB     *database.Queries
 	Logger zerolog.Logger
 	Hub    *realtime.Hub
+	// Fetcher downloads and parses feeds
+	Fetcher feedfetch.FeedFetcher
+	// HeadCheck sends a HEAD request before fetching a feed and skips the fetch when its
+	// ETag, Last-Modified and Content-Length match the last stored fetch. Needs a Fetcher
+	// that implements feedfetch.HeadFetcher; feeds whose HEAD fails are fetched as usual.
+	HeadCheck bool
+	// PostsCache is invalidated for a feed's followers when it gets new posts (nil = disabled)
+	PostsCache *cache.PostsCache
+	// MaxTitleBytes caps stored post titles (0 = default of textutil.DefaultMaxTitleBytes)
+	MaxTitleBytes int
+	// MaxDescriptionBytes caps the stored post description (0 = unlimited)
+	MaxDescriptionBytes int
+	// StoreFullDescription keeps the untruncated description alongside the truncated one
+	StoreFullDescription bool
+	// ContentFetcher extracts the article content of new posts of feeds with
+	// fetch_full_content set (nil = disabled)
+	ContentFetcher ContentFetcher
+	// PersistNotifications stores each new post signal in the notifications outbox,
+	// so users can fetch what they missed while disconnected
+	PersistNotifications bool
+	// NotificationRetention is how long unacknowledged notifications are kept
+	// (0 = DefaultNotificationRetention); acknowledged ones go at the next prune
+	NotificationRetention time.Duration
+
+	// lastPruned is when the scraping loop last pruned stale rows
+	lastPruned time.Time
 }
 
 func NewScraper(db *database.Queries, log zerolog.Logger, hub *realtime.Hub) *Scraper {
 	return &Scraper{
-		DB:     db,
-		Logger: log,
-		Hub:    hub,
+		DB:      db,
+		Logger:  log,
+		Hub:     hub,
+		Fetcher: feedfetch.NewGofeedFetcher(),
 	}
 }
 
@@ -39,14 +100,18 @@ func (s *Scraper) StartScraping(db *database.Queries, interval time.Duration) {
 	for range ticker.C {
 		s.Logger.Info().Msg("Ticker triggered: Fetching feeds...")
 
-		// Get feeds ordered by priority (high priority first, oldest updated first)
-		feeds, err := db.GetFeedsByPriority(context.Background())
+		if now := time.Now(); s.pruneDue(now) {
+			s.prune(context.Background(), db, now)
+		}
+
+		// Get feeds that are due, ordered by priority (high priority first, most overdue first)
+		feeds, err := db.GetFeedsDueForFetch(context.Background(), sql.NullTime{Time: time.Now().UTC(), Valid: true})
 		if err != nil {
 			logger.ErrorErr(err, "Error fetching feeds")
 			continue
 		}
 
-		logger.Infof("Found %d feeds to fetch (prioritized)", len(feeds))
+		logger.Infof("Found %d feeds due for fetching (prioritized)", len(feeds))
 
 		wg := &sync.WaitGroup{}
 		for _, feed := range feeds {
@@ -62,78 +127,411 @@ func (s *Scraper) scrapeFeed(db *database.Queries, wg *sync.WaitGroup, feed data
 	defer wg.Done()
 	logger.Debugf("Scraping feed: %s", feed.Name)
 
-	parsedFeed, errorParsedFeed := fetchFeed(feed.Url)
+	newPostCount := s.fetchAndStoreFeed(context.Background(), db, feed)
+	s.scheduleNextFetch(db, feed, newPostCount)
+}
+
+// fetchAndStoreFeed fetches a feed, stores its new posts and returns how many were created
+func (s *Scraper) fetchAndStoreFeed(ctx context.Context, store feedStore, feed database.Feed) int {
+	if s.unchangedSinceLastFetch(ctx, feed) {
+		logger.Debugf("Feed unchanged according to HEAD, skipping fetch: %s", feed.Name)
+		s.recordFetchResult(ctx, store, feed, nil)
+		return 0
+	}
+
+	parsedFeed, errorParsedFeed := s.fetch(ctx, feed)
+	s.recordFetchResult(ctx, store, feed, errorParsedFeed)
 	if errorParsedFeed != nil {
-		s.Logger.Error().Err(errorParsedFeed).Msg("Failed to fetch feed")
-		return
+		return 0
+	}
+
+	// Identical body to the last fetch - nothing new to store
+	if parsedFeed.Unchanged || feed.LastBodyHash.Valid && parsedFeed.BodyHash == feed.LastBodyHash.String {
+		logger.Debugf("Feed body unchanged, skipping: %s", feed.Name)
+		s.recordValidators(ctx, store, feed, parsedFeed.Validators)
+		return 0
 	}
 
-	newPostCount := 0
+	return len(s.storeParsedFeed(ctx, store, feed, parsedFeed))
+}
 
+// fetch fetches a feed, without parsing a body identical to the last stored one when
+// the Fetcher implements feedfetch.BodyHashFetcher
+func (s *Scraper) fetch(ctx context.Context, feed database.Feed) (*feedfetch.ParsedFeed, error) {
+	if hashFetcher, ok := s.Fetcher.(feedfetch.BodyHashFetcher); ok && feed.LastBodyHash.Valid {
+		return hashFetcher.FetchIfChanged(ctx, feed.Url, feed.LastBodyHash.String)
+	}
+	return s.Fetcher.Fetch(ctx, feed.Url)
+}
+
+// ImportFeedPosts stores the posts of a feed that was fetched outside the scrape loop,
+// e.g. when a user adds a new feed, and returns the posts it created
+func (s *Scraper) ImportFeedPosts(ctx context.Context, feed database.Feed, parsedFeed *feedfetch.ParsedFeed) []database.Post {
+	return s.storeParsedFeed(ctx, s.DB, feed, parsedFeed)
+}
+
+// storeParsedFeed stores the new posts of a fetched feed and returns the posts created
+func (s *Scraper) storeParsedFeed(ctx context.Context, store feedStore, feed database.Feed, parsedFeed *feedfetch.ParsedFeed) []database.Post {
+	if len(parsedFeed.Items) == 0 {
+		s.Logger.Debug().Str("feed_id", feed.ID.String()).Msg("Feed fetched but has no items")
+	}
+
+	postParams := make([]database.CreatePostParams, 0, len(parsedFeed.Items))
 	for _, item := range parsedFeed.Items {
-		description := sql.NullString{}
-		if item.Description != "" {
-			description.String = item.Description
-			description.Valid = true
-		}
+		description, descriptionTruncated, fullDescription := s.postDescription(item.Description)
 
-		var publishedAt time.Time
+		// Items without a usable date are treated as published when ingested
+		ingestedAt := time.Now().UTC()
+		publishedAt := ingestedAt
 		if item.PublishedParsed != nil {
-			publishedAt = *item.PublishedParsed
-		} else {
-			publishedAt = time.Now()
+			publishedAt = models.PostPublishedAt(*item.PublishedParsed, ingestedAt)
 		}
 
-		_, errCreatePost := db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now().UTC(),
-			UpdatedAt:   time.Now().UTC(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: description,
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
+		postParams = append(postParams, database.CreatePostParams{
+			ID:                   uuid.New(),
+			CreatedAt:            ingestedAt,
+			UpdatedAt:            ingestedAt,
+			Title:                textutil.TruncateTitle(item.Title, s.MaxTitleBytes),
+			Url:                  item.Link,
+			Description:          description,
+			PublishedAt:          publishedAt,
+			FeedID:               feed.ID,
+			DescriptionTruncated: descriptionTruncated,
+			FullDescription:      fullDescription,
 		})
+	}
+
+	createdPosts, skippedPostCount, failedPostCount := s.createPosts(ctx, store, postParams)
+	newPostCount := len(createdPosts)
+
+	// One line per feed; the per-post detail is logged at trace level by createPosts
+	s.Logger.Debug().
+		Str("feed_id", feed.ID.String()).
+		Int("new_posts", newPostCount).
+		Int("skipped_posts", skippedPostCount).
+		Int("failed_posts", failedPostCount).
+		Msgf("Stored posts for feed %s: %d new, %d skipped", feed.Name, newPostCount, skippedPostCount)
+
+	// Keep the old hash when posts were lost so the next cycle parses the body again
+	if failedPostCount > 0 {
+		s.Logger.Warn().
+			Int("failed_posts", failedPostCount).
+			Str("feed_id", feed.ID.String()).
+			Msg("Some posts could not be stored; feed will be re-parsed next cycle")
+	} else {
+		errUpdateHash := store.UpdateFeedLastBodyHash(ctx, database.UpdateFeedLastBodyHashParams{
+			ID:           feed.ID,
+			LastBodyHash: sql.NullString{String: parsedFeed.BodyHash, Valid: true},
+		})
+		if errUpdateHash != nil {
+			s.Logger.Error().Err(errUpdateHash).Msg("Failed to update feed body hash")
+		}
+		s.recordValidators(ctx, store, feed, parsedFeed.Validators)
+	}
+
+	if newPostCount > 0 {
+		s.recordLastPostAt(ctx, store, feed.ID, createdPosts)
+		s.recordActivity(ctx, store, feed.ID, newPostCount, time.Now())
+		s.sendNewPostSignal(ctx, store, feed, newPostCount)
+		// Last, so slow article pages don't delay the new post signal
+		s.storeFullContent(ctx, store, feed, createdPosts)
+	}
+
+	return createdPosts
+}
+
+// unchangedSinceLastFetch reports whether a HEAD request shows the feed unchanged since
+// its posts were last stored. It is false whenever HeadCheck is off, no validators were
+// stored yet or the HEAD request fails, e.g. with 405 from servers that don't support it.
+func (s *Scraper) unchangedSinceLastFetch(ctx context.Context, feed database.Feed) bool {
+	if !s.HeadCheck {
+		return false
+	}
+	headFetcher, ok := s.Fetcher.(feedfetch.HeadFetcher)
+	if !ok {
+		return false
+	}
+
+	last := storedValidators(feed)
+	if last.ETag == "" && last.LastModified == "" {
+		return false
+	}
+
+	current, err := headFetcher.Head(ctx, feed.Url)
+	if err != nil {
+		s.Logger.Debug().Err(err).Str("feed_id", feed.ID.String()).Msg("HEAD request failed, fetching feed")
+		return false
+	}
+	return current.Unchanged(last)
+}
+
+// storedValidators returns the validators stored for a feed
+func storedValidators(feed database.Feed) feedfetch.Validators {
+	contentLength := int64(-1)
+	if feed.HttpContentLength.Valid {
+		contentLength = feed.HttpContentLength.Int64
+	}
+	return feedfetch.Validators{
+		ETag:          feed.HttpEtag.String,
+		LastModified:  feed.HttpLastModified.String,
+		ContentLength: contentLength,
+	}
+}
+
+// recordValidators stores the validators of the fetch a feed's posts were stored from,
+// for HeadCheck. Nothing is written when HeadCheck is off or they didn't change.
+func (s *Scraper) recordValidators(ctx context.Context, store feedStore, feed database.Feed, validators feedfetch.Validators) {
+	if !s.HeadCheck || validators == storedValidators(feed) {
+		return
+	}
+
+	err := store.UpdateFeedHTTPValidators(ctx, database.UpdateFeedHTTPValidatorsParams{
+		ID:                feed.ID,
+		HttpEtag:          sql.NullString{String: validators.ETag, Valid: validators.ETag != ""},
+		HttpLastModified:  sql.NullString{String: validators.LastModified, Valid: validators.LastModified != ""},
+		HttpContentLength: sql.NullInt64{Int64: validators.ContentLength, Valid: validators.ContentLength >= 0},
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("feed_id", feed.ID.String()).Msg("Failed to update feed HTTP validators")
+	}
+}
+
+// recordLastPostAt moves the feed's last_post_at up to its newest created post
+func (s *Scraper) recordLastPostAt(ctx context.Context, store feedStore, feedID uuid.UUID, createdPosts []database.Post) {
+	var latest time.Time
+	for _, post := range createdPosts {
+		if post.PublishedAt.After(latest) {
+			latest = post.PublishedAt
+		}
+	}
+
+	err := store.UpdateFeedLastPostAt(ctx, database.UpdateFeedLastPostAtParams{
+		ID:         feedID,
+		LastPostAt: sql.NullTime{Time: latest, Valid: true},
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("feed_id", feedID.String()).Msg("Failed to update feed last post time")
+	}
+}
+
+// postDescription returns the description to store for a post, truncated to
+// MaxDescriptionBytes, and the full description when StoreFullDescription is set
+func (s *Scraper) postDescription(raw string) (sql.NullString, bool, sql.NullString) {
+	if raw == "" {
+		return sql.NullString{}, false, sql.NullString{}
+	}
+
+	description, truncated := textutil.TruncateUTF8(raw, s.MaxDescriptionBytes)
+
+	var full sql.NullString
+	if truncated && s.StoreFullDescription {
+		full = sql.NullString{String: raw, Valid: true}
+	}
 
+	return sql.NullString{String: description, Valid: true}, truncated, full
+}
+
+// fetchStatusStore is the subset of queries used to track failed fetches
+type fetchStatusStore interface {
+	RecordFeedFetchFailure(ctx context.Context, arg database.RecordFeedFetchFailureParams) error
+	ResetFeedFetchFailures(ctx context.Context, id uuid.UUID) error
+}
+
+// recordFetchResult updates the feed's failure counter after a fetch.
+// Network errors and malformed responses count as failures and are logged apart;
+// any successful fetch, including a valid feed without items, resets the counter.
+func (s *Scraper) recordFetchResult(ctx context.Context, store fetchStatusStore, feed database.Feed, fetchErr error) {
+	if fetchErr == nil {
+		if err := store.ResetFeedFetchFailures(ctx, feed.ID); err != nil {
+			s.Logger.Error().Err(err).Str("feed_id", feed.ID.String()).Msg("Failed to reset feed fetch failures")
+		}
+		return
+	}
+
+	if errors.Is(fetchErr, feedfetch.ErrMalformedFeed) {
+		s.Logger.Warn().Err(fetchErr).Str("feed_id", feed.ID.String()).Msg("Feed response is malformed")
+	} else {
+		s.Logger.Error().Err(fetchErr).Str("feed_id", feed.ID.String()).Msg("Failed to fetch feed")
+	}
+
+	err := store.RecordFeedFetchFailure(ctx, database.RecordFeedFetchFailureParams{
+		ID:             feed.ID,
+		LastFetchError: sql.NullString{String: fetchErr.Error(), Valid: true},
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("feed_id", feed.ID.String()).Msg("Failed to record feed fetch failure")
+	}
+}
+
+// scheduleNextFetch stores when the feed should be fetched again based on its activity
+func (s *Scraper) scheduleNextFetch(db *database.Queries, feed database.Feed, newPostCount int) {
+	interval := nextFetchInterval(time.Duration(feed.FetchIntervalSeconds)*time.Second, newPostCount, feed.Priority)
+
+	err := db.UpdateFeedSchedule(context.Background(), database.UpdateFeedScheduleParams{
+		ID:                   feed.ID,
+		NextFetchAt:          sql.NullTime{Time: time.Now().UTC().Add(interval), Valid: true},
+		FetchIntervalSeconds: int32(interval / time.Second),
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("feed_id", feed.ID.String()).Msg("Failed to schedule next feed fetch")
+		return
+	}
+
+	s.Logger.Debug().
+		Str("feed_id", feed.ID.String()).
+		Dur("interval", interval).
+		Msg("Scheduled next feed fetch")
+}
+
+// createPosts inserts scraped posts and returns the created posts plus how many duplicates
+// were skipped and how many posts were lost. Inserts failing for any other reason (e.g. a
+// transient DB error) are queued and retried once after the first pass; the queue is
+// bounded by maxRetryQueueSize and anything that still fails is logged as a permanent
+// failure. Each post is only logged at trace level; callers log a summary for the whole feed.
+func (s *Scraper) createPosts(ctx context.Context, store postStore, postParams []database.CreatePostParams) ([]database.Post, int, int) {
+	createdPosts := make([]database.Post, 0, len(postParams))
+	skippedPostCount := 0
+	failedPostCount := 0
+	retryQueue := make([]database.CreatePostParams, 0)
+
+	for _, params := range postParams {
+		post, errCreatePost := store.CreatePost(ctx, params)
 		if errCreatePost != nil {
-			if pqErr, ok := errCreatePost.(*pq.Error); ok && pqErr.Code == "23505" {
-				s.Logger.Debug().Msgf("Post already exists, skipping: %s", item.Title)
+			if isUniqueViolation(errCreatePost) {
+				skippedPostCount++
+				s.Logger.Trace().Str("post_url", params.Url).Msgf("Post already exists, skipping: %s", params.Title)
 				continue
 			}
-			s.Logger.Error().Err(errCreatePost).Msg("Failed to create post")
-		} else {
-			newPostCount++
-			s.Logger.Debug().Msgf("Successfully created post: %s", item.Title)
+
+			if len(retryQueue) >= maxRetryQueueSize {
+				failedPostCount++
+				s.Logger.Error().Err(errCreatePost).Str("post_url", params.Url).Msg("Failed to create post, retry queue full")
+				continue
+			}
+
+			s.Logger.Warn().Err(errCreatePost).Str("post_url", params.Url).Msg("Failed to create post, queued for retry")
+			retryQueue = append(retryQueue, params)
+			continue
 		}
+
+		createdPosts = append(createdPosts, post)
+		s.Logger.Trace().Str("post_url", params.Url).Msgf("Successfully created post: %s", params.Title)
 	}
 
-	if newPostCount > 0 {
-		s.sendNewPostSignal(context.Background(), feed, newPostCount)
+	for _, params := range retryQueue {
+		post, errCreatePost := store.CreatePost(ctx, params)
+		if errCreatePost != nil {
+			if isUniqueViolation(errCreatePost) {
+				skippedPostCount++
+				continue
+			}
+
+			failedPostCount++
+			s.Logger.Error().Err(errCreatePost).Str("post_url", params.Url).Msg("Permanently failed to create post after retry")
+			continue
+		}
+
+		createdPosts = append(createdPosts, post)
+		s.Logger.Trace().Str("post_url", params.Url).Msgf("Successfully created post on retry: %s", params.Title)
 	}
+
+	return createdPosts, skippedPostCount, failedPostCount
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
 }
 
-func (s *Scraper) sendNewPostSignal(ctx context.Context, feed database.Feed, newCount int) {
-	followers, err := s.DB.GetFollowersByFeedID(ctx, feed.ID)
+// sendNewPostSignal invalidates the followers' cached posts, stores a notification for
+// each of them when PersistNotifications is set and notifies them over the Hub.
+// Followers who turned notify off for the feed only get their cache invalidated.
+// The Hub never blocks the caller, so a busy Hub can't stall a scrape worker.
+func (s *Scraper) sendNewPostSignal(ctx context.Context, store followerStore, feed database.Feed, newCount int) {
+	// No Hub when realtime is disabled, no cache to invalidate and no outbox - nobody to notify
+	if s.Hub == nil && s.PostsCache == nil && !s.PersistNotifications {
+		s.Logger.Debug().Str("feed_id", feed.ID.String()).Msg("Realtime disabled, skipping new post signal.")
+		return
+	}
+
+	rows, err := store.GetFollowersByFeedID(ctx, feed.ID)
 	if err != nil {
 		s.Logger.Error().Err(err).Msgf("Scraper failed to get followers for feed %s", feed.ID)
 		return
 	}
 
-	signals := make(map[uuid.UUID][]byte)
-	signalPayload := []byte(fmt.Sprintf(
-		`{"type": "NEW_POST_AVAILABLE", "feed_id": "%s", "feed_name": "%s", "count": %d}`,
-		feed.ID.String(), feed.Name, newCount,
-	))
+	if s.PostsCache != nil {
+		followers := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			followers = append(followers, row.UserID)
+		}
+		s.PostsCache.InvalidateUsers(followers...)
+	}
 
-	for _, follower := range followers {
-		signals[follower] = signalPayload
+	notified := notifiedFollowers(rows)
+	if s.PersistNotifications {
+		s.persistNotifications(ctx, store, feed.ID, notified, newCount)
 	}
 
-	if len(signals) > 0 {
-		s.Hub.SendSignal(signals)
+	if s.Hub == nil {
+		return
+	}
+
+	signals := newPostSignals(feed, notified, newCount)
+	if len(signals) > 0 && s.Hub.SendSignal(signals) {
 		s.Logger.Info().
 			Int("followers_count", len(signals)).
 			Str("feed_id", feed.ID.String()).
 			Msg("New post signal published to Hub.")
 	}
 }
+
+// notifiedFollowers returns the followers who haven't turned notify off for the feed
+func notifiedFollowers(rows []database.GetFollowersByFeedIDRow) []uuid.UUID {
+	notified := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		if row.Notify {
+			notified = append(notified, row.UserID)
+		}
+	}
+	return notified
+}
+
+// newPostSignals maps each of the followers to the new post signal for feed
+func newPostSignals(feed database.Feed, followers []uuid.UUID, newCount int) map[uuid.UUID][]byte {
+	signalPayload := []byte(fmt.Sprintf(
+		`{"type": "%s", "feed_id": "%s", "feed_name": "%s", "count": %d}`,
+		newPostSignalType, feed.ID.String(), feed.Name, newCount,
+	))
+
+	signals := make(map[uuid.UUID][]byte, len(followers))
+	for _, follower := range followers {
+		signals[follower] = signalPayload
+	}
+	return signals
+}
+
+// persistNotifications stores a new post notification for each follower in one insert
+func (s *Scraper) persistNotifications(ctx context.Context, store followerStore, feedID uuid.UUID, followers []uuid.UUID, newCount int) {
+	if len(followers) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(followers))
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	err := store.CreateNotifications(ctx, database.CreateNotificationsParams{
+		Ids:       ids,
+		UserIds:   followers,
+		FeedID:    feedID,
+		Type:      newPostSignalType,
+		PostCount: int32(newCount),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("feed_id", feedID.String()).Msg("Failed to store new post notifications")
+	}
+}
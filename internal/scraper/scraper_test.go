@@ -0,0 +1,790 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/cache"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/database"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/feedfetch"
+	"github.com/mehmettalhairmak/rss-aggregator/internal/realtime"
+	"github.com/mmcdole/gofeed"
+	"github.com/rs/zerolog"
+)
+
+// stubPostStore fails CreatePost a configured number of times per post URL
+type stubPostStore struct {
+	failuresLeft map[string]int
+	duplicates   map[string]bool
+	calls        map[string]int
+	publishedAt  map[string]time.Time
+}
+
+func newStubPostStore() *stubPostStore {
+	return &stubPostStore{
+		failuresLeft: make(map[string]int),
+		duplicates:   make(map[string]bool),
+		calls:        make(map[string]int),
+		publishedAt:  make(map[string]time.Time),
+	}
+}
+
+func (s *stubPostStore) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	s.calls[arg.Url]++
+
+	if s.duplicates[arg.Url] {
+		return database.Post{}, &pq.Error{Code: "23505"}
+	}
+
+	if s.failuresLeft[arg.Url] > 0 {
+		s.failuresLeft[arg.Url]--
+		return database.Post{}, errors.New("connection reset by peer")
+	}
+
+	s.publishedAt[arg.Url] = arg.PublishedAt
+	return database.Post{ID: arg.ID, Url: arg.Url, Title: arg.Title, PublishedAt: arg.PublishedAt}, nil
+}
+
+func newTestScraper() *Scraper {
+	return NewScraper(nil, zerolog.Nop(), nil)
+}
+
+func postParams(urls ...string) []database.CreatePostParams {
+	params := make([]database.CreatePostParams, 0, len(urls))
+	for _, url := range urls {
+		params = append(params, database.CreatePostParams{ID: uuid.New(), Url: url, Title: url})
+	}
+	return params
+}
+
+func TestCreatePosts_TransientFailure_RetriesOnce(t *testing.T) {
+	store := newStubPostStore()
+	store.failuresLeft["https://example.com/a"] = 1
+
+	created, _, failed := newTestScraper().createPosts(context.Background(), store, postParams("https://example.com/a", "https://example.com/b"))
+
+	if len(created) != 2 {
+		t.Errorf("Expected 2 posts created, got %d", len(created))
+	}
+
+	if failed != 0 {
+		t.Errorf("Expected 0 failed posts, got %d", failed)
+	}
+
+	if store.calls["https://example.com/a"] != 2 {
+		t.Errorf("Expected failed post to be attempted twice, got %d", store.calls["https://example.com/a"])
+	}
+}
+
+func TestCreatePosts_PersistentFailure_GivesUpAfterRetry(t *testing.T) {
+	store := newStubPostStore()
+	store.failuresLeft["https://example.com/a"] = 5
+
+	created, _, failed := newTestScraper().createPosts(context.Background(), store, postParams("https://example.com/a"))
+
+	if len(created) != 0 {
+		t.Errorf("Expected 0 posts created, got %d", len(created))
+	}
+
+	if failed != 1 {
+		t.Errorf("Expected 1 failed post, got %d", failed)
+	}
+
+	if store.calls["https://example.com/a"] != 2 {
+		t.Errorf("Expected exactly one retry, got %d attempts", store.calls["https://example.com/a"])
+	}
+}
+
+func TestCreatePosts_Duplicate_IsNotRetried(t *testing.T) {
+	store := newStubPostStore()
+	store.duplicates["https://example.com/a"] = true
+
+	created, skipped, failed := newTestScraper().createPosts(context.Background(), store, postParams("https://example.com/a"))
+
+	if len(created) != 0 || skipped != 1 || failed != 0 {
+		t.Errorf("Expected duplicate to be skipped, got created=%d skipped=%d failed=%d", len(created), skipped, failed)
+	}
+
+	if store.calls["https://example.com/a"] != 1 {
+		t.Errorf("Expected duplicate to be attempted once, got %d", store.calls["https://example.com/a"])
+	}
+}
+
+func TestCreatePosts_RetryQueueFull_CountsOverflowAsFailed(t *testing.T) {
+	store := newStubPostStore()
+	urls := make([]string, 0, maxRetryQueueSize+3)
+	for i := 0; i < maxRetryQueueSize+3; i++ {
+		url := "https://example.com/" + uuid.NewString()
+		store.failuresLeft[url] = 1
+		urls = append(urls, url)
+	}
+
+	created, _, failed := newTestScraper().createPosts(context.Background(), store, postParams(urls...))
+
+	if len(created) != maxRetryQueueSize {
+		t.Errorf("Expected %d posts created on retry, got %d", maxRetryQueueSize, len(created))
+	}
+
+	if failed != 3 {
+		t.Errorf("Expected 3 posts lost to a full queue, got %d", failed)
+	}
+}
+
+func TestSendNewPostSignal_RealtimeDisabled_SkipsWithoutPanic(t *testing.T) {
+	// Neither DB nor Hub is configured; the nil Hub must short-circuit before any lookup
+	s := newTestScraper()
+
+	s.sendNewPostSignal(context.Background(), nil, database.Feed{ID: uuid.New(), Name: "Test"}, 3)
+}
+
+// stubFollowerStore returns followers with notify on and muted followers with it off
+type stubFollowerStore struct {
+	followers     []uuid.UUID
+	muted         []uuid.UUID
+	notifications []database.CreateNotificationsParams
+}
+
+func (s *stubFollowerStore) GetFollowersByFeedID(ctx context.Context, feedID uuid.UUID) ([]database.GetFollowersByFeedIDRow, error) {
+	rows := make([]database.GetFollowersByFeedIDRow, 0, len(s.followers)+len(s.muted))
+	for _, follower := range s.followers {
+		rows = append(rows, database.GetFollowersByFeedIDRow{UserID: follower, Notify: true})
+	}
+	for _, follower := range s.muted {
+		rows = append(rows, database.GetFollowersByFeedIDRow{UserID: follower})
+	}
+	return rows, nil
+}
+
+func (s *stubFollowerStore) CreateNotifications(ctx context.Context, arg database.CreateNotificationsParams) error {
+	s.notifications = append(s.notifications, arg)
+	return nil
+}
+
+func TestSendNewPostSignal_PersistNotifications_StoresOnePerFollower(t *testing.T) {
+	// No Hub: nobody is connected, the outbox is the only way followers learn about the posts
+	s := newTestScraper()
+	s.PersistNotifications = true
+	followers := []uuid.UUID{uuid.New(), uuid.New()}
+	store := &stubFollowerStore{followers: followers}
+	feed := database.Feed{ID: uuid.New(), Name: "Test"}
+
+	s.sendNewPostSignal(context.Background(), store, feed, 3)
+
+	if len(store.notifications) != 1 {
+		t.Fatalf("Expected 1 batched insert, got %d", len(store.notifications))
+	}
+	stored := store.notifications[0]
+	if len(stored.UserIds) != len(followers) || len(stored.Ids) != len(followers) {
+		t.Fatalf("Expected %d notifications, got %d ids for %d users", len(followers), len(stored.Ids), len(stored.UserIds))
+	}
+	for i, follower := range followers {
+		if stored.UserIds[i] != follower {
+			t.Errorf("Expected notification %d for %v, got %v", i, follower, stored.UserIds[i])
+		}
+	}
+	if stored.Ids[0] == stored.Ids[1] {
+		t.Error("Expected every notification to get its own id")
+	}
+	if stored.FeedID != feed.ID || stored.PostCount != 3 || stored.Type != newPostSignalType {
+		t.Errorf("Expected a %s notification of 3 posts for feed %v, got %+v", newPostSignalType, feed.ID, stored)
+	}
+}
+
+func TestSendNewPostSignal_MutedFollower_NotNotified(t *testing.T) {
+	s := newTestScraper()
+	s.PersistNotifications = true
+	s.PostsCache = cache.NewPostsCache(10, time.Minute)
+	follower, muted := uuid.New(), uuid.New()
+	store := &stubFollowerStore{followers: []uuid.UUID{follower}, muted: []uuid.UUID{muted}}
+	feed := database.Feed{ID: uuid.New(), Name: "Busy feed"}
+	for _, userID := range []uuid.UUID{follower, muted} {
+		s.PostsCache.Set(cache.PostsKey{UserID: userID, Limit: 20}, []database.Post{{ID: uuid.New()}})
+	}
+
+	s.sendNewPostSignal(context.Background(), store, feed, 5)
+
+	if len(store.notifications) != 1 || fmt.Sprint(store.notifications[0].UserIds) != fmt.Sprint([]uuid.UUID{follower}) {
+		t.Errorf("Expected a stored notification for %v only, got %+v", follower, store.notifications)
+	}
+	// Muted followers still see the new posts in their feed
+	for _, userID := range []uuid.UUID{follower, muted} {
+		if _, ok := s.PostsCache.Get(cache.PostsKey{UserID: userID, Limit: 20}); ok {
+			t.Errorf("Expected the cached posts of %v to be invalidated", userID)
+		}
+	}
+}
+
+func TestNewPostSignals_OnlyNotifiedFollowers(t *testing.T) {
+	follower, muted := uuid.New(), uuid.New()
+	rows := []database.GetFollowersByFeedIDRow{
+		{UserID: follower, Notify: true},
+		{UserID: muted, Notify: false},
+	}
+	feed := database.Feed{ID: uuid.New(), Name: "Busy feed"}
+
+	signals := newPostSignals(feed, notifiedFollowers(rows), 2)
+
+	if len(signals) != 1 {
+		t.Fatalf("Expected 1 signal, got %d", len(signals))
+	}
+	if _, ok := signals[muted]; ok {
+		t.Error("Expected no signal for the muted follower")
+	}
+	if payload := string(signals[follower]); !strings.Contains(payload, feed.ID.String()) || !strings.Contains(payload, `"count": 2`) {
+		t.Errorf("Expected a signal of 2 posts for feed %v, got %s", feed.ID, payload)
+	}
+}
+
+func TestSendNewPostSignal_PersistDisabled_StoresNothing(t *testing.T) {
+	s := NewScraper(nil, zerolog.Nop(), realtime.NewHubWithBuffer(zerolog.Nop(), 1))
+	store := &stubFollowerStore{followers: []uuid.UUID{uuid.New()}}
+
+	s.sendNewPostSignal(context.Background(), store, database.Feed{ID: uuid.New(), Name: "Test"}, 1)
+
+	if len(store.notifications) != 0 {
+		t.Errorf("Expected no stored notifications, got %d", len(store.notifications))
+	}
+}
+
+func TestSendNewPostSignal_BusyHub_DoesNotBlockScraper(t *testing.T) {
+	// Run is never started, so the Hub never drains its queue
+	hub := realtime.NewHubWithBuffer(zerolog.Nop(), 1)
+	s := NewScraper(nil, zerolog.Nop(), hub)
+	store := &stubFollowerStore{followers: []uuid.UUID{uuid.New()}}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			s.sendNewPostSignal(context.Background(), store, database.Feed{ID: uuid.New(), Name: "Test"}, 1)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected sendNewPostSignal to return while the Hub is busy")
+	}
+
+	if dropped := hub.Stats().DroppedSignals; dropped != 4 {
+		t.Errorf("Expected 4 dropped signals, got %d", dropped)
+	}
+}
+
+type stubFetchStatusStore struct {
+	failures int
+	resets   int
+}
+
+func (s *stubFetchStatusStore) RecordFeedFetchFailure(ctx context.Context, arg database.RecordFeedFetchFailureParams) error {
+	s.failures++
+	return nil
+}
+
+func (s *stubFetchStatusStore) ResetFeedFetchFailures(ctx context.Context, id uuid.UUID) error {
+	s.resets++
+	return nil
+}
+
+func TestRecordFetchResult(t *testing.T) {
+	testCases := []struct {
+		name             string
+		fetchErr         error
+		expectedFailures int
+		expectedResets   int
+	}{
+		{"Successful or empty feed", nil, 0, 1},
+		{"Malformed feed", fmt.Errorf("%w: unexpected EOF", feedfetch.ErrMalformedFeed), 1, 0},
+		{"Network error", errors.New("dial tcp: connection refused"), 1, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &stubFetchStatusStore{}
+
+			newTestScraper().recordFetchResult(context.Background(), store, database.Feed{ID: uuid.New()}, tc.fetchErr)
+
+			if store.failures != tc.expectedFailures {
+				t.Errorf("Expected %d failures recorded, got %d", tc.expectedFailures, store.failures)
+			}
+
+			if store.resets != tc.expectedResets {
+				t.Errorf("Expected %d resets, got %d", tc.expectedResets, store.resets)
+			}
+		})
+	}
+}
+
+func TestPostDescription_MultibyteBoundary_TruncatesCleanly(t *testing.T) {
+	s := newTestScraper()
+	s.MaxDescriptionBytes = 5
+
+	// "Çay" starts with a 2-byte character; bytes 4-5 split the second "Ç"
+	description, truncated, full := s.postDescription("ÇayÇay")
+
+	if !truncated {
+		t.Fatal("Expected description to be truncated")
+	}
+
+	if description.String != "Çay" {
+		t.Errorf("Expected %q, got %q", "Çay", description.String)
+	}
+
+	if full.Valid {
+		t.Error("Expected full description not to be stored when the feature is disabled")
+	}
+}
+
+func TestPostDescription_FullContentEnabled_KeepsOriginal(t *testing.T) {
+	s := newTestScraper()
+	s.MaxDescriptionBytes = 4
+	s.StoreFullDescription = true
+
+	description, truncated, full := s.postDescription("a long description")
+
+	if !truncated || description.String != "a lo" {
+		t.Errorf("Expected truncated description %q, got %q (truncated=%v)", "a lo", description.String, truncated)
+	}
+
+	if full.String != "a long description" {
+		t.Errorf("Expected full description to be kept, got %q", full.String)
+	}
+}
+
+func TestPostDescription_WithinLimit_NotTruncated(t *testing.T) {
+	s := newTestScraper()
+	s.MaxDescriptionBytes = 100
+	s.StoreFullDescription = true
+
+	description, truncated, full := s.postDescription("short")
+
+	if truncated || description.String != "short" || full.Valid {
+		t.Errorf("Expected description untouched, got %q (truncated=%v, full=%v)", description.String, truncated, full.Valid)
+	}
+}
+
+// stubFeedStore combines the stub stores used by a full scrape of one feed
+type stubFeedStore struct {
+	*stubPostStore
+	stubFetchStatusStore
+	stubActivityStore
+	stubFollowerStore
+	bodyHashes  map[uuid.UUID]string
+	validators  map[uuid.UUID]database.UpdateFeedHTTPValidatorsParams
+	lastPostAt  map[uuid.UUID]time.Time
+	fullContent map[uuid.UUID]string
+}
+
+func newStubFeedStore() *stubFeedStore {
+	return &stubFeedStore{
+		stubPostStore: newStubPostStore(),
+		bodyHashes:    make(map[uuid.UUID]string),
+		validators:    make(map[uuid.UUID]database.UpdateFeedHTTPValidatorsParams),
+		lastPostAt:    make(map[uuid.UUID]time.Time),
+		fullContent:   make(map[uuid.UUID]string),
+	}
+}
+
+func (s *stubFeedStore) UpdatePostFullContent(ctx context.Context, arg database.UpdatePostFullContentParams) error {
+	s.fullContent[arg.ID] = arg.FullContent.String
+	return nil
+}
+
+// UpdateFeedLastPostAt mirrors the query's condition: last_post_at only moves forward
+func (s *stubFeedStore) UpdateFeedLastPostAt(ctx context.Context, arg database.UpdateFeedLastPostAtParams) error {
+	if current, ok := s.lastPostAt[arg.ID]; !ok || current.Before(arg.LastPostAt.Time) {
+		s.lastPostAt[arg.ID] = arg.LastPostAt.Time
+	}
+	return nil
+}
+
+func (s *stubFeedStore) UpdateFeedLastBodyHash(ctx context.Context, arg database.UpdateFeedLastBodyHashParams) error {
+	s.bodyHashes[arg.ID] = arg.LastBodyHash.String
+	return nil
+}
+
+func (s *stubFeedStore) UpdateFeedHTTPValidators(ctx context.Context, arg database.UpdateFeedHTTPValidatorsParams) error {
+	s.validators[arg.ID] = arg
+	return nil
+}
+
+func fakeParsedFeed(bodyHash string, links ...string) *feedfetch.ParsedFeed {
+	items := make([]*gofeed.Item, 0, len(links))
+	for _, link := range links {
+		items = append(items, &gofeed.Item{Title: link, Link: link})
+	}
+	return &feedfetch.ParsedFeed{Feed: &gofeed.Feed{Items: items}, BodyHash: bodyHash}
+}
+
+func TestFetchAndStoreFeed_FakeFetcher_StoresPosts(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, fakeParsedFeed("hash-1", "https://example.com/a", "https://example.com/b"))
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	store := newStubFeedStore()
+
+	newPostCount := s.fetchAndStoreFeed(context.Background(), store, feed)
+
+	if newPostCount != 2 {
+		t.Errorf("Expected 2 new posts, got %d", newPostCount)
+	}
+
+	if store.bodyHashes[feed.ID] != "hash-1" {
+		t.Errorf("Expected body hash %q stored, got %q", "hash-1", store.bodyHashes[feed.ID])
+	}
+
+	if store.resets != 1 {
+		t.Errorf("Expected fetch failures reset once, got %d", store.resets)
+	}
+}
+
+func TestFetchAndStoreFeed_UnchangedBody_SkipsStoring(t *testing.T) {
+	feed := database.Feed{
+		ID:           uuid.New(),
+		Url:          "https://example.com/feed.xml",
+		LastBodyHash: sql.NullString{String: "hash-1", Valid: true},
+	}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, fakeParsedFeed("hash-1", "https://example.com/a"))
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	store := newStubFeedStore()
+
+	if newPostCount := s.fetchAndStoreFeed(context.Background(), store, feed); newPostCount != 0 {
+		t.Errorf("Expected 0 new posts, got %d", newPostCount)
+	}
+
+	if len(store.stubPostStore.calls) != 0 {
+		t.Errorf("Expected no posts stored, got %d inserts", len(store.stubPostStore.calls))
+	}
+}
+
+// headCheckedFeed is a feed stored from a fetch that returned ETag "v1"
+func headCheckedFeed() database.Feed {
+	return database.Feed{
+		ID:                uuid.New(),
+		Url:               "https://example.com/feed.xml",
+		HttpEtag:          sql.NullString{String: `"v1"`, Valid: true},
+		HttpContentLength: sql.NullInt64{Int64: 120, Valid: true},
+	}
+}
+
+func TestFetchAndStoreFeed_HeadCheck(t *testing.T) {
+	testCases := []struct {
+		name          string
+		headCheck     bool
+		head          *feedfetch.Validators
+		expectedGets  int
+		expectedHeads int
+	}{
+		{
+			name:          "unchanged validators skip the fetch",
+			headCheck:     true,
+			head:          &feedfetch.Validators{ETag: `"v1"`, ContentLength: 120},
+			expectedGets:  0,
+			expectedHeads: 1,
+		},
+		{
+			name:          "new etag fetches the feed",
+			headCheck:     true,
+			head:          &feedfetch.Validators{ETag: `"v2"`, ContentLength: 120},
+			expectedGets:  1,
+			expectedHeads: 1,
+		},
+		{
+			name:          "same etag with another length fetches the feed",
+			headCheck:     true,
+			head:          &feedfetch.Validators{ETag: `"v1"`, ContentLength: 180},
+			expectedGets:  1,
+			expectedHeads: 1,
+		},
+		{
+			name:          "HEAD not supported falls back to GET",
+			headCheck:     true,
+			head:          nil,
+			expectedGets:  1,
+			expectedHeads: 1,
+		},
+		{
+			name:          "disabled sends no HEAD",
+			headCheck:     false,
+			head:          &feedfetch.Validators{ETag: `"v1"`, ContentLength: 120},
+			expectedGets:  1,
+			expectedHeads: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := headCheckedFeed()
+			fetcher := feedfetch.NewFakeFetcher()
+			fetcher.SetFeed(feed.Url, fakeParsedFeed("hash-1", "https://example.com/a"))
+			if tc.head != nil {
+				fetcher.SetHead(feed.Url, *tc.head)
+			}
+
+			s := newTestScraper()
+			s.Fetcher = fetcher
+			s.HeadCheck = tc.headCheck
+			store := newStubFeedStore()
+
+			s.fetchAndStoreFeed(context.Background(), store, feed)
+
+			if got := fetcher.Calls(feed.Url); got != tc.expectedGets {
+				t.Errorf("Expected %d GET requests, got %d", tc.expectedGets, got)
+			}
+			if got := fetcher.HeadCalls(feed.Url); got != tc.expectedHeads {
+				t.Errorf("Expected %d HEAD requests, got %d", tc.expectedHeads, got)
+			}
+			if store.resets != 1 {
+				t.Errorf("Expected fetch failures reset once, got %d", store.resets)
+			}
+		})
+	}
+}
+
+func TestFetchAndStoreFeed_NoStoredValidators_SkipsHead(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, fakeParsedFeed("hash-1", "https://example.com/a"))
+	fetcher.SetHead(feed.Url, feedfetch.Validators{ETag: `"v1"`, ContentLength: -1})
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	s.HeadCheck = true
+	store := newStubFeedStore()
+
+	s.fetchAndStoreFeed(context.Background(), store, feed)
+
+	if got := fetcher.HeadCalls(feed.Url); got != 0 {
+		t.Errorf("Expected no HEAD request, got %d", got)
+	}
+	if got := fetcher.Calls(feed.Url); got != 1 {
+		t.Errorf("Expected 1 GET request, got %d", got)
+	}
+}
+
+func TestFetchAndStoreFeed_HeadCheck_StoresValidators(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	parsedFeed := fakeParsedFeed("hash-1", "https://example.com/a")
+	parsedFeed.Validators = feedfetch.Validators{ETag: `"v1"`, LastModified: "Wed, 14 Oct 2026 08:00:00 GMT", ContentLength: -1}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, parsedFeed)
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	s.HeadCheck = true
+	store := newStubFeedStore()
+
+	s.fetchAndStoreFeed(context.Background(), store, feed)
+
+	stored, ok := store.validators[feed.ID]
+	if !ok {
+		t.Fatal("Expected validators stored")
+	}
+	if stored.HttpEtag.String != `"v1"` || stored.HttpLastModified.String != "Wed, 14 Oct 2026 08:00:00 GMT" {
+		t.Errorf("Expected etag and last modified stored, got %+v", stored)
+	}
+	if stored.HttpContentLength.Valid {
+		t.Errorf("Expected unknown content length stored as NULL, got %d", stored.HttpContentLength.Int64)
+	}
+}
+
+func TestFetchAndStoreFeed_HeadCheckDisabled_StoresNoValidators(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	parsedFeed := fakeParsedFeed("hash-1", "https://example.com/a")
+	parsedFeed.Validators = feedfetch.Validators{ETag: `"v1"`, ContentLength: 120}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, parsedFeed)
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	store := newStubFeedStore()
+
+	s.fetchAndStoreFeed(context.Background(), store, feed)
+
+	if len(store.validators) != 0 {
+		t.Errorf("Expected no validators stored, got %d", len(store.validators))
+	}
+}
+
+func TestFetchAndStoreFeed_FetchError_RecordsFailure(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetError(feed.Url, fmt.Errorf("%w: unexpected EOF", feedfetch.ErrMalformedFeed))
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	store := newStubFeedStore()
+
+	if newPostCount := s.fetchAndStoreFeed(context.Background(), store, feed); newPostCount != 0 {
+		t.Errorf("Expected 0 new posts, got %d", newPostCount)
+	}
+
+	if store.failures != 1 {
+		t.Errorf("Expected 1 failure recorded, got %d", store.failures)
+	}
+}
+
+func TestFetchAndStoreFeed_MissingOrZeroDate_UsesIngestionTime(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	zero := time.Time{}
+
+	parsedFeed := fakeParsedFeed("hash-1", "https://example.com/dated", "https://example.com/undated", "https://example.com/zero")
+	parsedFeed.Items[0].PublishedParsed = &published
+	parsedFeed.Items[2].PublishedParsed = &zero
+
+	fetcher := feedfetch.NewFakeFetcher()
+	fetcher.SetFeed(feed.Url, parsedFeed)
+
+	s := newTestScraper()
+	s.Fetcher = fetcher
+	store := newStubFeedStore()
+
+	before := time.Now().UTC()
+	s.fetchAndStoreFeed(context.Background(), store, feed)
+
+	if got := store.publishedAt["https://example.com/dated"]; !got.Equal(published) {
+		t.Errorf("Expected published date %v kept, got %v", published, got)
+	}
+
+	for _, url := range []string{"https://example.com/undated", "https://example.com/zero"} {
+		if got := store.publishedAt[url]; got.Before(before) {
+			t.Errorf("Expected %s to be dated at ingestion, got %v", url, got)
+		}
+	}
+}
+
+func TestStoreParsedFeed_ReturnsCreatedPosts(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	store := newStubFeedStore()
+	store.duplicates["https://example.com/old"] = true
+
+	posts := newTestScraper().storeParsedFeed(context.Background(), store, feed,
+		fakeParsedFeed("hash-1", "https://example.com/a", "https://example.com/old", "https://example.com/b"))
+
+	if len(posts) != 2 {
+		t.Fatalf("Expected 2 created posts, got %d", len(posts))
+	}
+
+	if posts[0].Url != "https://example.com/a" || posts[1].Url != "https://example.com/b" {
+		t.Errorf("Expected the new posts only, got %s and %s", posts[0].Url, posts[1].Url)
+	}
+}
+
+func TestStoreParsedFeed_LogsOneSummaryPerFeed(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Example", Url: "https://example.com/feed.xml"}
+	store := newStubFeedStore()
+	urls := make([]string, 0, 50)
+	for i := 0; i < 50; i++ {
+		url := fmt.Sprintf("https://example.com/%d", i)
+		if i >= 3 {
+			store.duplicates[url] = true
+		}
+		urls = append(urls, url)
+	}
+
+	testCases := []struct {
+		name             string
+		level            zerolog.Level
+		expectedPerItems int
+	}{
+		{"Debug level", zerolog.DebugLevel, 0},
+		{"Trace level keeps detail", zerolog.TraceLevel, 50},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := NewScraper(nil, zerolog.New(&buf).Level(tc.level), nil)
+
+			s.storeParsedFeed(context.Background(), store, feed, fakeParsedFeed("hash-"+tc.name, urls...))
+
+			summaries, perItems := 0, 0
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				switch {
+				case strings.Contains(line, "Stored posts for feed Example: 3 new, 47 skipped"):
+					summaries++
+				case strings.Contains(line, "Post already exists"), strings.Contains(line, "Successfully created post"):
+					perItems++
+				}
+			}
+
+			if summaries != 1 {
+				t.Errorf("Expected 1 summary line, got %d:\n%s", summaries, buf.String())
+			}
+			if perItems != tc.expectedPerItems {
+				t.Errorf("Expected %d per-post lines, got %d", tc.expectedPerItems, perItems)
+			}
+		})
+	}
+}
+
+func TestStoreParsedFeed_LongTitle_IsTruncated(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	store := newStubFeedStore()
+	parsed := fakeParsedFeed("hash-1", "https://example.com/a")
+	parsed.Items[0].Title = strings.Repeat("世", 1<<20/3)
+
+	s := newTestScraper()
+	s.MaxTitleBytes = 100
+	posts := s.storeParsedFeed(context.Background(), store, feed, parsed)
+
+	if len(posts) != 1 {
+		t.Fatalf("Expected 1 created post, got %d", len(posts))
+	}
+	if len(posts[0].Title) > 100 || !utf8.ValidString(posts[0].Title) {
+		t.Errorf("Expected a valid title of at most 100 bytes, got %d bytes", len(posts[0].Title))
+	}
+}
+
+func TestStoreParsedFeed_RecordsNewestPostAsLastPostAt(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	store := newStubFeedStore()
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	parsed := fakeParsedFeed("hash-1", "https://example.com/a", "https://example.com/b")
+	parsed.Items[0].PublishedParsed = &older
+	parsed.Items[1].PublishedParsed = &newest
+	newTestScraper().storeParsedFeed(context.Background(), store, feed, parsed)
+
+	if got := store.lastPostAt[feed.ID]; !got.Equal(newest) {
+		t.Fatalf("Expected last_post_at %v, got %v", newest, got)
+	}
+
+	// A later fetch adding only an older, backdated post must not move it back
+	backdated := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	parsed = fakeParsedFeed("hash-2", "https://example.com/c")
+	parsed.Items[0].PublishedParsed = &backdated
+	newTestScraper().storeParsedFeed(context.Background(), store, feed, parsed)
+
+	if got := store.lastPostAt[feed.ID]; !got.Equal(newest) {
+		t.Errorf("Expected last_post_at to stay %v, got %v", newest, got)
+	}
+}
+
+func TestStoreParsedFeed_NoNewPosts_LeavesLastPostAt(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Url: "https://example.com/feed.xml"}
+	store := newStubFeedStore()
+	store.duplicates["https://example.com/old"] = true
+
+	newTestScraper().storeParsedFeed(context.Background(), store, feed, fakeParsedFeed("hash-1", "https://example.com/old"))
+
+	if got, ok := store.lastPostAt[feed.ID]; ok {
+		t.Errorf("Expected last_post_at to be left unset, got %v", got)
+	}
+}
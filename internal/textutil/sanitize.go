@@ -0,0 +1,144 @@
+package textutil
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags maps each tag kept by SanitizeHTML to the attributes it may keep
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"li":         nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"q":          nil,
+	"s":          nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         nil,
+	"tfoot":      nil,
+	"th":         nil,
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedWithContent are tags removed together with everything inside them
+var droppedWithContent = map[string]bool{
+	"iframe":   true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"title":    true,
+}
+
+// SanitizeHTML keeps only an allowlist of formatting tags and attributes from
+// feed-supplied HTML. Scripts, styles, embeds, event handlers and links or images
+// that aren't http(s) are removed; unknown tags are dropped but their text is kept.
+func SanitizeHTML(s string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	var b strings.Builder
+	skipDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		// The end of input (or a read error) ends the document
+		if tokenType == html.ErrorToken {
+			return b.String()
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedWithContent[token.Data] {
+				if tokenType == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if attrs, ok := allowedTags[token.Data]; ok {
+				token.Attr = sanitizeAttrs(token.Attr, attrs)
+				b.WriteString(token.String())
+			}
+		case html.EndTagToken:
+			if droppedWithContent[token.Data] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if _, ok := allowedTags[token.Data]; ok {
+				b.WriteString(token.String())
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+}
+
+// sanitizeAttrs keeps the allowed attributes, dropping URLs with unsafe schemes
+func sanitizeAttrs(attrs []html.Attribute, allowed []string) []html.Attribute {
+	kept := make([]html.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !slices.Contains(allowed, attr.Key) {
+			continue
+		}
+		if (attr.Key == "href" || attr.Key == "src") && !isSafeURL(attr.Val) {
+			continue
+		}
+		kept = append(kept, attr)
+	}
+	return kept
+}
+
+// isSafeURL reports whether raw is an absolute http(s) URL
+func isSafeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return (scheme == "http" || scheme == "https") && u.Host != ""
+}
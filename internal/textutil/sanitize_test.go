@@ -0,0 +1,30 @@
+package textutil
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Plain text", "Hello & welcome", "Hello &amp; welcome"},
+		{"Formatting kept", "<p>Hello <strong>world</strong></p>", "<p>Hello <strong>world</strong></p>"},
+		{"Script dropped with content", "<p>a</p><script>alert(1)</script><p>b</p>", "<p>a</p><p>b</p>"},
+		{"Style dropped with content", "<style>p{color:red}</style>text", "text"},
+		{"Event handler removed", `<p onclick="alert(1)">hi</p>`, "<p>hi</p>"},
+		{"Safe link kept", `<a href="https://example.com" target="_blank">x</a>`, `<a href="https://example.com">x</a>`},
+		{"Javascript link removed", `<a href="javascript:alert(1)">x</a>`, "<a>x</a>"},
+		{"Relative image removed", `<img src="/local.png" alt="pic">`, `<img alt="pic">`},
+		{"Unknown tag keeps text", "<custom>inner</custom>", "inner"},
+		{"Iframe dropped", `<iframe src="https://evil.example"></iframe>after`, "after"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SanitizeHTML(tc.input); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package textutil
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateUTF8 shortens s to at most maxBytes bytes without splitting a multibyte character.
+// It reports whether s was truncated. A maxBytes of 0 or less means no limit.
+func TruncateUTF8(s string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s, false
+	}
+
+	cut := maxBytes
+	// Back up to the start of the character that straddles the limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut], true
+}
+
+// DefaultMaxTitleBytes caps feed names and post titles when no limit is configured
+const DefaultMaxTitleBytes = 512
+
+// TruncateTitle trims surrounding whitespace from a feed name or post title and
+// shortens it to maxBytes (0 or less = DefaultMaxTitleBytes) with TruncateUTF8
+func TruncateTitle(title string, maxBytes int) string {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxTitleBytes
+	}
+	truncated, _ := TruncateUTF8(strings.TrimSpace(title), maxBytes)
+	return strings.TrimSpace(truncated)
+}
@@ -0,0 +1,83 @@
+package textutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		maxBytes          int
+		expected          string
+		expectedTruncated bool
+	}{
+		{"Short string", "hello", 10, "hello", false},
+		{"Exact length", "hello", 5, "hello", false},
+		{"ASCII cut", "hello world", 5, "hello", true},
+		{"No limit", "hello world", 0, "hello world", false},
+		// "é" is 2 bytes: a 2-byte limit would split it, so it is dropped entirely
+		{"Two-byte boundary", "aé", 2, "a", true},
+		// "世" is 3 bytes starting at index 1
+		{"Three-byte boundary", "a世界", 3, "a", true},
+		{"After full character", "a世界", 4, "a世", true},
+		// "😀" is 4 bytes
+		{"Four-byte boundary", "😀😀", 6, "😀", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, truncated := TruncateUTF8(tc.input, tc.maxBytes)
+
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+
+			if truncated != tc.expectedTruncated {
+				t.Errorf("Expected truncated=%v, got %v", tc.expectedTruncated, truncated)
+			}
+
+			if !utf8.ValidString(got) {
+				t.Errorf("Expected valid UTF-8, got %q", got)
+			}
+		})
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		maxBytes int
+		expected string
+	}{
+		{"Short title", "Go Blog", 10, "Go Blog"},
+		{"Surrounding whitespace", "  Go Blog\n", 10, "Go Blog"},
+		{"Cut on character boundary", "Café au lait", 4, "Caf"},
+		{"Trailing space after cut", "Go Blog", 3, "Go"},
+		{"Default limit", strings.Repeat("a", DefaultMaxTitleBytes+1), 0, strings.Repeat("a", DefaultMaxTitleBytes)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TruncateTitle(tc.input, tc.maxBytes); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTruncateTitle_MegabyteTitle_IsBounded(t *testing.T) {
+	title := strings.Repeat("世", 1<<20/3)
+
+	got := TruncateTitle(title, 100)
+
+	if len(got) > 100 {
+		t.Errorf("Expected at most 100 bytes, got %d", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Error("Expected valid UTF-8")
+	}
+}
@@ -0,0 +1,16 @@
+-- name: CreateBookmark :exec
+-- Bookmarking a post twice keeps the first bookmark
+INSERT INTO bookmarks (user_id, post_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: DeleteBookmark :exec
+DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetBookmarksForUser :many
+-- The page of the user's posts bookmarked before bookmarked_before, most recently bookmarked first
+SELECT posts.*, bookmarks.created_at AS bookmarked_at
+FROM bookmarks JOIN posts ON posts.id = bookmarks.post_id
+WHERE bookmarks.user_id = sqlc.arg(user_id) AND bookmarks.created_at < sqlc.arg(bookmarked_before)
+ORDER BY bookmarks.created_at DESC, bookmarks.post_id DESC
+LIMIT sqlc.arg(row_limit);
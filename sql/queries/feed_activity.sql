@@ -0,0 +1,24 @@
+-- name: IncrementFeedActivity :exec
+INSERT INTO feed_activity (feed_id, granularity, bucket_start, post_count)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (feed_id, granularity, bucket_start)
+DO UPDATE SET post_count = feed_activity.post_count + EXCLUDED.post_count;
+
+-- name: GetFeedActivity :many
+SELECT bucket_start, post_count FROM feed_activity
+WHERE feed_id = $1 AND granularity = $2 AND bucket_start >= $3
+ORDER BY bucket_start ASC;
+
+-- name: DeleteHourlyFeedActivityBefore :execrows
+-- Hour buckets are recorded alongside day buckets, so old ones carry no extra information
+DELETE FROM feed_activity
+WHERE granularity = 'hour' AND bucket_start < sqlc.arg(bucket_start_before);
+
+-- name: MergeFeedActivity :exec
+-- Adds from_feed_id's buckets onto to_feed_id's
+INSERT INTO feed_activity (feed_id, granularity, bucket_start, post_count)
+SELECT sqlc.arg(to_feed_id)::uuid, source.granularity, source.bucket_start, source.post_count
+FROM feed_activity AS source
+WHERE source.feed_id = sqlc.arg(from_feed_id)::uuid
+ON CONFLICT (feed_id, granularity, bucket_start)
+DO UPDATE SET post_count = feed_activity.post_count + EXCLUDED.post_count;
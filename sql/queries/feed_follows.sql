@@ -7,7 +7,73 @@ RETURNING *;
 SELECT * FROM feed_follows WHERE user_id=$1;
 
 -- name: DeleteFeedFollow :exec
-DELETE FROM feed_follows WHERE id=$1 AND user_id=$2;
+-- Also decrements the feed's cached follower count when a follow was deleted
+WITH deleted AS (
+    DELETE FROM feed_follows WHERE feed_follows.id = sqlc.arg(id) AND feed_follows.user_id = sqlc.arg(user_id)
+    RETURNING feed_follows.feed_id
+)
+UPDATE feeds SET follower_count = GREATEST(follower_count - 1, 0)
+FROM deleted WHERE feeds.id = deleted.feed_id;
+
+-- name: DeleteFeedFollowsByFeed :many
+-- Returns the users who followed the feed
+DELETE FROM feed_follows WHERE feed_id = $1
+RETURNING user_id;
 
 -- name: GetFollowersByFeedID :many
-SELECT user_id FROM feed_follows WHERE feed_id =$1;
\ No newline at end of file
+-- notify is false for followers who muted the feed's notifications
+SELECT user_id, notify FROM feed_follows WHERE feed_id = $1;
+
+-- name: CountFeedFollowsByUser :one
+SELECT COUNT(*) FROM feed_follows WHERE user_id = $1;
+
+-- name: GetFeedFollowsWithFeedName :many
+SELECT feed_follows.*, feeds.name AS feed_name
+FROM feed_follows JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1;
+
+-- name: GetFeedFollowsPaginated :many
+-- A page of the user's follows, newest first, optionally limited to one category
+SELECT feed_follows.*, feeds.name AS feed_name, feeds.last_post_at AS feed_last_post_at
+FROM feed_follows JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND feed_follows.created_at < sqlc.arg(created_before)
+  AND (sqlc.narg(category)::text IS NULL OR feed_follows.category = sqlc.narg(category))
+ORDER BY feed_follows.created_at DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateFeedFollow :one
+-- Sets the alias, category and/or notify; a field is left unchanged unless its set_ flag is true
+UPDATE feed_follows SET
+    alias = CASE WHEN sqlc.arg(set_alias)::boolean THEN sqlc.narg(alias) ELSE alias END,
+    category = CASE WHEN sqlc.arg(set_category)::boolean THEN sqlc.narg(category) ELSE category END,
+    notify = CASE WHEN sqlc.arg(set_notify)::boolean THEN sqlc.arg(notify)::boolean ELSE notify END,
+    updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: DeleteFeedFollowsAlreadyOnFeed :execrows
+-- Drops follows of from_feed_id whose user already follows to_feed_id
+DELETE FROM feed_follows AS duplicate
+WHERE duplicate.feed_id = sqlc.arg(from_feed_id)
+  AND EXISTS (
+      SELECT 1 FROM feed_follows AS canonical
+      WHERE canonical.feed_id = sqlc.arg(to_feed_id) AND canonical.user_id = duplicate.user_id
+  );
+
+-- name: MoveFeedFollows :execrows
+UPDATE feed_follows SET feed_id = sqlc.arg(to_feed_id) WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: GetUnreadCountsForUser :many
+-- One row per followed feed, counting its posts without a read by the user
+SELECT feed_follows.id AS feed_follow_id, feed_follows.feed_id, feed_follows.alias,
+       feeds.name AS feed_name,
+       COUNT(posts.id) FILTER (WHERE post_reads.post_id IS NULL) AS unread_count
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN posts ON posts.feed_id = feed_follows.feed_id
+  AND (NOT sqlc.arg(after_follow_only)::boolean OR posts.published_at >= feed_follows.created_at)
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+GROUP BY feed_follows.id, feeds.name
+ORDER BY feed_follows.created_at;
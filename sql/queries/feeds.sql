@@ -1,10 +1,118 @@
 -- name: CreateFeed :one
+-- Returns no row when a feed with the same URL already exists, e.g. one created concurrently
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, description, logo_url, priority)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (url) DO NOTHING
 RETURNING *;
 
 -- name: GetFeeds :many
 SELECT * FROM feeds;
 
+-- name: GetFeedsWithStats :many
+-- A page of feeds, newest first, with how many users follow each and whether
+-- user_id does (always false when user_id is NULL), optionally limited to feeds
+-- whose name or description matches search_pattern (an ILIKE pattern)
+SELECT feeds.*,
+       COUNT(feed_follows.id) AS follow_count,
+       COALESCE(BOOL_OR(feed_follows.user_id = sqlc.narg(user_id)::uuid), false)::boolean AS is_followed
+FROM feeds
+LEFT JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feeds.created_at < sqlc.arg(created_at)
+  AND (sqlc.narg(search_pattern)::text IS NULL
+       OR feeds.name ILIKE sqlc.narg(search_pattern)
+       OR feeds.description ILIKE sqlc.narg(search_pattern))
+GROUP BY feeds.id
+ORDER BY feeds.created_at DESC, feeds.id DESC
+LIMIT sqlc.arg(row_limit);
+
 -- name: GetFeedsByPriority :many
-SELECT * FROM feeds ORDER BY priority DESC, updated_at ASC;
\ No newline at end of file
+SELECT * FROM feeds ORDER BY priority DESC, updated_at ASC;
+
+-- name: UpdateFeedLastBodyHash :exec
+UPDATE feeds SET last_body_hash = $2 WHERE id = $1;
+
+-- name: UpdateFeedHTTPValidators :exec
+UPDATE feeds
+SET http_etag = sqlc.arg(http_etag),
+    http_last_modified = sqlc.arg(http_last_modified),
+    http_content_length = sqlc.arg(http_content_length)
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateFeedLastPostAt :exec
+-- Only moves last_post_at forward, so the row is left alone when nothing newer arrived
+UPDATE feeds SET last_post_at = sqlc.arg(last_post_at)
+WHERE id = sqlc.arg(id) AND (last_post_at IS NULL OR last_post_at < sqlc.arg(last_post_at));
+
+-- name: GetFeedsDueForFetch :many
+SELECT * FROM feeds
+WHERE next_fetch_at IS NULL OR next_fetch_at <= $1
+ORDER BY priority DESC, next_fetch_at ASC NULLS FIRST;
+
+-- name: UpdateFeedSchedule :exec
+UPDATE feeds SET next_fetch_at = $2, fetch_interval_seconds = $3 WHERE id = $1;
+
+-- name: GetFeedByID :one
+SELECT * FROM feeds WHERE id = $1;
+
+-- name: UpdateFeed :one
+-- When expected_updated_at is set the update only applies if nobody changed the feed since
+UPDATE feeds
+SET name = sqlc.arg(name),
+    description = sqlc.arg(description),
+    priority = sqlc.arg(priority),
+    fetch_full_content = sqlc.arg(fetch_full_content),
+    updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(expected_updated_at)::timestamp IS NULL OR updated_at = sqlc.narg(expected_updated_at))
+RETURNING *;
+
+-- name: RecordFeedFetchFailure :exec
+UPDATE feeds
+SET fetch_failure_count = fetch_failure_count + 1, last_fetch_error = $2
+WHERE id = $1;
+
+-- name: ResetFeedFetchFailures :exec
+UPDATE feeds
+SET fetch_failure_count = 0, last_fetch_error = NULL
+WHERE id = $1 AND fetch_failure_count > 0;
+
+-- name: GetFeedByURL :one
+SELECT * FROM feeds WHERE url = $1;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
+
+-- name: UpdateFeedURL :exec
+UPDATE feeds SET url = $2 WHERE id = $1;
+
+-- name: FeedNameTakenByUser :one
+-- Names are compared case-insensitively; exclude_id skips the feed being renamed
+SELECT EXISTS (
+    SELECT 1 FROM feeds
+    WHERE user_id = sqlc.arg(user_id)
+      AND lower(name) = lower(sqlc.arg(name))
+      AND id <> sqlc.arg(exclude_id)
+);
+
+-- name: IncrementFeedFollowerCount :exec
+UPDATE feeds SET follower_count = follower_count + 1 WHERE id = $1;
+
+-- name: ReconcileFeedFollowerCounts :execrows
+-- Recomputes every feed's cached follower count from feed_follows and
+-- corrects the ones that drifted; returns how many feeds were corrected
+UPDATE feeds SET follower_count = counts.follower_count
+FROM (
+    SELECT f.id, COUNT(feed_follows.id)::integer AS follower_count
+    FROM feeds AS f LEFT JOIN feed_follows ON feed_follows.feed_id = f.id
+    GROUP BY f.id
+) AS counts
+WHERE feeds.id = counts.id AND feeds.follower_count <> counts.follower_count;
+
+-- name: UpdateFeedPriority :one
+UPDATE feeds
+SET priority = sqlc.arg(priority),
+    updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+RETURNING *;
@@ -0,0 +1,19 @@
+-- name: CreateIdentity :one
+INSERT INTO identities (id, user_id, provider, provider_user_id, email, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetIdentityByProvider :one
+SELECT * FROM identities WHERE provider = $1 AND provider_user_id = $2;
+
+-- name: GetIdentityForUser :one
+SELECT * FROM identities WHERE id = $1 AND user_id = $2;
+
+-- name: ListIdentitiesByUser :many
+SELECT * FROM identities WHERE user_id = $1 ORDER BY created_at ASC;
+
+-- name: CountIdentitiesByUser :one
+SELECT COUNT(*) FROM identities WHERE user_id = $1;
+
+-- name: DeleteIdentity :exec
+DELETE FROM identities WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,22 @@
+-- name: CreateNotifications :exec
+-- One notification per user, ids[i] being the id of user_ids[i]'s notification
+INSERT INTO notifications (id, user_id, feed_id, type, post_count, created_at)
+SELECT unnest(sqlc.arg(ids)::uuid[]), unnest(sqlc.arg(user_ids)::uuid[]),
+    sqlc.arg(feed_id), sqlc.arg(type), sqlc.arg(post_count), sqlc.arg(created_at);
+
+-- name: ListUnackedNotifications :many
+SELECT notifications.id, notifications.feed_id, feeds.name AS feed_name, notifications.type,
+    notifications.post_count, notifications.created_at
+FROM notifications JOIN feeds ON notifications.feed_id = feeds.id
+WHERE notifications.user_id = sqlc.arg(user_id) AND notifications.acked_at IS NULL
+ORDER BY notifications.created_at
+LIMIT sqlc.arg(row_limit);
+
+-- name: AckNotifications :execrows
+UPDATE notifications SET acked_at = sqlc.arg(acked_at)
+WHERE user_id = sqlc.arg(user_id) AND id = ANY(sqlc.arg(ids)::uuid[]) AND acked_at IS NULL;
+
+-- name: DeleteStaleNotifications :execrows
+-- Acknowledged notifications are never listed again; unacknowledged ones expire
+DELETE FROM notifications
+WHERE acked_at IS NOT NULL OR created_at < sqlc.arg(created_before);
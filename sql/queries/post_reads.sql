@@ -0,0 +1,29 @@
+-- name: MarkPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT sqlc.arg(user_id), posts.id, sqlc.arg(read_at)
+FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.id = ANY(sqlc.arg(post_ids)::uuid[])
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostRead :execrows
+-- Marks one post read, only if it is in one of the user's followed feeds
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT sqlc.arg(user_id), posts.id, sqlc.arg(read_at)
+FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.id = sqlc.arg(post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = post_reads.read_at;
+
+-- name: UnmarkPostRead :execrows
+DELETE FROM post_reads WHERE user_id = $1 AND post_id = $2;
+
+-- name: IsFollowedPost :one
+-- Whether the post is in one of the user's followed feeds
+SELECT EXISTS (
+    SELECT 1 FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+    WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.id = sqlc.arg(post_id)
+);
+
+-- name: GetReadPostIDs :many
+-- The ids among post_ids the user has marked read
+SELECT post_id FROM post_reads
+WHERE user_id = sqlc.arg(user_id) AND post_id = ANY(sqlc.arg(post_ids)::uuid[]);
@@ -1,12 +1,121 @@
 -- name: CreatePost :one
 INSERT INTO posts (id, created_at, updated_at,
-                   title, url, description, published_at, feed_id)
+                   title, url, description, published_at, feed_id,
+                   description_truncated, full_description)
 VALUES ($1, $2, $3, $4,
-        $5, $6, $7, $8)
+        $5, $6, $7, $8,
+        $9, $10)
 RETURNING *;
 
 -- name: GetPostsForUser :many
+-- With unread_only, posts the user has read are left out
 SELECT posts.* from posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1 AND posts.published_at < $2
-ORDER BY posts.published_at DESC
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.published_at < sqlc.arg(published_at)
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetPostsForUserAfterFollow :many
+SELECT posts.* from posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.published_at < sqlc.arg(published_at)
+  AND posts.published_at >= feed_follows.created_at
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetPostsByFeed :many
+SELECT * FROM posts
+WHERE feed_id = $1 AND published_at < $2
+ORDER BY published_at DESC, id DESC
 LIMIT $3;
+
+-- name: CountPostsByFeedSince :one
+SELECT COUNT(*) FROM posts WHERE feed_id = $1 AND published_at >= $2;
+
+-- name: MovePosts :execrows
+UPDATE posts SET feed_id = sqlc.arg(to_feed_id) WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: GetPostsForUserWithFeed :many
+-- Same page as GetPostsForUser/GetPostsForUserAfterFollow, joined with each post's feed
+SELECT posts.*,
+       feeds.name AS feed_name, feeds.url AS feed_url, feeds.logo_url AS feed_logo_url,
+       feed_follows.alias AS feed_alias
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.published_at < sqlc.arg(published_at)
+  AND (NOT sqlc.arg(after_follow_only)::boolean OR posts.published_at >= feed_follows.created_at)
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetPostsForUserOldestFirst :many
+-- GetPostsForUser/GetPostsForUserAfterFollow in chronological order: the page after
+-- published_at, oldest first
+SELECT posts.* FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.published_at > sqlc.arg(published_at)
+  AND (NOT sqlc.arg(after_follow_only)::boolean OR posts.published_at >= feed_follows.created_at)
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at ASC, posts.id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetPostsForUserWithFeedOldestFirst :many
+-- Same page as GetPostsForUserOldestFirst, joined with each post's feed
+SELECT posts.*,
+       feeds.name AS feed_name, feeds.url AS feed_url, feeds.logo_url AS feed_logo_url,
+       feed_follows.alias AS feed_alias
+FROM posts
+JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND posts.published_at > sqlc.arg(published_at)
+  AND (NOT sqlc.arg(after_follow_only)::boolean OR posts.published_at >= feed_follows.created_at)
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = feed_follows.user_id AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at ASC, posts.id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdatePostFullContent :exec
+-- Stores the article content extracted for a post of a feed with fetch_full_content
+UPDATE posts SET full_content = $2 WHERE id = $1;
+
+-- name: GetFollowedPostContent :one
+-- The stored content of a post, only if it is in one of the user's followed feeds
+SELECT posts.id, posts.description, posts.description_truncated, posts.full_description, posts.full_content
+FROM posts JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
+WHERE posts.id = sqlc.arg(id) AND feed_follows.user_id = sqlc.arg(user_id);
+
+-- name: DeleteOrphanedPosts :execrows
+-- Deletes up to batch_size posts whose feed no longer exists
+DELETE FROM posts
+WHERE posts.id IN (
+    SELECT orphan.id FROM posts AS orphan
+    LEFT JOIN feeds ON feeds.id = orphan.feed_id
+    WHERE feeds.id IS NULL
+    LIMIT sqlc.arg(batch_size)
+);
+
+-- name: GetTrendingPostCandidates :many
+-- The newest posts published in [published_since, published_before] from feeds with
+-- followers, joined with their feed; the handler ranks them by trending score
+SELECT posts.*,
+       feeds.name AS feed_name, feeds.url AS feed_url, feeds.logo_url AS feed_logo_url,
+       feeds.follower_count AS feed_follower_count
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feeds.follower_count > 0
+  AND posts.published_at >= sqlc.arg(published_since)
+  AND posts.published_at <= sqlc.arg(published_before)
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeletePostsByFeed :execrows
+DELETE FROM posts WHERE feed_id = $1;
@@ -1,10 +1,30 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at, previous_token_hash, session_id, device_label,
+                            user_agent_hash, ip_prefix)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: GetRefreshTokenByHash :one
 SELECT * FROM refresh_tokens WHERE token_hash = $1;
 
+-- name: CreateRotatedRefreshToken :exec
+INSERT INTO rotated_refresh_tokens (token_hash, user_id, session_id, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (token_hash) DO NOTHING;
+
+-- name: GetRotatedRefreshToken :one
+SELECT * FROM rotated_refresh_tokens WHERE token_hash = $1;
+
+-- name: DeleteExpiredRotatedRefreshTokens :exec
+DELETE FROM rotated_refresh_tokens WHERE user_id = $1 AND expires_at < $2;
+
 -- name: DeleteRefreshToken :exec
-DELETE FROM refresh_tokens WHERE user_id = $1;
\ No newline at end of file
+DELETE FROM refresh_tokens WHERE user_id = $1;
+-- name: DeleteRefreshTokenByHash :execrows
+DELETE FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2;
+
+-- name: GetRefreshTokensByUserID :many
+SELECT * FROM refresh_tokens WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: DeleteSessionForUser :execrows
+DELETE FROM refresh_tokens WHERE session_id = $1 AND user_id = $2;
@@ -4,7 +4,12 @@ VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1;
+-- Case-insensitive, so accounts registered before emails were lowercased are still found
+SELECT * FROM users WHERE lower(email) = lower(sqlc.narg(email));
 
 -- name: GetUserByID :one
-SELECT * FROM users WHERE id = $1;
\ No newline at end of file
+SELECT * FROM users WHERE id = $1;
+
+-- name: LockUserForUpdate :exec
+-- Serializes concurrent transactions acting on behalf of the same user
+SELECT id FROM users WHERE id = $1 FOR UPDATE;
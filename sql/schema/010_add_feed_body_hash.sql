@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE feeds ADD COLUMN last_body_hash TEXT;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN last_body_hash;
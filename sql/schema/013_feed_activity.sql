@@ -0,0 +1,12 @@
+-- +goose Up
+
+CREATE TABLE feed_activity (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    granularity TEXT NOT NULL,
+    bucket_start TIMESTAMP NOT NULL,
+    post_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (feed_id, granularity, bucket_start)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS feed_activity;
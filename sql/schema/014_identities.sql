@@ -0,0 +1,17 @@
+-- +goose Up
+
+CREATE TABLE identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    provider_user_id TEXT NOT NULL,
+    email TEXT,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE(provider, provider_user_id)
+);
+
+CREATE INDEX identities_user_id_idx ON identities (user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS identities_user_id_idx;
+DROP TABLE IF EXISTS identities;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE feeds ADD COLUMN fetch_failure_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feeds ADD COLUMN last_fetch_error TEXT;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN last_fetch_error;
+ALTER TABLE feeds DROP COLUMN fetch_failure_count;
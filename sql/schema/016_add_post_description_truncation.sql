@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE posts ADD COLUMN description_truncated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE posts ADD COLUMN full_description TEXT;
+
+-- +goose Down
+
+ALTER TABLE posts DROP COLUMN full_description;
+ALTER TABLE posts DROP COLUMN description_truncated;
@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- Posts stored with a zero or pre-1970 date are treated as published when ingested
+UPDATE posts SET published_at = created_at WHERE published_at <= '1970-01-01 00:00:00';
+
+-- +goose Down
+
+-- Backfilled dates can't be told apart from real ones, so there is nothing to undo
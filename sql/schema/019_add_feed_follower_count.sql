@@ -0,0 +1,13 @@
+-- +goose Up
+
+-- Cached number of feed_follows rows for the feed, kept in step on follow/unfollow
+-- and periodically reconciled because cascading deletes bypass that bookkeeping
+ALTER TABLE feeds ADD COLUMN follower_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE feeds SET follower_count = counts.follower_count
+FROM (SELECT feed_id, COUNT(*) AS follower_count FROM feed_follows GROUP BY feed_id) AS counts
+WHERE feeds.id = counts.feed_id;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN follower_count;
@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- Trending posts scan the most recently published posts across all feeds
+CREATE INDEX posts_published_at_idx ON posts (published_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS posts_published_at_idx;
@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- A user's own grouping for a followed feed, e.g. "news" or "tech"
+ALTER TABLE feed_follows ADD COLUMN category TEXT;
+
+CREATE INDEX feed_follows_user_id_created_at_idx ON feed_follows (user_id, created_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS feed_follows_user_id_created_at_idx;
+ALTER TABLE feed_follows DROP COLUMN category;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- When the newest post of each feed was published, so quiet feeds can be spotted
+ALTER TABLE feeds ADD COLUMN last_post_at TIMESTAMP;
+
+UPDATE feeds SET last_post_at = latest.published_at
+FROM (SELECT feed_id, MAX(published_at) AS published_at FROM posts GROUP BY feed_id) AS latest
+WHERE feeds.id = latest.feed_id;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN last_post_at;
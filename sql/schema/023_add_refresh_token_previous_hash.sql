@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- Hash of the token each refresh token replaced, to detect replay of rotated tokens
+ALTER TABLE refresh_tokens ADD COLUMN previous_token_hash TEXT;
+
+CREATE INDEX refresh_tokens_previous_token_hash_idx ON refresh_tokens (previous_token_hash);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS refresh_tokens_previous_token_hash_idx;
+ALTER TABLE refresh_tokens DROP COLUMN previous_token_hash;
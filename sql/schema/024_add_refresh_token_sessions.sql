@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- Each login starts a session that keeps its id across refresh token rotations
+ALTER TABLE refresh_tokens ADD COLUMN session_id UUID;
+UPDATE refresh_tokens SET session_id = id;
+ALTER TABLE refresh_tokens ALTER COLUMN session_id SET NOT NULL;
+
+-- Label of the device that logged in, shown when listing sessions
+ALTER TABLE refresh_tokens ADD COLUMN device_label TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX refresh_tokens_user_session_idx ON refresh_tokens (user_id, session_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS refresh_tokens_user_session_idx;
+ALTER TABLE refresh_tokens DROP COLUMN device_label;
+ALTER TABLE refresh_tokens DROP COLUMN session_id;
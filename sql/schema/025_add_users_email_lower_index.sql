@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- Accounts registered before emails were normalized may differ only in case or
+-- surrounding spaces. Which of them keeps the email is for an operator to decide,
+-- so the migration stops and names them instead of changing any account.
+-- +goose StatementBegin
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(email || ': ' || ids, '; ')
+    INTO conflicts
+    FROM (
+        SELECT email, string_agg(id::TEXT, ', ' ORDER BY created_at, id) AS ids
+        FROM (SELECT id, created_at, lower(trim(email)) AS email FROM users WHERE email IS NOT NULL) normalized
+        GROUP BY email
+        HAVING count(*) > 1
+    ) duplicates;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'users share an email once lowercased and trimmed, merge or change them first: %', conflicts;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+UPDATE users SET email = lower(trim(email))
+WHERE email <> lower(trim(email));
+
+-- Logins look users up by lowercased email, which must identify one account
+CREATE UNIQUE INDEX users_email_lower_idx ON users (lower(email));
+
+-- +goose Down
+
+DROP INDEX IF EXISTS users_email_lower_idx;
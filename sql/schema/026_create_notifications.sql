@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- Outbox of realtime signals, so users who weren't connected can catch up
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    post_count INT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    acked_at TIMESTAMP
+);
+
+CREATE INDEX notifications_user_unacked_idx ON notifications (user_id, created_at) WHERE acked_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- Fingerprint of the client a session was started from, checked on refresh when
+-- REFRESH_TOKEN_BIND is set. Empty for tokens issued before it was recorded.
+ALTER TABLE refresh_tokens ADD COLUMN user_agent_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN ip_prefix TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+
+ALTER TABLE refresh_tokens DROP COLUMN ip_prefix;
+ALTER TABLE refresh_tokens DROP COLUMN user_agent_hash;
@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- Feeds with fetch_full_content set have each new post's article fetched and
+-- its main content extracted into posts.full_content
+ALTER TABLE feeds ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE posts ADD COLUMN full_content TEXT;
+
+-- +goose Down
+
+ALTER TABLE posts DROP COLUMN full_content;
+ALTER TABLE feeds DROP COLUMN fetch_full_content;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- Whether new posts of the followed feed notify the user, over the realtime hub and
+-- in the notifications outbox. Muted follows still see the posts in their feed.
+ALTER TABLE feed_follows ADD COLUMN notify BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- +goose Down
+
+ALTER TABLE feed_follows DROP COLUMN notify;
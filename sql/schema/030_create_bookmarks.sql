@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- Posts users saved for later; a bookmark outlives unfollowing the post's feed
+CREATE TABLE bookmarks (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX bookmarks_user_id_created_at_idx ON bookmarks (user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS bookmarks;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- Response headers of the fetch the feed's posts were last stored from. With
+-- FEED_HEAD_CHECK_ENABLED the scraper compares them to a HEAD request first and
+-- skips the full fetch when they match.
+ALTER TABLE feeds ADD COLUMN http_etag TEXT;
+ALTER TABLE feeds ADD COLUMN http_last_modified TEXT;
+ALTER TABLE feeds ADD COLUMN http_content_length BIGINT;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN http_content_length;
+ALTER TABLE feeds DROP COLUMN http_last_modified;
+ALTER TABLE feeds DROP COLUMN http_etag;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- Every refresh token a session rotated away, so a replay of any of them, not only
+-- the latest, is recognized and ends that session
+CREATE TABLE rotated_refresh_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX rotated_refresh_tokens_user_expires_at_idx ON rotated_refresh_tokens (user_id, expires_at);
+
+INSERT INTO rotated_refresh_tokens (token_hash, user_id, session_id, expires_at)
+SELECT previous_token_hash, user_id, session_id, expires_at
+FROM refresh_tokens
+WHERE previous_token_hash IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS rotated_refresh_tokens;
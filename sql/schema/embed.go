@@ -0,0 +1,9 @@
+// Package schema embeds the goose migrations so the API can apply them at startup
+package schema
+
+import "embed"
+
+// FS holds every migration file in this directory
+//
+//go:embed *.sql
+var FS embed.FS